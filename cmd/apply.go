@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"os"
 
 	"github.com/shapedthought/owlctl/config"
 	"github.com/shapedthought/owlctl/models"
@@ -48,6 +47,9 @@ Examples:
   # Dry run a group
   owlctl job apply --group sql-tier --dry-run
 
+  # Apply all job specs whose labels match a selector
+  owlctl job apply -l "env=prod,tier in (gold,silver)"
+
 Overlay Resolution:
   1. If --overlay is specified, use that overlay file
   2. If --env is specified, use overlay from owlctl.yaml for that environment
@@ -56,22 +58,22 @@ Overlay Resolution:
 `,
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if groupName != "" {
+		if groupName != "" || labelSelector != "" {
 			// Validate mutual exclusivity
 			if len(args) > 0 {
-				log.Fatal("Cannot use --group with a positional config file argument")
+				log.Fatal("Cannot use --group or --selector with a positional config file argument")
 			}
 			if overlayFile != "" {
-				log.Fatal("Cannot use --group with --overlay (group defines its own overlay)")
+				log.Fatal("Cannot use --group or --selector with --overlay (group defines its own overlay)")
 			}
 			if environment != "" {
-				log.Fatal("Cannot use --group with --env (group defines its own overlay)")
+				log.Fatal("Cannot use --group or --selector with --env (group defines its own overlay)")
 			}
 			applyGroup(groupName)
 		} else if len(args) > 0 {
 			applyJob(args[0])
 		} else {
-			log.Fatal("Provide a config file or use --group")
+			log.Fatal("Provide a config file, use --group, or use --selector")
 		}
 	},
 }
@@ -97,14 +99,15 @@ func applyJob(configFile string) {
 
 	// Determine which overlay to use
 	var finalSpec resources.ResourceSpec
+	var provenance map[string]string
 	if overlayFile != "" {
 		// Explicit overlay file provided
 		fmt.Printf("Applying overlay: %s\n", overlayFile)
-		mergedSpec, err := resources.MergeYAMLFiles(configFile, overlayFile, resources.DefaultMergeOptions())
+		mergedSpec, mergedProvenance, err := resources.MergeYAMLFilesWithProvenance(configFile, overlayFile, resources.DefaultMergeOptions())
 		if err != nil {
 			log.Fatalf("Failed to merge with overlay: %v", err)
 		}
-		finalSpec = mergedSpec
+		finalSpec, provenance = mergedSpec, mergedProvenance
 	} else if environment != "" || needsConfigOverlay() {
 		// Try to use overlay from owlctl.yaml
 		overlayPath, err := getConfiguredOverlay()
@@ -114,11 +117,11 @@ func applyJob(configFile string) {
 			finalSpec = baseSpec
 		} else {
 			fmt.Printf("Applying environment overlay: %s\n", overlayPath)
-			mergedSpec, err := resources.MergeYAMLFiles(configFile, overlayPath, resources.DefaultMergeOptions())
+			mergedSpec, mergedProvenance, err := resources.MergeYAMLFilesWithProvenance(configFile, overlayPath, resources.DefaultMergeOptions())
 			if err != nil {
 				log.Fatalf("Failed to merge with configured overlay: %v", err)
 			}
-			finalSpec = mergedSpec
+			finalSpec, provenance = mergedSpec, mergedProvenance
 		}
 	} else {
 		// No overlay specified
@@ -126,6 +129,10 @@ func applyJob(configFile string) {
 		finalSpec = baseSpec
 	}
 
+	if err := validateSpec(finalSpec, provenance); err != nil {
+		log.Fatal(err)
+	}
+
 	// Display merged configuration in dry-run mode
 	if dryRun {
 		fmt.Println("\n╔════════════════════════════════════════════════════════════════════╗")
@@ -155,7 +162,7 @@ func applyJob(configFile string) {
 			if err := json.Unmarshal(currentRaw, &currentMap); err != nil {
 				log.Fatalf("Failed to unmarshal current job: %v", err)
 			}
-			showJobDiff(finalSpec, currentMap)
+			showJobDiff(finalSpec, currentMap, provenance)
 		}
 
 		fmt.Println("\n=== End Dry Run ===")
@@ -165,8 +172,9 @@ func applyJob(configFile string) {
 	}
 
 	// Apply the job configuration
-	if err := applyVBRJob(finalSpec, profile); err != nil {
-		log.Fatalf("Failed to apply job: %v", err)
+	if err := applyVBRJob(finalSpec, profile, provenance); err != nil {
+		log.Printf("Failed to apply job: %v", err)
+		ciExit(ExitError)
 	}
 
 	fmt.Printf("\n✓ Successfully applied job: %s\n", finalSpec.Metadata.Name)
@@ -180,7 +188,7 @@ func applyGroup(group string) {
 	}
 	cfg.WarnDeprecatedFields()
 
-	groupCfg, err := cfg.GetGroup(group)
+	groupCfg, err := lookupGroup(cfg, group)
 	if err != nil {
 		log.Fatalf("Group error: %v", err)
 	}
@@ -189,55 +197,27 @@ func applyGroup(group string) {
 	profile := activateGroupInstance(cfg, groupCfg)
 
 	// Resolve effective specs (Specs + SpecsDir)
-	specsList := resolveGroupSpecs(cfg, groupCfg)
+	specsList := resolveGroupSpecs(cfg, groupCfg, resources.KindVBRJob)
 
 	if len(specsList) == 0 {
-		log.Fatalf("Group %q has no specs defined", group)
+		noGroupSpecs(group, resources.KindVBRJob)
 	}
 
-	// Resolve paths relative to owlctl.yaml
-	profilePath := ""
-	if groupCfg.Profile != "" {
-		profilePath = cfg.ResolvePath(groupCfg.Profile)
-	}
-	overlayPath := ""
-	if groupCfg.Overlay != "" {
-		overlayPath = cfg.ResolvePath(groupCfg.Overlay)
+	// Load the profile and overlay chains once to avoid repeated disk I/O
+	layers, err := loadGroupLayers(cfg, groupCfg)
+	if err != nil {
+		log.Fatalf("Group error: %v", err)
 	}
 
-	fmt.Printf("Applying group: %s (%d specs)\n", group, len(specsList))
+	fmt.Printf("Applying group: %s (%d specs)\n", groupLabel(group), len(specsList))
 	if instanceFlag != "" {
 		fmt.Printf("  Instance: %s (from --instance flag)\n", instanceFlag)
 	} else if groupCfg.Instance != "" {
 		fmt.Printf("  Instance: %s\n", groupCfg.Instance)
 	}
-	if profilePath != "" {
-		fmt.Printf("  Profile: %s\n", groupCfg.Profile)
-	}
-	if overlayPath != "" {
-		fmt.Printf("  Overlay: %s\n", groupCfg.Overlay)
-	}
+	layers.printHeader()
 	fmt.Println()
 
-	// Pre-load profile and overlay once to avoid repeated disk I/O
-	var profileSpec, overlaySpec *resources.ResourceSpec
-	opts := resources.DefaultMergeOptions()
-
-	if profilePath != "" {
-		p, err := resources.LoadResourceSpec(profilePath)
-		if err != nil {
-			log.Fatalf("Failed to load profile %s: %v", profilePath, err)
-		}
-		profileSpec = &p
-	}
-	if overlayPath != "" {
-		o, err := resources.LoadResourceSpec(overlayPath)
-		if err != nil {
-			log.Fatalf("Failed to load overlay %s: %v", overlayPath, err)
-		}
-		overlaySpec = &o
-	}
-
 	var results []GroupApplyResult
 
 	for _, specRelPath := range specsList {
@@ -252,13 +232,14 @@ func applyGroup(group string) {
 			continue
 		}
 
-		// Merge with cached profile/overlay
-		mergedSpec, err := resources.ApplyGroupMergeFromSpecs(spec, profileSpec, overlaySpec, opts)
+		// Merge with cached profile/overlay chains, tracking which layer set each field
+		merged, err := layers.mergeChain(spec, specRelPath)
 		if err != nil {
 			result.Error = fmt.Errorf("merge failed: %w", err)
 			results = append(results, result)
 			continue
 		}
+		mergedSpec := merged.Spec
 
 		result.ResourceName = mergedSpec.Metadata.Name
 
@@ -268,6 +249,11 @@ func applyGroup(group string) {
 			results = append(results, result)
 			continue
 		}
+		if err := validateSpec(mergedSpec, merged.Provenance); err != nil {
+			result.Error = err
+			results = append(results, result)
+			continue
+		}
 
 		// Check existence BEFORE apply to correctly determine created vs updated
 		existingRaw, _, err := fetchCurrentJob(mergedSpec.Metadata.Name, profile)
@@ -288,12 +274,13 @@ func applyGroup(group string) {
 				result.Action = "would-create"
 			} else {
 				fmt.Printf("  Found in VBR — would be updated\n")
+				printGroupDryRunChanges(existingRaw, mergedSpec, merged.Provenance)
 				result.Action = "would-update"
 			}
 			fmt.Println()
 		} else {
 			// Apply the job
-			if err := applyVBRJob(mergedSpec, profile); err != nil {
+			if err := applyVBRJob(mergedSpec, profile, merged.Provenance); err != nil {
 				result.Error = err
 			} else {
 				if existedBefore {
@@ -318,9 +305,9 @@ func applyGroup(group string) {
 	}
 
 	if successCount == 0 {
-		os.Exit(ExitError)
+		ciExit(ExitError)
 	} else if successCount < len(results) {
-		os.Exit(ExitPartialApply)
+		ciExit(ExitPartialApply)
 	}
 	// All succeeded — exit 0 (default)
 }
@@ -449,9 +436,29 @@ func prepareJobPayload(spec, existing map[string]interface{}) (map[string]interf
 	return spec, nil
 }
 
+// printGroupDryRunChanges lists the fields a group dry-run would change on an existing job,
+// with the profile, spec or overlay file that set each new value
+func printGroupDryRunChanges(existingRaw []byte, spec resources.ResourceSpec, provenance map[string]string) {
+	var existingMap map[string]interface{}
+	if err := json.Unmarshal(existingRaw, &existingMap); err != nil {
+		fmt.Printf("  Failed to parse current job: %v\n", err)
+		return
+	}
+
+	changes := computeFieldChanges(existingMap, spec.Spec, jobApplyConfig.IgnoreFields)
+	if len(changes) == 0 {
+		fmt.Println("  No changes detected.")
+		return
+	}
+	for _, change := range annotateChangeSources(changes, provenance) {
+		fmt.Printf("    ~ %s: %s -> %s%s\n", change.Path, applyFormatValue(change.OldValue), applyFormatValue(change.NewValue), changeSourceSuffix(change.Source))
+	}
+}
+
 // applyVBRJob creates or updates a VBR job based on the specification.
 // Delegates to the generic applyResourceSpec infrastructure.
-func applyVBRJob(spec resources.ResourceSpec, profile models.Profile) error {
+// provenance (may be nil) labels each applied change with the file that set it.
+func applyVBRJob(spec resources.ResourceSpec, profile models.Profile, provenance map[string]string) error {
 	// Ensure the payload name matches metadata.name so the API lookup key
 	// and the body sent to VBR stay consistent (e.g. after overlay changes).
 	if spec.Spec == nil {
@@ -461,7 +468,7 @@ func applyVBRJob(spec resources.ResourceSpec, profile models.Profile) error {
 		spec.Spec["name"] = spec.Metadata.Name
 	}
 
-	result := applyResourceSpec(spec, jobApplyConfig, profile, false, nil)
+	result := applyResourceSpec(spec, jobApplyConfig, profile, false, nil, provenance)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -473,6 +480,8 @@ func init() {
 	applyCmd.Flags().StringVar(&environment, "env", "", "Environment to use (looks up overlay from owlctl.yaml)")
 	applyCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview changes without applying them")
 	applyCmd.Flags().StringVar(&groupName, "group", "", "Apply all specs in named group (from owlctl.yaml)")
+	addSelectorFlag(applyCmd, "Apply specs whose labels match a selector; with --group, filters the group (e.g. env=prod)")
+	addApplyWaitFlags(applyCmd)
 
 	jobsCmd.AddCommand(applyCmd)
 }
@@ -6,13 +6,35 @@ import (
 	"reflect"
 	"sort"
 	"strings"
+
+	"github.com/shapedthought/owlctl/resources"
 )
 
 // FieldChange represents a single field that changed during apply
 type FieldChange struct {
-	Path     string      // Dotted path like "storage.retentionPolicy.quantity"
-	OldValue interface{} // Value before apply (from VBR)
-	NewValue interface{} // Value after apply (from spec)
+	Path     string      `json:"path"`             // Dotted path like "storage.retentionPolicy.quantity"
+	OldValue interface{} `json:"oldValue"`         // Value before apply (from VBR)
+	NewValue interface{} `json:"newValue"`         // Value after apply (from spec)
+	Source   string      `json:"source,omitempty"` // Layer (profile, spec or overlay file) that set NewValue, if known
+}
+
+// annotateChangeSources sets each change's Source from merge provenance (see resources.MergeChain)
+func annotateChangeSources(changes []FieldChange, provenance map[string]string) []FieldChange {
+	if len(provenance) == 0 {
+		return changes
+	}
+	for i := range changes {
+		changes[i].Source = resources.ProvenanceFor(provenance, changes[i].Path)
+	}
+	return changes
+}
+
+// changeSourceSuffix formats a change's source for display after its values
+func changeSourceSuffix(source string) string {
+	if source == "" {
+		return ""
+	}
+	return fmt.Sprintf("  (from %s)", source)
 }
 
 // computeFieldChanges compares two maps and returns the fields that differ.
@@ -163,9 +185,9 @@ func printApplyChanges(changes []FieldChange, resourceName string, success bool)
 		newStr := applyFormatValue(change.NewValue)
 
 		if success {
-			fmt.Printf("  Applied: %s: %s -> %s\n", change.Path, oldStr, newStr)
+			fmt.Printf("  Applied: %s: %s -> %s%s\n", change.Path, oldStr, newStr, changeSourceSuffix(change.Source))
 		} else {
-			fmt.Printf("  Attempted: %s: %s -> %s\n", change.Path, oldStr, newStr)
+			fmt.Printf("  Attempted: %s: %s -> %s%s\n", change.Path, oldStr, newStr, changeSourceSuffix(change.Source))
 		}
 	}
 
@@ -191,7 +213,7 @@ func printDryRunUpdate(resourceName, resourceKind string, changes []FieldChange)
 		for _, change := range changes {
 			oldStr := applyFormatValue(change.OldValue)
 			newStr := applyFormatValue(change.NewValue)
-			fmt.Printf("  ~ %s: %s -> %s\n", change.Path, oldStr, newStr)
+			fmt.Printf("  ~ %s: %s -> %s%s\n", change.Path, oldStr, newStr, changeSourceSuffix(change.Source))
 		}
 		fmt.Printf("\n%d field(s) would be changed.\n", len(changes))
 	}
@@ -375,8 +397,8 @@ func BuildKmsGuidance(resourceName, origin string) RemediationGuidance {
 
 // SkippedField represents a field that was skipped during apply
 type SkippedField struct {
-	Path   string
-	Reason string
+	Path   string `json:"path"`
+	Reason string `json:"reason,omitempty"`
 }
 
 // printSkippedFields prints fields that were skipped due to policy or known immutability
@@ -409,7 +431,7 @@ func printDryRunUpdateWithSkipped(resourceName, resourceKind string, changes []F
 			for _, change := range changes {
 				oldStr := applyFormatValue(change.OldValue)
 				newStr := applyFormatValue(change.NewValue)
-				fmt.Printf("  ~ %s: %s -> %s\n", change.Path, oldStr, newStr)
+				fmt.Printf("  ~ %s: %s -> %s%s\n", change.Path, oldStr, newStr, changeSourceSuffix(change.Source))
 			}
 			fmt.Printf("\n%d field(s) would be changed.\n", len(changes))
 		}
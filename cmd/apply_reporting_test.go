@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/shapedthought/owlctl/resources"
+)
+
+func TestAnnotateChangeSources(t *testing.T) {
+	provenance := map[string]string{
+		"description":                "specs/sql.yaml",
+		"storage.retention.quantity": "overlays/compliance.yaml",
+		"storage.retention.type":     "profiles/gold.yaml",
+	}
+	changes := []FieldChange{
+		{Path: "description", OldValue: "old", NewValue: "new"},
+		{Path: "storage.retention.quantity", OldValue: 7, NewValue: 30},
+		{Path: "storage.retention", OldValue: nil, NewValue: map[string]interface{}{}},
+		{Path: "schedule.daily", OldValue: nil, NewValue: "22:00"},
+	}
+
+	got := annotateChangeSources(changes, provenance)
+	want := []string{"specs/sql.yaml", "overlays/compliance.yaml", "overlays/compliance.yaml, profiles/gold.yaml", ""}
+	for i, w := range want {
+		if got[i].Source != w {
+			t.Errorf("changes[%d] (%s) Source = %q, want %q", i, got[i].Path, got[i].Source, w)
+		}
+	}
+
+	// No provenance leaves changes untouched
+	plain := annotateChangeSources([]FieldChange{{Path: "description"}}, nil)
+	if plain[0].Source != "" {
+		t.Errorf("Source = %q, want empty", plain[0].Source)
+	}
+}
+
+func TestPrintDryRunUpdateWithSources(t *testing.T) {
+	origStdout := os.Stdout
+	defer func() { os.Stdout = origStdout }()
+
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	printDryRunUpdateWithSkipped("SQL Backup", resources.KindVBRJob, []FieldChange{
+		{Path: "storage.retention.quantity", OldValue: 7.0, NewValue: 30, Source: "overlays/compliance.yaml"},
+		{Path: "description", OldValue: "a", NewValue: "b"},
+	}, nil)
+
+	w.Close()
+	os.Stdout = origStdout
+	out, _ := io.ReadAll(r)
+
+	if !strings.Contains(string(out), "~ storage.retention.quantity: 7 -> 30  (from overlays/compliance.yaml)") {
+		t.Errorf("missing sourced change:\n%s", out)
+	}
+	if !strings.Contains(string(out), "~ description: \"a\" -> \"b\"\n") {
+		t.Errorf("change without source should have no suffix:\n%s", out)
+	}
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os/user"
 	"strings"
@@ -42,6 +43,10 @@ type ResourceApplyConfig struct {
 	// Mode determines whether POST is allowed for new resources
 	Mode ApplyMode
 
+	// Singleton indicates the resource is updated via PUT to Endpoint itself
+	// (no ID path segment), e.g. configuration backup and global settings.
+	Singleton bool
+
 	// FetchCurrent retrieves the current resource from VBR by name.
 	// Returns (rawJSON, resourceID, error).
 	// If not found, returns (nil, "", nil) - not an error.
@@ -76,7 +81,7 @@ func applyResource(specFile string, cfg ResourceApplyConfig, profile models.Prof
 	if err != nil {
 		return ApplyResult{Error: fmt.Errorf("failed to load spec file: %w", err), DryRun: dryRun}
 	}
-	return applyResourceSpec(spec, cfg, profile, dryRun, nil)
+	return applyResourceSpec(spec, cfg, profile, dryRun, nil, nil)
 }
 
 // applyWithOptionalOverlay applies a resource spec with an optional overlay merge.
@@ -84,11 +89,11 @@ func applyResource(specFile string, cfg ResourceApplyConfig, profile models.Prof
 func applyWithOptionalOverlay(specFile, overlayFile string, cfg ResourceApplyConfig, profile models.Profile, dryRun bool) ApplyResult {
 	if overlayFile != "" {
 		fmt.Printf("Applying overlay: %s\n", overlayFile)
-		mergedSpec, err := resources.MergeYAMLFiles(specFile, overlayFile, resources.DefaultMergeOptions())
+		mergedSpec, provenance, err := resources.MergeYAMLFilesWithProvenance(specFile, overlayFile, resources.DefaultMergeOptions())
 		if err != nil {
 			return ApplyResult{Error: fmt.Errorf("failed to merge with overlay: %w", err), DryRun: dryRun}
 		}
-		return applyResourceSpec(mergedSpec, cfg, profile, dryRun, nil)
+		return applyResourceSpec(mergedSpec, cfg, profile, dryRun, nil, provenance)
 	}
 	return applyResource(specFile, cfg, profile, dryRun)
 }
@@ -98,22 +103,124 @@ func applyWithOptionalOverlay(specFile, overlayFile string, cfg ResourceApplyCon
 // If dryRun is true, it fetches current state (read-only) and displays what would change,
 // but makes no modifications to VBR and does not update state.
 // If cachedRemCfg is non-nil, it is used instead of loading remediation config from disk.
-func applyResourceSpec(spec resources.ResourceSpec, cfg ResourceApplyConfig, profile models.Profile, dryRun bool, cachedRemCfg *remediation.Config) ApplyResult {
-	result := ApplyResult{DryRun: dryRun}
+// provenance (from a profile/overlay merge, may be nil) labels each reported change with the
+// file that set it.
+func applyResourceSpec(spec resources.ResourceSpec, cfg ResourceApplyConfig, profile models.Profile, dryRun bool, cachedRemCfg *remediation.Config, provenance map[string]string) (result ApplyResult) {
+	result = ApplyResult{DryRun: dryRun}
 
 	result.ResourceName = spec.Metadata.Name
+	defer func() { reportApply(cfg.Kind, result) }()
+
+	change, err := planResourceChange(spec, cfg, profile, cachedRemCfg, provenance)
+	if err != nil {
+		var notFound resourceNotFoundError
+		result.NotFound = errors.As(err, &notFound)
+		result.Error = err
+		return result
+	}
+
+	if change.Action == "create" {
+		if dryRun {
+			// Dry-run mode: show what would be created
+			printDryRunCreate(spec.Metadata.Name, cfg.Kind, change.Payload)
+			result.Action = "would-create"
+			return result
+		}
+
+		// Create the resource
+		fmt.Printf("Creating new %s: %s\n", cfg.Kind, spec.Metadata.Name)
+		newID, err := executeResourceChange(change, cfg, profile)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		result.ResourceID = newID
+		result.Action = "created"
+		fmt.Printf("Created %s with ID: %s\n", cfg.Kind, newID)
+
+	} else {
+		// Resource exists: update it
+		result.ResourceID = change.ResourceID
+		result.Changes = change.Changes
+		result.Skipped = change.Skipped
+
+		if dryRun {
+			// Dry-run mode: show what would change (including skipped)
+			printDryRunUpdateWithSkipped(spec.Metadata.Name, cfg.Kind, result.Changes, result.Skipped)
+			result.Action = "would-update"
+			return result
+		}
+
+		// Print changes being applied and skipped
+		printApplyChanges(result.Changes, spec.Metadata.Name, true)
+		printSkippedFields(result.Skipped)
+
+		// PUT the updated resource
+		if _, err := executeResourceChange(change, cfg, profile); err != nil {
+			result.Error = err
+			return result
+		}
+
+		result.Action = "updated"
+	}
+
+	// Update state with origin: "applied" (skip in dry-run mode)
+	if !dryRun {
+		// Extract field names from changes for audit trail
+		changedFields := extractFieldNames(result.Changes)
+		// Use "created" action for new resources, "applied" for updates
+		action := "applied"
+		if result.Action == "created" {
+			action = "created"
+		}
+		if err := updateResourceStateWithAction(spec, result.ResourceID, cfg.Kind, changedFields, action); err != nil {
+			// Log warning but don't fail the apply
+			fmt.Printf("Warning: Failed to update state: %v\n", err)
+		}
+	}
+
+	return result
+}
+
+// resourceChange is what applying a spec would do to a VBR resource, computed without
+// modifying anything. Payload is the exact body that would be sent.
+type resourceChange struct {
+	Name       string // metadata.name, for output
+	Action     string // "create" or "update"
+	ResourceID string
+	Payload    map[string]interface{}
+	Changes    []FieldChange
+	Skipped    []SkippedField
+	Live       map[string]interface{} // The resource as fetched from VBR (nil when creating)
+}
+
+// resourceNotFoundError is returned when an update-only resource doesn't exist in VBR
+type resourceNotFoundError struct {
+	name string
+}
+
+func (e resourceNotFoundError) Error() string {
+	return fmt.Sprintf("resource '%s' not found in VBR (update-only mode)", e.name)
+}
+
+// planResourceChange fetches the live resource and computes the create or update payload for a
+// spec, filtering changes through the remediation policy. It makes no changes to VBR.
+func planResourceChange(spec resources.ResourceSpec, cfg ResourceApplyConfig, profile models.Profile, cachedRemCfg *remediation.Config, provenance map[string]string) (resourceChange, error) {
+	change := resourceChange{Name: spec.Metadata.Name}
 
 	// Validate resource kind
 	if spec.Kind != cfg.Kind {
-		result.Error = fmt.Errorf("invalid resource kind: expected %s, got %s", cfg.Kind, spec.Kind)
-		return result
+		return change, fmt.Errorf("invalid resource kind: expected %s, got %s", cfg.Kind, spec.Kind)
+	}
+	if err := validateSpec(spec, provenance); err != nil {
+		return change, err
 	}
 
 	// Fetch existing resource by name
 	existingRaw, existingID, err := cfg.FetchCurrent(spec.Metadata.Name, profile)
 	if err != nil {
-		result.Error = fmt.Errorf("failed to fetch current resource: %w", err)
-		return result
+		return change, fmt.Errorf("failed to fetch current resource: %w", err)
 	}
 
 	resourceExists := existingRaw != nil && existingID != ""
@@ -132,9 +239,7 @@ func applyResourceSpec(spec resources.ResourceSpec, cfg ResourceApplyConfig, pro
 		// Resource doesn't exist
 		if cfg.Mode == ApplyUpdateOnly {
 			// Update-only mode: error on missing resource
-			result.NotFound = true
-			result.Error = fmt.Errorf("resource '%s' not found in VBR (update-only mode)", spec.Metadata.Name)
-			return result
+			return change, resourceNotFoundError{name: spec.Metadata.Name}
 		}
 
 		// ApplyCreateOrUpdate mode: create new resource
@@ -145,120 +250,111 @@ func applyResourceSpec(spec resources.ResourceSpec, cfg ResourceApplyConfig, pro
 		if cfg.PreparePayload != nil {
 			cleanedSpec, err = cfg.PreparePayload(cleanedSpec, nil)
 			if err != nil {
-				result.Error = fmt.Errorf("failed to prepare payload: %w", err)
-				return result
+				return change, fmt.Errorf("failed to prepare payload: %w", err)
 			}
 		}
 
-		if dryRun {
-			// Dry-run mode: show what would be created
-			printDryRunCreate(spec.Metadata.Name, cfg.Kind, cleanedSpec)
-			result.Action = "would-create"
-			return result
-		}
-
-		// Create the resource
-		fmt.Printf("Creating new %s: %s\n", cfg.Kind, spec.Metadata.Name)
-		var newID string
-		if cfg.PostCreate != nil {
-			newID, err = cfg.PostCreate(cleanedSpec, profile, cfg.Endpoint)
-		} else {
-			newID, err = defaultPostCreate(cleanedSpec, profile, cfg.Endpoint)
-		}
-		if err != nil {
-			result.Error = fmt.Errorf("failed to create resource: %w", err)
-			return result
-		}
+		change.Action = "create"
+		change.Payload = cleanedSpec
+		return change, nil
+	}
 
-		result.ResourceID = newID
-		result.Action = "created"
-		fmt.Printf("Created %s with ID: %s\n", cfg.Kind, newID)
+	// Resource exists: update it
+	change.Action = "update"
+	change.ResourceID = existingID
 
-	} else {
-		// Resource exists: update it
-		result.ResourceID = existingID
+	// Parse existing resource into map
+	var existingMap map[string]interface{}
+	if err := json.Unmarshal(existingRaw, &existingMap); err != nil {
+		return change, fmt.Errorf("failed to parse existing resource: %w", err)
+	}
+	change.Live = existingMap
 
-		// Parse existing resource into map
-		var existingMap map[string]interface{}
-		if err := json.Unmarshal(existingRaw, &existingMap); err != nil {
-			result.Error = fmt.Errorf("failed to parse existing resource: %w", err)
-			return result
-		}
+	// Deep merge spec into existing (spec values override existing).
+	// Merge into a copy so Live keeps the resource as fetched.
+	var liveCopy map[string]interface{}
+	if err := json.Unmarshal(existingRaw, &liveCopy); err != nil {
+		return change, fmt.Errorf("failed to parse existing resource: %w", err)
+	}
+	mergedSpec, err := resources.DeepMergeMaps(liveCopy, spec.Spec)
+	if err != nil {
+		return change, fmt.Errorf("failed to merge specs: %w", err)
+	}
 
-		// Deep merge spec into existing (spec values override existing)
-		mergedSpec, err := resources.DeepMergeMaps(existingMap, spec.Spec)
-		if err != nil {
-			result.Error = fmt.Errorf("failed to merge specs: %w", err)
-			return result
-		}
+	// Remove ignored fields
+	mergedSpec = cleanSpec(mergedSpec, cfg.IgnoreFields)
 
-		// Remove ignored fields
-		mergedSpec = cleanSpec(mergedSpec, cfg.IgnoreFields)
+	// Compute field changes for reporting
+	allChanges := computeFieldChanges(existingMap, mergedSpec, cfg.IgnoreFields)
 
-		// Compute field changes for reporting
-		allChanges := computeFieldChanges(existingMap, mergedSpec, cfg.IgnoreFields)
+	// Filter changes based on remediation policy
+	toApply, toSkip := filterChangesWithRemediation(remediationCfg, cfg.Kind, allChanges)
+	change.Changes = annotateChangeSources(toApply, provenance)
+	change.Skipped = toSkip
 
-		// Filter changes based on remediation policy
-		toApply, toSkip := filterChangesWithRemediation(remediationCfg, cfg.Kind, allChanges)
-		result.Changes = toApply
-		result.Skipped = toSkip
+	// Restore existing values for skipped fields (don't change them)
+	mergedSpec = restoreSkippedFields(mergedSpec, existingMap, toSkip)
 
-		// Restore existing values for skipped fields (don't change them)
-		mergedSpec = restoreSkippedFields(mergedSpec, existingMap, toSkip)
+	// VBR API requires the id field in PUT request bodies. cleanSpec strips it
+	// (it's in IgnoreFields for drift/export), so restore it from the existing resource.
+	if id, ok := existingMap["id"]; ok {
+		mergedSpec["id"] = id
+	}
 
-		// VBR API requires the id field in PUT request bodies. cleanSpec strips it
-		// (it's in IgnoreFields for drift/export), so restore it from the existing resource.
-		if id, ok := existingMap["id"]; ok {
-			mergedSpec["id"] = id
+	// Apply payload transformation if defined
+	if cfg.PreparePayload != nil {
+		mergedSpec, err = cfg.PreparePayload(mergedSpec, existingMap)
+		if err != nil {
+			return change, fmt.Errorf("failed to prepare payload: %w", err)
 		}
+	}
 
-		// Apply payload transformation if defined
-		if cfg.PreparePayload != nil {
-			mergedSpec, err = cfg.PreparePayload(mergedSpec, existingMap)
-			if err != nil {
-				result.Error = fmt.Errorf("failed to prepare payload: %w", err)
-				return result
-			}
-		}
+	change.Payload = mergedSpec
+	return change, nil
+}
 
-		if dryRun {
-			// Dry-run mode: show what would change (including skipped)
-			printDryRunUpdateWithSkipped(spec.Metadata.Name, cfg.Kind, result.Changes, result.Skipped)
-			result.Action = "would-update"
-			return result
+// executeResourceChange sends a planned change to VBR: POST for a create, PUT for an update.
+// Returns the resource ID (the new ID for a create).
+func executeResourceChange(change resourceChange, cfg ResourceApplyConfig, profile models.Profile) (string, error) {
+	if change.Action == "create" {
+		var newID string
+		var err error
+		if cfg.PostCreate != nil {
+			newID, err = cfg.PostCreate(change.Payload, profile, cfg.Endpoint)
+		} else {
+			newID, err = defaultPostCreate(change.Payload, profile, cfg.Endpoint)
 		}
-
-		// Print changes being applied and skipped
-		printApplyChanges(result.Changes, spec.Metadata.Name, true)
-		printSkippedFields(result.Skipped)
-
-		// PUT the updated resource
-		endpoint := fmt.Sprintf("%s/%s", cfg.Endpoint, existingID)
-		_, err = vhttp.PutDataWithError(endpoint, mergedSpec, profile)
 		if err != nil {
-			result.Error = fmt.Errorf("failed to update resource: %w", err)
-			return result
+			return "", fmt.Errorf("failed to create resource: %w", err)
 		}
-
-		result.Action = "updated"
+		return newID, nil
 	}
 
-	// Update state with origin: "applied" (skip in dry-run mode)
-	if !dryRun {
-		// Extract field names from changes for audit trail
-		changedFields := extractFieldNames(result.Changes)
-		// Use "created" action for new resources, "applied" for updates
-		action := "applied"
-		if result.Action == "created" {
-			action = "created"
-		}
-		if err := updateResourceStateWithAction(spec, result.ResourceID, cfg.Kind, changedFields, action); err != nil {
-			// Log warning but don't fail the apply
-			fmt.Printf("Warning: Failed to update state: %v\n", err)
+	endpoint := fmt.Sprintf("%s/%s", cfg.Endpoint, change.ResourceID)
+	if cfg.Singleton {
+		endpoint = cfg.Endpoint
+	}
+	body, err := vhttp.PutDataWithError(endpoint, change.Payload, profile)
+	if err != nil {
+		return "", fmt.Errorf("failed to update resource: %w", err)
+	}
+	if session, ok := parseSessionResponse(body); ok {
+		if _, err := handleApplySession(change.Name, session, profile); err != nil {
+			return "", err
 		}
 	}
+	return change.ResourceID, nil
+}
 
-	return result
+// handleApplySession deals with a VBR session returned by an apply request. With --wait it
+// waits for the session and fails if the session failed; otherwise it reports the session.
+// It returns the finished session, or the session as started when it did not wait.
+func handleApplySession(name string, session *vbrSession, profile models.Profile) (*vbrSession, error) {
+	if !applyWait {
+		fmt.Printf("VBR session started: %s (use --wait to wait for it)\n", sessionName(session))
+		return session, nil
+	}
+	return waitForApplySession(name, session, applyWaitTimeout, profile)
 }
 
 // extractFieldNames returns just the field paths from a list of changes
@@ -381,6 +477,39 @@ func defaultPostCreate(spec map[string]interface{}, profile models.Profile, endp
 	if err != nil {
 		return "", err
 	}
+	name, _ := spec["name"].(string)
+	return createdResourceID(name, responseBytes, profile)
+}
+
+// createdResourceID returns the ID of a created resource from the POST response: the
+// resource itself, or a session whose resourceId is set once it finishes
+func createdResourceID(name string, responseBytes []byte, profile models.Profile) (string, error) {
+	// Asynchronous creates return a session; the new resource's ID is in the session
+	if session, ok := parseSessionResponse(responseBytes); ok {
+		var finished *vbrSession
+		var err error
+		if session.ResourceID == "" && !applyWait {
+			// The ID is usually set only when the session finishes, and state needs it, so
+			// the create waits for its session even without --wait, within a time limit
+			timeout := applyWaitTimeout
+			if timeout == 0 {
+				timeout = createWaitTimeout
+			}
+			finished, err = waitForApplySession(name, session, timeout, profile)
+			if err != nil && finished == nil {
+				return "", fmt.Errorf("%w; the resource may still be created in VBR, run apply again once the session finishes to record it in state", err)
+			}
+		} else {
+			finished, err = handleApplySession(name, session, profile)
+		}
+		if err != nil {
+			return "", err
+		}
+		if finished.ResourceID == "" {
+			return "", fmt.Errorf("session %s finished without a resource ID; run apply again to record the resource in state", sessionName(finished))
+		}
+		return finished.ResourceID, nil
+	}
 
 	// Extract ID from response
 	var response struct {
@@ -425,6 +554,7 @@ func updateResourceStateWithAction(spec resources.ResourceSpec, resourceID, reso
 		LastApplied:   time.Now(),
 		LastAppliedBy: currentUser,
 		Origin:        "applied",
+		Labels:        spec.Metadata.Labels,
 		Spec:          spec.Spec,
 		History:       existingHistory,
 	}
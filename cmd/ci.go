@@ -0,0 +1,436 @@
+package cmd
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shapedthought/owlctl/auth"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ciFormatFlag  string
+	ciSummaryFlag string
+)
+
+// ciOutput is set when CI output is enabled for the running command. Its methods are
+// no-ops on a nil reporter, so call sites do not need to check.
+var ciOutput *ciReporter
+
+// ciCommands are the commands that emit CI annotations, a task result and a job summary
+var ciCommands = map[string]bool{
+	"scan":       true,
+	"diff":       true,
+	"apply":      true,
+	"compliance": true,
+	"sobr-diff":  true,
+	"sobr-apply": true,
+	"kms-diff":   true,
+	"kms-apply":  true,
+}
+
+// ciReporter emits annotations in a CI platform's native format and collects them for
+// the job summary. Annotations are written to stderr, which GitHub Actions and Azure
+// DevOps scan for commands like stdout, so machine-readable stdout stays intact.
+type ciReporter struct {
+	format  string // github, ado, gitlab
+	command string
+	summary string // summary file path; empty for the platform default
+	out     io.Writer
+	issues  []ciIssue
+	done    bool
+}
+
+// ciIssue is one annotation: a drift, a skipped field, an apply failure or an incident
+type ciIssue struct {
+	Level    string // error, warning, notice
+	Category string // Drift, Skipped field, Apply failed, Incident
+	Resource string
+	Field    string
+	Message  string
+	Severity Severity
+}
+
+// detectCIFormat returns the CI format for the current environment, or "" outside CI
+func detectCIFormat() string {
+	if !auth.IsCI() {
+		return ""
+	}
+	switch {
+	case os.Getenv("GITHUB_ACTIONS") != "":
+		return "github"
+	case os.Getenv("TF_BUILD") != "":
+		return "ado"
+	case os.Getenv("GITLAB_CI") != "":
+		return "gitlab"
+	}
+	return ""
+}
+
+// configureCIOutput enables CI output for supported commands from --ci-format or the
+// detected platform
+func configureCIOutput(cmd *cobra.Command) error {
+	format := strings.ToLower(ciFormatFlag)
+	switch format {
+	case "":
+		format = detectCIFormat()
+	case "github", "ado", "gitlab", "none":
+	default:
+		return fmt.Errorf("invalid --ci-format %q (use github, ado, gitlab, or none)", ciFormatFlag)
+	}
+	if format == "" || format == "none" || !ciCommands[cmd.Name()] {
+		return nil
+	}
+	ciOutput = &ciReporter{format: format, command: cmd.CommandPath(), summary: ciSummaryFlag, out: os.Stderr}
+	return nil
+}
+
+// ciExit completes CI output and exits with code
+func ciExit(code int) {
+	ciOutput.complete(code)
+	os.Exit(code)
+}
+
+// driftLevel maps a drift severity to an annotation level
+func driftLevel(sev Severity) string {
+	switch sev {
+	case SeverityCritical:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	}
+	return "notice"
+}
+
+// drifts annotates each drift on a resource
+func (c *ciReporter) drifts(resource string, drifts []Drift) {
+	if c == nil {
+		return
+	}
+	for _, d := range drifts {
+		c.add(ciIssue{
+			Level:    driftLevel(d.Severity),
+			Category: "Drift",
+			Resource: resource,
+			Field:    d.Path,
+			Message:  strings.TrimSpace(formatDriftLine(d)),
+			Severity: d.Severity,
+		})
+	}
+}
+
+// skippedFields annotates fields an apply left unchanged because of policy or immutability
+func (c *ciReporter) skippedFields(resource string, skipped []SkippedField) {
+	if c == nil {
+		return
+	}
+	for _, s := range skipped {
+		msg := s.Path + " was not applied"
+		if s.Reason != "" {
+			msg += ": " + s.Reason
+		}
+		c.add(ciIssue{Level: "warning", Category: "Skipped field", Resource: resource, Field: s.Path, Message: msg})
+	}
+}
+
+// applyFailed annotates a resource that could not be applied
+func (c *ciReporter) applyFailed(resource string, err error) {
+	if c == nil || err == nil {
+		return
+	}
+	c.add(ciIssue{Level: "error", Category: "Apply failed", Resource: resource, Message: err.Error()})
+}
+
+// incident annotates a correlation incident
+func (c *ciReporter) incident(instance string, inc Incident) {
+	if c == nil {
+		return
+	}
+	msg := inc.Rule
+	if inc.Description != "" {
+		msg += ": " + inc.Description
+	}
+	c.add(ciIssue{
+		Level:    driftLevel(inc.Severity),
+		Category: "Incident",
+		Resource: instanceLabel(instance) + strings.Join(inc.Resources, ", "),
+		Message:  msg,
+		Severity: inc.Severity,
+	})
+}
+
+// scanReport annotates every drift and incident in a scan
+func (c *ciReporter) scanReport(r ScanReport) {
+	if c == nil {
+		return
+	}
+	for _, inst := range r.Instances {
+		if inst.Error != "" {
+			c.add(ciIssue{Level: "error", Category: "Scan failed", Resource: inst.Name, Message: inst.Error})
+		}
+		for _, kind := range inst.Kinds {
+			if kind.Error != "" {
+				c.add(ciIssue{Level: "error", Category: "Scan failed", Resource: instanceLabel(inst.Name) + kind.Kind, Message: kind.Error})
+			}
+			for _, res := range kind.Resources {
+				c.drifts(instanceLabel(inst.Name)+kind.Kind+"/"+res.Name, res.Drifts)
+			}
+		}
+		for _, inc := range inst.Incidents {
+			c.incident(inst.Name, inc)
+		}
+	}
+}
+
+func (c *ciReporter) add(issue ciIssue) {
+	c.issues = append(c.issues, issue)
+	switch c.format {
+	case "github":
+		fmt.Fprintf(c.out, "::%s title=%s::%s\n", issue.Level,
+			githubEscapeProperty(fmt.Sprintf("%s: %s", issue.Category, issue.Resource)),
+			githubEscapeData(issue.Message))
+	case "ado":
+		// Azure DevOps only has error and warning issues; notices stay in the summary
+		if issue.Level == "notice" {
+			return
+		}
+		fmt.Fprintf(c.out, "##vso[task.logissue type=%s]%s\n", issue.Level,
+			adoEscape(fmt.Sprintf("%s: %s: %s", issue.Category, issue.Resource, issue.Message)))
+	}
+	// GitLab has no log annotations; issues go to the Code Quality report on completion
+}
+
+// complete sets the task result and writes the job summary. It runs once per command.
+func (c *ciReporter) complete(code int) {
+	if c == nil || c.done {
+		return
+	}
+	c.done = true
+
+	if err := c.writeResult(code); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to set CI result: %v\n", err)
+	}
+	if err := c.writeSummary(code); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write CI job summary: %v\n", err)
+	}
+}
+
+// ciResult maps an exit code to succeeded, succeeded-with-issues or failed
+func ciResult(code int) string {
+	switch code {
+	case ExitSuccess:
+		return "succeeded"
+	case ExitDriftWarning, ExitPartialApply, ExitSessionWarning:
+		return "succeeded-with-issues"
+	}
+	return "failed"
+}
+
+func (c *ciReporter) writeResult(code int) error {
+	result := ciResult(code)
+	switch c.format {
+	case "github":
+		// GitHub has no task result beyond the exit code; expose it as step outputs
+		return appendToFile(os.Getenv("GITHUB_OUTPUT"), fmt.Sprintf("result=%s\nexit-code=%d\n", result, code))
+	case "ado":
+		switch result {
+		case "succeeded-with-issues":
+			fmt.Fprintf(c.out, "##vso[task.complete result=SucceededWithIssues;]%s\n", adoEscape(exitCodeSummary(code)))
+		case "failed":
+			fmt.Fprintf(c.out, "##vso[task.complete result=Failed;]%s\n", adoEscape(exitCodeSummary(code)))
+		}
+		fmt.Fprintf(c.out, "##vso[task.setvariable variable=owlctlResult]%s\n", result)
+	case "gitlab":
+		return writeCodeQualityReport(filepath.Join(ciWorkDir("CI_PROJECT_DIR"), "gl-code-quality-report.json"), c.issues)
+	}
+	return nil
+}
+
+func (c *ciReporter) writeSummary(code int) error {
+	var b strings.Builder
+	if err := renderReportMarkdown(&b, c.summaryBlocks(code)); err != nil {
+		return err
+	}
+
+	path := c.summary
+	switch {
+	case path != "":
+	case c.format == "github":
+		path = os.Getenv("GITHUB_STEP_SUMMARY")
+	case c.format == "ado":
+		path = filepath.Join(ciWorkDir("AGENT_TEMPDIRECTORY"), "owlctl-summary.md")
+	default:
+		path = filepath.Join(ciWorkDir("CI_PROJECT_DIR"), "owlctl-summary.md")
+	}
+	if path == "" {
+		return nil
+	}
+
+	// GitHub appends step summaries from every command; elsewhere the file is per command
+	if c.format == "github" {
+		if err := appendToFile(path, b.String()); err != nil {
+			return err
+		}
+	} else if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return err
+	}
+
+	if c.format == "ado" {
+		abs, err := filepath.Abs(path)
+		if err == nil {
+			path = abs
+		}
+		fmt.Fprintf(c.out, "##vso[task.uploadsummary]%s\n", path)
+	}
+	return nil
+}
+
+// summaryBlocks lays out the job summary
+func (c *ciReporter) summaryBlocks(code int) []reportBlock {
+	blocks := []reportBlock{
+		{Heading: 2, Text: c.command},
+		{Text: fmt.Sprintf("Result: %s (exit code %d)", exitCodeSummary(code), code)},
+	}
+	if len(c.issues) == 0 {
+		return blocks
+	}
+
+	var rows [][]string
+	for _, i := range c.issues {
+		level := string(i.Severity)
+		if level == "" {
+			level = strings.ToUpper(i.Level)
+		}
+		rows = append(rows, []string{level, i.Category, i.Resource, i.Message})
+	}
+	return append(blocks, reportBlock{Headers: []string{"Level", "Type", "Resource", "Detail"}, Rows: rows})
+}
+
+// exitCodeSummary describes an exit code for task results and summaries
+func exitCodeSummary(code int) string {
+	switch code {
+	case ExitSuccess:
+		return "Succeeded"
+	case ExitDriftWarning:
+		return "Drift detected"
+	case ExitDriftCritical:
+		return "CRITICAL drift detected"
+	case ExitPartialApply:
+		return "Partial apply"
+	case ExitResourceNotFound:
+		return "Resource not found"
+	case ExitSessionWarning:
+		return "Session finished with warnings"
+	case ExitSessionFailed:
+		return "Session failed"
+	}
+	return "Failed"
+}
+
+// codeQualityIssue is an entry in a GitLab Code Quality report
+type codeQualityIssue struct {
+	Description string              `json:"description"`
+	CheckName   string              `json:"check_name"`
+	Fingerprint string              `json:"fingerprint"`
+	Severity    string              `json:"severity"`
+	Location    codeQualityLocation `json:"location"`
+}
+
+type codeQualityLocation struct {
+	Path  string `json:"path"`
+	Lines struct {
+		Begin int `json:"begin"`
+	} `json:"lines"`
+}
+
+// writeCodeQualityReport writes issues as a GitLab Code Quality report, shown in merge
+// requests when the file is declared under artifacts:reports:codequality
+func writeCodeQualityReport(path string, issues []ciIssue) error {
+	report := make([]codeQualityIssue, 0, len(issues))
+	for _, i := range issues {
+		sum := sha1.Sum([]byte(i.Category + "|" + i.Resource + "|" + i.Field + "|" + i.Message))
+		entry := codeQualityIssue{
+			Description: fmt.Sprintf("%s: %s", i.Resource, i.Message),
+			CheckName:   "owlctl " + strings.ToLower(i.Category),
+			Fingerprint: hex.EncodeToString(sum[:]),
+			Severity:    codeQualitySeverity(i),
+		}
+		entry.Location.Path = i.Resource
+		entry.Location.Lines.Begin = 1
+		report = append(report, entry)
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func codeQualitySeverity(i ciIssue) string {
+	switch {
+	case i.Category == "Apply failed" || i.Category == "Scan failed":
+		return "blocker"
+	case i.Severity == SeverityCritical:
+		return "critical"
+	case i.Level == "warning":
+		return "major"
+	case i.Level == "notice":
+		return "info"
+	}
+	return "minor"
+}
+
+// ciWorkDir returns the directory named by env, or the working directory
+func ciWorkDir(env string) string {
+	if dir := os.Getenv(env); dir != "" {
+		return dir
+	}
+	return "."
+}
+
+func appendToFile(path, content string) error {
+	if path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// githubEscapeData escapes a workflow command message
+func githubEscapeData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	return strings.ReplaceAll(s, "\n", "%0A")
+}
+
+// githubEscapeProperty escapes a workflow command property value
+func githubEscapeProperty(s string) string {
+	s = githubEscapeData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	return strings.ReplaceAll(s, ",", "%2C")
+}
+
+// adoEscape escapes an Azure DevOps logging command message
+func adoEscape(s string) string {
+	s = strings.ReplaceAll(s, "%", "%AZP25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	return strings.ReplaceAll(s, "\n", "%0A")
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&ciFormatFlag, "ci-format", "", "CI annotations for scan, diff and apply: github, ado, gitlab, or none (default: detected in CI)")
+	rootCmd.PersistentFlags().StringVar(&ciSummaryFlag, "ci-summary", "", "Job summary file (default: the platform's summary location)")
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func ciTestDrift() Drift {
+	return Drift{Path: "isDisabled", Action: "modified", State: false, VBR: true, Severity: SeverityCritical}
+}
+
+func TestCIReporter_GitHub(t *testing.T) {
+	dir := t.TempDir()
+	summary := filepath.Join(dir, "summary.md")
+	output := filepath.Join(dir, "output")
+	t.Setenv("GITHUB_STEP_SUMMARY", summary)
+	t.Setenv("GITHUB_OUTPUT", output)
+
+	var buf bytes.Buffer
+	c := &ciReporter{format: "github", command: "owlctl job diff", out: &buf}
+	c.drifts("VBRJob/Nightly, SQL", []Drift{ciTestDrift()})
+	c.skippedFields("VBRRepository/Hardened", []SkippedField{{Path: "type", Reason: "Known immutable"}})
+	c.complete(ExitDriftCritical)
+	c.complete(ExitSuccess) // second call is ignored
+
+	for _, want := range []string{
+		"::error title=Drift%3A VBRJob/Nightly%2C SQL::CRITICAL ~ isDisabled: false (state) -> true (VBR)\n",
+		"::warning title=Skipped field%3A VBRRepository/Hardened::type was not applied: Known immutable\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Output missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	out, _ := os.ReadFile(output)
+	if string(out) != "result=failed\nexit-code=4\n" {
+		t.Errorf("Unexpected step outputs: %q", out)
+	}
+	md, _ := os.ReadFile(summary)
+	for _, want := range []string{"## owlctl job diff", "Result: CRITICAL drift detected (exit code 4)", "| CRITICAL | Drift | VBRJob/Nightly, SQL |"} {
+		if !strings.Contains(string(md), want) {
+			t.Errorf("Summary missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestCIReporter_ADO(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("AGENT_TEMPDIRECTORY", dir)
+
+	var buf bytes.Buffer
+	c := &ciReporter{format: "ado", command: "owlctl scan", out: &buf}
+	c.drifts("VBRJob/Nightly", []Drift{
+		{Path: "description", Action: "modified", State: "a", VBR: "b", Severity: SeverityInfo},
+		{Path: "retention", Action: "modified", State: float64(14), VBR: float64(7), Severity: SeverityWarning},
+	})
+	c.applyFailed("VBRKmsServer/kms", errors.New("HTTP 400: 100% invalid"))
+	c.complete(ExitDriftWarning)
+
+	out := buf.String()
+	if strings.Contains(out, "description") {
+		t.Errorf("INFO drift should not be logged as an issue:\n%s", out)
+	}
+	for _, want := range []string{
+		"##vso[task.logissue type=warning]Drift: VBRJob/Nightly: WARNING ~ retention: 14 (state) -> 7 (VBR)\n",
+		"##vso[task.logissue type=error]Apply failed: VBRKmsServer/kms: HTTP 400: 100%AZP25 invalid\n",
+		"##vso[task.complete result=SucceededWithIssues;]Drift detected\n",
+		"##vso[task.uploadsummary]" + filepath.Join(dir, "owlctl-summary.md") + "\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Output missing %q:\n%s", want, out)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "owlctl-summary.md")); err != nil {
+		t.Errorf("Summary file not written: %v", err)
+	}
+}
+
+func TestCIReporter_GitLabCodeQuality(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("CI_PROJECT_DIR", dir)
+
+	var buf bytes.Buffer
+	c := &ciReporter{format: "gitlab", command: "owlctl scan", out: &buf}
+	c.drifts("VBRJob/Nightly", []Drift{ciTestDrift()})
+	c.complete(ExitDriftCritical)
+
+	if buf.Len() != 0 {
+		t.Errorf("GitLab should not write log annotations, got:\n%s", buf.String())
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "gl-code-quality-report.json"))
+	if err != nil {
+		t.Fatalf("Code Quality report not written: %v", err)
+	}
+	var issues []codeQualityIssue
+	if err := json.Unmarshal(data, &issues); err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].Severity != "critical" || issues[0].Location.Path != "VBRJob/Nightly" || issues[0].Fingerprint == "" {
+		t.Errorf("Unexpected Code Quality report: %+v", issues)
+	}
+}
+
+func TestCIResult(t *testing.T) {
+	tests := map[int]string{
+		ExitSuccess:        "succeeded",
+		ExitDriftWarning:   "succeeded-with-issues",
+		ExitPartialApply:   "succeeded-with-issues",
+		ExitSessionWarning: "succeeded-with-issues",
+		ExitDriftCritical:  "failed",
+		ExitError:          "failed",
+	}
+	for code, want := range tests {
+		if got := ciResult(code); got != want {
+			t.Errorf("ciResult(%d) = %q, want %q", code, got, want)
+		}
+	}
+}
+
+func TestCIReporter_Nil(t *testing.T) {
+	var c *ciReporter
+	c.drifts("VBRJob/a", []Drift{ciTestDrift()})
+	c.skippedFields("VBRJob/a", []SkippedField{{Path: "x"}})
+	c.applyFailed("VBRJob/a", errors.New("x"))
+	c.complete(ExitError)
+}
+
+func TestConfigureCIOutput_Commands(t *testing.T) {
+	defer func() { ciOutput, ciFormatFlag = nil, "" }()
+	ciFormatFlag = "github"
+
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"job", "diff"}, true},
+		{[]string{"repo", "sobr-diff"}, true},
+		{[]string{"repo", "sobr-apply"}, true},
+		{[]string{"encryption", "kms-diff"}, true},
+		{[]string{"encryption", "kms-apply"}, true},
+		{[]string{"report", "sessions"}, false},
+	}
+	for _, tt := range tests {
+		c, _, err := rootCmd.Find(tt.args)
+		if err != nil || c.Name() != tt.args[len(tt.args)-1] {
+			t.Fatalf("command %v not found: %v", tt.args, err)
+		}
+		ciOutput = nil
+		if err := configureCIOutput(c); err != nil {
+			t.Fatal(err)
+		}
+		if got := ciOutput != nil; got != tt.want {
+			t.Errorf("CI output for %v = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
@@ -1,20 +1,7 @@
 package cmd
 
 import (
-	"encoding/json"
-	"fmt"
-	"log"
-	"os"
-	"os/user"
-	"time"
-
-	"github.com/shapedthought/owlctl/models"
 	"github.com/shapedthought/owlctl/resources"
-	"github.com/shapedthought/owlctl/state"
-	"github.com/shapedthought/owlctl/utils"
-	"github.com/shapedthought/owlctl/vhttp"
-	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 )
 
 // configBackupStateKey is the fixed state key for the singleton configuration backup resource.
@@ -23,19 +10,19 @@ const configBackupStateKey = "ConfigurationBackup"
 // configBackupEndpoint is the VBR API endpoint for configuration backup settings.
 const configBackupEndpoint = "configBackup"
 
-var (
-	configBackupExportOutput string
-	configBackupApplyOverlay string
-	configBackupApplyDryRun  bool
-	configBackupDiffSeverity string
-	configBackupSecurityOnly bool
-)
+// configBackupResource defines the configuration backup singleton
+var configBackupResource = SingletonResourceConfig{
+	Kind:         resources.KindVBRConfigurationBackup,
+	StateKey:     configBackupStateKey,
+	Endpoint:     configBackupEndpoint,
+	CommandName:  "config-backup",
+	DisplayName:  "Configuration backup settings",
+	IgnoreFields: configBackupIgnoreFields,
+	SeverityMap:  configBackupSeverityMap,
+}
 
 // configBackupCmd is the parent command for configuration backup management.
-var configBackupCmd = &cobra.Command{
-	Use:   "config-backup",
-	Short: "VBR configuration backup settings management",
-	Long: `Manage VBR configuration backup settings using declarative state.
+var configBackupCmd = newSingletonCmd(configBackupResource, "VBR configuration backup settings management", `Manage VBR configuration backup settings using declarative state.
 
 Configuration backup is a singleton resource — there is one set of settings per VBR server.
 No name or --all flag is required.
@@ -58,269 +45,8 @@ Subcommands:
     owlctl config-backup apply config-backup.yaml
     owlctl config-backup apply config-backup.yaml --dry-run
     owlctl config-backup apply config-backup.yaml --overlay prod-overlay.yaml
-`,
-}
-
-// ---- snapshot ----------------------------------------------------------------
-
-var configBackupSnapshotCmd = &cobra.Command{
-	Use:   "snapshot",
-	Short: "Snapshot configuration backup settings to state",
-	Long:  `Fetches the current VBR configuration backup settings and saves them to state.json.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		snapshotConfigBackup()
-	},
-}
-
-func snapshotConfigBackup() {
-	settings := utils.ReadSettings()
-	profile := utils.GetCurrentProfile()
-
-	if settings.SelectedProfile != "vbr" {
-		log.Fatal("This command only works with VBR at the moment.")
-	}
-
-	rawData := vhttp.GetData[json.RawMessage](configBackupEndpoint, profile)
-
-	if err := saveConfigBackupToState(rawData); err != nil {
-		log.Fatalf("Failed to save configuration backup state: %v", err)
-	}
-
-	stateMgr := state.NewManager()
-	fmt.Printf("Configuration backup settings snapshot saved.\nState: %s\n", stateMgr.GetStatePath())
-}
-
-func saveConfigBackupToState(rawData json.RawMessage) error {
-	var spec map[string]interface{}
-	if err := json.Unmarshal(rawData, &spec); err != nil {
-		return fmt.Errorf("failed to unmarshal data: %w", err)
-	}
-
-	stateMgr := state.NewManager()
-
-	currentUser := "unknown"
-	if usr, err := user.Current(); err == nil {
-		currentUser = usr.Username
-	}
-
-	var existingHistory []state.ResourceEvent
-	if existing, err := stateMgr.GetResource(configBackupStateKey); err == nil {
-		existingHistory = existing.History
-	}
-
-	resource := &state.Resource{
-		Type:          resources.KindVBRConfigurationBackup,
-		ID:            configBackupEndpoint,
-		Name:          configBackupStateKey,
-		LastApplied:   time.Now(),
-		LastAppliedBy: currentUser,
-		Origin:        "observed",
-		Spec:          spec,
-		History:       existingHistory,
-	}
-
-	resource.AddEvent(state.NewEvent("snapshotted", currentUser))
-
-	if err := stateMgr.UpdateResource(resource); err != nil {
-		return fmt.Errorf("failed to update state: %w", err)
-	}
-
-	return nil
-}
-
-// ---- diff --------------------------------------------------------------------
-
-var configBackupDiffCmd = &cobra.Command{
-	Use:   "diff",
-	Short: "Detect drift in configuration backup settings",
-	Long: `Compares the snapshotted configuration backup settings in state against the live VBR configuration.
-
-Exit codes:
-  0 = No drift
-  3 = Drift detected (INFO or WARNING)
-  4 = Critical drift detected
-  1 = Error
-`,
-	Run: func(cmd *cobra.Command, args []string) {
-		diffConfigBackup()
-	},
-}
-
-func diffConfigBackup() {
-	settings := utils.ReadSettings()
-	profile := utils.GetCurrentProfile()
-
-	if settings.SelectedProfile != "vbr" {
-		log.Fatal("This command only works with VBR at the moment.")
-	}
-
-	stateMgr := state.NewManager()
-	stateEntry, err := stateMgr.GetResource(configBackupStateKey)
-	if err != nil {
-		fmt.Println("No snapshot found for configuration backup. Run 'owlctl config-backup snapshot' first.")
-		os.Exit(ExitError)
-	}
-
-	if stateEntry.Type != resources.KindVBRConfigurationBackup {
-		log.Fatalf("State entry '%s' is not a configuration backup resource (type: %s).", configBackupStateKey, stateEntry.Type)
-	}
-
-	rawData := vhttp.GetData[json.RawMessage](configBackupEndpoint, profile)
-
-	var liveSpec map[string]interface{}
-	if err := json.Unmarshal(rawData, &liveSpec); err != nil {
-		log.Fatalf("Failed to parse configuration backup response: %v", err)
-	}
-
-	drifts := detectDrift(stateEntry.Spec, liveSpec, configBackupIgnoreFields)
-	drifts = classifyDrifts(drifts, configBackupSeverityMap)
-
-	minSev := SeverityInfo
-	if configBackupSecurityOnly {
-		minSev = SeverityWarning
-	} else if configBackupDiffSeverity != "" {
-		switch configBackupDiffSeverity {
-		case "critical":
-			minSev = SeverityCritical
-		case "warning":
-			minSev = SeverityWarning
-		}
-	}
-
-	drifts = filterDriftsBySeverity(drifts, minSev)
-
-	if len(drifts) == 0 {
-		fmt.Println(noDriftMessage("Configuration backup settings match state.", minSev))
-		os.Exit(ExitSuccess)
-	}
-
-	fmt.Println("Configuration Backup Settings drift:")
-	for _, d := range drifts {
-		printDriftWithSeverity(d)
-	}
-
-	os.Exit(exitCodeForDrifts(drifts))
-}
-
-// ---- export ------------------------------------------------------------------
-
-var configBackupExportCmd = &cobra.Command{
-	Use:   "export",
-	Short: "Export configuration backup settings to YAML",
-	Long:  `Fetches the current VBR configuration backup settings and exports them as a declarative YAML spec.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		exportConfigBackup()
-	},
-}
-
-func exportConfigBackup() {
-	settings := utils.ReadSettings()
-	profile := utils.GetCurrentProfile()
-
-	if settings.SelectedProfile != "vbr" {
-		log.Fatal("This command only works with VBR at the moment.")
-	}
-
-	rawData := vhttp.GetData[json.RawMessage](configBackupEndpoint, profile)
-
-	var spec map[string]interface{}
-	if err := json.Unmarshal(rawData, &spec); err != nil {
-		log.Fatalf("Failed to parse configuration backup response: %v", err)
-	}
-
-	// Strip runtime fields before export
-	for field := range configBackupIgnoreFields {
-		delete(spec, field)
-	}
-
-	resourceSpec := resources.ResourceSpec{
-		APIVersion: "owlctl.veeam.com/v1",
-		Kind:       resources.KindVBRConfigurationBackup,
-		Metadata: resources.Metadata{
-			Name: configBackupStateKey,
-		},
-		Spec: spec,
-	}
-
-	header := "# VBR Configuration Backup Settings\n# Singleton resource — one per VBR server\n#\n# Apply with: owlctl config-backup apply <file>\n\n"
-
-	yamlBytes, err := yaml.Marshal(resourceSpec)
-	if err != nil {
-		log.Fatalf("Failed to marshal to YAML: %v", err)
-	}
-
-	output := append([]byte(header), yamlBytes...)
-
-	if configBackupExportOutput != "" {
-		if err := os.WriteFile(configBackupExportOutput, output, 0644); err != nil {
-			log.Fatalf("Failed to write file: %v", err)
-		}
-		fmt.Printf("Configuration backup settings exported to %s\n", configBackupExportOutput)
-	} else {
-		fmt.Print(string(output))
-	}
-}
-
-// ---- apply -------------------------------------------------------------------
-
-var configBackupApplyCmd = &cobra.Command{
-	Use:   "apply <file>",
-	Short: "Apply configuration backup settings from a YAML file",
-	Long: `Applies configuration backup settings from a declarative YAML spec to VBR.
-
-Uses PUT /api/v1/configurationBackup to update settings.
-
-Supports --overlay for environment-specific overrides and --dry-run to preview changes.
-`,
-	Args: cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		settings := utils.ReadSettings()
-		profile := utils.GetCurrentProfile()
-
-		if settings.SelectedProfile != "vbr" {
-			log.Fatal("This command only works with VBR at the moment.")
-		}
-
-		cfg := ResourceApplyConfig{
-			Kind:         resources.KindVBRConfigurationBackup,
-			Endpoint:     configBackupEndpoint,
-			IgnoreFields: configBackupIgnoreFields,
-			Mode:         ApplyUpdateOnly,
-			FetchCurrent: func(name string, profile models.Profile) (json.RawMessage, string, error) {
-				rawData := vhttp.GetData[json.RawMessage](configBackupEndpoint, profile)
-				return rawData, configBackupEndpoint, nil
-			},
-		}
-
-		result := applyWithOptionalOverlay(args[0], configBackupApplyOverlay, cfg, profile, configBackupApplyDryRun)
-
-		if result.Error != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", result.Error)
-			outcome := DetermineApplyOutcome([]ApplyResult{result})
-			os.Exit(ExitCodeForOutcome(outcome))
-		}
-
-		if result.DryRun {
-			return // dry-run output already printed
-		}
-
-		fmt.Printf("\nSuccessfully updated configuration backup settings.\n")
-	},
-}
+`)
 
 func init() {
 	rootCmd.AddCommand(configBackupCmd)
-
-	configBackupCmd.AddCommand(configBackupSnapshotCmd)
-
-	configBackupCmd.AddCommand(configBackupDiffCmd)
-	configBackupDiffCmd.Flags().StringVar(&configBackupDiffSeverity, "severity", "", "Minimum severity to show (critical, warning, info)")
-	configBackupDiffCmd.Flags().BoolVar(&configBackupSecurityOnly, "security-only", false, "Show only WARNING and CRITICAL drifts")
-
-	configBackupCmd.AddCommand(configBackupExportCmd)
-	configBackupExportCmd.Flags().StringVarP(&configBackupExportOutput, "output", "o", "", "Output file path (default: stdout)")
-
-	configBackupCmd.AddCommand(configBackupApplyCmd)
-	configBackupApplyCmd.Flags().StringVar(&configBackupApplyOverlay, "overlay", "", "Overlay file to merge with the spec before applying")
-	configBackupApplyCmd.Flags().BoolVar(&configBackupApplyDryRun, "dry-run", false, "Preview changes without applying")
 }
@@ -0,0 +1,412 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/shapedthought/owlctl/resources"
+	"gopkg.in/yaml.v3"
+)
+
+// CorrelationRule raises an incident when drifts on several resources match together.
+// Attack patterns rarely show as one change: immutability reduced on a repository, retention
+// cut on jobs and configuration backup disabled are each one drift, but together they are
+// one incident.
+//
+// User rules are loaded from correlation-rules.yaml in OWLCTL_SETTINGS_PATH or ~/.owlctl/,
+// or from --correlation-rules. A user rule with the name of a built-in rule replaces it.
+//
+// Example:
+//
+//	rules:
+//	  - name: lab-retention-and-immutability
+//	    description: Retention cut while immutability was reduced
+//	    severity: CRITICAL
+//	    minMatches: 2
+//	    sameRepository: true
+//	    conditions:
+//	      - kind: VBRRepository
+//	        path: repository.makeRecentBackupsImmutableDays
+//	        change: decreased
+//	      - kind: VBRJob
+//	        path: storage.retentionPolicy.quantity
+//	        change: decreased
+type CorrelationRule struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	// Severity of the incident (default CRITICAL)
+	Severity Severity `yaml:"severity,omitempty"`
+	// MinMatches is the number of conditions that must match (default all)
+	MinMatches int `yaml:"minMatches,omitempty"`
+	// SameRepository links job drift to repository drift: a job drift only matches when the
+	// job's backup repository (from state) also matched, and a repository drift only when
+	// one of its jobs did. Drift on other kinds is not affected.
+	SameRepository bool                   `yaml:"sameRepository,omitempty"`
+	Disabled       bool                   `yaml:"disabled,omitempty"`
+	Conditions     []CorrelationCondition `yaml:"conditions"`
+}
+
+// CorrelationCondition matches drifts in a scan. Empty fields match anything.
+type CorrelationCondition struct {
+	// Kind is the resource kind (e.g. VBRJob)
+	Kind string `yaml:"kind,omitempty"`
+	// Path is a dotted field path; it also matches fields below it, and * matches one segment
+	Path string `yaml:"path,omitempty"`
+	// Action is modified, added or removed
+	Action string `yaml:"action,omitempty"`
+	// Change is the direction of the change: decreased, increased, disabled (became false)
+	// or enabled (became true)
+	Change      string   `yaml:"change,omitempty"`
+	MinSeverity Severity `yaml:"minSeverity,omitempty"`
+}
+
+// correlationRulesFile is the structure of correlation-rules.yaml
+type correlationRulesFile struct {
+	Rules []CorrelationRule `yaml:"rules"`
+}
+
+// Incident is a correlation rule match, listing the drifts that triggered it
+type Incident struct {
+	Rule        string          `json:"rule"`
+	Description string          `json:"description,omitempty"`
+	Severity    Severity        `json:"severity"`
+	Resources   []string        `json:"resources"`
+	Drifts      []IncidentDrift `json:"drifts"`
+}
+
+// IncidentDrift is a contributing drift and the resource it was found on
+type IncidentDrift struct {
+	Kind     string `json:"kind"`
+	Resource string `json:"resource"`
+	Drift    Drift  `json:"drift"`
+
+	id           string // Resource ID, for sameRepository
+	repositoryID string // Job's backup repository ID, for sameRepository
+}
+
+// builtinCorrelationRules are the ransomware-preparation patterns evaluated by default
+var builtinCorrelationRules = []CorrelationRule{
+	{
+		Name:           "immutability-and-retention-cut",
+		Description:    "Repository immutability reduced while retention was cut on its jobs",
+		SameRepository: true,
+		Conditions: []CorrelationCondition{
+			{Kind: "VBRRepository", Path: "repository.makeRecentBackupsImmutableDays", Change: "decreased"},
+			{Kind: "VBRJob", Path: "storage.retentionPolicy.quantity", Change: "decreased"},
+		},
+	},
+	{
+		Name:        "recovery-safeguards-weakened",
+		Description: "Several recovery safeguards weakened together: immutability, retention, backup encryption, configuration backup",
+		MinMatches:  3,
+		Conditions: []CorrelationCondition{
+			{Kind: "VBRRepository", Path: "repository.makeRecentBackupsImmutableDays", Change: "decreased"},
+			{Kind: "VBRScaleOutRepository", Path: "immutabilityMode"},
+			{Kind: "VBRJob", Path: "storage.retentionPolicy.quantity", Change: "decreased"},
+			{Kind: "VBRJob", Path: "storage.advancedSettings.storageData.encryption.isEnabled", Change: "disabled"},
+			{Kind: "VBRConfigurationBackup", Path: "isEnabled", Change: "disabled"},
+			{Kind: "VBRConfigurationBackup", Path: "encryption.isEnabled", Change: "disabled"},
+		},
+	},
+	{
+		Name:        "detection-and-alerting-disabled",
+		Description: "Detection, approval or alerting controls disabled together",
+		MinMatches:  2,
+		Conditions: []CorrelationCondition{
+			{Kind: resources.KindVBRMalwareDetection, Change: "disabled"},
+			{Kind: resources.KindVBRSecuritySettings, Change: "disabled", MinSeverity: SeverityCritical},
+			{Kind: resources.KindVBRSyslogServers, Action: "removed"},
+			{Kind: "VBREmailSettings", Change: "disabled"},
+		},
+	},
+	{
+		Name:        "privileged-access-and-jobs-disabled",
+		Description: "Privileged access granted while jobs were disabled or encryption keys removed",
+		MinMatches:  2,
+		Conditions: []CorrelationCondition{
+			{Kind: resources.KindVBRUserRoles, MinSeverity: SeverityCritical},
+			{Kind: "VBRJob", Path: "isDisabled", Change: "enabled"},
+			{Kind: "VBREncryptionPassword", Action: "removed"},
+		},
+	},
+}
+
+// loadCorrelationRules returns the built-in rules merged with user rules from
+// correlation-rules.yaml and the given extra file (if any)
+func loadCorrelationRules(extraFile string) ([]CorrelationRule, error) {
+	rules := append([]CorrelationRule{}, builtinCorrelationRules...)
+
+	paths := []string{}
+	if p := findCorrelationRules(); p != "" {
+		paths = append(paths, p)
+	}
+	if extraFile != "" {
+		paths = append(paths, extraFile)
+	}
+
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read correlation rules: %w", err)
+		}
+		user, err := parseCorrelationRules(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		rules = mergeCorrelationRules(rules, user)
+	}
+	return rules, nil
+}
+
+// findCorrelationRules returns the path of correlation-rules.yaml, or "" if there is none
+func findCorrelationRules() string {
+	if settingsPath := os.Getenv("OWLCTL_SETTINGS_PATH"); settingsPath != "" {
+		p := filepath.Join(settingsPath, "correlation-rules.yaml")
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		p := filepath.Join(home, ".owlctl", "correlation-rules.yaml")
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	return ""
+}
+
+// parseCorrelationRules parses and validates a correlation rules document
+func parseCorrelationRules(data []byte) ([]CorrelationRule, error) {
+	var file correlationRulesFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid correlation rules: %w", err)
+	}
+
+	for i := range file.Rules {
+		r := &file.Rules[i]
+		if r.Name == "" {
+			return nil, fmt.Errorf("rule %d: name is required", i+1)
+		}
+		if r.Disabled {
+			continue
+		}
+		if len(r.Conditions) == 0 {
+			return nil, fmt.Errorf("rule %s: at least one condition is required", r.Name)
+		}
+		if r.MinMatches < 0 || r.MinMatches > len(r.Conditions) {
+			return nil, fmt.Errorf("rule %s: minMatches must be between 1 and %d", r.Name, len(r.Conditions))
+		}
+		if r.Severity != "" {
+			r.Severity = Severity(strings.ToUpper(string(r.Severity)))
+			if severityRank(r.Severity) == 0 {
+				return nil, fmt.Errorf("rule %s: unknown severity %q (use CRITICAL, WARNING, or INFO)", r.Name, r.Severity)
+			}
+		}
+		for j := range r.Conditions {
+			c := &r.Conditions[j]
+			switch c.Action {
+			case "", "modified", "added", "removed":
+			default:
+				return nil, fmt.Errorf("rule %s: condition %d: unknown action %q (use modified, added, or removed)", r.Name, j+1, c.Action)
+			}
+			switch c.Change {
+			case "", "decreased", "increased", "disabled", "enabled":
+			default:
+				return nil, fmt.Errorf("rule %s: condition %d: unknown change %q (use decreased, increased, disabled, or enabled)", r.Name, j+1, c.Change)
+			}
+			if c.MinSeverity != "" {
+				c.MinSeverity = Severity(strings.ToUpper(string(c.MinSeverity)))
+				if severityRank(c.MinSeverity) == 0 {
+					return nil, fmt.Errorf("rule %s: condition %d: unknown minSeverity %q", r.Name, j+1, c.MinSeverity)
+				}
+			}
+		}
+	}
+	return file.Rules, nil
+}
+
+// mergeCorrelationRules adds user rules to base, replacing rules with the same name.
+// Disabled rules are removed.
+func mergeCorrelationRules(base, user []CorrelationRule) []CorrelationRule {
+	merged := append([]CorrelationRule{}, base...)
+	for _, u := range user {
+		replaced := false
+		for i := range merged {
+			if merged[i].Name == u.Name {
+				merged[i] = u
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, u)
+		}
+	}
+
+	var enabled []CorrelationRule
+	for _, r := range merged {
+		if !r.Disabled {
+			enabled = append(enabled, r)
+		}
+	}
+	return enabled
+}
+
+// correlateDrifts evaluates the rules against the drift found on one instance
+func correlateDrifts(rules []CorrelationRule, kinds []ScanKind) []Incident {
+	var incidents []Incident
+	for _, rule := range rules {
+		need := rule.MinMatches
+		if need == 0 {
+			need = len(rule.Conditions)
+		}
+
+		hits := make([][]IncidentDrift, len(rule.Conditions))
+		for i, cond := range rule.Conditions {
+			hits[i] = matchCondition(cond, kinds)
+		}
+		if rule.SameRepository {
+			hits = linkJobRepositories(hits)
+		}
+
+		matched := 0
+		var contributing []IncidentDrift
+		for _, h := range hits {
+			if len(h) > 0 {
+				matched++
+				contributing = append(contributing, h...)
+			}
+		}
+		if matched < need {
+			continue
+		}
+
+		sev := rule.Severity
+		if sev == "" {
+			sev = SeverityCritical
+		}
+		incidents = append(incidents, Incident{
+			Rule:        rule.Name,
+			Description: rule.Description,
+			Severity:    sev,
+			Resources:   incidentResources(contributing),
+			Drifts:      contributing,
+		})
+	}
+	return incidents
+}
+
+// matchCondition returns every drift in the scan that satisfies the condition
+func matchCondition(cond CorrelationCondition, kinds []ScanKind) []IncidentDrift {
+	var hits []IncidentDrift
+	for _, kind := range kinds {
+		if cond.Kind != "" && cond.Kind != kind.Kind {
+			continue
+		}
+		for _, res := range kind.Resources {
+			for _, d := range res.Drifts {
+				if driftMatchesCondition(cond, d) {
+					hits = append(hits, IncidentDrift{Kind: kind.Kind, Resource: res.Name, Drift: d, id: res.ID, repositoryID: res.RepositoryID})
+				}
+			}
+		}
+	}
+	return hits
+}
+
+// linkJobRepositories keeps the job drifts whose backup repository also drifted and the
+// repository drifts with a drifted job, for rules with sameRepository
+func linkJobRepositories(hits [][]IncidentDrift) [][]IncidentDrift {
+	repos := make(map[string]bool)
+	jobRepos := make(map[string]bool)
+	for _, h := range hits {
+		for _, d := range h {
+			switch {
+			case (d.Kind == "VBRRepository" || d.Kind == "VBRScaleOutRepository") && d.id != "":
+				repos[d.id] = true
+			case d.Kind == "VBRJob" && d.repositoryID != "":
+				jobRepos[d.repositoryID] = true
+			}
+		}
+	}
+
+	linked := make([][]IncidentDrift, len(hits))
+	for i, h := range hits {
+		for _, d := range h {
+			switch {
+			case d.Kind == "VBRJob" && !repos[d.repositoryID]:
+				continue
+			case (d.Kind == "VBRRepository" || d.Kind == "VBRScaleOutRepository") && !jobRepos[d.id]:
+				continue
+			}
+			linked[i] = append(linked[i], d)
+		}
+	}
+	return linked
+}
+
+func driftMatchesCondition(cond CorrelationCondition, d Drift) bool {
+	if cond.Path != "" && !fieldPathMatches(cond.Path, d.Path) {
+		return false
+	}
+	if cond.Action != "" && cond.Action != d.Action {
+		return false
+	}
+	if cond.MinSeverity != "" && severityRank(d.Severity) < severityRank(cond.MinSeverity) {
+		return false
+	}
+	if cond.Change != "" && !driftChangeIs(d, cond.Change) {
+		return false
+	}
+	return true
+}
+
+// fieldPathMatches reports whether a drift path is the pattern or a field below it. A *
+// segment in the pattern matches any one segment.
+func fieldPathMatches(pattern, path string) bool {
+	want := strings.Split(pattern, ".")
+	got := strings.Split(path, ".")
+	if len(got) < len(want) {
+		return false
+	}
+	for i, w := range want {
+		if w != "*" && w != got[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// driftChangeIs reports the direction of a drift's value change. A removed value counts as
+// decreased or disabled; an added one as increased or enabled.
+func driftChangeIs(d Drift, change string) bool {
+	switch change {
+	case "decreased":
+		return d.Action != "added" && toFloat64(d.VBR) < toFloat64(d.State)
+	case "increased":
+		return d.Action != "removed" && toFloat64(d.VBR) > toFloat64(d.State)
+	case "disabled":
+		return d.Action != "added" && toBool(d.State) && !toBool(d.VBR)
+	case "enabled":
+		return d.Action != "removed" && toBool(d.VBR) && !toBool(d.State)
+	}
+	return false
+}
+
+// incidentResources returns the distinct kind/name pairs of the contributing drifts
+func incidentResources(drifts []IncidentDrift) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, d := range drifts {
+		name := d.Kind + "/" + d.Resource
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
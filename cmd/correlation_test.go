@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+// ransomwareScan returns scan results with immutability reduced on a repository and
+// retention cut on a job that backs up to it
+func ransomwareScan() []ScanKind {
+	return []ScanKind{
+		{Kind: "VBRJob", Resources: []ScanResource{
+			{Name: "Nightly", ID: "job-1", RepositoryID: "repo-1", Drifts: []Drift{
+				{Path: "storage.retentionPolicy.quantity", Action: "modified", State: float64(14), VBR: float64(1), Severity: SeverityCritical},
+			}},
+			{Name: "Weekly"},
+		}},
+		{Kind: "VBRRepository", Resources: []ScanResource{
+			{Name: "Hardened", ID: "repo-1", Drifts: []Drift{
+				{Path: "repository.makeRecentBackupsImmutableDays", Action: "modified", State: float64(30), VBR: float64(7), Severity: SeverityCritical},
+			}},
+		}},
+	}
+}
+
+func TestCorrelateDrifts_ImmutabilityAndRetention(t *testing.T) {
+	incidents := correlateDrifts(builtinCorrelationRules, ransomwareScan())
+	if len(incidents) != 1 {
+		t.Fatalf("Expected 1 incident, got %d: %+v", len(incidents), incidents)
+	}
+
+	inc := incidents[0]
+	if inc.Rule != "immutability-and-retention-cut" || inc.Severity != SeverityCritical {
+		t.Errorf("Unexpected incident: %+v", inc)
+	}
+	if len(inc.Drifts) != 2 {
+		t.Errorf("Expected 2 contributing drifts, got %d", len(inc.Drifts))
+	}
+	if strings.Join(inc.Resources, ",") != "VBRJob/Nightly,VBRRepository/Hardened" {
+		t.Errorf("Unexpected resources: %v", inc.Resources)
+	}
+}
+
+func TestCorrelateDrifts_UnrelatedJob(t *testing.T) {
+	kinds := ransomwareScan()
+	// Retention cut on a job that backs up to another repository
+	kinds[0].Resources[0].RepositoryID = "repo-2"
+
+	for _, inc := range correlateDrifts(builtinCorrelationRules, kinds) {
+		if inc.Rule == "immutability-and-retention-cut" {
+			t.Errorf("Unrelated job and repository drift should not raise an incident: %+v", inc)
+		}
+	}
+
+	// A second, unrelated repository drift is not part of the incident
+	kinds = ransomwareScan()
+	kinds[1].Resources = append(kinds[1].Resources, ScanResource{Name: "Other", ID: "repo-2", Drifts: []Drift{
+		{Path: "repository.makeRecentBackupsImmutableDays", Action: "modified", State: float64(30), VBR: float64(0), Severity: SeverityCritical},
+	}})
+	incidents := correlateDrifts(builtinCorrelationRules, kinds)
+	if len(incidents) != 1 || strings.Join(incidents[0].Resources, ",") != "VBRJob/Nightly,VBRRepository/Hardened" {
+		t.Errorf("Unexpected incidents: %+v", incidents)
+	}
+}
+
+func TestCorrelateDrifts_DirectionMatters(t *testing.T) {
+	kinds := ransomwareScan()
+	// Retention increased rather than cut: no incident
+	kinds[0].Resources[0].Drifts[0].VBR = float64(30)
+
+	if incidents := correlateDrifts(builtinCorrelationRules, kinds); len(incidents) != 0 {
+		t.Errorf("Expected no incidents, got %+v", incidents)
+	}
+}
+
+func TestCorrelateDrifts_MinMatches(t *testing.T) {
+	rules := []CorrelationRule{{
+		Name:       "two-of-three",
+		Severity:   SeverityWarning,
+		MinMatches: 2,
+		Conditions: []CorrelationCondition{
+			{Kind: "VBRJob", Path: "storage.retentionPolicy"},
+			{Kind: "VBRRepository", MinSeverity: SeverityCritical},
+			{Kind: "VBRConfigurationBackup", Path: "isEnabled", Change: "disabled"},
+		},
+	}}
+
+	incidents := correlateDrifts(rules, ransomwareScan())
+	if len(incidents) != 1 || incidents[0].Severity != SeverityWarning {
+		t.Fatalf("Expected one WARNING incident, got %+v", incidents)
+	}
+
+	rules[0].MinMatches = 3
+	if incidents := correlateDrifts(rules, ransomwareScan()); len(incidents) != 0 {
+		t.Errorf("Expected no incidents with minMatches 3, got %+v", incidents)
+	}
+}
+
+func TestFieldPathMatches(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"isEnabled", "isEnabled", true},
+		{"isEnabled", "encryption.isEnabled", false},
+		{"encryption", "encryption.isEnabled", true},
+		{"*.isEnabled", "encryption.isEnabled", true},
+		{"storage.retentionPolicy", "storage.retentionPolicy.quantity", true},
+		{"storage.retentionPolicy.quantity", "storage.retentionPolicy", false},
+	}
+	for _, tt := range tests {
+		if got := fieldPathMatches(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("fieldPathMatches(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestDriftChangeIs(t *testing.T) {
+	tests := []struct {
+		drift  Drift
+		change string
+		want   bool
+	}{
+		{Drift{Action: "modified", State: true, VBR: false}, "disabled", true},
+		{Drift{Action: "modified", State: false, VBR: true}, "disabled", false},
+		{Drift{Action: "modified", State: false, VBR: true}, "enabled", true},
+		{Drift{Action: "removed", State: true}, "disabled", true},
+		{Drift{Action: "modified", State: float64(7), VBR: float64(3)}, "decreased", true},
+		{Drift{Action: "modified", State: float64(7), VBR: float64(9)}, "increased", true},
+		{Drift{Action: "added", VBR: float64(9)}, "decreased", false},
+	}
+	for _, tt := range tests {
+		if got := driftChangeIs(tt.drift, tt.change); got != tt.want {
+			t.Errorf("driftChangeIs(%+v, %q) = %v, want %v", tt.drift, tt.change, got, tt.want)
+		}
+	}
+}
+
+func TestParseCorrelationRules(t *testing.T) {
+	rules, err := parseCorrelationRules([]byte(`
+rules:
+  - name: custom
+    severity: warning
+    conditions:
+      - kind: VBRJob
+        path: isDisabled
+        change: enabled
+  - name: immutability-and-retention-cut
+    disabled: true
+`))
+	if err != nil {
+		t.Fatalf("parseCorrelationRules failed: %v", err)
+	}
+	if rules[0].Severity != SeverityWarning {
+		t.Errorf("Severity should be normalised to WARNING, got %q", rules[0].Severity)
+	}
+
+	merged := mergeCorrelationRules(builtinCorrelationRules, rules)
+	for _, r := range merged {
+		if r.Name == "immutability-and-retention-cut" {
+			t.Error("Disabled built-in rule should be removed")
+		}
+	}
+	if merged[len(merged)-1].Name != "custom" {
+		t.Errorf("Custom rule should be appended, got %q", merged[len(merged)-1].Name)
+	}
+}
+
+func TestParseCorrelationRules_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"missing name":  "rules:\n  - conditions: [{kind: VBRJob}]\n",
+		"no conditions": "rules:\n  - name: empty\n",
+		"bad change":    "rules:\n  - name: r\n    conditions: [{kind: VBRJob, change: sideways}]\n",
+		"bad action":    "rules:\n  - name: r\n    conditions: [{action: renamed}]\n",
+		"bad severity":  "rules:\n  - name: r\n    severity: HIGH\n    conditions: [{kind: VBRJob}]\n",
+		"minMatches":    "rules:\n  - name: r\n    minMatches: 3\n    conditions: [{kind: VBRJob}]\n",
+	}
+	for name, doc := range tests {
+		if _, err := parseCorrelationRules([]byte(doc)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestScanReportFinish_IncidentRaisesExitCode(t *testing.T) {
+	report := ScanReport{Instances: []ScanInstance{{
+		Kinds: []ScanKind{{Kind: "VBRJob", Resources: []ScanResource{
+			{Name: "job", Drifts: []Drift{{Severity: SeverityWarning}}},
+		}}},
+		Incidents: []Incident{{Rule: "r", Severity: SeverityCritical}},
+	}}}
+	report.finish()
+	if report.ExitCode != ExitDriftCritical {
+		t.Errorf("ExitCode = %d, want %d", report.ExitCode, ExitDriftCritical)
+	}
+}
+
+func TestLoadCorrelationRules_Example(t *testing.T) {
+	t.Setenv("OWLCTL_SETTINGS_PATH", t.TempDir())
+
+	rules, err := loadCorrelationRules("../examples/correlation-rules.yaml")
+	if err != nil {
+		t.Fatalf("Example rules failed to load: %v", err)
+	}
+	names := make(map[string]bool)
+	for _, r := range rules {
+		names[r.Name] = true
+	}
+	if names["recovery-safeguards-weakened"] || !names["jobs-disabled-and-offload-unencrypted"] {
+		t.Errorf("Unexpected rule set: %v", names)
+	}
+}
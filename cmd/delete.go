@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// deleteCmd represents the delete command
+var deleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Sends a DELETE command to API",
+	Long: `Sends a DELETE command to the selected profile.
+
+Note that owlctl does not ask for confirmation.
+
+Commands:
+owlctl delete jobs/<job-id>
+owlctl delete backupInfrastructure/repositories/<repo-id> -p deleteBackups=false
+
+	`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		res := sendImperative("DELETE", args[0], nil)
+		printImperativeResponse(res)
+	},
+}
+
+func init() {
+	addQueryFlags(deleteCmd)
+	addOutputFlags(deleteCmd)
+	rootCmd.AddCommand(deleteCmd)
+
+}
@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/shapedthought/owlctl/config"
+	"github.com/shapedthought/owlctl/resources"
+	"github.com/shapedthought/owlctl/utils"
+	"github.com/spf13/cobra"
+)
+
+// diffAgainst is the --against flag shared by the diff commands: "state" (the default) or
+// "specs"
+var diffAgainst string
+
+// addAgainstFlag registers the shared --against flag on a diff command
+func addAgainstFlag(c *cobra.Command) {
+	c.Flags().StringVar(&diffAgainst, "against", "", "Compare live VBR against: state (last apply or snapshot, default) or specs (merged spec files in the repository)")
+}
+
+// againstSpecs validates --against and reports whether a single-resource or --all diff
+// compares against the repository specs rather than state
+func againstSpecs() bool {
+	switch diffAgainst {
+	case "", "state":
+		return false
+	case "specs":
+		return true
+	default:
+		log.Fatalf("Invalid --against: %s (use state or specs)", diffAgainst)
+	}
+	return false
+}
+
+// checkGroupAgainst rejects --against state on --group and --selector diffs, which always
+// compare against the merged group specs
+func checkGroupAgainst() {
+	if diffAgainst == "state" {
+		log.Fatal("--group and --selector diffs compare against specs; omit --against or use --against specs")
+	}
+	againstSpecs()
+}
+
+// specTarget is the desired state of one resource declared in the repository
+type specTarget struct {
+	Name   string
+	Source string // "group <name>" or the spec file path
+	Spec   resources.ResourceSpec
+	Error  error
+}
+
+// loadSpecTargets returns the desired state of every resource of a kind declared in the
+// repository: each group's specs merged with the group's profile and overlay chains, then
+// the spec files that are in no group, as they are. Groups bound to an instance other than
+// the active one are skipped and returned by name. A resource declared more than once is
+// an error, since its desired state is ambiguous.
+func loadSpecTargets(cfg *config.VCLIConfig, dcfg GroupDiffConfig) (targets []specTarget, skippedGroups []string) {
+	kind := normalizeSpecKind(dcfg.Kind)
+	nameOf := func(spec resources.ResourceSpec) string {
+		if dcfg.StateKey != "" {
+			return dcfg.StateKey
+		}
+		return spec.Metadata.Name
+	}
+	active := os.Getenv("OWLCTL_ACTIVE_INSTANCE")
+	grouped := make(map[string]bool)
+	byName := make(map[string]int)
+
+	add := func(t specTarget) {
+		if t.Error == nil {
+			if i, ok := byName[t.Name]; ok {
+				targets[i].Error = fmt.Errorf("declared in both %s and %s", targets[i].Source, t.Source)
+				return
+			}
+			byName[t.Name] = len(targets)
+		}
+		targets = append(targets, t)
+	}
+
+	for _, name := range cfg.ListGroups() {
+		groupCfg, err := cfg.GetGroup(name)
+		if err != nil {
+			continue
+		}
+		// Specs of every group, including skipped ones, are not standalone specs
+		if paths, err := cfg.ResolveGroupSpecs(groupCfg); err == nil {
+			for _, p := range paths {
+				grouped[filepath.Clean(cfg.ResolvePath(p))] = true
+			}
+		}
+		if groupCfg.Instance != "" && groupCfg.Instance != active {
+			skippedGroups = append(skippedGroups, fmt.Sprintf("%s (instance %s)", name, groupCfg.Instance))
+			continue
+		}
+
+		source := "group " + name
+		merged, err := loadMergedGroupSpecs(cfg, groupCfg)
+		if err != nil {
+			add(specTarget{Name: name, Source: source, Error: err})
+			continue
+		}
+		for _, m := range merged {
+			switch {
+			case m.Error != nil:
+				add(specTarget{Name: m.SpecPath, Source: source, Error: m.Error})
+			case normalizeSpecKind(m.Spec.Kind) == kind:
+				add(specTarget{Name: nameOf(m.Spec), Source: source, Spec: m.Spec})
+			}
+		}
+	}
+
+	// Specs outside groups use the top-level and instance variables
+	if err := configureSpecVariables(cfg, nil); err != nil {
+		log.Fatalf("Variable error: %v", err)
+	}
+	files, err := cfg.ResourceSpecFiles()
+	if err != nil {
+		add(specTarget{Name: cfg.ConfigDir, Source: cfg.ConfigDir, Error: err})
+		return targets, skippedGroups
+	}
+	for _, f := range files {
+		path := cfg.ResolvePath(f)
+		if grouped[filepath.Clean(path)] {
+			continue
+		}
+		spec, err := resources.LoadResourceSpec(path)
+		switch {
+		case err != nil:
+			add(specTarget{Name: f, Source: f, Error: fmt.Errorf("failed to load spec: %w", err)})
+		case normalizeSpecKind(spec.Kind) == kind:
+			add(specTarget{Name: nameOf(spec), Source: f, Spec: spec})
+		}
+	}
+	return targets, skippedGroups
+}
+
+// normalizeSpecKind maps kind aliases to the kind the diff commands use
+func normalizeSpecKind(kind string) string {
+	if kind == resources.KindVBRSOBR {
+		return resources.KindVBRScaleOutRepository
+	}
+	return kind
+}
+
+// diffAgainstSpecs compares live VBR against the desired state declared in the repository
+// for one resource, or for every declared resource of the kind when name is empty. Unlike
+// state-based diff, a re-snapshot after an unauthorized change cannot hide the drift.
+func diffAgainstSpecs(dcfg GroupDiffConfig, name string) {
+	loadSeverityOverrides()
+	settings := utils.ReadSettings()
+	profile := utils.GetCurrentProfile()
+
+	if settings.SelectedProfile != "vbr" {
+		log.Fatal("This command only works with VBR at the moment.")
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("--against specs requires owlctl.yaml: %v", err)
+	}
+	cfg.WarnDeprecatedFields()
+
+	targets, skipped := loadSpecTargets(cfg, dcfg)
+	sel := parseLabelSelector()
+	var selected []specTarget
+	for _, t := range targets {
+		if name != "" && t.Name != name {
+			continue
+		}
+		if t.Error == nil && !sel.Matches(t.Spec.Metadata.Labels) {
+			continue
+		}
+		selected = append(selected, t)
+	}
+	if name != "" && len(selected) == 0 {
+		fmt.Printf("No %s named '%s' is declared in the specs under %s\n", dcfg.DisplayName, name, cfg.ConfigDir)
+		ciExit(ExitResourceNotFound)
+	}
+
+	plural := dcfg.pluralDisplayName()
+	fmt.Printf("Checking drift against specs: %d %s declared in %s\n", len(selected), plural, cfg.ConfigDir)
+	for _, g := range skipped {
+		fmt.Printf("  Skipping group %s\n", g)
+	}
+	fmt.Println()
+
+	minSev := parseSeverityFlag()
+	cleanCount := 0
+	driftedCount := 0
+	notFoundCount := 0
+	errorCount := 0
+	var allDrifts []Drift
+
+	for _, t := range selected {
+		if t.Error != nil {
+			fmt.Printf("  %s (%s): %v\n", t.Name, t.Source, t.Error)
+			errorCount++
+			continue
+		}
+
+		currentRaw, _, err := dcfg.FetchCurrent(t.Name, profile)
+		if err != nil {
+			fmt.Printf("  %s: Failed to fetch current: %v\n", t.Name, err)
+			errorCount++
+			continue
+		}
+		if currentRaw == nil {
+			fmt.Printf("  %s: Not found in VBR (would be created by apply from %s)\n", t.Name, t.Source)
+			notFoundCount++
+			continue
+		}
+
+		var currentMap map[string]interface{}
+		if err := json.Unmarshal(currentRaw, &currentMap); err != nil {
+			fmt.Printf("  %s: Failed to unmarshal current data: %v\n", t.Name, err)
+			errorCount++
+			continue
+		}
+
+		drifts := dcfg.detect(t.Spec.Spec, currentMap)
+		drifts = applySeverityRules(dcfg.Kind, t.Name, t.Spec.Metadata.Labels, drifts)
+		drifts = suppressAckedDrifts(t.Name, drifts)
+		drifts = filterDriftsBySeverity(drifts, minSev)
+
+		if len(drifts) > 0 {
+			fmt.Printf("  %s %s: %d drifts detected (%s)\n", getMaxSeverity(drifts), t.Name, len(drifts), t.Source)
+			reportDrifts(dcfg.Kind, t.Name, drifts)
+			for _, d := range drifts {
+				// Drift lines label the desired value "state"; here it comes from the spec
+				fmt.Println("  " + formatDriftLine(d))
+			}
+			allDrifts = append(allDrifts, drifts...)
+			driftedCount++
+		} else {
+			fmt.Printf("  %s: No drift (%s)\n", t.Name, t.Source)
+			cleanCount++
+		}
+	}
+
+	if driftedCount > 0 {
+		fmt.Println()
+		printSecuritySummary(allDrifts)
+	}
+
+	fmt.Printf("\nSummary:\n")
+	fmt.Printf("  - %d %s match their specs\n", cleanCount, plural)
+	if driftedCount > 0 {
+		fmt.Printf("  - %d %s drifted from their specs — remediate by applying the group or spec shown for each\n", driftedCount, plural)
+	}
+	if notFoundCount > 0 {
+		fmt.Printf("  - %d %s not found in VBR (would be created by apply)\n", notFoundCount, plural)
+	}
+	if errorCount > 0 {
+		fmt.Printf("  - %d specs failed to evaluate (see errors above)\n", errorCount)
+	}
+
+	if errorCount > 0 {
+		ciExit(ExitError)
+	}
+	if driftedCount > 0 {
+		ciExit(exitCodeForDrifts(allDrifts))
+	}
+	ciExit(ExitSuccess)
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shapedthought/owlctl/config"
+	"github.com/shapedthought/owlctl/resources"
+)
+
+func TestLoadSpecTargets(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	job := func(name, extra string) string {
+		return "apiVersion: owlctl.veeam.com/v1\nkind: VBRJob\nmetadata:\n  name: " + name + "\nspec:\n  description: " + name + "\n" + extra
+	}
+
+	write("profiles/base.yaml", "apiVersion: owlctl.veeam.com/v1\nkind: Profile\nmetadata:\n  name: base\nspec:\n  isHighPriority: true\n  description: base\n")
+	write("specs/grouped.yaml", job("Grouped", ""))
+	write("specs/standalone.yaml", job("Standalone", ""))
+	write("specs/dup-a.yaml", job("Dup", ""))
+	write("specs/dup-b.yaml", job("Dup", ""))
+	write("specs/lab.yaml", job("Lab", ""))
+	write("specs/repo.yaml", "apiVersion: owlctl.veeam.com/v1\nkind: VBRRepository\nmetadata:\n  name: Repo\nspec:\n  description: repo\n")
+
+	cfg := &config.VCLIConfig{
+		ConfigDir: dir,
+		Groups: map[string]config.GroupConfig{
+			"prod": {Profile: "profiles/base.yaml", Specs: []string{"specs/grouped.yaml", "specs/dup-a.yaml"}},
+			"lab":  {Instance: "lab", Specs: []string{"specs/lab.yaml"}},
+		},
+	}
+	t.Setenv("OWLCTL_ACTIVE_INSTANCE", "prod")
+	defer resources.SetSpecVariables(nil, false)
+
+	targets, skipped := loadSpecTargets(cfg, jobDiffConfig)
+
+	if len(skipped) != 1 || !strings.HasPrefix(skipped[0], "lab ") {
+		t.Errorf("skipped = %v, want the lab group", skipped)
+	}
+
+	byName := make(map[string]specTarget)
+	for _, tt := range targets {
+		byName[tt.Name] = tt
+	}
+	if len(byName) != 3 {
+		t.Fatalf("targets = %+v, want Grouped, Dup and Standalone", targets)
+	}
+
+	grouped := byName["Grouped"]
+	if grouped.Source != "group prod" || grouped.Spec.Spec["isHighPriority"] != true || grouped.Spec.Spec["description"] != "Grouped" {
+		t.Errorf("Grouped = %+v, want the spec merged with the prod profile", grouped)
+	}
+	if standalone := byName["Standalone"]; standalone.Source != filepath.Join("specs", "standalone.yaml") || standalone.Error != nil {
+		t.Errorf("Standalone = %+v, want the standalone spec", standalone)
+	}
+	if dup := byName["Dup"]; dup.Error == nil || !strings.Contains(dup.Error.Error(), "declared in both") {
+		t.Errorf("Dup error = %v, want a duplicate declaration error", dup.Error)
+	}
+
+	// Singletons are reported under their state key
+	singleton := GroupDiffConfig{Kind: resources.KindVBRRepository, StateKey: "Repos"}
+	targets, _ = loadSpecTargets(cfg, singleton)
+	if len(targets) != 1 || targets[0].Name != "Repos" {
+		t.Errorf("targets = %+v, want one target named by the state key", targets)
+	}
+}
+
+func TestAgainstSpecs(t *testing.T) {
+	defer func() { diffAgainst = "" }()
+
+	for value, want := range map[string]bool{"": false, "state": false, "specs": true} {
+		diffAgainst = value
+		if got := againstSpecs(); got != want {
+			t.Errorf("againstSpecs() with %q = %v, want %v", value, got, want)
+		}
+	}
+}
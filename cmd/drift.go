@@ -20,11 +20,11 @@ const (
 
 // Drift represents a single configuration drift between state and live VBR
 type Drift struct {
-	Path     string
-	Action   string // "modified", "added", "removed"
-	State    interface{}
-	VBR      interface{}
-	Severity Severity
+	Path     string      `json:"path"`
+	Action   string      `json:"action"` // "modified", "added", "removed"
+	State    interface{} `json:"state,omitempty"`
+	VBR      interface{} `json:"vbr,omitempty"`
+	Severity Severity    `json:"severity"`
 }
 
 // SeverityMap maps field path segments to severity levels.
@@ -146,6 +146,30 @@ var configBackupIgnoreFields = map[string]bool{
 	"lastSuccessfulBackup": true,
 }
 
+// emailSettingsIgnoreFields defines fields to ignore during email settings drift detection
+var emailSettingsIgnoreFields = map[string]bool{
+	"password": true,
+}
+
+// trafficRulesIgnoreFields defines read-only fields to ignore during traffic rules drift detection
+var trafficRulesIgnoreFields = map[string]bool{
+	"id": true,
+}
+
+// generalOptionsIgnoreFields defines fields to ignore during general options drift detection.
+// Email settings are tracked separately by the VBREmailSettings singleton.
+var generalOptionsIgnoreFields = map[string]bool{
+	"emailSettings": true,
+}
+
+// securityIgnoreFields defines runtime fields to ignore during security configuration drift detection
+var securityIgnoreFields = map[string]bool{
+	"id":                   true,
+	"lastModified":         true,
+	"lastLogon":            true,
+	"signatureLastUpdated": true,
+}
+
 // --- Per-resource severity maps ---
 
 // jobSeverityMap classifies job drift fields by severity
@@ -253,6 +277,81 @@ var configBackupSeverityMap = SeverityMap{
 	"notifications":           SeverityInfo,
 }
 
+// emailSettingsSeverityMap classifies email notification settings drift fields by severity
+var emailSettingsSeverityMap = SeverityMap{
+	// WARNING — notifications silenced or redirected
+	"isEnabled":       SeverityWarning,
+	"notifyOnFailure": SeverityWarning,
+	"notifyOnWarning": SeverityWarning,
+	"to":              SeverityWarning,
+	"smtpServerName":  SeverityWarning,
+	// INFO — cosmetic or low-impact changes
+	"notifyOnSuccess": SeverityInfo,
+	"subject":         SeverityInfo,
+}
+
+// trafficRulesSeverityMap classifies network traffic rules drift fields by severity
+var trafficRulesSeverityMap = SeverityMap{
+	// WARNING — in-flight encryption or throttling rules changed
+	"rules":             SeverityWarning,
+	"encryptionEnabled": SeverityWarning,
+	// INFO — stream tuning
+	"useMultipleStreamsPerJob": SeverityInfo,
+	"uploadStreamsCount":       SeverityInfo,
+}
+
+// generalOptionsSeverityMap classifies global option drift fields by severity
+var generalOptionsSeverityMap = SeverityMap{
+	// WARNING — audit trail and event visibility reduced
+	"sessionHistoryRetention": SeverityWarning,
+	"siemIntegration":         SeverityWarning,
+	"eventForwarding":         SeverityWarning,
+	// INFO — storage threshold notifications
+	"notifications": SeverityInfo,
+}
+
+// malwareDetectionSeverityMap classifies malware detection settings drift fields by severity.
+// Detection toggles switched off are escalated to CRITICAL by enhanceSecurityToggleSeverity.
+var malwareDetectionSeverityMap = SeverityMap{
+	// CRITICAL — detection coverage removed
+	"isEnabled":           SeverityCritical,
+	"signatureDetection":  SeverityCritical,
+	"encryptionDetection": SeverityCritical,
+	// WARNING — detection weakened
+	"sensitivityLevel": SeverityWarning,
+	"fileIndexing":     SeverityWarning,
+	"exclusions":       SeverityWarning,
+}
+
+// securitySettingsSeverityMap classifies four-eyes and MFA settings drift fields by severity
+var securitySettingsSeverityMap = SeverityMap{
+	// CRITICAL — approval and authentication controls removed
+	"isFourEyesAuthorizationEnabled": SeverityCritical,
+	"fourEyesAuthorization":          SeverityCritical,
+	"isMfaEnabled":                   SeverityCritical,
+	"mfa":                            SeverityCritical,
+	// WARNING — session controls weakened
+	"isAutoLogoffEnabled": SeverityWarning,
+	"autoLogoffTimeout":   SeverityWarning,
+}
+
+// userRolesSeverityMap classifies user and role assignment drift fields by severity.
+// New privileged users and role escalations are raised to CRITICAL by enhanceUserRoleDriftSeverity.
+var userRolesSeverityMap = SeverityMap{
+	"roles":            SeverityWarning,
+	"type":             SeverityWarning,
+	"isServiceAccount": SeverityWarning,
+}
+
+// syslogServersSeverityMap classifies syslog server drift fields by severity.
+// Removed servers are raised to CRITICAL by enhanceSyslogDriftSeverity.
+var syslogServersSeverityMap = SeverityMap{
+	"serverName":  SeverityWarning,
+	"port":        SeverityWarning,
+	"protocol":    SeverityWarning,
+	"certificate": SeverityWarning,
+}
+
 // --- Drift detection ---
 
 // detectDrift compares state spec against live VBR config, ignoring specified fields
@@ -396,19 +495,27 @@ func noDriftMessage(resourceDesc string, minSev Severity) string {
 
 // printDriftWithSeverity prints a single drift entry with its severity label
 func printDriftWithSeverity(drift Drift) {
+	if line := formatDriftLine(drift); line != "" {
+		fmt.Println(line)
+	}
+}
+
+// formatDriftLine renders a drift entry as printed by printDriftWithSeverity
+func formatDriftLine(drift Drift) string {
 	sev := string(drift.Severity)
 
 	switch drift.Action {
 	case "modified":
 		stateStr := formatValue(drift.State)
 		vbrStr := formatValue(drift.VBR)
-		fmt.Printf("  %s ~ %s: %s (state) -> %s (VBR)\n", sev, drift.Path, stateStr, vbrStr)
+		return fmt.Sprintf("  %s ~ %s: %s (state) -> %s (VBR)", sev, drift.Path, stateStr, vbrStr)
 	case "removed":
-		fmt.Printf("  %s - %s: Removed from VBR\n", sev, drift.Path)
+		return fmt.Sprintf("  %s - %s: Removed from VBR", sev, drift.Path)
 	case "added":
 		vbrStr := formatValue(drift.VBR)
-		fmt.Printf("  %s + %s: Added in VBR (value: %s)\n", sev, drift.Path, vbrStr)
+		return fmt.Sprintf("  %s + %s: Added in VBR (value: %s)", sev, drift.Path, vbrStr)
 	}
+	return ""
 }
 
 func formatValue(value interface{}) string {
@@ -0,0 +1,399 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/shapedthought/owlctl/state"
+	"github.com/spf13/cobra"
+)
+
+// History and event actions for acknowledgement changes
+const (
+	ackAction   = "acknowledged"
+	unackAction = "unacknowledged"
+)
+
+var (
+	ackUntil  string
+	ackReason string
+	acksAll   bool
+	acksPrune bool
+	acksFmt   string
+)
+
+var driftAckCmd = &cobra.Command{
+	Use:   "ack <resource> <path>",
+	Short: "Accept drift on a resource field until a date",
+	Long: `Acknowledges known drift on a field of a resource in state. While the
+acknowledgement is active, diff and scan do not report drift on that field or on
+any field below it, and it does not count towards the exit code. Once it expires
+the drift is reported again.
+
+The path is the dotted field path shown in drift output, for example
+storage.retentionPolicy.quantity. Acknowledging storage.retentionPolicy covers
+every field below it.
+
+--until takes a date (YYYY-MM-DD, expiring at 00:00 UTC on that date), an RFC 3339
+timestamp, or a duration from now such as 30d or 12h. A reason is required.
+Acknowledging the same resource and path again replaces the earlier entry.
+
+Acknowledgements are stored in state for the active instance. Use
+'owlctl drift acks' to list them and 'owlctl drift unack' to remove them.
+
+Examples:
+  owlctl drift ack "Nightly SQL" storage.retentionPolicy.quantity --until 2026-11-01 --reason "CHG-1042 storage migration"
+  owlctl drift ack EmailSettings isEnabled --until 14d --reason "Mail relay replacement"
+`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		runDriftAck(args[0], args[1])
+	},
+}
+
+var driftAcksCmd = &cobra.Command{
+	Use:   "acks",
+	Short: "List drift acknowledgements",
+	Long: `Lists the drift acknowledgements of the active instance. Expired
+acknowledgements are listed until they are pruned; they no longer suppress drift.
+
+Examples:
+  owlctl drift acks
+  owlctl drift acks --format json
+  owlctl drift acks --prune
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runDriftAcks()
+	},
+}
+
+var driftUnackCmd = &cobra.Command{
+	Use:   "unack <resource> [path]",
+	Short: "Remove drift acknowledgements",
+	Long: `Removes the acknowledgement on a resource field, or every acknowledgement on
+the resource when no path is given.
+
+Examples:
+  owlctl drift unack "Nightly SQL" storage.retentionPolicy.quantity
+  owlctl drift unack "Nightly SQL"
+`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := ""
+		if len(args) == 2 {
+			path = args[1]
+		}
+		runDriftUnack(args[0], path)
+	},
+}
+
+// AckStatus is an acknowledgement with its status at scan time and the number of drifts it
+// suppressed
+type AckStatus struct {
+	state.Acknowledgement
+	Status     string `json:"status"` // "active", "expired"
+	Suppressed int    `json:"suppressed"`
+}
+
+func runDriftAck(resource, path string) {
+	if ackReason == "" {
+		log.Fatal("--reason is required")
+	}
+	now := time.Now().UTC()
+	until, err := parseAckUntil(ackUntil, now)
+	if err != nil {
+		log.Fatalf("Invalid --until: %v", err)
+	}
+
+	stateMgr := state.NewManager()
+	r, err := stateMgr.GetResource(resource)
+	if err != nil {
+		log.Fatalf("Cannot acknowledge drift: %v", err)
+	}
+
+	ack := state.Acknowledgement{
+		Kind:      r.Type,
+		Resource:  r.Name,
+		Path:      path,
+		Until:     until,
+		Reason:    ackReason,
+		User:      currentUsername(),
+		CreatedAt: now,
+	}
+	if err := stateMgr.SetAck(ack); err != nil {
+		log.Fatalf("Failed to save acknowledgement: %v", err)
+	}
+	recordAckEvent(stateMgr, ackAction, ack)
+	fmt.Printf("Acknowledged drift on %s/%s %s until %s\n", ack.Kind, ack.Resource, ack.Path, ack.Until.Format("2006-01-02 15:04 UTC"))
+}
+
+func runDriftAcks() {
+	if acksFmt != "table" && acksFmt != "json" {
+		log.Fatalf("Invalid --format: %s (use table or json)", acksFmt)
+	}
+	stateMgr := state.NewManager()
+	now := time.Now().UTC()
+
+	if acksPrune {
+		var pruned []state.Acknowledgement
+		removed, err := stateMgr.RemoveAcks(func(a state.Acknowledgement) bool {
+			if a.Active(now) {
+				return false
+			}
+			pruned = append(pruned, a)
+			return true
+		})
+		if err != nil {
+			log.Fatalf("Failed to prune acknowledgements: %v", err)
+		}
+		for _, a := range pruned {
+			recordAckEvent(stateMgr, unackAction, a)
+		}
+		fmt.Printf("Removed %d expired acknowledgements\n", removed)
+		return
+	}
+
+	acks, err := stateMgr.ListAcks()
+	if err != nil {
+		log.Fatalf("Failed to load state: %v", err)
+	}
+	statuses := ackStatuses(acks, now)
+	if !acksAll {
+		var active []AckStatus
+		for _, s := range statuses {
+			if s.Status == "active" {
+				active = append(active, s)
+			}
+		}
+		statuses = active
+	}
+
+	if acksFmt == "json" {
+		if statuses == nil {
+			statuses = []AckStatus{}
+		}
+		if err := writeReportJSON(os.Stdout, statuses); err != nil {
+			log.Fatalf("Failed to write acknowledgements: %v", err)
+		}
+		return
+	}
+	if len(statuses) == 0 {
+		fmt.Println("No drift acknowledgements.")
+		return
+	}
+	printAcks(os.Stdout, statuses)
+}
+
+func runDriftUnack(resource, path string) {
+	stateMgr := state.NewManager()
+	var acks []state.Acknowledgement
+	removed, err := stateMgr.RemoveAcks(func(a state.Acknowledgement) bool {
+		if a.Resource != resource || (path != "" && a.Path != path) {
+			return false
+		}
+		acks = append(acks, a)
+		return true
+	})
+	if err != nil {
+		log.Fatalf("Failed to remove acknowledgement: %v", err)
+	}
+	if removed == 0 {
+		log.Fatalf("No acknowledgement found for %s %s", resource, path)
+	}
+	for _, a := range acks {
+		recordAckEvent(stateMgr, unackAction, a)
+	}
+	fmt.Printf("Removed %d acknowledgements\n", removed)
+}
+
+// recordAckEvent records an acknowledgement change in the resource's history, which also
+// forwards it to event sinks. Changes to acks on resources no longer in state are only
+// forwarded.
+func recordAckEvent(stateMgr *state.Manager, action string, ack state.Acknowledgement) {
+	ev := state.NewEventWithFields(action, currentUsername(), []string{ack.Path}, false)
+	ev.Note = fmt.Sprintf("%s until %s (%s)", ack.Path, ack.Until.UTC().Format("2006-01-02 15:04 UTC"), ack.Reason)
+
+	r, err := stateMgr.GetResource(ack.Resource)
+	if err != nil {
+		emitStateEvent("", &state.Resource{Type: ack.Kind, Name: ack.Resource}, ev)
+		return
+	}
+	r.AddEvent(ev)
+	if err := stateMgr.UpdateResource(r); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record %s in the history of %s: %v\n", action, ack.Resource, err)
+	}
+}
+
+// parseAckUntil parses --until as a date, an RFC 3339 timestamp or a duration from now.
+// The expiry must be in the future.
+func parseAckUntil(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, fmt.Errorf("--until is required")
+	}
+	var until time.Time
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		until = t
+	} else if t, err := time.Parse(time.RFC3339, value); err == nil {
+		until = t.UTC()
+	} else if d, err := parseRPODuration(value); err == nil {
+		until = now.Add(d)
+	} else {
+		return time.Time{}, fmt.Errorf("%q is not a date (YYYY-MM-DD), timestamp or duration (e.g. 30d)", value)
+	}
+	if !until.After(now) {
+		return time.Time{}, fmt.Errorf("%s is not in the future", until.Format("2006-01-02 15:04 UTC"))
+	}
+	return until, nil
+}
+
+// ackStatuses returns the acknowledgements sorted by resource and path with their status
+func ackStatuses(acks []state.Acknowledgement, now time.Time) []AckStatus {
+	var statuses []AckStatus
+	for _, a := range acks {
+		status := "expired"
+		if a.Active(now) {
+			status = "active"
+		}
+		statuses = append(statuses, AckStatus{Acknowledgement: a, Status: status})
+	}
+	sort.SliceStable(statuses, func(i, j int) bool {
+		if statuses[i].Resource != statuses[j].Resource {
+			return statuses[i].Resource < statuses[j].Resource
+		}
+		return statuses[i].Path < statuses[j].Path
+	})
+	return statuses
+}
+
+// coveringAck returns the index of the first active acknowledgement covering a field of the
+// named resource, or -1
+func coveringAck(acks []AckStatus, resource, path string) int {
+	for i, a := range acks {
+		if a.Status == "active" && a.Covers(resource, path) {
+			return i
+		}
+	}
+	return -1
+}
+
+// partitionAckedDrifts splits a resource's drift into unacknowledged and acknowledged drift,
+// counting the suppressed drifts on each acknowledgement
+func partitionAckedDrifts(acks []AckStatus, resource string, drifts []Drift) (kept, acked []Drift) {
+	for _, d := range drifts {
+		if i := coveringAck(acks, resource, d.Path); i >= 0 {
+			acks[i].Suppressed++
+			acked = append(acked, d)
+			continue
+		}
+		kept = append(kept, d)
+	}
+	return kept, acked
+}
+
+// applyScanAcks moves acknowledged drift out of each resource's drifts and returns the
+// instance's acknowledgements with their status
+func applyScanAcks(acks []state.Acknowledgement, kinds []ScanKind, now time.Time) []AckStatus {
+	statuses := ackStatuses(acks, now)
+	if len(statuses) == 0 {
+		return nil
+	}
+	for i := range kinds {
+		for j := range kinds[i].Resources {
+			res := &kinds[i].Resources[j]
+			res.Drifts, res.Acknowledged = partitionAckedDrifts(statuses, res.Name, res.Drifts)
+		}
+	}
+	return statuses
+}
+
+// diffAcks caches the active instance's acknowledgements for the diff commands
+var diffAcks []AckStatus
+
+var diffAcksLoaded bool
+
+// suppressAckedDrifts removes acknowledged drift from a diff result and notes how many
+// drifts were suppressed. If state cannot be read, the drift is returned unchanged.
+func suppressAckedDrifts(resource string, drifts []Drift) []Drift {
+	if !diffAcksLoaded {
+		diffAcksLoaded = true
+		acks, err := state.NewManager().ListAcks()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not load drift acknowledgements: %v\n", err)
+		}
+		diffAcks = ackStatuses(acks, time.Now().UTC())
+	}
+	kept, acked := partitionAckedDrifts(diffAcks, resource, drifts)
+	if len(acked) > 0 {
+		fmt.Fprintf(os.Stderr, "Note: %d acknowledged drifts on %s suppressed (see 'owlctl drift acks')\n", len(acked), resource)
+	}
+	return kept
+}
+
+// printAcks prints acknowledgements as a table
+func printAcks(w io.Writer, acks []AckStatus) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RESOURCE\tPATH\tSTATUS\tUNTIL\tBY\tREASON")
+	for _, a := range acks {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			ackResourceLabel(a.Acknowledgement), a.Path, a.Status, a.Until.UTC().Format("2006-01-02 15:04"), valueOrDash(a.User), a.Reason)
+	}
+	tw.Flush()
+}
+
+// printScanAcks prints the drift suppressed by acknowledgements and the expired
+// acknowledgements of a scanned instance
+func printScanAcks(w io.Writer, inst ScanInstance) {
+	if len(inst.Acks) == 0 {
+		return
+	}
+	var expired []AckStatus
+	for _, a := range inst.Acks {
+		if a.Status == "expired" {
+			expired = append(expired, a)
+		}
+	}
+
+	var lines []string
+	for _, kind := range inst.Kinds {
+		for _, res := range kind.Resources {
+			for _, d := range res.Acknowledged {
+				lines = append(lines, fmt.Sprintf("  %s / %s: %s (%s)", kind.DisplayName, res.Name, d.Path, d.Severity))
+			}
+		}
+	}
+	if len(lines) > 0 {
+		fmt.Fprintf(w, "\nAcknowledged drift (suppressed):\n")
+		for _, l := range lines {
+			fmt.Fprintln(w, l)
+		}
+	}
+	if len(expired) > 0 {
+		fmt.Fprintf(w, "\nExpired acknowledgements (drift is reported again):\n")
+		for _, a := range expired {
+			fmt.Fprintf(w, "  %s %s: expired %s (%s)\n", ackResourceLabel(a.Acknowledgement), a.Path, a.Until.UTC().Format("2006-01-02"), a.Reason)
+		}
+	}
+}
+
+func ackResourceLabel(a state.Acknowledgement) string {
+	if a.Kind == "" {
+		return a.Resource
+	}
+	return a.Kind + "/" + a.Resource
+}
+
+func init() {
+	driftAckCmd.Flags().StringVar(&ackUntil, "until", "", "When the acknowledgement expires: YYYY-MM-DD, RFC 3339 timestamp, or duration such as 30d (required)")
+	driftAckCmd.Flags().StringVar(&ackReason, "reason", "", "Why the drift is accepted (required)")
+	driftAcksCmd.Flags().BoolVar(&acksAll, "all", false, "Include expired acknowledgements")
+	driftAcksCmd.Flags().BoolVar(&acksPrune, "prune", false, "Remove expired acknowledgements")
+	driftAcksCmd.Flags().StringVar(&acksFmt, "format", "table", "Output format: table or json")
+	driftCmd.AddCommand(driftAckCmd)
+	driftCmd.AddCommand(driftAcksCmd)
+	driftCmd.AddCommand(driftUnackCmd)
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shapedthought/owlctl/events"
+	"github.com/shapedthought/owlctl/state"
+)
+
+func TestParseAckUntil(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr string
+	}{
+		{value: "2026-11-01", want: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{value: "2026-11-01T12:00:00+02:00", want: time.Date(2026, 11, 1, 10, 0, 0, 0, time.UTC)},
+		{value: "14d", want: now.Add(14 * 24 * time.Hour)},
+		{value: "12h", want: now.Add(12 * time.Hour)},
+		{value: "", wantErr: "required"},
+		{value: "next week", wantErr: "is not a date"},
+		{value: "2026-10-01", wantErr: "not in the future"},
+	}
+	for _, tt := range tests {
+		got, err := parseAckUntil(tt.value, now)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseAckUntil(%q) error = %v, want %q", tt.value, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("parseAckUntil(%q) = %s, %v, want %s", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestApplyScanAcks(t *testing.T) {
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	acks := []state.Acknowledgement{
+		{Kind: "VBRJob", Resource: "Nightly", Path: "storage.retentionPolicy", Until: now.Add(24 * time.Hour), Reason: "CHG-1042"},
+		{Kind: "VBRJob", Resource: "Nightly", Path: "isDisabled", Until: now.Add(-time.Hour), Reason: "maintenance"},
+	}
+	kinds := []ScanKind{{Kind: "VBRJob", Resources: []ScanResource{
+		{Name: "Nightly", Drifts: []Drift{
+			{Path: "isDisabled", Severity: SeverityCritical},
+			{Path: "storage.retentionPolicy.quantity", Severity: SeverityWarning},
+			{Path: "storage.retentionPolicy.type", Severity: SeverityWarning},
+		}},
+		{Name: "Weekly", Drifts: []Drift{{Path: "storage.retentionPolicy.quantity", Severity: SeverityWarning}}},
+	}}}
+
+	statuses := applyScanAcks(acks, kinds, now)
+
+	nightly := kinds[0].Resources[0]
+	if len(nightly.Drifts) != 1 || nightly.Drifts[0].Path != "isDisabled" {
+		t.Errorf("Expired acks must not suppress drift: %+v", nightly.Drifts)
+	}
+	if len(nightly.Acknowledged) != 2 {
+		t.Errorf("Expected fields below the acked path to be suppressed: %+v", nightly.Acknowledged)
+	}
+	if len(kinds[0].Resources[1].Drifts) != 1 {
+		t.Error("Acks apply only to the named resource")
+	}
+	if len(statuses) != 2 || statuses[0].Path != "isDisabled" || statuses[0].Status != "expired" ||
+		statuses[1].Status != "active" || statuses[1].Suppressed != 2 {
+		t.Errorf("Unexpected statuses: %+v", statuses)
+	}
+
+	if applyScanAcks(nil, kinds, now) != nil {
+		t.Error("Expected no statuses without acks")
+	}
+}
+
+func TestScanAcksReporting(t *testing.T) {
+	scan := complianceTestScan()
+	until := scan.Timestamp.Add(14 * 24 * time.Hour)
+	inst := &scan.Instances[0]
+	inst.Acks = applyScanAcks([]state.Acknowledgement{
+		{Kind: "VBRJob", Resource: "Nightly", Path: "isDisabled", Until: until, User: "alice", Reason: "CHG-1042"},
+		{Kind: "VBRJob", Resource: "Weekly", Path: "description", Until: scan.Timestamp.Add(-time.Hour), User: "bob", Reason: "old"},
+	}, inst.Kinds, scan.Timestamp)
+	scan.finish()
+
+	if scan.MaxSeverity != SeverityInfo {
+		t.Errorf("Acknowledged CRITICAL drift should not count, max severity = %s", scan.MaxSeverity)
+	}
+
+	var out bytes.Buffer
+	printScanReport(&out, scan)
+	for _, want := range []string{"Acknowledged drift (suppressed):", "Jobs / Nightly: isDisabled (CRITICAL)", "VBRJob/Weekly description: expired"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Scan output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	records := scanHistoryRecords(scan)
+	for _, r := range records {
+		if r.Resource == "Nightly" && len(r.Drifts) != 2 {
+			t.Errorf("Acknowledged drift should still be recorded in history: %+v", r.Drifts)
+		}
+	}
+
+	report := buildComplianceReport(scan, complianceTestState(), nil)
+	if len(report.Acks) != 2 || report.Summary.Critical != 0 {
+		t.Fatalf("Unexpected report acks %+v, summary %+v", report.Acks, report.Summary)
+	}
+	var md bytes.Buffer
+	if err := writeComplianceReport(&md, "markdown", report); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"## Acknowledged Drift", "| VBRJob/Nightly | isDisabled | Active | 2026-11-01 09:00 | 1 | alice | CHG-1042 |", "| Expired |"} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("Report missing %q:\n%s", want, md.String())
+		}
+	}
+}
+
+func TestDriftAckRecordsHistoryAndEvents(t *testing.T) {
+	t.Setenv("OWLCTL_SETTINGS_PATH", t.TempDir())
+	t.Setenv("OWLCTL_ACTIVE_INSTANCE", "")
+
+	var forwarded []events.Event
+	state.EventHook = func(instance string, r *state.Resource, ev state.ResourceEvent) {
+		forwarded = append(forwarded, stateEvent(instance, r, ev))
+	}
+	defer func() { state.EventHook = emitStateEvent }()
+
+	stateMgr := state.NewManager()
+	if err := stateMgr.UpdateResource(&state.Resource{Type: "VBRJob", ID: "job-1", Name: "Nightly"}); err != nil {
+		t.Fatal(err)
+	}
+
+	ackUntil, ackReason = "2099-01-01", "CHG-1042"
+	defer func() { ackUntil, ackReason = "", "" }()
+	runDriftAck("Nightly", "isDisabled")
+	runDriftUnack("Nightly", "")
+
+	r, err := stateMgr.GetResource("Nightly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.History) != 2 || r.History[0].Action != unackAction || r.History[1].Action != ackAction {
+		t.Fatalf("history = %+v, want acknowledged then unacknowledged", r.History)
+	}
+	if len(forwarded) != 2 {
+		t.Fatalf("events = %+v, want two", forwarded)
+	}
+	e := forwarded[0]
+	if e.Type != events.TypeState || e.Action != ackAction || e.Severity != events.SeverityWarning || e.Field != "isDisabled" || e.User == "" {
+		t.Errorf("ack event = %+v", e)
+	}
+	if !strings.Contains(e.Message, "until 2099-01-01 00:00 UTC (CHG-1042)") {
+		t.Errorf("ack event message = %q, want the expiry and reason", e.Message)
+	}
+	if forwarded[1].Action != unackAction {
+		t.Errorf("unack event = %+v", forwarded[1])
+	}
+}
@@ -0,0 +1,368 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/shapedthought/owlctl/config"
+	"github.com/shapedthought/owlctl/history"
+	"github.com/spf13/cobra"
+)
+
+// driftHistoryTopFields is the default number of fields in the top drifting fields table
+const driftHistoryTopFields = 10
+
+var (
+	scanRecord bool
+
+	historyFile         string
+	historySince        string
+	historyKind         string
+	historyResource     string
+	historyField        string
+	historyOpenOnly     bool
+	historyTop          int
+	historyFormat       string
+	historyAllInstances bool
+)
+
+var driftCmd = &cobra.Command{
+	Use:   "drift",
+	Short: "Drift history and acknowledgements",
+	Long: `Queries drift recorded by previous scans and manages acknowledgements of known
+drift.
+
+Subcommands:
+  owlctl drift history
+  owlctl drift history --kind VBRJob --since 30d
+  owlctl drift ack "Nightly SQL" isDisabled --until 2026-11-01 --reason "CHG-1042"
+  owlctl drift acks
+  owlctl drift unack "Nightly SQL" isDisabled
+`,
+}
+
+var driftHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show when fields drifted, how long they stayed drifted and MTTR",
+	Long: `Reads the local drift history and reconstructs drift episodes: the period from the
+first scan that reported a drifted field until the first later scan that found it
+clean. For each episode it shows when the field first drifted and how long it
+stayed drifted (open episodes run until now), then the mean time to remediation
+(MTTR) per resource and the fields that drift most often.
+
+Scans are recorded when driftHistory is enabled in owlctl.yaml, or with
+'owlctl scan --record'. Only scans of the active instance are shown unless
+--all-instances is set.
+
+--since limits the output to episodes that were open during the window; an
+episode that started earlier still shows when it first drifted.
+
+Examples:
+  owlctl drift history
+  owlctl drift history --since 30d --kind VBRJob
+  owlctl drift history --resource "Nightly SQL" --field storage.retentionPolicy.quantity
+  owlctl drift history --open --format json
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runDriftHistory()
+	},
+}
+
+// DriftHistoryReport is the output of drift history
+type DriftHistoryReport struct {
+	Path        string                    `json:"path"`
+	Since       *time.Time                `json:"since,omitempty"`
+	Scans       int                       `json:"scans"`
+	Open        int                       `json:"open"`
+	Resolved    int                       `json:"resolved"`
+	MTTRSeconds int64                     `json:"mttrSeconds,omitempty"`
+	Episodes    []history.Episode         `json:"episodes"`
+	Resources   []history.ResourceSummary `json:"resources"`
+	TopFields   []history.FieldSummary    `json:"topFields"`
+}
+
+func runDriftHistory() {
+	if historyFormat != "table" && historyFormat != "json" {
+		log.Fatalf("Invalid --format: %s (use table or json)", historyFormat)
+	}
+	var since time.Time
+	if historySince != "" {
+		window, err := parseRPODuration(historySince)
+		if err != nil {
+			log.Fatalf("Invalid --since: %v", err)
+		}
+		since = time.Now().UTC().Add(-window)
+	}
+
+	store := historyFileStore()
+	records, err := store.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	instance := ""
+	if !historyAllInstances {
+		instance = os.Getenv("OWLCTL_ACTIVE_INSTANCE")
+	}
+	records = filterHistoryRecords(records, !historyAllInstances, instance)
+	if len(records) == 0 && historyFormat == "table" {
+		fmt.Printf("No drift history recorded in %s.\n", store.Path())
+		fmt.Println("Enable driftHistory in owlctl.yaml or run 'owlctl scan --record' to start recording scans.")
+		return
+	}
+
+	report := buildDriftHistoryReport(records, since, time.Now().UTC())
+	report.Path = store.Path()
+
+	if historyFormat == "json" {
+		if err := writeReportJSON(os.Stdout, report); err != nil {
+			log.Fatalf("Failed to write report: %v", err)
+		}
+		return
+	}
+	printDriftHistory(os.Stdout, report)
+}
+
+// historyFileStore returns the store named by --file, or the configured one
+func historyFileStore() *history.Store {
+	if historyFile != "" {
+		return history.NewStore(historyFile)
+	}
+	store, _ := driftHistoryStore()
+	return store
+}
+
+// driftHistoryStore returns the history store configured in owlctl.yaml and whether
+// recording is enabled there. Without a config the default store is returned.
+func driftHistoryStore() (*history.Store, bool) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return history.NewStore(""), false
+	}
+	path := cfg.DriftHistory.Path
+	if path != "" {
+		path = cfg.ResolvePath(path)
+	}
+	return history.NewStore(path), cfg.DriftHistory.Enabled
+}
+
+// recordScan appends a scan to the drift history when driftHistory is enabled or --record
+// is set. Failures are warnings; they never change the scan result.
+func recordScan(r ScanReport) {
+	store, enabled := driftHistoryStore()
+	if !enabled && !scanRecord {
+		return
+	}
+	if err := store.Append(scanHistoryRecords(r)); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: scan not recorded in drift history: %v\n", err)
+	}
+}
+
+// scanHistoryRecords converts a scan into one record per checked resource. Kinds and
+// instances that failed or were skipped are left out: they say nothing about drift.
+func scanHistoryRecords(r ScanReport) []history.Record {
+	var records []history.Record
+	for _, inst := range r.Instances {
+		if inst.Error != "" || inst.Skipped != "" {
+			continue
+		}
+		for _, kind := range inst.Kinds {
+			if kind.Error != "" || kind.Skipped != "" {
+				continue
+			}
+			for _, res := range kind.Resources {
+				if res.Error != "" {
+					continue
+				}
+				rec := history.Record{
+					Time:        r.Timestamp,
+					Instance:    inst.Name,
+					Kind:        kind.Kind,
+					Resource:    res.Name,
+					MinSeverity: string(r.MinSeverity),
+				}
+				// Acknowledged drift is still drift in VBR; recording it keeps episodes open
+				drifts := append(append([]Drift(nil), res.Drifts...), res.Acknowledged...)
+				for _, d := range drifts {
+					rec.Drifts = append(rec.Drifts, history.Field{
+						Path:     d.Path,
+						Action:   d.Action,
+						Severity: string(d.Severity),
+						State:    d.State,
+						VBR:      d.VBR,
+					})
+				}
+				records = append(records, rec)
+			}
+		}
+	}
+	return records
+}
+
+// filterHistoryRecords keeps the records of one instance (when byInstance is set) and of
+// the --kind and --resource filters
+func filterHistoryRecords(records []history.Record, byInstance bool, instance string) []history.Record {
+	var kept []history.Record
+	for _, r := range records {
+		if byInstance && r.Instance != instance {
+			continue
+		}
+		if historyKind != "" && !strings.EqualFold(r.Kind, historyKind) {
+			continue
+		}
+		if historyResource != "" && r.Resource != historyResource {
+			continue
+		}
+		kept = append(kept, r)
+	}
+	return kept
+}
+
+// buildDriftHistoryReport reconstructs episodes and keeps those open at or after since
+func buildDriftHistoryReport(records []history.Record, since, now time.Time) DriftHistoryReport {
+	report := DriftHistoryReport{
+		Episodes:  []history.Episode{},
+		Resources: []history.ResourceSummary{},
+		TopFields: []history.FieldSummary{},
+	}
+	if !since.IsZero() {
+		report.Since = &since
+	}
+
+	scans := make(map[time.Time]bool)
+	for _, r := range records {
+		if !r.Time.Before(since) {
+			scans[r.Time] = true
+		}
+	}
+	report.Scans = len(scans)
+
+	for _, ep := range history.Episodes(records, now) {
+		if historyField != "" && ep.Field != historyField {
+			continue
+		}
+		if historyOpenOnly && !ep.Open() {
+			continue
+		}
+		if !since.IsZero() && !ep.Open() && ep.ResolvedAt.Before(since) {
+			continue
+		}
+		report.Episodes = append(report.Episodes, ep)
+		if ep.Open() {
+			report.Open++
+		} else {
+			report.Resolved++
+		}
+	}
+
+	mttr, _ := history.MTTR(report.Episodes)
+	report.MTTRSeconds = int64(mttr / time.Second)
+	if resources := history.SummarizeResources(report.Episodes); resources != nil {
+		report.Resources = resources
+	}
+	if fields := history.TopFields(report.Episodes, historyTop); fields != nil {
+		report.TopFields = fields
+	}
+	return report
+}
+
+func printDriftHistory(w io.Writer, r DriftHistoryReport) {
+	window := "all recorded scans"
+	if r.Since != nil {
+		window = "since " + r.Since.Local().Format("2006-01-02 15:04")
+	}
+	fmt.Fprintf(w, "Drift history (%s, %d scans)\n", window, r.Scans)
+
+	if len(r.Episodes) == 0 {
+		fmt.Fprintln(w, "\nNo drift recorded.")
+		return
+	}
+
+	showInstance := historyAllInstances
+	withInstance := func(instance string, cells ...string) string {
+		if showInstance {
+			cells = append([]string{valueOrDash(instance)}, cells...)
+		}
+		return strings.Join(cells, "\t")
+	}
+
+	fmt.Fprintln(w, "\nEpisodes:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, withInstance("INSTANCE", "KIND", "RESOURCE", "FIELD", "SEVERITY", "FIRST DRIFTED", "RESOLVED", "DRIFTED FOR"))
+	for _, ep := range r.Episodes {
+		resolved := "open"
+		if !ep.Open() {
+			resolved = ep.ResolvedAt.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Fprintln(tw, withInstance(ep.Instance, ep.Kind, ep.Resource, ep.Field, ep.Severity,
+			ep.FirstDrifted.Local().Format("2006-01-02 15:04"), resolved, formatElapsed(ep.Duration())))
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w, "\nTime to remediation by resource:")
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, withInstance("INSTANCE", "KIND", "RESOURCE", "EPISODES", "OPEN", "MTTR"))
+	for _, res := range r.Resources {
+		mttr := "-"
+		if res.MTTRSeconds > 0 {
+			mttr = formatElapsed(res.MTTR())
+		}
+		fmt.Fprintln(tw, withInstance(res.Instance, res.Kind, res.Resource, strconv.Itoa(res.Episodes), strconv.Itoa(res.Open), mttr))
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w, "\nTop drifting fields:")
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tFIELD\tEPISODES\tRESOURCES\tOPEN\tTOTAL DRIFTED")
+	for _, f := range r.TopFields {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%s\n", f.Kind, f.Field, f.Episodes, f.Resources, f.Open, formatElapsed(time.Duration(f.DriftedSeconds)*time.Second))
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\nSummary:\n")
+	fmt.Fprintf(w, "  - %d drift episodes (%d open, %d resolved)\n", len(r.Episodes), r.Open, r.Resolved)
+	if r.Resolved > 0 {
+		fmt.Fprintf(w, "  - Mean time to remediation: %s\n", formatElapsed(time.Duration(r.MTTRSeconds)*time.Second))
+	}
+}
+
+// formatElapsed renders a duration in days, hours and minutes (e.g. "3d 4h", "45m")
+func formatElapsed(d time.Duration) string {
+	if d < time.Minute {
+		return "<1m"
+	}
+	days := d / (24 * time.Hour)
+	hours := (d % (24 * time.Hour)) / time.Hour
+	minutes := (d % time.Hour) / time.Minute
+	switch {
+	case days > 0 && hours > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case days > 0:
+		return fmt.Sprintf("%dd", days)
+	case hours > 0 && minutes > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	case hours > 0:
+		return fmt.Sprintf("%dh", hours)
+	}
+	return fmt.Sprintf("%dm", minutes)
+}
+
+func init() {
+	driftHistoryCmd.Flags().StringVar(&historyFile, "file", "", "Drift history file (default: driftHistory.path in owlctl.yaml, or drift-history.jsonl in the settings directory)")
+	driftHistoryCmd.Flags().StringVar(&historySince, "since", "", "Only show episodes open during this window (e.g. 24h, 30d)")
+	driftHistoryCmd.Flags().StringVar(&historyKind, "kind", "", "Only show this resource kind (e.g. VBRJob)")
+	driftHistoryCmd.Flags().StringVar(&historyResource, "resource", "", "Only show this resource")
+	driftHistoryCmd.Flags().StringVar(&historyField, "field", "", "Only show this field path")
+	driftHistoryCmd.Flags().BoolVar(&historyOpenOnly, "open", false, "Only show episodes that are still drifted")
+	driftHistoryCmd.Flags().IntVar(&historyTop, "top", driftHistoryTopFields, "Number of top drifting fields to show (0 for all)")
+	driftHistoryCmd.Flags().StringVar(&historyFormat, "format", "table", "Output format: table or json")
+	driftHistoryCmd.Flags().BoolVar(&historyAllInstances, "all-instances", false, "Show every instance's history")
+	driftCmd.AddCommand(driftHistoryCmd)
+	rootCmd.AddCommand(driftCmd)
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shapedthought/owlctl/history"
+)
+
+func TestScanHistoryRecords(t *testing.T) {
+	scan := complianceTestScan()
+	scan.MinSeverity = SeverityWarning
+	scan.Instances = append(scan.Instances, ScanInstance{Name: "dr", Error: "connection refused"})
+
+	records := scanHistoryRecords(scan)
+	if len(records) != 2 {
+		t.Fatalf("Expected one record per checked resource, got %+v", records)
+	}
+	if records[0].Resource != "Weekly" || len(records[0].Drifts) != 0 {
+		t.Errorf("Clean resources are recorded without drifts: %+v", records[0])
+	}
+	nightly := records[1]
+	if nightly.Kind != "VBRJob" || !nightly.Time.Equal(scan.Timestamp) || nightly.MinSeverity != "WARNING" {
+		t.Errorf("Unexpected record: %+v", nightly)
+	}
+	if len(nightly.Drifts) != 2 || nightly.Drifts[0].Path != "isDisabled" || nightly.Drifts[0].Severity != "CRITICAL" {
+		t.Errorf("Unexpected drifts: %+v", nightly.Drifts)
+	}
+}
+
+func TestBuildDriftHistoryReport(t *testing.T) {
+	start := time.Date(2026, 9, 1, 9, 0, 0, 0, time.UTC)
+	disabled := history.Field{Path: "isDisabled", Action: "modified", Severity: "CRITICAL"}
+	records := []history.Record{
+		{Time: start, Kind: "VBRJob", Resource: "Nightly", Drifts: []history.Field{disabled}},
+		{Time: start.Add(2 * time.Hour), Kind: "VBRJob", Resource: "Nightly"},
+		{Time: start.Add(30 * 24 * time.Hour), Kind: "VBRJob", Resource: "Nightly", Drifts: []history.Field{disabled}},
+		{Time: start.Add(31 * 24 * time.Hour), Kind: "VBRJob", Resource: "Nightly"},
+	}
+	now := start.Add(40 * 24 * time.Hour)
+
+	all := buildDriftHistoryReport(records, time.Time{}, now)
+	if all.Scans != 4 || len(all.Episodes) != 2 || all.Resolved != 2 || all.MTTRSeconds != 13*3600 {
+		t.Errorf("Unexpected report: %+v", all)
+	}
+
+	recent := buildDriftHistoryReport(records, start.Add(20*24*time.Hour), now)
+	if len(recent.Episodes) != 1 || recent.Scans != 2 || recent.MTTRSeconds != 24*3600 {
+		t.Errorf("--since should drop episodes resolved before the window: %+v", recent)
+	}
+
+	var buf bytes.Buffer
+	printDriftHistory(&buf, all)
+	for _, want := range []string{"FIRST DRIFTED", "2h", "Top drifting fields", "Mean time to remediation: 13h"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Output missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestComplianceHistory(t *testing.T) {
+	scan := complianceTestScan()
+	since := scan.Timestamp.Add(-30 * 24 * time.Hour)
+	records := []history.Record{
+		{Time: scan.Timestamp.Add(-3 * 24 * time.Hour), Kind: "VBRJob", Resource: "Nightly", Drifts: []history.Field{{Path: "isDisabled", Severity: "CRITICAL"}}},
+		{Time: scan.Timestamp.Add(-24 * time.Hour), Instance: "other", Kind: "VBRJob", Resource: "Nightly"},
+	}
+
+	h := complianceHistory(records, scan, since, scan.Timestamp)
+	if h == nil || h.Episodes != 1 || len(h.Open) != 1 || h.Open[0].Duration() != 72*time.Hour {
+		t.Fatalf("Unexpected history: %+v", h)
+	}
+	if complianceHistory(records[1:], scan, since, scan.Timestamp) != nil {
+		t.Error("Records of other instances should be ignored")
+	}
+
+	var buf bytes.Buffer
+	report := buildComplianceReport(scan, complianceTestState(), nil)
+	report.History = h
+	if err := writeComplianceReport(&buf, "markdown", report); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"## Drift History", "### Open Drift", "| VBRJob/Nightly | isDisabled | CRITICAL | 2026-10-15 09:00 | 3d |"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Report missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestFormatElapsed(t *testing.T) {
+	tests := map[time.Duration]string{
+		30 * time.Second:               "<1m",
+		45 * time.Minute:               "45m",
+		2 * time.Hour:                  "2h",
+		2*time.Hour + 5*time.Minute:    "2h 5m",
+		3 * 24 * time.Hour:             "3d",
+		3*24*time.Hour + 4*time.Hour:   "3d 4h",
+		3*24*time.Hour + 4*time.Minute: "3d",
+	}
+	for d, want := range tests {
+		if got := formatElapsed(d); got != want {
+			t.Errorf("formatElapsed(%s) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -267,21 +267,25 @@ func diffSingleEncryptionPassword(hint string) {
 		fmt.Printf("CRITICAL - Encryption password '%s' (ID: %s) has been removed from VBR!\n", hint, resource.ID)
 		fmt.Println("\nThis password may be referenced by active backup jobs.")
 		fmt.Println("Encrypted backups using this password may become unrecoverable.")
-		os.Exit(4) // CRITICAL drift
+		reportDrifts("VBREncryptionPassword", hint, []Drift{{Path: "inventory", Action: "removed", State: hint, Severity: SeverityCritical}})
+		ciExit(ExitDriftCritical)
 	}
 
 	// Compare, classify, filter
 	drifts := detectDrift(resource.Spec, currentMap, encryptionIgnoreFields)
 	drifts = classifyDrifts(drifts, encryptionSeverityMap)
 	minSev := parseSeverityFlag()
+	drifts = applySeverityRules("VBREncryptionPassword", resource.Name, resource.Labels, drifts)
+	drifts = suppressAckedDrifts(resource.Name, drifts)
 	drifts = filterDriftsBySeverity(drifts, minSev)
 
 	if len(drifts) == 0 {
 		fmt.Println(noDriftMessage("Encryption password matches snapshot state.", minSev))
-		os.Exit(0)
+		ciExit(ExitSuccess)
 	}
 
 	printSecuritySummary(drifts)
+	reportDrifts("VBREncryptionPassword", hint, drifts)
 	fmt.Println("Drift detected:")
 	for _, drift := range drifts {
 		printDriftWithSeverity(drift)
@@ -301,7 +305,7 @@ func diffSingleEncryptionPassword(hint string) {
 	// Show guidance based on origin
 	printRemediationGuidance(BuildEncryptionGuidance(hint, resource.Origin))
 
-	os.Exit(exitCodeForDrifts(drifts))
+	ciExit(exitCodeForDrifts(drifts))
 }
 
 func diffAllEncryptionPasswords() {
@@ -389,6 +393,8 @@ func diffAllEncryptionPasswords() {
 		if currentMap, exists := currentByID[id]; exists {
 			drifts := detectDrift(stateRes.Spec, currentMap, encryptionIgnoreFields)
 			drifts = classifyDrifts(drifts, encryptionSeverityMap)
+			drifts = applySeverityRules("VBREncryptionPassword", stateRes.Name, stateRes.Labels, drifts)
+			drifts = suppressAckedDrifts(stateRes.Name, drifts)
 			drifts = filterDriftsBySeverity(drifts, minSev)
 
 			// Show origin label for observed resources
@@ -399,6 +405,7 @@ func diffAllEncryptionPasswords() {
 
 			if len(drifts) > 0 {
 				fmt.Printf("  %s%s: %d drifts detected\n", stateRes.Name, originLabel, len(drifts))
+				reportDrifts("VBREncryptionPassword", stateRes.Name, drifts)
 				for _, d := range drifts {
 					printDriftWithSeverity(d)
 				}
@@ -435,9 +442,9 @@ func diffAllEncryptionPasswords() {
 	}
 
 	if driftedCount > 0 {
-		os.Exit(exitCodeForDrifts(allDrifts))
+		ciExit(exitCodeForDrifts(allDrifts))
 	}
-	os.Exit(0)
+	ciExit(ExitSuccess)
 }
 
 // --- KMS Server commands ---
@@ -491,12 +498,12 @@ Exit Codes:
 `,
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if kmsApplyGroupName != "" {
+		if kmsApplyGroupName != "" || labelSelector != "" {
 			if len(args) > 0 {
-				log.Fatal("Cannot use --group with a positional spec file argument")
+				log.Fatal("Cannot use --group or --selector with a positional spec file argument")
 			}
 			if kmsApplyOverlayFile != "" {
-				log.Fatal("Cannot use --group with --overlay (group defines its own overlay)")
+				log.Fatal("Cannot use --group or --selector with --overlay (group defines its own overlay)")
 			}
 			applyGroupResource(kmsApplyGroupName, kmsApplyConfig, kmsApplyDryRun)
 		} else if len(args) > 0 {
@@ -511,7 +518,7 @@ Exit Codes:
 			if result.Error != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", result.Error)
 				outcome := DetermineApplyOutcome([]ApplyResult{result})
-				os.Exit(ExitCodeForOutcome(outcome))
+				ciExit(ExitCodeForOutcome(outcome))
 			}
 
 			if result.DryRun {
@@ -548,6 +555,16 @@ Examples:
 	},
 }
 
+// kmsDiffConfig compares KMS servers against group or repository specs
+var kmsDiffConfig = GroupDiffConfig{
+	Kind:         "VBRKmsServer",
+	DisplayName:  "KMS server",
+	FetchCurrent: fetchCurrentKmsServer,
+	IgnoreFields: kmsIgnoreFields,
+	SeverityMap:  kmsSeverityMap,
+	RemediateCmd: "owlctl encryption kms-apply --group %s",
+}
+
 var kmsDiffCmd = &cobra.Command{
 	Use:   "kms-diff [kms-name]",
 	Short: "Detect KMS server configuration drift",
@@ -561,33 +578,38 @@ Examples:
   # Check all KMS servers
   owlctl encryption kms-diff --all
 
+  # Compare against the KMS server specs in Git instead of state
+  owlctl encryption kms-diff --all --against specs
+
 Exit Codes:
   0 - No drift detected
   3 - Drift detected (INFO or WARNING)
   4 - Critical security drift detected
   1 - Error occurred`,
 	Run: func(cmd *cobra.Command, args []string) {
-		if kmsDiffGroupName != "" {
+		if kmsDiffGroupName != "" || (labelSelector != "" && !kmsDiffAll) {
 			if kmsDiffAll {
 				log.Fatal("Cannot use --group with --all")
 			}
 			if len(args) > 0 {
-				log.Fatal("Cannot use --group with a positional KMS server name argument")
+				log.Fatal("Cannot use --group or --selector with a positional KMS server name argument")
+			}
+			checkGroupAgainst()
+			diffGroupResource(kmsDiffGroupName, kmsDiffConfig)
+		} else if againstSpecs() {
+			if kmsDiffAll {
+				diffAgainstSpecs(kmsDiffConfig, "")
+			} else if len(args) > 0 {
+				diffAgainstSpecs(kmsDiffConfig, args[0])
+			} else {
+				log.Fatal("Provide KMS server name or use --all")
 			}
-			diffGroupResource(kmsDiffGroupName, GroupDiffConfig{
-				Kind:         "VBRKmsServer",
-				DisplayName:  "KMS server",
-				FetchCurrent: fetchCurrentKmsServer,
-				IgnoreFields: kmsIgnoreFields,
-				SeverityMap:  kmsSeverityMap,
-				RemediateCmd: "owlctl encryption kms-apply --group %s",
-			})
 		} else if kmsDiffAll {
 			diffAllKmsServers()
 		} else if len(args) > 0 {
 			diffSingleKmsServer(args[0])
 		} else {
-			log.Fatal("Provide KMS server name, use --all, or use --group")
+			log.Fatal("Provide KMS server name, use --all, use --group, or use --selector")
 		}
 	},
 }
@@ -720,21 +742,25 @@ func diffSingleKmsServer(name string) {
 
 	if !found {
 		fmt.Printf("CRITICAL - KMS server '%s' (ID: %s) has been removed from VBR!\n", name, resource.ID)
-		os.Exit(4) // CRITICAL drift
+		reportDrifts("VBRKmsServer", name, []Drift{{Path: "inventory", Action: "removed", State: name, Severity: SeverityCritical}})
+		ciExit(ExitDriftCritical)
 	}
 
 	// Compare, classify, filter
 	drifts := detectDrift(resource.Spec, currentMap, kmsIgnoreFields)
 	drifts = classifyDrifts(drifts, kmsSeverityMap)
 	minSev := parseSeverityFlag()
+	drifts = applySeverityRules("VBRKmsServer", resource.Name, resource.Labels, drifts)
+	drifts = suppressAckedDrifts(resource.Name, drifts)
 	drifts = filterDriftsBySeverity(drifts, minSev)
 
 	if len(drifts) == 0 {
 		fmt.Println(noDriftMessage("KMS server matches snapshot state.", minSev))
-		os.Exit(0)
+		ciExit(ExitSuccess)
 	}
 
 	printSecuritySummary(drifts)
+	reportDrifts("VBRKmsServer", name, drifts)
 	fmt.Println("Drift detected:")
 	for _, drift := range drifts {
 		printDriftWithSeverity(drift)
@@ -754,7 +780,7 @@ func diffSingleKmsServer(name string) {
 	// Show guidance based on origin
 	printRemediationGuidance(BuildKmsGuidance(name, resource.Origin))
 
-	os.Exit(exitCodeForDrifts(drifts))
+	ciExit(exitCodeForDrifts(drifts))
 }
 
 func diffAllKmsServers() {
@@ -767,7 +793,7 @@ func diffAllKmsServers() {
 	}
 
 	stateMgr := state.NewManager()
-	stateResources, err := stateMgr.ListResources("VBRKmsServer")
+	stateResources, err := listSelectedResources(stateMgr, "VBRKmsServer")
 	if err != nil {
 		log.Fatalf("Failed to load state: %v\n", err)
 	}
@@ -842,6 +868,8 @@ func diffAllKmsServers() {
 		if currentMap, exists := currentByID[id]; exists {
 			drifts := detectDrift(stateRes.Spec, currentMap, kmsIgnoreFields)
 			drifts = classifyDrifts(drifts, kmsSeverityMap)
+			drifts = applySeverityRules("VBRKmsServer", stateRes.Name, stateRes.Labels, drifts)
+			drifts = suppressAckedDrifts(stateRes.Name, drifts)
 			drifts = filterDriftsBySeverity(drifts, minSev)
 
 			// Show origin label for observed resources
@@ -852,6 +880,7 @@ func diffAllKmsServers() {
 
 			if len(drifts) > 0 {
 				fmt.Printf("  %s%s: %d drifts detected\n", stateRes.Name, originLabel, len(drifts))
+				reportDrifts("VBRKmsServer", stateRes.Name, drifts)
 				for _, d := range drifts {
 					printDriftWithSeverity(d)
 				}
@@ -887,9 +916,9 @@ func diffAllKmsServers() {
 	}
 
 	if driftedCount > 0 {
-		os.Exit(exitCodeForDrifts(allDrifts))
+		ciExit(exitCodeForDrifts(allDrifts))
 	}
-	os.Exit(0)
+	ciExit(ExitSuccess)
 }
 
 // --- Encryption Password Export command ---
@@ -979,7 +1008,7 @@ Examples:
 			SupportsOverlay: true,
 		}
 
-		if kmsExportAll {
+		if kmsExportAll || labelSelector != "" {
 			exportAllResources(cfg, profile, kmsExportDirectory, kmsExportAsOverlay, kmsExportBasePath)
 		} else if len(args) > 0 {
 			exportSingleResource(args[0], cfg, profile, kmsExportOutput, kmsExportAsOverlay, kmsExportBasePath)
@@ -1074,6 +1103,7 @@ func init() {
 	kmsExportCmd.Flags().StringVarP(&kmsExportOutput, "output", "o", "", "Output file (default: stdout)")
 	kmsExportCmd.Flags().StringVarP(&kmsExportDirectory, "directory", "d", "", "Output directory for bulk export")
 	kmsExportCmd.Flags().BoolVar(&kmsExportAll, "all", false, "Export all KMS servers")
+	addSelectorFlag(kmsExportCmd, "Export only resources whose labels recorded in state match a selector (implies --all)")
 	kmsExportCmd.Flags().BoolVar(&kmsExportAsOverlay, "as-overlay", false, "Export as overlay (minimal patch)")
 	kmsExportCmd.Flags().StringVar(&kmsExportBasePath, "base", "", "Base template to diff against (for overlay export)")
 
@@ -1083,10 +1113,14 @@ func init() {
 	kmsSnapshotCmd.Flags().BoolVar(&kmsSnapshotAll, "all", false, "Snapshot all KMS servers")
 	kmsDiffCmd.Flags().BoolVar(&kmsDiffAll, "all", false, "Check drift for all KMS servers in state")
 	kmsDiffCmd.Flags().StringVar(&kmsDiffGroupName, "group", "", "Check drift for all specs in named group (from owlctl.yaml)")
+	addSelectorFlag(kmsDiffCmd, "Check drift for specs whose labels match a selector; with --all, filters state by recorded labels")
+	addAgainstFlag(kmsDiffCmd)
 	addSeverityFlags(kmsDiffCmd)
 	kmsApplyCmd.Flags().BoolVar(&kmsApplyDryRun, "dry-run", false, "Preview changes without applying them")
 	kmsApplyCmd.Flags().StringVar(&kmsApplyGroupName, "group", "", "Apply all specs in named group (from owlctl.yaml)")
+	addSelectorFlag(kmsApplyCmd, "Apply specs whose labels match a selector; with --group, filters the group (e.g. env=prod)")
 	kmsApplyCmd.Flags().StringVar(&kmsApplyOverlayFile, "overlay", "", "Overlay file to merge with base configuration")
+	addApplyWaitFlags(kmsApplyCmd)
 
 	encryptionCmd.AddCommand(encExportCmd)
 	encryptionCmd.AddCommand(encSnapshotCmd)
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/shapedthought/owlctl/config"
+	"github.com/shapedthought/owlctl/events"
+	"github.com/shapedthought/owlctl/state"
+)
+
+var (
+	eventDispatcher *events.Dispatcher
+	eventsOnce      sync.Once
+)
+
+// eventSinks returns the event sinks configured in owlctl.yaml, loading them on first use.
+// A broken sink configuration is reported once and disables forwarding for the command.
+func eventSinks() *events.Dispatcher {
+	eventsOnce.Do(func() {
+		cfg, err := config.LoadConfig()
+		if err != nil || len(cfg.EventSinks) == 0 {
+			return
+		}
+		sinks := make([]config.EventSinkConfig, len(cfg.EventSinks))
+		for i, s := range cfg.EventSinks {
+			if s.Path != "" {
+				s.Path = cfg.ResolvePath(s.Path)
+			}
+			if s.CAFile != "" {
+				s.CAFile = cfg.ResolvePath(s.CAFile)
+			}
+			sinks[i] = s
+		}
+		d, err := events.New(sinks)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: events not forwarded: %v\n", err)
+			return
+		}
+		eventDispatcher = d
+	})
+	return eventDispatcher
+}
+
+// emitEvent forwards an event to the configured sinks. Delivery failures are warnings;
+// they never change a command's outcome.
+func emitEvent(e events.Event) {
+	d := eventSinks()
+	if d == nil {
+		return
+	}
+	if e.Instance == "" {
+		e.Instance = os.Getenv("OWLCTL_ACTIVE_INSTANCE")
+	}
+	if err := d.Emit(e); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// reportDrifts records drift on a resource for CI annotations and event sinks
+func reportDrifts(kind, name string, drifts []Drift) {
+	ciOutput.drifts(kind+"/"+name, drifts)
+	emitDriftEvents("", kind, name, drifts)
+}
+
+// emitDriftEvents sends one event per drift
+func emitDriftEvents(instance, kind, name string, drifts []Drift) {
+	for _, d := range drifts {
+		emitEvent(events.Event{
+			Type:     events.TypeDrift,
+			Action:   d.Action,
+			Severity: string(d.Severity),
+			Instance: instance,
+			Kind:     kind,
+			Resource: name,
+			Field:    d.Path,
+			Expected: d.State,
+			Actual:   d.VBR,
+			Message:  fmt.Sprintf("%s drift on %s/%s %s: %s", d.Severity, kind, name, d.Path, driftChangeText(d)),
+		})
+	}
+}
+
+// emitScanEvents sends drift events for every resource in a scan
+func emitScanEvents(r ScanReport) {
+	for _, inst := range r.Instances {
+		for _, kind := range inst.Kinds {
+			for _, res := range kind.Resources {
+				emitDriftEvents(inst.Name, kind.Kind, res.Name, res.Drifts)
+			}
+		}
+	}
+}
+
+// reportApply records an apply result for CI annotations and event sinks. Dry runs are
+// annotated but not forwarded.
+func reportApply(kind string, result ApplyResult) {
+	resource := kind + "/" + result.ResourceName
+	ciOutput.skippedFields(resource, result.Skipped)
+	ciOutput.applyFailed(resource, result.Error)
+	if result.DryRun {
+		return
+	}
+
+	e := events.Event{
+		Type:     events.TypeApply,
+		Action:   result.Action,
+		Severity: events.SeverityInfo,
+		Kind:     kind,
+		Resource: result.ResourceName,
+		User:     currentUsername(),
+		Message:  fmt.Sprintf("%s %s", resource, result.Action),
+	}
+	if result.Error != nil {
+		e.Action = "failed"
+		e.Severity = events.SeverityError
+		e.Message = fmt.Sprintf("Apply of %s failed: %v", resource, result.Error)
+	} else if len(result.Skipped) > 0 {
+		e.Severity = events.SeverityWarning
+		e.Message += fmt.Sprintf(" (%d fields skipped)", len(result.Skipped))
+	}
+	emitEvent(e)
+}
+
+// emitStateEvent forwards a state change recorded by state.Manager
+func emitStateEvent(instance string, r *state.Resource, ev state.ResourceEvent) {
+	emitEvent(stateEvent(instance, r, ev))
+}
+
+// stateEvent builds the event for a state change. Drift acknowledgements change which drift
+// is reported, so they are forwarded as warnings with the acknowledged field.
+func stateEvent(instance string, r *state.Resource, ev state.ResourceEvent) events.Event {
+	e := events.Event{
+		Time:     ev.Timestamp,
+		Type:     events.TypeState,
+		Action:   ev.Action,
+		Severity: events.SeverityInfo,
+		Instance: instance,
+		Kind:     r.Type,
+		Resource: r.Name,
+		User:     ev.User,
+		Message:  fmt.Sprintf("%s/%s %s in state by %s", r.Type, r.Name, ev.Action, ev.User),
+	}
+	if ev.Action == ackAction || ev.Action == unackAction {
+		e.Severity = events.SeverityWarning
+		if len(ev.Fields) > 0 {
+			e.Field = ev.Fields[0]
+		}
+	}
+	if ev.Note != "" {
+		e.Message += ": " + ev.Note
+	}
+	return e
+}
+
+func init() {
+	state.EventHook = emitStateEvent
+}
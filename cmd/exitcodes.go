@@ -32,6 +32,14 @@ const (
 	// Used when applying to update-only resources (repos, SOBRs, KMS)
 	// that must be created via VBR console first.
 	ExitResourceNotFound = 6
+
+	// ExitSessionWarning indicates a waited-on VBR session finished with a Warning result.
+	// Used by job run control commands with --wait.
+	ExitSessionWarning = 7
+
+	// ExitSessionFailed indicates a waited-on VBR session finished with a Failed result.
+	// Used by job run control commands with --wait.
+	ExitSessionFailed = 8
 )
 
 // ApplyOutcome represents the overall outcome of an apply operation
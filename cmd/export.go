@@ -59,7 +59,7 @@ Examples:
 			log.Fatal("This command only works with VBR at the moment.")
 		}
 
-		if exportAll {
+		if exportAll || labelSelector != "" {
 			exportAllJobs(profile)
 		} else {
 			if len(args) == 0 {
@@ -113,6 +113,17 @@ func exportAllJobs(profile models.Profile) {
 
 	jobs := vhttp.GetData[JobList]("jobs", profile)
 
+	// With --selector, only export jobs whose state labels match
+	if selected := selectedStateNames("VBRJob"); selected != nil {
+		filtered := jobs.Data[:0]
+		for _, job := range jobs.Data {
+			if selected[job.Name] {
+				filtered = append(filtered, job)
+			}
+		}
+		jobs.Data = filtered
+	}
+
 	if len(jobs.Data) == 0 {
 		fmt.Println("No jobs found")
 		return
@@ -500,6 +511,7 @@ func addExportFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Output file (default: stdout)")
 	cmd.Flags().StringVarP(&exportDirectory, "directory", "d", "", "Output directory for bulk export")
 	cmd.Flags().BoolVar(&exportAll, "all", false, "Export all jobs")
+	addSelectorFlag(cmd, "Export only jobs whose labels recorded in state match a selector (implies --all)")
 	cmd.Flags().BoolVar(&exportSimplified, "simplified", false, "Export simplified format (legacy)")
 	cmd.Flags().BoolVar(&exportAsOverlay, "as-overlay", false, "Export as overlay (minimal patch)")
 	cmd.Flags().StringVar(&exportBasePath, "base", "", "Base template to diff against (for overlay export)")
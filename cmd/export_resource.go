@@ -92,6 +92,17 @@ func exportAllResources(cfg ResourceExportConfig, profile models.Profile, direct
 		log.Fatalf("Failed to list %s: %v", cfg.PluralName, err)
 	}
 
+	// With --selector, only export resources whose state labels match
+	if selected := selectedStateNames(cfg.Kind); selected != nil {
+		filtered := items[:0]
+		for _, item := range items {
+			if selected[item.Name] {
+				filtered = append(filtered, item)
+			}
+		}
+		items = filtered
+	}
+
 	if len(items) == 0 {
 		fmt.Printf("No %s found\n", cfg.PluralName)
 		return
@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"log"
+	"os"
+
 	"github.com/shapedthought/owlctl/models"
 	"github.com/shapedthought/owlctl/utils"
 	"github.com/shapedthought/owlctl/vhttp"
@@ -23,7 +26,17 @@ Returns the data in JSON format.
 owlctl get <command> - use the end of the API request after the version e.g. /v4/
 It will print to stdout in JSON by default, --yaml will print out to YAML.
 
+Query parameters can be given as flags, and the response filtered with a JMESPath
+or JSONPath expression (-q) and printed as a table (--format table, --columns).
+
+Examples:
+  owlctl get jobs
+  owlctl get jobs --limit 10 -p typeFilter=Backup
+  owlctl get jobs -q 'data[].name'
+  owlctl get jobs -q '$.data[?(@.isDisabled == false)].name'
+  owlctl get jobs --format table --columns name,type,isDisabled
 `,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		profile := utils.GetCurrentProfile()
 
@@ -34,17 +47,25 @@ It will print to stdout in JSON by default, --yaml will print out to YAML.
 func init() {
 	getCmd.Flags().BoolVarP(&yamlF, "yaml", "y", false, "prints output in yaml format")
 	getCmd.Flags().BoolVarP(&jsonF, "json", "j", false, "prints output in json format")
+	addQueryFlags(getCmd)
+	addOutputFlags(getCmd)
 	rootCmd.AddCommand(getCmd)
 
 }
 
 func customGet(profile models.Profile, custom string) {
-	cust := vhttp.GetData[interface{}](custom, profile)
+	endpoint, err := endpointWithQuery(custom)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	if !yamlF {
-		utils.PrintJson(&cust)
-	} else {
-		utils.PrintYaml(&cust)
+	cust := vhttp.GetData[interface{}](endpoint, profile)
+
+	if yamlF && outputFormat == "" {
+		outputFormat = "yaml"
+	}
+	if err := printResponse(os.Stdout, cust); err != nil {
+		log.Fatal(err)
 	}
 
 }
@@ -3,9 +3,12 @@ package cmd
 import (
 	"fmt"
 	"log"
+	"os"
 
 	"github.com/shapedthought/owlctl/config"
+	"github.com/shapedthought/owlctl/resources"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 var groupCmd = &cobra.Command{
@@ -25,10 +28,25 @@ Example owlctl.yaml:
       specs:
         - specs/sql-vm-01.yaml
         - specs/sql-vm-02.yaml
+    prod-gold:
+      description: Production gold-tier specs, selected by label
+      selector: "env=prod,tier in (gold)"
+
+    emea-sql:
+      description: Layered composition, applied in order
+      profiles: [profiles/org-baseline.yaml, profiles/emea.yaml, profiles/london.yaml]
+      overlays: [overlays/compliance.yaml, overlays/dba-team.yaml]
+      specsDir: specs/sql
+
+A group with a selector (or matchLabels) and no specs/specsDir searches every
+resource spec under the owlctl.yaml directory and keeps those whose labels match.
+
+Merge order: profile, profiles... -> spec -> overlay, overlays... (later layers win).
 
 Commands:
   owlctl group list              List all defined groups
   owlctl group show <name>       Show details of a specific group
+  owlctl group render <name>     Print every merged spec with the layer that set each field
 `,
 }
 
@@ -63,17 +81,12 @@ var groupListCmd = &cobra.Command{
 			if group.Instance != "" {
 				instanceName = group.Instance
 			}
-			profileFlag := "-"
-			if group.Profile != "" {
-				profileFlag = "yes"
-			}
-			overlayFlag := "-"
-			if group.Overlay != "" {
-				overlayFlag = "yes"
-			}
+			profiles, overlays, _ := cfg.GroupLayers(group)
+			profileFlag := layerCountFlag(len(profiles))
+			overlayFlag := layerCountFlag(len(overlays))
 
 			specCount := len(group.Specs)
-			if group.SpecsDir != "" {
+			if group.SpecsDir != "" || group.HasLabelSelector() {
 				specs, err := cfg.ResolveGroupSpecs(group)
 				if err == nil {
 					specCount = len(specs)
@@ -114,18 +127,11 @@ var groupShowCmd = &cobra.Command{
 			fmt.Println("Instance: (none)")
 		}
 
-		if group.Profile != "" {
-			fmt.Printf("Profile: %s\n", group.Profile)
-			fmt.Printf("  (resolved: %s)\n", cfg.ResolvePath(group.Profile))
-		} else {
-			fmt.Println("Profile: (none)")
-		}
-
-		if group.Overlay != "" {
-			fmt.Printf("Overlay: %s\n", group.Overlay)
-			fmt.Printf("  (resolved: %s)\n", cfg.ResolvePath(group.Overlay))
-		} else {
-			fmt.Println("Overlay: (none)")
+		profiles, overlays, warnings := cfg.GroupLayers(group)
+		printLayerPaths(cfg, "Profile", profiles)
+		printLayerPaths(cfg, "Overlay", overlays)
+		for _, w := range warnings {
+			fmt.Printf("Warning: %s\n", w)
 		}
 
 		if group.SpecsDir != "" {
@@ -133,6 +139,17 @@ var groupShowCmd = &cobra.Command{
 			fmt.Printf("  (resolved: %s)\n", cfg.ResolvePath(group.SpecsDir))
 		}
 
+		if group.HasLabelSelector() {
+			sel, err := group.LabelSelector()
+			if err != nil {
+				log.Fatalf("Invalid group selector: %v", err)
+			}
+			fmt.Printf("Selector: %s\n", sel)
+			if len(group.Specs) == 0 && group.SpecsDir == "" {
+				fmt.Printf("  (searching: %s)\n", cfg.ResolvePath("."))
+			}
+		}
+
 		// Resolve effective specs (Specs + SpecsDir)
 		allSpecs, err := cfg.ResolveGroupSpecs(group)
 		if err != nil {
@@ -146,8 +163,157 @@ var groupShowCmd = &cobra.Command{
 	},
 }
 
+var groupRenderNoProvenance bool
+
+var groupRenderCmd = &cobra.Command{
+	Use:   "render [name]",
+	Short: "Print the fully merged spec for every member of a group",
+	Long: `Render merges every spec in a group with the group's profile and overlay chains,
+exactly as apply and diff would, and prints the results as a multi-document YAML stream.
+No connection to VBR is made.
+
+Each spec field is annotated with the layer that set it: a profile, the spec file itself,
+or an overlay. Warnings are written to stderr for:
+  - a layer listed more than once in the chain (a cycle; only the first occurrence is applied)
+  - a field set by one profile (or overlay) and overridden by a later one in the same chain
+
+Examples:
+  owlctl group render sql-tier
+  owlctl group render sql-tier --no-provenance > rendered.yaml
+  owlctl group render sql-tier -l tier=gold
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			log.Fatalf("Failed to load owlctl.yaml: %v", err)
+		}
+		cfg.WarnDeprecatedFields()
+
+		groupCfg, err := lookupGroup(cfg, args[0])
+		if err != nil {
+			log.Fatalf("Group error: %v", err)
+		}
+
+		specs, err := loadMergedGroupSpecs(cfg, groupCfg)
+		if err != nil {
+			log.Fatalf("Group error: %v", err)
+		}
+		if len(specs) == 0 {
+			fmt.Fprintf(os.Stderr, "No specs in group %s.\n", groupLabel(args[0]))
+			return
+		}
+
+		failed := 0
+		for i, s := range specs {
+			if s.Error != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s: %v\n", s.SpecPath, s.Error)
+				failed++
+				continue
+			}
+			for _, c := range s.Conflicts {
+				fmt.Fprintf(os.Stderr, "Warning: %s: %s overrides %s set by %s (%v -> %v)\n",
+					s.SpecPath, c.Layer, c.Path, c.Previous, formatValue(c.From), formatValue(c.To))
+			}
+
+			data, err := renderMergedSpec(s.Spec, s.Provenance, !groupRenderNoProvenance)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s: %v\n", s.SpecPath, err)
+				failed++
+				continue
+			}
+			if i > 0 {
+				fmt.Println("---")
+			}
+			fmt.Printf("# Source: %s\n", s.SpecPath)
+			fmt.Print(string(data))
+		}
+
+		if failed > 0 {
+			os.Exit(ExitError)
+		}
+	},
+}
+
+// renderMergedSpec encodes a merged spec as YAML. With provenance, each spec field carries a
+// line comment naming the layer that set it.
+func renderMergedSpec(spec resources.ResourceSpec, provenance map[string]string, withProvenance bool) ([]byte, error) {
+	var doc yaml.Node
+	if err := doc.Encode(spec); err != nil {
+		return nil, fmt.Errorf("failed to encode spec: %w", err)
+	}
+	if withProvenance {
+		for i := 0; i+1 < len(doc.Content); i += 2 {
+			if doc.Content[i].Value == "spec" {
+				annotateProvenance(doc.Content[i+1], "", provenance)
+			}
+		}
+	}
+	return yaml.Marshal(&doc)
+}
+
+// annotateProvenance sets a line comment on every leaf field of a spec mapping node
+func annotateProvenance(node *yaml.Node, path string, provenance map[string]string) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		fieldPath := key.Value
+		if path != "" {
+			fieldPath = path + "." + key.Value
+		}
+		if value.Kind == yaml.MappingNode && len(value.Content) > 0 {
+			annotateProvenance(value, fieldPath, provenance)
+			continue
+		}
+		source, ok := provenance[fieldPath]
+		if !ok {
+			continue
+		}
+		// Empty collections are written in flow style ({} / []), which only keeps value comments
+		if len(value.Content) == 0 && value.Kind != yaml.ScalarNode {
+			value.LineComment = source
+		} else {
+			key.LineComment = source
+		}
+	}
+}
+
+func layerCountFlag(n int) string {
+	switch n {
+	case 0:
+		return "-"
+	case 1:
+		return "yes"
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
+
+// printLayerPaths prints a group's profile or overlay chain with resolved paths
+func printLayerPaths(cfg *config.VCLIConfig, label string, paths []string) {
+	switch len(paths) {
+	case 0:
+		fmt.Printf("%s: (none)\n", label)
+	case 1:
+		fmt.Printf("%s: %s\n", label, paths[0])
+		fmt.Printf("  (resolved: %s)\n", cfg.ResolvePath(paths[0]))
+	default:
+		fmt.Printf("%ss (%d, applied in order):\n", label, len(paths))
+		for i, p := range paths {
+			fmt.Printf("  %d. %s\n", i+1, p)
+			fmt.Printf("     (resolved: %s)\n", cfg.ResolvePath(p))
+		}
+	}
+}
+
 func init() {
+	addSelectorFlag(groupRenderCmd, "Only render group specs whose labels match (e.g. tier=gold)")
+	groupRenderCmd.Flags().BoolVar(&groupRenderNoProvenance, "no-provenance", false, "Omit the per-field layer comments")
+
 	groupCmd.AddCommand(groupListCmd)
 	groupCmd.AddCommand(groupShowCmd)
+	groupCmd.AddCommand(groupRenderCmd)
 	rootCmd.AddCommand(groupCmd)
 }
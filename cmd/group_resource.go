@@ -35,17 +35,44 @@ func activateGroupInstance(cfg *config.VCLIConfig, groupCfg config.GroupConfig)
 			log.Fatalf("Failed to activate group instance %q: %v", groupCfg.Instance, err)
 		}
 	}
+	// Group vars (and the group instance's vars) apply to its specs, profile and overlay
+	applyGroupVariables(cfg, groupCfg)
+
 	// Re-read settings/profile after potential activation
 	return utils.GetCurrentProfile()
 }
 
 // resolveGroupSpecs returns the effective spec list for a group (Specs + SpecsDir globs).
-func resolveGroupSpecs(cfg *config.VCLIConfig, groupCfg config.GroupConfig) []string {
+// Label-selected groups may span resource types, so specs of other kinds are skipped.
+func resolveGroupSpecs(cfg *config.VCLIConfig, groupCfg config.GroupConfig, kind string) []string {
 	specs, err := cfg.ResolveGroupSpecs(groupCfg)
 	if err != nil {
 		log.Fatalf("Failed to resolve specs: %v", err)
 	}
-	return specs
+	if !groupCfg.HasLabelSelector() {
+		return specs
+	}
+
+	var matched []string
+	for _, specPath := range specs {
+		spec, err := resources.LoadResourceSpec(cfg.ResolvePath(specPath))
+		if err != nil || spec.Kind == kind {
+			// Load errors are reported per spec by the caller
+			matched = append(matched, specPath)
+		}
+	}
+	return matched
+}
+
+// noGroupSpecs exits when a group operation resolves to no specs
+func noGroupSpecs(group, kind string) {
+	if group == "" {
+		log.Fatalf("No %s specs match selector %q", kind, labelSelector)
+	}
+	if labelSelector != "" {
+		log.Fatalf("No %s specs in group %q match selector %q", kind, group, labelSelector)
+	}
+	log.Fatalf("Group %q has no specs defined", group)
 }
 
 // GroupDiffConfig defines resource-specific parameters for group diff operations
@@ -65,6 +92,12 @@ type GroupDiffConfig struct {
 	SeverityMap SeverityMap
 	// RemediateCmd is the remediation command template shown in summary (e.g., "owlctl repo apply --group %s")
 	RemediateCmd string
+	// DetectDrift compares a desired spec against live VBR. If nil, detectDrift with
+	// IgnoreFields and classifyDrifts with SeverityMap are used.
+	DetectDrift func(desired, live map[string]interface{}) []Drift
+	// StateKey is the fixed state name of a singleton resource. When set, specs of the kind
+	// are compared, acknowledged and reported under it rather than their metadata name.
+	StateKey string
 }
 
 // pluralDisplayName returns the plural form of the display name
@@ -75,6 +108,14 @@ func (dcfg GroupDiffConfig) pluralDisplayName() string {
 	return dcfg.DisplayName + "s"
 }
 
+// detect compares a desired spec against live VBR and classifies the drifts
+func (dcfg GroupDiffConfig) detect(desired, live map[string]interface{}) []Drift {
+	if dcfg.DetectDrift != nil {
+		return dcfg.DetectDrift(desired, live)
+	}
+	return classifyDrifts(detectDrift(desired, live, dcfg.IgnoreFields), dcfg.SeverityMap)
+}
+
 // applyGroupResource applies all specs in a named group using the generic resource apply path.
 // It loads the group config, resolves profile/overlay, merges each spec, and applies via applyResourceSpec.
 func applyGroupResource(group string, applyCfg ResourceApplyConfig, dryRun bool) {
@@ -84,7 +125,7 @@ func applyGroupResource(group string, applyCfg ResourceApplyConfig, dryRun bool)
 	}
 	cfg.WarnDeprecatedFields()
 
-	groupCfg, err := cfg.GetGroup(group)
+	groupCfg, err := lookupGroup(cfg, group)
 	if err != nil {
 		log.Fatalf("Group error: %v", err)
 	}
@@ -93,55 +134,27 @@ func applyGroupResource(group string, applyCfg ResourceApplyConfig, dryRun bool)
 	profile := activateGroupInstance(cfg, groupCfg)
 
 	// Resolve effective specs (Specs + SpecsDir)
-	specsList := resolveGroupSpecs(cfg, groupCfg)
+	specsList := resolveGroupSpecs(cfg, groupCfg, applyCfg.Kind)
 
 	if len(specsList) == 0 {
-		log.Fatalf("Group %q has no specs defined", group)
+		noGroupSpecs(group, applyCfg.Kind)
 	}
 
-	// Resolve paths relative to owlctl.yaml
-	profilePath := ""
-	if groupCfg.Profile != "" {
-		profilePath = cfg.ResolvePath(groupCfg.Profile)
-	}
-	overlayPath := ""
-	if groupCfg.Overlay != "" {
-		overlayPath = cfg.ResolvePath(groupCfg.Overlay)
+	// Load the profile and overlay chains once to avoid repeated disk I/O
+	layers, err := loadGroupLayers(cfg, groupCfg)
+	if err != nil {
+		log.Fatalf("Group error: %v", err)
 	}
 
-	fmt.Printf("Applying group: %s (%d specs)\n", group, len(specsList))
+	fmt.Printf("Applying group: %s (%d specs)\n", groupLabel(group), len(specsList))
 	if instanceFlag != "" {
 		fmt.Printf("  Instance: %s (from --instance flag)\n", instanceFlag)
 	} else if groupCfg.Instance != "" {
 		fmt.Printf("  Instance: %s\n", groupCfg.Instance)
 	}
-	if profilePath != "" {
-		fmt.Printf("  Profile: %s\n", groupCfg.Profile)
-	}
-	if overlayPath != "" {
-		fmt.Printf("  Overlay: %s\n", groupCfg.Overlay)
-	}
+	layers.printHeader()
 	fmt.Println()
 
-	// Pre-load profile and overlay once to avoid repeated disk I/O
-	var profileSpec, overlaySpec *resources.ResourceSpec
-	opts := resources.DefaultMergeOptions()
-
-	if profilePath != "" {
-		p, err := resources.LoadResourceSpec(profilePath)
-		if err != nil {
-			log.Fatalf("Failed to load profile %s: %v", profilePath, err)
-		}
-		profileSpec = &p
-	}
-	if overlayPath != "" {
-		o, err := resources.LoadResourceSpec(overlayPath)
-		if err != nil {
-			log.Fatalf("Failed to load overlay %s: %v", overlayPath, err)
-		}
-		overlaySpec = &o
-	}
-
 	// Pre-load remediation config once to avoid repeated disk I/O per spec
 	remediationCfg, remediationErr := remediation.LoadConfig()
 	if remediationErr != nil {
@@ -162,13 +175,14 @@ func applyGroupResource(group string, applyCfg ResourceApplyConfig, dryRun bool)
 			continue
 		}
 
-		// Merge with cached profile/overlay
-		mergedSpec, err := resources.ApplyGroupMergeFromSpecs(spec, profileSpec, overlaySpec, opts)
+		// Merge with cached profile/overlay chains, tracking which layer set each field
+		merged, err := layers.mergeChain(spec, specRelPath)
 		if err != nil {
 			result.Error = fmt.Errorf("merge failed: %w", err)
 			results = append(results, result)
 			continue
 		}
+		mergedSpec := merged.Spec
 
 		result.ResourceName = mergedSpec.Metadata.Name
 
@@ -180,7 +194,7 @@ func applyGroupResource(group string, applyCfg ResourceApplyConfig, dryRun bool)
 		}
 
 		// Apply via generic resource apply (pass cached remediation config)
-		applyResult := applyResourceSpec(mergedSpec, applyCfg, profile, dryRun, remediationCfg)
+		applyResult := applyResourceSpec(mergedSpec, applyCfg, profile, dryRun, remediationCfg, merged.Provenance)
 		if applyResult.Error != nil {
 			result.Error = applyResult.Error
 		} else {
@@ -201,13 +215,136 @@ func applyGroupResource(group string, applyCfg ResourceApplyConfig, dryRun bool)
 	}
 
 	if successCount == 0 {
-		os.Exit(ExitError)
+		ciExit(ExitError)
 	} else if successCount < len(results) {
-		os.Exit(ExitPartialApply)
+		ciExit(ExitPartialApply)
 	}
 	// All succeeded — exit 0 (default)
 }
 
+// mergedGroupSpec is a single group spec after profile/overlay merge, or the error that prevented it
+type mergedGroupSpec struct {
+	SpecPath   string
+	Spec       resources.ResourceSpec
+	Provenance map[string]string
+	Conflicts  []resources.LayerConflict
+	Error      error
+}
+
+// groupLayers is a group's profile and overlay chains, loaded once per command
+type groupLayers struct {
+	Profiles []resources.MergeLayer
+	Overlays []resources.MergeLayer
+	Warnings []string
+}
+
+// loadGroupLayers loads the group's profile and overlay chains in merge order
+func loadGroupLayers(cfg *config.VCLIConfig, groupCfg config.GroupConfig) (groupLayers, error) {
+	profilePaths, overlayPaths, warnings := cfg.GroupLayers(groupCfg)
+	layers := groupLayers{Warnings: warnings}
+
+	for _, path := range profilePaths {
+		spec, err := resources.LoadResourceSpec(cfg.ResolvePath(path))
+		if err != nil {
+			return layers, fmt.Errorf("failed to load profile %s: %w", path, err)
+		}
+		layers.Profiles = append(layers.Profiles, resources.MergeLayer{Source: path, Spec: spec})
+	}
+	for _, path := range overlayPaths {
+		spec, err := resources.LoadResourceSpec(cfg.ResolvePath(path))
+		if err != nil {
+			return layers, fmt.Errorf("failed to load overlay %s: %w", path, err)
+		}
+		layers.Overlays = append(layers.Overlays, resources.MergeLayer{Source: path, Spec: spec})
+	}
+	return layers, nil
+}
+
+// mergeChain merges a group spec with the profile and overlay chains, tracking provenance
+func (l groupLayers) mergeChain(spec resources.ResourceSpec, specPath string) (resources.MergeChainResult, error) {
+	return resources.MergeChain(spec, specPath, l.Profiles, l.Overlays, resources.DefaultMergeOptions())
+}
+
+// merge merges a group spec with the profile and overlay chains
+func (l groupLayers) merge(spec resources.ResourceSpec, specPath string) (resources.ResourceSpec, error) {
+	result, err := l.mergeChain(spec, specPath)
+	if err != nil {
+		return resources.ResourceSpec{}, err
+	}
+	return result.Spec, nil
+}
+
+// printHeader prints the layer chains (and any chain warnings) under a group command heading
+func (l groupLayers) printHeader() {
+	printLayerChain("Profile", l.Profiles)
+	printLayerChain("Overlay", l.Overlays)
+	for _, w := range l.Warnings {
+		fmt.Printf("  Warning: %s\n", w)
+	}
+}
+
+func printLayerChain(label string, layers []resources.MergeLayer) {
+	switch len(layers) {
+	case 0:
+	case 1:
+		fmt.Printf("  %s: %s\n", label, layers[0].Source)
+	default:
+		fmt.Printf("  %ss: %s\n", label, strings.Join(layerSources(layers), " -> "))
+	}
+}
+
+func layerSources(layers []resources.MergeLayer) []string {
+	sources := make([]string, len(layers))
+	for i, layer := range layers {
+		sources[i] = layer.Source
+	}
+	return sources
+}
+
+// loadMergedGroupSpecs loads every spec in a group and merges it with the group's profile and overlay chains.
+// Per-spec load or merge failures are returned in the result rather than aborting the whole group.
+func loadMergedGroupSpecs(cfg *config.VCLIConfig, groupCfg config.GroupConfig) ([]mergedGroupSpec, error) {
+	applyGroupVariables(cfg, groupCfg)
+
+	layers, err := loadGroupLayers(cfg, groupCfg)
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range layers.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
+
+	specsList, err := cfg.ResolveGroupSpecs(groupCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve specs: %w", err)
+	}
+
+	var results []mergedGroupSpec
+	for _, specRelPath := range specsList {
+		result := mergedGroupSpec{SpecPath: specRelPath}
+
+		spec, err := resources.LoadResourceSpec(cfg.ResolvePath(specRelPath))
+		if err != nil {
+			result.Error = fmt.Errorf("failed to load spec: %w", err)
+			results = append(results, result)
+			continue
+		}
+
+		merged, err := layers.mergeChain(spec, specRelPath)
+		if err != nil {
+			result.Error = fmt.Errorf("merge failed: %w", err)
+			results = append(results, result)
+			continue
+		}
+
+		result.Spec = merged.Spec
+		result.Provenance = merged.Provenance
+		result.Conflicts = merged.Conflicts
+		results = append(results, result)
+	}
+	return results, nil
+}
+
 // diffGroupResource compares merged group specs (profile+spec+overlay) against live VBR state.
 // Unlike state-based diff, group diff does NOT require state.json — the group definition
 // IS the source of truth.
@@ -220,7 +357,7 @@ func diffGroupResource(group string, dcfg GroupDiffConfig) {
 	}
 	cfg.WarnDeprecatedFields()
 
-	groupCfg, err := cfg.GetGroup(group)
+	groupCfg, err := lookupGroup(cfg, group)
 	if err != nil {
 		log.Fatalf("Group error: %v", err)
 	}
@@ -229,55 +366,27 @@ func diffGroupResource(group string, dcfg GroupDiffConfig) {
 	profile := activateGroupInstance(cfg, groupCfg)
 
 	// Resolve effective specs (Specs + SpecsDir)
-	specsList := resolveGroupSpecs(cfg, groupCfg)
+	specsList := resolveGroupSpecs(cfg, groupCfg, dcfg.Kind)
 
 	if len(specsList) == 0 {
-		log.Fatalf("Group %q has no specs defined", group)
+		noGroupSpecs(group, dcfg.Kind)
 	}
 
-	// Resolve paths
-	profilePath := ""
-	if groupCfg.Profile != "" {
-		profilePath = cfg.ResolvePath(groupCfg.Profile)
-	}
-	overlayPath := ""
-	if groupCfg.Overlay != "" {
-		overlayPath = cfg.ResolvePath(groupCfg.Overlay)
+	// Load the profile and overlay chains once to avoid repeated disk I/O
+	layers, err := loadGroupLayers(cfg, groupCfg)
+	if err != nil {
+		log.Fatalf("Group error: %v", err)
 	}
 
-	fmt.Printf("Checking drift for group: %s (%d specs)\n", group, len(specsList))
+	fmt.Printf("Checking drift for group: %s (%d specs)\n", groupLabel(group), len(specsList))
 	if instanceFlag != "" {
 		fmt.Printf("  Instance: %s (from --instance flag)\n", instanceFlag)
 	} else if groupCfg.Instance != "" {
 		fmt.Printf("  Instance: %s\n", groupCfg.Instance)
 	}
-	if profilePath != "" {
-		fmt.Printf("  Profile: %s\n", groupCfg.Profile)
-	}
-	if overlayPath != "" {
-		fmt.Printf("  Overlay: %s\n", groupCfg.Overlay)
-	}
+	layers.printHeader()
 	fmt.Println()
 
-	// Pre-load profile and overlay once to avoid repeated disk I/O
-	var profileSpec, overlaySpec *resources.ResourceSpec
-	opts := resources.DefaultMergeOptions()
-
-	if profilePath != "" {
-		p, err := resources.LoadResourceSpec(profilePath)
-		if err != nil {
-			log.Fatalf("Failed to load profile %s: %v", profilePath, err)
-		}
-		profileSpec = &p
-	}
-	if overlayPath != "" {
-		o, err := resources.LoadResourceSpec(overlayPath)
-		if err != nil {
-			log.Fatalf("Failed to load overlay %s: %v", overlayPath, err)
-		}
-		overlaySpec = &o
-	}
-
 	minSev := parseSeverityFlag()
 	cleanCount := 0
 	driftedCount := 0
@@ -297,7 +406,7 @@ func diffGroupResource(group string, dcfg GroupDiffConfig) {
 		}
 
 		// Compute desired state from group merge using cached profile/overlay
-		desiredSpec, err := resources.ApplyGroupMergeFromSpecs(spec, profileSpec, overlaySpec, opts)
+		desiredSpec, err := layers.merge(spec, specRelPath)
 		if err != nil {
 			fmt.Printf("  %s: Failed to merge: %v\n", specRelPath, err)
 			errorCount++
@@ -336,13 +445,15 @@ func diffGroupResource(group string, dcfg GroupDiffConfig) {
 		}
 
 		// Compare merged desired spec against live VBR
-		drifts := detectDrift(desiredSpec.Spec, currentMap, dcfg.IgnoreFields)
-		drifts = classifyDrifts(drifts, dcfg.SeverityMap)
+		drifts := dcfg.detect(desiredSpec.Spec, currentMap)
+		drifts = applySeverityRules(dcfg.Kind, resourceName, desiredSpec.Metadata.Labels, drifts)
+		drifts = suppressAckedDrifts(resourceName, drifts)
 		drifts = filterDriftsBySeverity(drifts, minSev)
 
 		if len(drifts) > 0 {
 			maxSev := getMaxSeverity(drifts)
 			fmt.Printf("  %s %s: %d drifts detected\n", maxSev, resourceName, len(drifts))
+			reportDrifts(dcfg.Kind, resourceName, drifts)
 			allDrifts = append(allDrifts, drifts...)
 			driftedCount++
 		} else {
@@ -361,7 +472,7 @@ func diffGroupResource(group string, dcfg GroupDiffConfig) {
 	fmt.Printf("\nSummary:\n")
 	fmt.Printf("  - %d %s clean\n", cleanCount, plural)
 	if driftedCount > 0 {
-		fmt.Printf("  - %d %s drifted — remediate with: %s\n", driftedCount, plural, fmt.Sprintf(dcfg.RemediateCmd, group))
+		fmt.Printf("  - %d %s drifted — remediate with: %s\n", driftedCount, plural, remediateCommand(dcfg.RemediateCmd, group))
 	}
 	if notFoundCount > 0 {
 		fmt.Printf("  - %d %s not found in VBR (would be created by apply)\n", notFoundCount, plural)
@@ -371,17 +482,17 @@ func diffGroupResource(group string, dcfg GroupDiffConfig) {
 	}
 
 	if errorCount > 0 {
-		os.Exit(ExitError)
+		ciExit(ExitError)
 	}
 	if driftedCount > 0 {
-		os.Exit(exitCodeForDrifts(allDrifts))
+		ciExit(exitCodeForDrifts(allDrifts))
 	}
-	os.Exit(0)
+	ciExit(ExitSuccess)
 }
 
 // printGroupApplySummary prints a summary table after group apply
 func printGroupApplySummary(group string, results []GroupApplyResult) {
-	fmt.Printf("\n=== Group Apply Summary: %s ===\n", group)
+	fmt.Printf("\n=== Group Apply Summary: %s ===\n", groupLabel(group))
 	fmt.Printf("%-40s %-20s %-10s\n", "SPEC", "RESOURCE", "STATUS")
 	fmt.Printf("%-40s %-20s %-10s\n", "----", "--------", "------")
 
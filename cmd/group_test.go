@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shapedthought/owlctl/resources"
+)
+
+func TestRenderMergedSpec(t *testing.T) {
+	spec := resources.ResourceSpec{
+		APIVersion: "owlctl.veeam.com/v1",
+		Kind:       resources.KindVBRJob,
+		Metadata:   resources.Metadata{Name: "sql"},
+		Spec: map[string]interface{}{
+			"description": "SQL job",
+			"objects":     []interface{}{},
+			"tags":        []interface{}{"a"},
+			"storage": map[string]interface{}{
+				"retention": map[string]interface{}{"quantity": 30},
+			},
+		},
+	}
+	provenance := map[string]string{
+		"description":                "specs/sql.yaml",
+		"objects":                    "specs/sql.yaml",
+		"tags":                       "overlays/team.yaml",
+		"storage.retention.quantity": "overlays/compliance.yaml",
+	}
+
+	data, err := renderMergedSpec(spec, provenance, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := string(data)
+
+	for _, want := range []string{
+		"description: SQL job # specs/sql.yaml",
+		"objects: [] # specs/sql.yaml",
+		"tags: # overlays/team.yaml",
+		"quantity: 30 # overlays/compliance.yaml",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "name: sql #") {
+		t.Errorf("metadata should not be annotated:\n%s", out)
+	}
+
+	data, err = renderMergedSpec(spec, provenance, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(data), "#") {
+		t.Errorf("output without provenance has comments:\n%s", data)
+	}
+}
@@ -0,0 +1,327 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/shapedthought/owlctl/utils"
+	"github.com/shapedthought/owlctl/vhttp"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Flags shared by the imperative get, post, put and delete commands
+var (
+	payloadFile   string
+	payloadStdin  bool
+	queryParams   []string
+	queryLimit    int
+	querySkip     int
+	outputQuery   string
+	outputFormat  string
+	outputColumns []string
+)
+
+// addQueryFlags registers the query parameter flags
+func addQueryFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVarP(&queryParams, "param", "p", nil, "Query parameter (name=value); repeatable")
+	cmd.Flags().IntVar(&queryLimit, "limit", 0, "Maximum number of items to return (limit query parameter)")
+	cmd.Flags().IntVar(&querySkip, "skip", 0, "Number of items to skip (skip query parameter)")
+}
+
+// addPayloadFlags registers the payload flags
+func addPayloadFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&payloadFile, "file", "f", "", "Payload file in JSON or YAML format (- for stdin)")
+	cmd.Flags().BoolVarP(&payloadStdin, "stdin", "s", false, "Read the payload from stdin")
+}
+
+// addOutputFlags registers the response output flags
+func addOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&outputQuery, "query", "q", "", "Filter the response with a JMESPath (data[].name) or JSONPath ($.data[*].name) expression")
+	cmd.Flags().StringVar(&outputFormat, "format", "", "Output format: json, yaml, or table (default json)")
+	cmd.Flags().StringSliceVar(&outputColumns, "columns", nil, "Table columns as JMESPath expressions, e.g. name,type,storage.retentionPolicy.quantity (implies --format table)")
+}
+
+// endpointWithQuery adds the query parameter flags to an endpoint. Parameters already in the
+// endpoint (jobs?typeFilter=Backup) are kept.
+func endpointWithQuery(endpoint string) (string, error) {
+	path, rawQuery, _ := strings.Cut(endpoint, "?")
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", fmt.Errorf("invalid query in endpoint %q: %w", endpoint, err)
+	}
+
+	for _, p := range queryParams {
+		name, value, ok := strings.Cut(p, "=")
+		if !ok || name == "" {
+			return "", fmt.Errorf("invalid --param %q: expected name=value", p)
+		}
+		values.Add(name, value)
+	}
+	if queryLimit > 0 {
+		values.Set("limit", fmt.Sprint(queryLimit))
+	}
+	if querySkip > 0 {
+		values.Set("skip", fmt.Sprint(querySkip))
+	}
+
+	if len(values) == 0 {
+		return path, nil
+	}
+	return path + "?" + values.Encode(), nil
+}
+
+// readPayload reads the request payload from --file or stdin and returns it as JSON.
+// YAML or JSON is detected from the content, not the file extension. Returns nil when no
+// payload was given.
+func readPayload(stdin io.Reader) ([]byte, error) {
+	var data []byte
+	var err error
+	switch {
+	case payloadStdin || payloadFile == "-":
+		data, err = io.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read payload from stdin: %w", err)
+		}
+	case payloadFile != "":
+		data, err = os.ReadFile(payloadFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read payload: %w", err)
+		}
+	default:
+		return nil, nil
+	}
+
+	payload, err := decodePayload(data)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(payload)
+}
+
+// decodePayload parses a JSON or YAML document into a JSON-compatible value
+func decodePayload(data []byte) (interface{}, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("payload is empty")
+	}
+
+	var payload interface{}
+	if trimmed[0] == '{' || trimmed[0] == '[' {
+		// JSON; keep numbers as written so large IDs aren't rounded
+		dec := json.NewDecoder(bytes.NewReader(trimmed))
+		dec.UseNumber()
+		if err := dec.Decode(&payload); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON payload: %w", err)
+		}
+		return payload, nil
+	}
+
+	if err := yaml.Unmarshal(trimmed, &payload); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML payload: %w", err)
+	}
+	switch payload.(type) {
+	case map[string]interface{}, []interface{}:
+		return payload, nil
+	}
+	return nil, fmt.Errorf("payload must be a JSON or YAML object or array")
+}
+
+// printResponseBody decodes a JSON response body and prints it like printResponse. Empty
+// bodies (e.g. 204 No Content) print nothing.
+func printResponseBody(w io.Writer, body []byte) error {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		// Not JSON: print as-is
+		_, err = fmt.Fprintln(w, string(body))
+		return err
+	}
+	return printResponse(w, data)
+}
+
+// sendImperative sends a request for the post, put and delete commands and returns the
+// response, exiting on errors. The status goes to stderr so stdout can be piped.
+func sendImperative(method string, endpoint string, payload []byte) *vhttp.Response {
+	endpoint, err := endpointWithQuery(endpoint)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	profile := utils.GetCurrentProfile()
+	res, err := vhttp.Send(method, endpoint, payload, profile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		log.Fatal(res.Error())
+	}
+
+	fmt.Fprintln(os.Stderr, "Status:", res.Status)
+	return res
+}
+
+// printImperativeResponse prints a response body to stdout, exiting on errors
+func printImperativeResponse(res *vhttp.Response) {
+	if err := printResponseBody(os.Stdout, res.Body); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// printResponse filters a decoded response with --query and prints it in --format
+func printResponse(w io.Writer, data interface{}) error {
+	if outputQuery != "" {
+		filtered, err := utils.Query(data, outputQuery)
+		if err != nil {
+			return err
+		}
+		data = filtered
+	}
+
+	format := outputFormat
+	if format == "" {
+		format = "json"
+		if len(outputColumns) > 0 {
+			format = "table"
+		}
+	}
+
+	switch format {
+	case "json":
+		out, err := json.MarshalIndent(data, "", "    ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(out))
+		return err
+	case "yaml":
+		out, err := yaml.Marshal(data)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprint(w, string(out))
+		return err
+	case "table":
+		return printTable(w, data, outputColumns)
+	}
+	return fmt.Errorf("unknown output format %q (expected json, yaml, or table)", format)
+}
+
+// printTable prints a list of objects as a table, one row per item. A VBR list response
+// ({"data": [...], "pagination": {...}}) is printed from its data array. Without columns,
+// the top-level scalar fields of the first item are shown.
+func printTable(w io.Writer, data interface{}, columns []string) error {
+	if envelope, ok := data.(map[string]interface{}); ok {
+		if items, ok := envelope["data"].([]interface{}); ok {
+			data = items
+		}
+	}
+
+	var rows []interface{}
+	switch v := data.(type) {
+	case []interface{}:
+		rows = v
+	case nil:
+	default:
+		rows = []interface{}{v}
+	}
+
+	if len(columns) == 0 {
+		columns = defaultColumns(rows)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if len(columns) == 0 {
+		// Scalars: one value per line
+		fmt.Fprintln(tw, "VALUE")
+		for _, row := range rows {
+			fmt.Fprintln(tw, tableCell(row))
+		}
+		return tw.Flush()
+	}
+
+	headers := make([]string, len(columns))
+	for i, c := range columns {
+		headers[i] = strings.ToUpper(c)
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+
+	for _, row := range rows {
+		cells := make([]string, len(columns))
+		for i, c := range columns {
+			value, err := utils.Query(row, c)
+			if err != nil {
+				return fmt.Errorf("column %s: %w", c, err)
+			}
+			cells[i] = tableCell(value)
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// defaultColumns returns the scalar fields of the first object row: name and id first, then
+// the rest alphabetically
+func defaultColumns(rows []interface{}) []string {
+	if len(rows) == 0 {
+		return nil
+	}
+	first, ok := rows[0].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	var columns []string
+	for k, v := range first {
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+			continue
+		}
+		columns = append(columns, k)
+	}
+	rank := func(c string) int {
+		switch c {
+		case "name":
+			return 0
+		case "id":
+			return 1
+		}
+		return 2
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		if rank(columns[i]) != rank(columns[j]) {
+			return rank(columns[i]) < rank(columns[j])
+		}
+		return columns[i] < columns[j]
+	})
+	return columns
+}
+
+func tableCell(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return "-"
+	case string:
+		return valueOrDash(x)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case map[string]interface{}, []interface{}:
+		out, err := json.Marshal(x)
+		if err != nil {
+			return fmt.Sprint(x)
+		}
+		return string(out)
+	}
+	return fmt.Sprint(v)
+}
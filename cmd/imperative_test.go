@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestEndpointWithQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		params   []string
+		limit    int
+		skip     int
+		want     string
+		wantErr  bool
+	}{
+		{name: "no parameters", endpoint: "jobs", want: "jobs"},
+		{name: "params and paging", endpoint: "jobs", params: []string{"typeFilter=Backup"}, limit: 10, skip: 5, want: "jobs?limit=10&skip=5&typeFilter=Backup"},
+		{name: "keeps endpoint query", endpoint: "jobs?nameFilter=SQL*", params: []string{"orderAsc=true"}, want: "jobs?nameFilter=SQL%2A&orderAsc=true"},
+		{name: "value with equals", endpoint: "sessions", params: []string{"filter=a=b"}, want: "sessions?filter=a%3Db"},
+		{name: "missing value", endpoint: "jobs", params: []string{"typeFilter"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryParams, queryLimit, querySkip = tt.params, tt.limit, tt.skip
+			defer func() { queryParams, queryLimit, querySkip = nil, 0, 0 }()
+
+			got, err := endpointWithQuery(tt.endpoint)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("endpointWithQuery(%q) = %q, want %q", tt.endpoint, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadPayload(t *testing.T) {
+	tests := []struct {
+		name    string
+		stdin   string
+		want    string
+		wantErr string
+	}{
+		{name: "json", stdin: `{"name": "job", "id": 12345678901234567890}`, want: `{"id":12345678901234567890,"name":"job"}`},
+		{name: "yaml", stdin: "name: job\nisDisabled: false\nschedule:\n  daily:\n    localTime: \"22:00\"\n", want: `{"isDisabled":false,"name":"job","schedule":{"daily":{"localTime":"22:00"}}}`},
+		{name: "json array", stdin: `[1, 2]`, want: `[1,2]`},
+		{name: "empty", stdin: "  \n", wantErr: "payload is empty"},
+		{name: "scalar", stdin: "just text", wantErr: "object or array"},
+		{name: "bad json", stdin: `{"name": `, wantErr: "failed to parse JSON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payloadStdin = true
+			defer func() { payloadStdin = false }()
+
+			got, err := readPayload(strings.NewReader(tt.stdin))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("payload = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if got, err := readPayload(strings.NewReader("ignored")); got != nil || err != nil {
+		t.Errorf("no payload flags: got %q, %v; want nil", got, err)
+	}
+}
+
+func TestPrintResponse(t *testing.T) {
+	var data interface{}
+	if err := json.Unmarshal([]byte(`{"data": [
+		{"name": "SQL Backup", "id": "a1", "type": "VSphereBackup", "isDisabled": false, "storage": {"quantity": 7}},
+		{"name": "Web Backup", "id": "b2", "type": "VSphereBackup", "isDisabled": true, "storage": {"quantity": 1000000}}
+	], "pagination": {"total": 2}}`), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		format  string
+		columns []string
+		want    string
+	}{
+		{name: "query to json", query: "data[].name", want: "[\n    \"SQL Backup\",\n    \"Web Backup\"\n]\n"},
+		{name: "query to yaml", query: "data[0].name", format: "yaml", want: "SQL Backup\n"},
+		{name: "default table columns", format: "table", want: "NAME        ID  ISDISABLED  TYPE\nSQL Backup  a1  false       VSphereBackup\nWeb Backup  b2  true        VSphereBackup\n"},
+		{name: "columns imply table", columns: []string{"name", "storage.quantity"}, want: "NAME        STORAGE.QUANTITY\nSQL Backup  7\nWeb Backup  1000000\n"},
+		{name: "query then table", query: "data[?isDisabled]", columns: []string{"name"}, want: "NAME\nWeb Backup\n"},
+		{name: "scalar list table", query: "data[].id", format: "table", want: "VALUE\na1\nb2\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputQuery, outputFormat, outputColumns = tt.query, tt.format, tt.columns
+			defer func() { outputQuery, outputFormat, outputColumns = "", "", nil }()
+
+			var buf bytes.Buffer
+			if err := printResponse(&buf, data); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("output =\n%s\nwant\n%s", buf.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,317 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/shapedthought/owlctl/config"
+	"github.com/shapedthought/owlctl/models"
+	"github.com/shapedthought/owlctl/resources"
+	"github.com/shapedthought/owlctl/state"
+	"github.com/shapedthought/owlctl/utils"
+	"github.com/shapedthought/owlctl/vhttp"
+	"github.com/spf13/cobra"
+)
+
+var (
+	jobControlGroup      string
+	jobControlSelector   string
+	jobControlWait       bool
+	jobControlTimeout    time.Duration
+	jobControlActiveFull bool
+)
+
+// jobControlAction describes a run control action on a VBR job
+type jobControlAction struct {
+	// Name is the subcommand and API action (e.g., "start" for POST jobs/{id}/start)
+	Name string
+	// Verb is the past-tense verb used in output (e.g., "started")
+	Verb string
+	// StartsSession is true when the API returns a session that can be waited on
+	StartsSession bool
+}
+
+var (
+	jobStartAction   = jobControlAction{Name: "start", Verb: "started", StartsSession: true}
+	jobStopAction    = jobControlAction{Name: "stop", Verb: "stopped", StartsSession: true}
+	jobRetryAction   = jobControlAction{Name: "retry", Verb: "retried", StartsSession: true}
+	jobEnableAction  = jobControlAction{Name: "enable", Verb: "enabled"}
+	jobDisableAction = jobControlAction{Name: "disable", Verb: "disabled"}
+)
+
+// jobControlTarget is a job resolved to its VBR ID
+type jobControlTarget struct {
+	Name string
+	ID   string
+}
+
+// jobControlResult records the outcome of a run control action on one job
+type jobControlResult struct {
+	Job     string
+	Session *vbrSession
+	Error   error
+}
+
+const jobControlExitCodes = `
+Exit codes:
+  0 = Action accepted (or, with --wait, session succeeded)
+  7 = Session finished with Warning (--wait)
+  8 = Session failed (--wait)
+  1 = Error (job not found, API error, or --timeout exceeded)`
+
+const jobControlTargeting = `
+Jobs can be targeted by name, by group (all VBRJob specs in a group from
+owlctl.yaml), or by label selector (matched against the labels of VBRJob specs
+in all groups, or only in --group when both are given). Names are resolved to
+IDs from state, falling back to the VBR API.`
+
+func newJobControlCmd(action jobControlAction, short, examples string) *cobra.Command {
+	long := short + ".\n" + jobControlTargeting + "\n"
+	if action.StartsSession {
+		long += "\nUse --wait to block until the session finishes, printing progress.\n" + jobControlExitCodes
+	}
+
+	c := &cobra.Command{
+		Use:   action.Name + " [job-name...]",
+		Short: short,
+		Long:  long + "\n\nExamples:\n" + examples,
+		Run: func(cmd *cobra.Command, args []string) {
+			runJobControl(action, args)
+		},
+	}
+	c.Flags().StringVar(&jobControlGroup, "group", "", "Target all VBRJob specs in named group (from owlctl.yaml)")
+	c.Flags().StringVarP(&jobControlSelector, "selector", "l", "", "Target jobs whose spec labels match a selector (e.g. env=prod,tier in (gold,silver))")
+	if action.StartsSession {
+		c.Flags().BoolVar(&jobControlWait, "wait", false, "Wait for the session to finish and exit with its result")
+		c.Flags().DurationVar(&jobControlTimeout, "timeout", 0, "Maximum time to wait with --wait (e.g. 2h; default: no limit)")
+	}
+	return c
+}
+
+var jobStartCmd = newJobControlCmd(jobStartAction, "Start VBR jobs", `  owlctl job start "SQL Backup Job"
+  owlctl job start "SQL Backup Job" --wait
+  owlctl job start --group production --wait --timeout 4h
+  owlctl job start -l "tier=gold" --active-full
+`)
+
+var jobStopCmd = newJobControlCmd(jobStopAction, "Stop running VBR jobs", `  owlctl job stop "SQL Backup Job"
+  owlctl job stop --group production --wait
+`)
+
+var jobRetryCmd = newJobControlCmd(jobRetryAction, "Retry failed VBR jobs", `  owlctl job retry "SQL Backup Job" --wait
+  owlctl job retry -l "env=prod"
+`)
+
+var jobEnableCmd = newJobControlCmd(jobEnableAction, "Enable VBR jobs", `  owlctl job enable "SQL Backup Job"
+  owlctl job enable --group dr-site
+`)
+
+var jobDisableCmd = newJobControlCmd(jobDisableAction, "Disable VBR jobs", `  owlctl job disable "SQL Backup Job"
+  owlctl job disable -l "site=primary"
+`)
+
+func runJobControl(action jobControlAction, args []string) {
+	settings := utils.ReadSettings()
+	if settings.SelectedProfile != "vbr" {
+		log.Fatal("This command only works with VBR at the moment.")
+	}
+
+	if len(args) > 0 && (jobControlGroup != "" || jobControlSelector != "") {
+		log.Fatal("Cannot combine job name arguments with --group or --selector")
+	}
+	if len(args) == 0 && jobControlGroup == "" && jobControlSelector == "" {
+		log.Fatal("Provide job name(s), use --group, or use --selector")
+	}
+
+	var names []string
+	profile := utils.GetCurrentProfile()
+	if len(args) > 0 {
+		names = args
+	} else {
+		names, profile = resolveJobNamesFromSpecs(jobControlGroup, jobControlSelector)
+	}
+
+	targets, missing := resolveJobTargets(names, profile)
+	var results []jobControlResult
+	for _, name := range missing {
+		results = append(results, jobControlResult{Job: name, Error: fmt.Errorf("job not found in VBR")})
+		fmt.Printf("  %s: not found in VBR\n", name)
+	}
+
+	for _, t := range targets {
+		result := jobControlResult{Job: t.Name}
+		session, err := postJobAction(action, t.ID, profile)
+		if err != nil {
+			result.Error = err
+			fmt.Printf("  %s: failed to %s: %v\n", t.Name, action.Name, err)
+		} else {
+			result.Session = session
+			fmt.Printf("  %s: %s\n", t.Name, action.Verb)
+		}
+		results = append(results, result)
+	}
+
+	if jobControlWait && action.StartsSession {
+		fmt.Println()
+		for i := range results {
+			if results[i].Error != nil || results[i].Session == nil || results[i].Session.ID == "" {
+				continue
+			}
+			session, err := waitForSession(os.Stdout, results[i].Job, results[i].Session.ID, jobControlTimeout, profile)
+			if err != nil {
+				results[i].Error = err
+				fmt.Printf("  %s: %v\n", results[i].Job, err)
+				continue
+			}
+			results[i].Session = session
+		}
+	}
+
+	os.Exit(jobControlExitCode(results, jobControlWait && action.StartsSession))
+}
+
+// resolveJobNamesFromSpecs returns the names of VBRJob specs in a group and/or matching a selector.
+// Returns the profile to use, which reflects the group's instance when --group is given.
+func resolveJobNamesFromSpecs(group, selectorExpr string) ([]string, models.Profile) {
+	selector, err := resources.ParseSelector(selectorExpr)
+	if err != nil {
+		log.Fatalf("Invalid --selector: %v", err)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load owlctl.yaml: %v", err)
+	}
+	cfg.WarnDeprecatedFields()
+
+	groupNames := cfg.ListGroups()
+	profile := utils.GetCurrentProfile()
+	if group != "" {
+		groupCfg, err := cfg.GetGroup(group)
+		if err != nil {
+			log.Fatalf("Group error: %v", err)
+		}
+		profile = activateGroupInstance(cfg, groupCfg)
+		groupNames = []string{group}
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, g := range groupNames {
+		specs, err := loadMergedGroupSpecs(cfg, cfg.Groups[g])
+		if err != nil {
+			log.Fatalf("Failed to load group %q: %v", g, err)
+		}
+		for _, s := range specs {
+			if s.Error != nil {
+				log.Fatalf("%s: %v", s.SpecPath, s.Error)
+			}
+			if s.Spec.Kind != resources.KindVBRJob || !selector.Matches(s.Spec.Metadata.Labels) {
+				continue
+			}
+			if !seen[s.Spec.Metadata.Name] {
+				seen[s.Spec.Metadata.Name] = true
+				names = append(names, s.Spec.Metadata.Name)
+			}
+		}
+	}
+
+	if len(names) == 0 {
+		fmt.Println("No jobs matched.")
+		os.Exit(ExitError)
+	}
+	sort.Strings(names)
+	return names, profile
+}
+
+// resolveJobTargets resolves job names to VBR IDs, preferring state and falling back to the API.
+// Returns the resolved targets and the names that could not be found.
+func resolveJobTargets(names []string, profile models.Profile) ([]jobControlTarget, []string) {
+	stateMgr := state.NewManager()
+
+	var targets []jobControlTarget
+	var missing []string
+	var apiIDs map[string]string
+
+	for _, name := range names {
+		if res, err := stateMgr.GetResource(name); err == nil && res.Type == "VBRJob" && res.ID != "" {
+			targets = append(targets, jobControlTarget{Name: name, ID: res.ID})
+			continue
+		}
+
+		if apiIDs == nil {
+			apiIDs = fetchJobIDsByName(profile)
+		}
+		if id, ok := apiIDs[name]; ok {
+			targets = append(targets, jobControlTarget{Name: name, ID: id})
+		} else {
+			missing = append(missing, name)
+		}
+	}
+	return targets, missing
+}
+
+// postJobAction sends POST jobs/{id}/{action}. For session-starting actions the returned
+// session model is parsed so it can be waited on.
+func postJobAction(action jobControlAction, jobID string, profile models.Profile) (*vbrSession, error) {
+	var body interface{}
+	switch action.Name {
+	case "start":
+		body = map[string]interface{}{"performActiveFull": jobControlActiveFull}
+	case "stop", "retry":
+		body = map[string]interface{}{}
+	}
+
+	endpoint := fmt.Sprintf("jobs/%s/%s", jobID, action.Name)
+	respBody, err := vhttp.PostDataWithError(endpoint, body, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	if !action.StartsSession || len(respBody) == 0 {
+		return nil, nil
+	}
+
+	var session vbrSession
+	if err := json.Unmarshal(respBody, &session); err != nil {
+		return nil, fmt.Errorf("failed to parse session response: %w", err)
+	}
+	return &session, nil
+}
+
+// jobControlExitCode returns the exit code for a batch of run control results.
+// Errors take precedence; with --wait, Failed sessions outrank Warning sessions.
+func jobControlExitCode(results []jobControlResult, waited bool) int {
+	code := ExitSuccess
+	for _, r := range results {
+		if r.Error != nil {
+			return ExitError
+		}
+		if !waited || r.Session == nil {
+			continue
+		}
+		switch r.Session.Result.Result {
+		case "Failed":
+			code = ExitSessionFailed
+		case "Warning":
+			if code != ExitSessionFailed {
+				code = ExitSessionWarning
+			}
+		}
+	}
+	return code
+}
+
+func init() {
+	jobStartCmd.Flags().BoolVar(&jobControlActiveFull, "active-full", false, "Perform an active full backup")
+
+	jobsCmd.AddCommand(jobStartCmd)
+	jobsCmd.AddCommand(jobStopCmd)
+	jobsCmd.AddCommand(jobRetryCmd)
+	jobsCmd.AddCommand(jobEnableCmd)
+	jobsCmd.AddCommand(jobDisableCmd)
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+)
+
+func sessionWithResult(result string) *vbrSession {
+	s := &vbrSession{ID: "s1", State: "Stopped"}
+	s.Result.Result = result
+	return s
+}
+
+func TestJobControlExitCode(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []jobControlResult
+		waited  bool
+		want    int
+	}{
+		{
+			name:    "accepted without wait",
+			results: []jobControlResult{{Job: "a", Session: sessionWithResult("None")}},
+			want:    ExitSuccess,
+		},
+		{
+			name:    "failed session ignored without wait",
+			results: []jobControlResult{{Job: "a", Session: sessionWithResult("Failed")}},
+			want:    ExitSuccess,
+		},
+		{
+			name:    "success with wait",
+			results: []jobControlResult{{Job: "a", Session: sessionWithResult("Success")}},
+			waited:  true,
+			want:    ExitSuccess,
+		},
+		{
+			name: "warning with wait",
+			results: []jobControlResult{
+				{Job: "a", Session: sessionWithResult("Success")},
+				{Job: "b", Session: sessionWithResult("Warning")},
+			},
+			waited: true,
+			want:   ExitSessionWarning,
+		},
+		{
+			name: "failed outranks warning",
+			results: []jobControlResult{
+				{Job: "a", Session: sessionWithResult("Failed")},
+				{Job: "b", Session: sessionWithResult("Warning")},
+			},
+			waited: true,
+			want:   ExitSessionFailed,
+		},
+		{
+			name: "error outranks session results",
+			results: []jobControlResult{
+				{Job: "a", Session: sessionWithResult("Failed")},
+				{Job: "b", Error: errors.New("job not found in VBR")},
+			},
+			waited: true,
+			want:   ExitError,
+		},
+		{
+			name:    "enable without session",
+			results: []jobControlResult{{Job: "a"}},
+			want:    ExitSuccess,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jobControlExitCode(tt.results, tt.waited); got != tt.want {
+				t.Errorf("jobControlExitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -309,6 +309,15 @@ var (
 	snapshotAll   bool
 )
 
+// jobDiffConfig compares jobs against job specs, with the job-specific drift checks
+var jobDiffConfig = GroupDiffConfig{
+	Kind:         resources.KindVBRJob,
+	DisplayName:  "job",
+	FetchCurrent: fetchCurrentJob,
+	DetectDrift:  detectJobDrift,
+	RemediateCmd: "owlctl job apply --group %s",
+}
+
 var diffCmd = &cobra.Command{
 	Use:   "diff [job-name]",
 	Short: "Detect configuration drift from applied state",
@@ -330,27 +339,39 @@ Examples:
   # Show only critical drifts
   owlctl job diff --all --severity critical
 
+  # Compare against the job specs in Git instead of state
+  owlctl job diff --all --against specs
+
 Exit Codes:
   0 - No drift detected
   3 - Drift detected (INFO or WARNING)
   4 - Critical security drift detected
   1 - Error occurred`,
 	Run: func(cmd *cobra.Command, args []string) {
-		if diffGroupName != "" {
+		if diffGroupName != "" || (labelSelector != "" && !diffAll) {
 			// Validate mutual exclusivity
 			if diffAll {
 				log.Fatal("Cannot use --group with --all")
 			}
 			if len(args) > 0 {
-				log.Fatal("Cannot use --group with a positional job name argument")
+				log.Fatal("Cannot use --group or --selector with a positional job name argument")
 			}
+			checkGroupAgainst()
 			diffGroup(diffGroupName)
+		} else if againstSpecs() {
+			if diffAll {
+				diffAgainstSpecs(jobDiffConfig, "")
+			} else if len(args) > 0 {
+				diffAgainstSpecs(jobDiffConfig, args[0])
+			} else {
+				log.Fatal("Provide job name or use --all")
+			}
 		} else if diffAll {
 			diffAllJobs()
 		} else if len(args) > 0 {
 			diffSingleJob(args[0])
 		} else {
-			log.Fatal("Provide job name, use --all, or use --group")
+			log.Fatal("Provide job name, use --all, use --group, or use --selector")
 		}
 	},
 }
@@ -391,6 +412,15 @@ After snapshotting, use 'owlctl job diff' to detect drift:
 	},
 }
 
+// detectJobDrift compares a job's state spec against live VBR and classifies the drift,
+// including the directional and repository hardening checks
+func detectJobDrift(stateSpec, liveSpec map[string]interface{}) []Drift {
+	drifts := detectDrift(stateSpec, liveSpec, jobIgnoreFields)
+	drifts = classifyDrifts(drifts, jobSeverityMap)
+	drifts = enhanceJobDriftSeverity(drifts)
+	return checkRepoHardeningDrift(drifts, stateSpec)
+}
+
 func diffSingleJob(jobName string) {
 	loadSeverityOverrides()
 	settings := utils.ReadSettings()
@@ -424,20 +454,20 @@ func diffSingleJob(jobName string) {
 	}
 
 	// Compare, classify, enhance, filter
-	drifts := detectDrift(resource.Spec, currentMap, jobIgnoreFields)
-	drifts = classifyDrifts(drifts, jobSeverityMap)
-	drifts = enhanceJobDriftSeverity(drifts)
-	drifts = checkRepoHardeningDrift(drifts, resource.Spec)
+	drifts := detectJobDrift(resource.Spec, currentMap)
 	minSev := parseSeverityFlag()
+	drifts = applySeverityRules("VBRJob", resource.Name, resource.Labels, drifts)
+	drifts = suppressAckedDrifts(resource.Name, drifts)
 	drifts = filterDriftsBySeverity(drifts, minSev)
 
 	if len(drifts) == 0 {
 		fmt.Println(noDriftMessage("Job matches applied state.", minSev))
-		os.Exit(0)
+		ciExit(ExitSuccess)
 	}
 
 	// Display drift
 	printSecuritySummary(drifts)
+	reportDrifts("VBRJob", jobName, drifts)
 	fmt.Println("Drift detected:")
 	for _, drift := range drifts {
 		printDriftWithSeverity(drift)
@@ -457,7 +487,7 @@ func diffSingleJob(jobName string) {
 	// Show guidance based on origin
 	printRemediationGuidance(BuildJobGuidance(jobName, resource.Origin))
 
-	os.Exit(exitCodeForDrifts(drifts))
+	ciExit(exitCodeForDrifts(drifts))
 }
 
 func diffAllJobs() {
@@ -470,7 +500,7 @@ func diffAllJobs() {
 	}
 
 	stateMgr := state.NewManager()
-	resources, err := stateMgr.ListResources("VBRJob")
+	resources, err := listSelectedResources(stateMgr, "VBRJob")
 	if err != nil {
 		log.Fatalf("Failed to load state: %v\n", err)
 	}
@@ -500,10 +530,9 @@ func diffAllJobs() {
 		}
 
 		// Detect, classify, enhance, filter
-		drifts := detectDrift(resource.Spec, currentMap, jobIgnoreFields)
-		drifts = classifyDrifts(drifts, jobSeverityMap)
-		drifts = enhanceJobDriftSeverity(drifts)
-		drifts = checkRepoHardeningDrift(drifts, resource.Spec)
+		drifts := detectJobDrift(resource.Spec, currentMap)
+		drifts = applySeverityRules("VBRJob", resource.Name, resource.Labels, drifts)
+		drifts = suppressAckedDrifts(resource.Name, drifts)
 		drifts = filterDriftsBySeverity(drifts, minSev)
 
 		// Show origin label for observed resources
@@ -515,6 +544,7 @@ func diffAllJobs() {
 		if len(drifts) > 0 {
 			maxSev := getMaxSeverity(drifts)
 			fmt.Printf("  %s %s%s: %d drifts detected\n", maxSev, resource.Name, originLabel, len(drifts))
+			reportDrifts("VBRJob", resource.Name, drifts)
 			allDrifts = append(allDrifts, drifts...)
 			if resource.Origin == "observed" {
 				driftedObserved++
@@ -543,9 +573,9 @@ func diffAllJobs() {
 	}
 
 	if totalDrifted > 0 {
-		os.Exit(exitCodeForDrifts(allDrifts))
+		ciExit(exitCodeForDrifts(allDrifts))
 	}
-	os.Exit(0)
+	ciExit(ExitSuccess)
 }
 
 // diffGroup compares merged group specs (profile+spec+overlay) against live VBR state.
@@ -560,7 +590,7 @@ func diffGroup(group string) {
 	}
 	cfg.WarnDeprecatedFields()
 
-	groupCfg, err := cfg.GetGroup(group)
+	groupCfg, err := lookupGroup(cfg, group)
 	if err != nil {
 		log.Fatalf("Group error: %v", err)
 	}
@@ -569,55 +599,27 @@ func diffGroup(group string) {
 	profile := activateGroupInstance(cfg, groupCfg)
 
 	// Resolve effective specs (Specs + SpecsDir)
-	specsList := resolveGroupSpecs(cfg, groupCfg)
+	specsList := resolveGroupSpecs(cfg, groupCfg, resources.KindVBRJob)
 
 	if len(specsList) == 0 {
-		log.Fatalf("Group %q has no specs defined", group)
+		noGroupSpecs(group, resources.KindVBRJob)
 	}
 
-	// Resolve paths
-	profilePath := ""
-	if groupCfg.Profile != "" {
-		profilePath = cfg.ResolvePath(groupCfg.Profile)
-	}
-	overlayPath := ""
-	if groupCfg.Overlay != "" {
-		overlayPath = cfg.ResolvePath(groupCfg.Overlay)
+	// Load the profile and overlay chains once to avoid repeated disk I/O
+	layers, err := loadGroupLayers(cfg, groupCfg)
+	if err != nil {
+		log.Fatalf("Group error: %v", err)
 	}
 
-	fmt.Printf("Checking drift for group: %s (%d specs)\n", group, len(specsList))
+	fmt.Printf("Checking drift for group: %s (%d specs)\n", groupLabel(group), len(specsList))
 	if instanceFlag != "" {
 		fmt.Printf("  Instance: %s (from --instance flag)\n", instanceFlag)
 	} else if groupCfg.Instance != "" {
 		fmt.Printf("  Instance: %s\n", groupCfg.Instance)
 	}
-	if profilePath != "" {
-		fmt.Printf("  Profile: %s\n", groupCfg.Profile)
-	}
-	if overlayPath != "" {
-		fmt.Printf("  Overlay: %s\n", groupCfg.Overlay)
-	}
+	layers.printHeader()
 	fmt.Println()
 
-	// Pre-load profile and overlay once to avoid repeated disk I/O
-	var profileSpec, overlaySpec *resources.ResourceSpec
-	opts := resources.DefaultMergeOptions()
-
-	if profilePath != "" {
-		p, err := resources.LoadResourceSpec(profilePath)
-		if err != nil {
-			log.Fatalf("Failed to load profile %s: %v", profilePath, err)
-		}
-		profileSpec = &p
-	}
-	if overlayPath != "" {
-		o, err := resources.LoadResourceSpec(overlayPath)
-		if err != nil {
-			log.Fatalf("Failed to load overlay %s: %v", overlayPath, err)
-		}
-		overlaySpec = &o
-	}
-
 	minSev := parseSeverityFlag()
 	cleanCount := 0
 	driftedCount := 0
@@ -637,7 +639,7 @@ func diffGroup(group string) {
 		}
 
 		// Compute desired state from group merge using cached profile/overlay
-		desiredSpec, err := resources.ApplyGroupMergeFromSpecs(spec, profileSpec, overlaySpec, opts)
+		desiredSpec, err := layers.merge(spec, specRelPath)
 		if err != nil {
 			fmt.Printf("  %s: Failed to merge: %v\n", specRelPath, err)
 			errorCount++
@@ -667,15 +669,15 @@ func diffGroup(group string) {
 		}
 
 		// Compare merged desired spec against live VBR
-		drifts := detectDrift(desiredSpec.Spec, currentMap, jobIgnoreFields)
-		drifts = classifyDrifts(drifts, jobSeverityMap)
-		drifts = enhanceJobDriftSeverity(drifts)
-		drifts = checkRepoHardeningDrift(drifts, desiredSpec.Spec)
+		drifts := detectJobDrift(desiredSpec.Spec, currentMap)
+		drifts = applySeverityRules("VBRJob", jobName, desiredSpec.Metadata.Labels, drifts)
+		drifts = suppressAckedDrifts(jobName, drifts)
 		drifts = filterDriftsBySeverity(drifts, minSev)
 
 		if len(drifts) > 0 {
 			maxSev := getMaxSeverity(drifts)
 			fmt.Printf("  %s %s: %d drifts detected\n", maxSev, jobName, len(drifts))
+			reportDrifts("VBRJob", jobName, drifts)
 			allDrifts = append(allDrifts, drifts...)
 			driftedCount++
 		} else {
@@ -693,7 +695,7 @@ func diffGroup(group string) {
 	fmt.Printf("\nSummary:\n")
 	fmt.Printf("  - %d jobs clean\n", cleanCount)
 	if driftedCount > 0 {
-		fmt.Printf("  - %d jobs drifted — remediate with: owlctl job apply --group %s\n", driftedCount, group)
+		fmt.Printf("  - %d jobs drifted — remediate with: %s\n", driftedCount, remediateCommand("owlctl job apply --group %s", group))
 	}
 	if notFoundCount > 0 {
 		fmt.Printf("  - %d jobs not found in VBR (would be created by apply)\n", notFoundCount)
@@ -703,12 +705,12 @@ func diffGroup(group string) {
 	}
 
 	if errorCount > 0 {
-		os.Exit(ExitError)
+		ciExit(ExitError)
 	}
 	if driftedCount > 0 {
-		os.Exit(exitCodeForDrifts(allDrifts))
+		ciExit(exitCodeForDrifts(allDrifts))
 	}
-	os.Exit(0)
+	ciExit(ExitSuccess)
 }
 
 // snapshotSingleJob captures a single job's current configuration into state
@@ -786,6 +788,8 @@ func saveJobToState(name, id string, rawData json.RawMessage) error {
 func init() {
 	diffCmd.Flags().BoolVar(&diffAll, "all", false, "Check drift for all jobs in state")
 	diffCmd.Flags().StringVar(&diffGroupName, "group", "", "Check drift for all specs in named group (from owlctl.yaml)")
+	addSelectorFlag(diffCmd, "Check drift for specs whose labels match a selector; with --all, filters state by recorded labels")
+	addAgainstFlag(diffCmd)
 	addSeverityFlags(diffCmd)
 	jobsCmd.AddCommand(diffCmd)
 
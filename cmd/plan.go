@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/shapedthought/owlctl/config"
+	"github.com/shapedthought/owlctl/models"
 	"github.com/shapedthought/owlctl/resources"
 	"github.com/shapedthought/owlctl/utils"
 	"github.com/spf13/cobra"
@@ -17,8 +18,18 @@ var (
 	planOverlayFile string
 	planEnvironment string
 	planShowYAML    bool
+	planGroupName   string
 )
 
+// jobPlan is a merged job spec ready to be previewed against VBR
+type jobPlan struct {
+	Spec       resources.ResourceSpec
+	SpecFile   string
+	Layers     []string          // Header lines describing the merge layers
+	Provenance map[string]string // Which file set each field (nil when no merge happened)
+	ApplyCmd   string
+}
+
 var planCmd = &cobra.Command{
 	Use:   "plan [config-file]",
 	Short: "Preview merged configuration without applying",
@@ -43,12 +54,41 @@ Examples:
   # Show full YAML output
   owlctl job plan base-job.yaml --overlay prod-overlay.yaml --show-yaml
 
+  # Plan every job spec whose labels match a selector
+  owlctl job plan -l "env=prod"
+
+  # Plan every job in a group (profile + spec + overlay chains)
+  owlctl job plan --group sql-tier
+
+When specs are merged with profiles or overlays, each change shows the file that
+set the new value, e.g. "(from overlays/compliance.yaml)".
+
 Note: This command shows the merged configuration but does not compare
 against current VBR state. Full drift detection will be available in Phase 2.
 `,
-	Args: cobra.ExactArgs(1),
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		planJob(args[0])
+		if planGroupName != "" {
+			if len(args) > 0 || planOverlayFile != "" || planEnvironment != "" {
+				log.Fatal("--group cannot be combined with a config file, --overlay or --env")
+			}
+			planGroup(planGroupName)
+		} else if labelSelector != "" {
+			if len(args) > 0 {
+				log.Fatal("Cannot use --selector with a positional config file argument")
+			}
+			paths, _ := selectSpecsByLabel(resources.KindVBRJob)
+			for i, path := range paths {
+				if i > 0 {
+					fmt.Println()
+				}
+				planJob(path)
+			}
+		} else if len(args) > 0 {
+			planJob(args[0])
+		} else {
+			log.Fatal("Provide a config file or use --selector")
+		}
 	},
 }
 
@@ -72,16 +112,17 @@ func planJob(configFile string) {
 
 	// Determine which overlay to use (same logic as apply command)
 	var finalSpec resources.ResourceSpec
+	var provenance map[string]string
 	var overlayUsed string
 
 	if planOverlayFile != "" {
 		// Explicit overlay file provided
 		overlayUsed = planOverlayFile
-		mergedSpec, err := resources.MergeYAMLFiles(configFile, planOverlayFile, resources.DefaultMergeOptions())
+		mergedSpec, mergedProvenance, err := resources.MergeYAMLFilesWithProvenance(configFile, planOverlayFile, resources.DefaultMergeOptions())
 		if err != nil {
 			log.Fatalf("Failed to merge with overlay: %v", err)
 		}
-		finalSpec = mergedSpec
+		finalSpec, provenance = mergedSpec, mergedProvenance
 	} else if planEnvironment != "" || needsPlanConfigOverlay() {
 		// Try to use overlay from owlctl.yaml
 		overlayPath, err := getPlanConfiguredOverlay()
@@ -91,11 +132,11 @@ func planJob(configFile string) {
 			finalSpec = baseSpec
 		} else {
 			overlayUsed = overlayPath
-			mergedSpec, err := resources.MergeYAMLFiles(configFile, overlayPath, resources.DefaultMergeOptions())
+			mergedSpec, mergedProvenance, err := resources.MergeYAMLFilesWithProvenance(configFile, overlayPath, resources.DefaultMergeOptions())
 			if err != nil {
 				log.Fatalf("Failed to merge with configured overlay: %v", err)
 			}
-			finalSpec = mergedSpec
+			finalSpec, provenance = mergedSpec, mergedProvenance
 		}
 	} else {
 		// No overlay specified
@@ -103,6 +144,102 @@ func planJob(configFile string) {
 		finalSpec = baseSpec
 	}
 
+	if err := validateSpec(finalSpec, provenance); err != nil {
+		log.Fatal(err)
+	}
+
+	applyCmd := fmt.Sprintf("owlctl job apply %s", configFile)
+	if overlayUsed != "none" {
+		if planOverlayFile != "" {
+			applyCmd = fmt.Sprintf("owlctl job apply %s --overlay %s", configFile, planOverlayFile)
+		} else {
+			applyCmd = fmt.Sprintf("owlctl job apply %s --env %s", configFile, planEnvironment)
+		}
+	}
+
+	showPlan(jobPlan{
+		Spec:       finalSpec,
+		SpecFile:   configFile,
+		Layers:     []string{fmt.Sprintf("Overlay:       %s", overlayUsed)},
+		Provenance: provenance,
+		ApplyCmd:   applyCmd,
+	}, utils.GetCurrentProfile())
+}
+
+// planGroup previews every job spec in a group, merged with the group's profile and overlay chains
+func planGroup(group string) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load owlctl.yaml: %v", err)
+	}
+	cfg.WarnDeprecatedFields()
+
+	groupCfg, err := lookupGroup(cfg, group)
+	if err != nil {
+		log.Fatalf("Group error: %v", err)
+	}
+
+	profile := activateGroupInstance(cfg, groupCfg)
+
+	specsList := resolveGroupSpecs(cfg, groupCfg, resources.KindVBRJob)
+	if len(specsList) == 0 {
+		noGroupSpecs(group, resources.KindVBRJob)
+	}
+
+	layers, err := loadGroupLayers(cfg, groupCfg)
+	if err != nil {
+		log.Fatalf("Group error: %v", err)
+	}
+	for _, w := range layers.Warnings {
+		fmt.Printf("Warning: %s\n", w)
+	}
+
+	var layerLines []string
+	if len(layers.Profiles) > 0 {
+		layerLines = append(layerLines, fmt.Sprintf("Profiles:      %s", strings.Join(layerSources(layers.Profiles), " -> ")))
+	}
+	if len(layers.Overlays) > 0 {
+		layerLines = append(layerLines, fmt.Sprintf("Overlays:      %s", strings.Join(layerSources(layers.Overlays), " -> ")))
+	}
+
+	for i, specRelPath := range specsList {
+		if i > 0 {
+			fmt.Println()
+		}
+
+		spec, err := resources.LoadResourceSpec(cfg.ResolvePath(specRelPath))
+		if err != nil {
+			fmt.Printf("%s: Failed to load spec: %v\n", specRelPath, err)
+			continue
+		}
+		merged, err := layers.mergeChain(spec, specRelPath)
+		if err != nil {
+			fmt.Printf("%s: Failed to merge: %v\n", specRelPath, err)
+			continue
+		}
+		if merged.Spec.Kind != resources.KindVBRJob {
+			fmt.Printf("%s: Unsupported kind: %s (only VBRJob is supported)\n", specRelPath, merged.Spec.Kind)
+			continue
+		}
+		if err := validateSpec(merged.Spec, merged.Provenance); err != nil {
+			fmt.Printf("%s: %v\n", specRelPath, err)
+			continue
+		}
+
+		showPlan(jobPlan{
+			Spec:       merged.Spec,
+			SpecFile:   specRelPath,
+			Layers:     layerLines,
+			Provenance: merged.Provenance,
+			ApplyCmd:   fmt.Sprintf("owlctl job apply --group %s", group),
+		}, profile)
+	}
+}
+
+// showPlan prints the plan for one merged job spec, comparing it against the job in VBR
+func showPlan(plan jobPlan, profile models.Profile) {
+	finalSpec := plan.Spec
+
 	// Display plan header
 	fmt.Println("╔════════════════════════════════════════════════════════════════════╗")
 	fmt.Println("║                     Configuration Plan Preview                     ║")
@@ -111,8 +248,10 @@ func planJob(configFile string) {
 
 	fmt.Printf("Resource Name: %s\n", finalSpec.Metadata.Name)
 	fmt.Printf("Resource Type: %s\n", finalSpec.Kind)
-	fmt.Printf("Base Config:   %s\n", configFile)
-	fmt.Printf("Overlay:       %s\n", overlayUsed)
+	fmt.Printf("Base Config:   %s\n", plan.SpecFile)
+	for _, line := range plan.Layers {
+		fmt.Println(line)
+	}
 	fmt.Println()
 
 	// Show labels if present
@@ -125,7 +264,6 @@ func planJob(configFile string) {
 	}
 
 	// Fetch current job from VBR to show diff
-	profile := utils.GetCurrentProfile()
 	currentRaw, currentID, err := fetchCurrentJob(finalSpec.Metadata.Name, profile)
 	if err != nil {
 		log.Fatalf("Failed to fetch current job: %v", err)
@@ -145,14 +283,19 @@ func planJob(configFile string) {
 		if err := json.Unmarshal(currentRaw, &currentMap); err != nil {
 			log.Fatalf("Failed to unmarshal current job: %v", err)
 		}
-		showJobDiff(finalSpec, currentMap)
+		showJobDiff(finalSpec, currentMap, plan.Provenance)
 	}
 
 	// Show full YAML if requested
 	if planShowYAML {
 		fmt.Println("\nFull YAML Configuration:")
 		fmt.Println("─────────────────────────────────────────────────────────────────")
-		yamlBytes, err := yaml.Marshal(finalSpec)
+		var yamlBytes []byte
+		if plan.Provenance != nil {
+			yamlBytes, err = renderMergedSpec(finalSpec, plan.Provenance, true)
+		} else {
+			yamlBytes, err = yaml.Marshal(finalSpec)
+		}
 		if err != nil {
 			log.Fatalf("Failed to marshal YAML: %v", err)
 		}
@@ -163,15 +306,7 @@ func planJob(configFile string) {
 	// Show next steps
 	fmt.Println("\nNext Steps:")
 	fmt.Printf("  To apply this configuration:\n")
-	if overlayUsed != "none" {
-		if planOverlayFile != "" {
-			fmt.Printf("    owlctl job apply %s --overlay %s\n", configFile, planOverlayFile)
-		} else {
-			fmt.Printf("    owlctl job apply %s --env %s\n", configFile, planEnvironment)
-		}
-	} else {
-		fmt.Printf("    owlctl job apply %s\n", configFile)
-	}
+	fmt.Printf("    %s\n", plan.ApplyCmd)
 	fmt.Println()
 }
 
@@ -275,8 +410,9 @@ func showNewJobSummary(spec resources.ResourceSpec) {
 	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 }
 
-// showJobDiff compares desired spec with current VBR job (as map) and displays differences
-func showJobDiff(desiredSpec resources.ResourceSpec, currentMap map[string]interface{}) {
+// showJobDiff compares desired spec with current VBR job (as map) and displays differences.
+// provenance (may be nil) labels each change with the profile, spec or overlay file that set it.
+func showJobDiff(desiredSpec resources.ResourceSpec, currentMap map[string]interface{}, provenance map[string]string) {
 	// Convert both to comparable maps
 	desiredMap := desiredSpec.Spec
 
@@ -319,28 +455,28 @@ func showJobDiff(desiredSpec resources.ResourceSpec, currentMap map[string]inter
 	if len(storageDrifts) > 0 {
 		fmt.Println("\nStorage Settings:")
 		for _, d := range storageDrifts {
-			printPlanDrift(d)
+			printPlanDrift(d, resources.ProvenanceFor(provenance, d.Path))
 		}
 	}
 
 	if len(scheduleDrifts) > 0 {
 		fmt.Println("\nSchedule Settings:")
 		for _, d := range scheduleDrifts {
-			printPlanDrift(d)
+			printPlanDrift(d, resources.ProvenanceFor(provenance, d.Path))
 		}
 	}
 
 	if len(objectDrifts) > 0 {
 		fmt.Println("\nBackup Objects:")
 		for _, d := range objectDrifts {
-			printPlanDrift(d)
+			printPlanDrift(d, resources.ProvenanceFor(provenance, d.Path))
 		}
 	}
 
 	if len(otherDrifts) > 0 {
 		fmt.Println("\nOther Settings:")
 		for _, d := range otherDrifts {
-			printPlanDrift(d)
+			printPlanDrift(d, resources.ProvenanceFor(provenance, d.Path))
 		}
 	}
 
@@ -369,8 +505,9 @@ func showJobDiff(desiredSpec resources.ResourceSpec, currentMap map[string]inter
 	fmt.Println()
 }
 
-// printPlanDrift prints a single drift in plan format, labeling values as current (VBR) and new (desired)
-func printPlanDrift(drift Drift) {
+// printPlanDrift prints a single drift in plan format, labeling values as current (VBR) and new (desired).
+// source is the file that set the desired value, if known.
+func printPlanDrift(drift Drift, source string) {
 	// For plan, we show: current (VBR) -> new (desired from YAML)
 	// Drift detection also compares desired (state) to current (VBR) and shows it as: state -> VBR
 	// The comparison direction is the same, we just label the values differently for clarity
@@ -381,11 +518,11 @@ func printPlanDrift(drift Drift) {
 	case "modified":
 		desiredStr := formatValue(drift.State) // What we want (from YAML)
 		currentStr := formatValue(drift.VBR)   // What's currently in VBR
-		fmt.Printf("  %s ~ %s: %s (current) -> %s (new)\n", sev, drift.Path, currentStr, desiredStr)
+		fmt.Printf("  %s ~ %s: %s (current) -> %s (new)%s\n", sev, drift.Path, currentStr, desiredStr, changeSourceSuffix(source))
 	case "removed":
 		// Field exists in YAML but not in VBR - will be added when applying
 		desiredStr := formatValue(drift.State)
-		fmt.Printf("  %s + %s: Will be added with value %s\n", sev, drift.Path, desiredStr)
+		fmt.Printf("  %s + %s: Will be added with value %s%s\n", sev, drift.Path, desiredStr, changeSourceSuffix(source))
 	case "added":
 		// Field exists in VBR but not in YAML - will be removed/unset when applying
 		currentStr := formatValue(drift.VBR)
@@ -397,6 +534,8 @@ func init() {
 	planCmd.Flags().StringVar(&planOverlayFile, "overlay", "", "Overlay file to merge with base configuration")
 	planCmd.Flags().StringVar(&planEnvironment, "env", "", "Environment to use (looks up overlay from owlctl.yaml)")
 	planCmd.Flags().BoolVar(&planShowYAML, "show-yaml", false, "Display full merged YAML configuration")
+	planCmd.Flags().StringVar(&planGroupName, "group", "", "Plan all job specs in named group (from owlctl.yaml)")
+	addSelectorFlag(planCmd, "Plan every VBRJob spec whose labels match a selector; with --group, filters the group (e.g. env=prod)")
 
 	jobsCmd.AddCommand(planCmd)
 }
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/shapedthought/owlctl/models"
+	"github.com/shapedthought/owlctl/resources"
+)
+
+// planFileVersion is the saved plan format version. Plans written by a different
+// version are rejected rather than guessed at.
+const planFileVersion = 1
+
+// planKeyEnv names the environment variable holding the key used to sign plan files.
+// When set at plan time the plan is signed; when set at apply time a valid signature is required.
+const planKeyEnv = "OWLCTL_PLAN_KEY"
+
+// savedPlan is the content of a plan file written by "owlctl plan --out".
+// Each resource carries the exact payload apply will send, plus a fingerprint of the live
+// resource at planning time so apply can refuse to run against a changed VBR.
+type savedPlan struct {
+	Version   int             `json:"version"`
+	CreatedAt time.Time       `json:"createdAt"`
+	CreatedBy string          `json:"createdBy"`
+	Server    string          `json:"server"`             // OWLCTL_URL the plan was made against
+	Instance  string          `json:"instance,omitempty"` // Active instance, re-activated on apply
+	Resources []plannedChange `json:"resources"`
+}
+
+// plannedChange is one resource in a plan
+type plannedChange struct {
+	Kind       string                 `json:"kind"`
+	Name       string                 `json:"name"`
+	SpecPath   string                 `json:"specPath"`
+	Action     string                 `json:"action"` // "create", "update" or "no-op"
+	ResourceID string                 `json:"resourceId,omitempty"`
+	Payload    map[string]interface{} `json:"payload,omitempty"`
+	Changes    []FieldChange          `json:"changes,omitempty"`
+	Skipped    []SkippedField         `json:"skipped,omitempty"`
+	LiveHash   string                 `json:"liveHash,omitempty"` // Empty when the resource did not exist
+	// Spec is the merged spec, recorded in state once the change is applied
+	Spec resources.ResourceSpec `json:"spec"`
+}
+
+// planEnvelope wraps the plan with its checksum and optional signature. The plan is kept as
+// raw bytes so the checksum covers exactly what was written.
+type planEnvelope struct {
+	Plan      json.RawMessage `json:"plan"`
+	SHA256    string          `json:"sha256"`
+	Signature string          `json:"signature,omitempty"` // HMAC-SHA256 of the plan with OWLCTL_PLAN_KEY
+}
+
+// writePlanFile writes a plan as gzip-compressed JSON, checksummed and, if key is non-empty, signed
+func writePlanFile(path string, plan savedPlan, key string) error {
+	planJSON, err := json.Marshal(plan)
+	if err != nil {
+		return fmt.Errorf("failed to encode plan: %w", err)
+	}
+
+	sum := sha256.Sum256(planJSON)
+	envelope := planEnvelope{Plan: planJSON, SHA256: hex.EncodeToString(sum[:])}
+	if key != "" {
+		envelope.Signature = signPlan(planJSON, key)
+	}
+
+	envelopeJSON, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("failed to encode plan: %w", err)
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(envelopeJSON); err != nil {
+		return fmt.Errorf("failed to compress plan: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to compress plan: %w", err)
+	}
+
+	// Payloads may include sensitive settings, so keep the file private
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write plan file: %w", err)
+	}
+	return nil
+}
+
+// readPlanFile reads and verifies a plan file. The checksum must match; if key is non-empty the
+// plan must carry a valid signature, and a signed plan cannot be read without the key.
+func readPlanFile(path string, key string) (savedPlan, error) {
+	var plan savedPlan
+
+	f, err := os.Open(path)
+	if err != nil {
+		return plan, fmt.Errorf("failed to open plan file: %w", err)
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return plan, fmt.Errorf("%s is not an owlctl plan file: %w", path, err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return plan, fmt.Errorf("failed to read plan file: %w", err)
+	}
+
+	var envelope planEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil || len(envelope.Plan) == 0 {
+		return plan, fmt.Errorf("%s is not an owlctl plan file", path)
+	}
+
+	sum := sha256.Sum256(envelope.Plan)
+	if hex.EncodeToString(sum[:]) != envelope.SHA256 {
+		return plan, fmt.Errorf("plan file checksum mismatch: %s has been modified or corrupted", path)
+	}
+
+	switch {
+	case key != "" && envelope.Signature == "":
+		return plan, fmt.Errorf("plan file is not signed, but %s is set", planKeyEnv)
+	case key == "" && envelope.Signature != "":
+		return plan, fmt.Errorf("plan file is signed: set %s to verify it", planKeyEnv)
+	case key != "" && !hmac.Equal([]byte(envelope.Signature), []byte(signPlan(envelope.Plan, key))):
+		return plan, fmt.Errorf("plan file signature is invalid (wrong %s, or the plan was modified)", planKeyEnv)
+	}
+
+	if err := json.Unmarshal(envelope.Plan, &plan); err != nil {
+		return plan, fmt.Errorf("failed to decode plan: %w", err)
+	}
+	if plan.Version != planFileVersion {
+		return plan, fmt.Errorf("unsupported plan file version %d (expected %d)", plan.Version, planFileVersion)
+	}
+	return plan, nil
+}
+
+func signPlan(planJSON []byte, key string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write(planJSON)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// liveResourceHash fingerprints a live VBR resource, ignoring runtime fields, so a plan can tell
+// whether the resource changed between planning and apply. A nil resource hashes to "".
+func liveResourceHash(live map[string]interface{}, ignoreFields map[string]bool) string {
+	if live == nil {
+		return ""
+	}
+	// encoding/json sorts map keys, so equal resources always produce the same bytes
+	data, err := json.Marshal(cleanSpec(live, ignoreFields))
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// stalePlanResources re-fetches every resource in a plan and describes each one whose live
+// state no longer matches what was planned against
+func stalePlanResources(plan savedPlan, profile models.Profile, configFor func(kind string) (ResourceApplyConfig, bool)) []string {
+	var stale []string
+	for _, r := range plan.Resources {
+		cfg, ok := configFor(r.Kind)
+		if !ok {
+			stale = append(stale, fmt.Sprintf("%s %q: kind is not supported by this version of owlctl", r.Kind, r.Name))
+			continue
+		}
+
+		raw, id, err := cfg.FetchCurrent(r.Name, profile)
+		if err != nil {
+			stale = append(stale, fmt.Sprintf("%s %q: failed to fetch current resource: %v", r.Kind, r.Name, err))
+			continue
+		}
+
+		var live map[string]interface{}
+		if raw != nil && id != "" {
+			if err := json.Unmarshal(raw, &live); err != nil {
+				stale = append(stale, fmt.Sprintf("%s %q: failed to parse current resource: %v", r.Kind, r.Name, err))
+				continue
+			}
+		}
+
+		current := liveResourceHash(live, cfg.IgnoreFields)
+		switch {
+		case current == r.LiveHash:
+		case r.LiveHash == "":
+			stale = append(stale, fmt.Sprintf("%s %q: created in VBR since the plan was made", r.Kind, r.Name))
+		case current == "":
+			stale = append(stale, fmt.Sprintf("%s %q: deleted from VBR since the plan was made", r.Kind, r.Name))
+		default:
+			stale = append(stale, fmt.Sprintf("%s %q: changed in VBR since the plan was made", r.Kind, r.Name))
+		}
+	}
+	return stale
+}
@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shapedthought/owlctl/models"
+)
+
+func testPlan() savedPlan {
+	return savedPlan{
+		Version:   planFileVersion,
+		CreatedAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+		CreatedBy: "ci",
+		Server:    "https://vbr.example.com",
+		Resources: []plannedChange{
+			{
+				Kind:       "VBRJob",
+				Name:       "SQL Backup",
+				SpecPath:   "specs/sql.yaml",
+				Action:     "update",
+				ResourceID: "job-1",
+				Payload:    map[string]interface{}{"id": "job-1", "description": "nightly"},
+				Changes:    []FieldChange{{Path: "description", OldValue: "old", NewValue: "nightly", Source: "overlays/prod.yaml"}},
+				LiveHash:   "abc",
+			},
+		},
+	}
+}
+
+func TestPlanFile_RoundTrip(t *testing.T) {
+	for _, key := range []string{"", "secret"} {
+		path := filepath.Join(t.TempDir(), "plan.bin")
+		if err := writePlanFile(path, testPlan(), key); err != nil {
+			t.Fatalf("writePlanFile: %v", err)
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("plan file mode = %v, want 0600", info.Mode().Perm())
+		}
+
+		plan, err := readPlanFile(path, key)
+		if err != nil {
+			t.Fatalf("readPlanFile (key %q): %v", key, err)
+		}
+		if len(plan.Resources) != 1 || plan.Resources[0].Payload["description"] != "nightly" {
+			t.Errorf("resources = %+v", plan.Resources)
+		}
+		if c := plan.Resources[0].Changes[0]; c.Source != "overlays/prod.yaml" || c.NewValue != "nightly" {
+			t.Errorf("change = %+v", c)
+		}
+	}
+}
+
+func TestPlanFile_Verification(t *testing.T) {
+	dir := t.TempDir()
+
+	signed := filepath.Join(dir, "signed.bin")
+	if err := writePlanFile(signed, testPlan(), "secret"); err != nil {
+		t.Fatal(err)
+	}
+	unsigned := filepath.Join(dir, "unsigned.bin")
+	if err := writePlanFile(unsigned, testPlan(), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// Change the payload inside the envelope without updating the checksum
+	tampered := filepath.Join(dir, "tampered.bin")
+	envelope := readEnvelope(t, unsigned)
+	envelope.Plan = json.RawMessage(strings.Replace(string(envelope.Plan), "nightly", "hourly", 1))
+	writeEnvelope(t, tampered, envelope)
+
+	notAPlan := filepath.Join(dir, "plan.yaml")
+	if err := os.WriteFile(notAPlan, []byte("kind: VBRJob\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		key     string
+		wantErr string
+	}{
+		{"wrong key", signed, "other", "signature is invalid"},
+		{"signed without key", signed, "", "plan file is signed"},
+		{"unsigned with key", unsigned, "secret", "not signed"},
+		{"tampered", tampered, "", "checksum mismatch"},
+		{"not a plan", notAPlan, "", "not an owlctl plan file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readPlanFile(tt.path, tt.key)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func readEnvelope(t *testing.T, path string) planEnvelope {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var envelope planEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		t.Fatal(err)
+	}
+	return envelope
+}
+
+func writeEnvelope(t *testing.T, path string, envelope planEnvelope) {
+	t.Helper()
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(data)
+	zw.Close()
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLiveResourceHash(t *testing.T) {
+	ignore := map[string]bool{"lastRun": true}
+	a := map[string]interface{}{"name": "job", "retention": 7.0, "lastRun": "yesterday"}
+	b := map[string]interface{}{"retention": 7.0, "lastRun": "today", "name": "job"}
+	c := map[string]interface{}{"name": "job", "retention": 14.0}
+
+	if liveResourceHash(a, ignore) != liveResourceHash(b, ignore) {
+		t.Error("hash should ignore key order and ignored fields")
+	}
+	if liveResourceHash(a, ignore) == liveResourceHash(c, ignore) {
+		t.Error("hash should change when a field changes")
+	}
+	if liveResourceHash(nil, ignore) != "" {
+		t.Error("missing resource should hash to empty string")
+	}
+}
+
+func TestStalePlanResources(t *testing.T) {
+	live := map[string]string{
+		"unchanged": `{"id":"1","description":"a"}`,
+		"changed":   `{"id":"2","description":"edited in console"}`,
+		"deleted":   "",
+		"appeared":  `{"id":"4","description":"d"}`,
+	}
+	cfg := ResourceApplyConfig{
+		Kind:         "VBRJob",
+		IgnoreFields: map[string]bool{"id": true},
+		FetchCurrent: func(name string, profile models.Profile) (json.RawMessage, string, error) {
+			if live[name] == "" {
+				return nil, "", nil
+			}
+			return json.RawMessage(live[name]), name, nil
+		},
+	}
+	configFor := func(kind string) (ResourceApplyConfig, bool) {
+		return cfg, kind == "VBRJob"
+	}
+
+	planned := map[string]string{
+		"unchanged": `{"id":"1","description":"a"}`,
+		"changed":   `{"id":"2","description":"b"}`,
+		"deleted":   `{"id":"3","description":"c"}`,
+		"appeared":  "",
+	}
+	var plan savedPlan
+	for _, name := range []string{"unchanged", "changed", "deleted", "appeared"} {
+		var m map[string]interface{}
+		if planned[name] != "" {
+			json.Unmarshal([]byte(planned[name]), &m)
+		}
+		plan.Resources = append(plan.Resources, plannedChange{Kind: "VBRJob", Name: name, LiveHash: liveResourceHash(m, cfg.IgnoreFields)})
+	}
+	plan.Resources = append(plan.Resources, plannedChange{Kind: "VBRUnknown", Name: "other"})
+
+	stale := stalePlanResources(plan, models.Profile{}, configFor)
+	want := []string{
+		`VBRJob "changed": changed in VBR`,
+		`VBRJob "deleted": deleted from VBR`,
+		`VBRJob "appeared": created in VBR`,
+		`VBRUnknown "other": kind is not supported`,
+	}
+	if len(stale) != len(want) {
+		t.Fatalf("stale = %q, want %d entries", stale, len(want))
+	}
+	for i, w := range want {
+		if !strings.HasPrefix(stale[i], w) {
+			t.Errorf("stale[%d] = %q, want prefix %q", i, stale[i], w)
+		}
+	}
+}
+
+func TestPlanSummary(t *testing.T) {
+	planned := []plannedChange{{Action: "create"}, {Action: "update"}, {Action: "update"}, {Action: "no-op"}}
+	if got := planSummary(planned, 0); got != "Plan: 1 to create, 2 to update, 1 unchanged" {
+		t.Errorf("planSummary = %q", got)
+	}
+	if got := planSummary(nil, 2); got != "Plan: 0 to create, 0 to update, 0 unchanged, 2 failed" {
+		t.Errorf("planSummary = %q", got)
+	}
+}
+
+func TestDeclarativeApplyConfig(t *testing.T) {
+	for kind, want := range map[string]string{
+		"VBRJob":                "VBRJob",
+		"VBRSOBR":               sobrApplyConfig.Kind,
+		"VBRScaleOutRepository": sobrApplyConfig.Kind,
+	} {
+		cfg, ok := declarativeApplyConfig(kind)
+		if !ok || cfg.Kind != want {
+			t.Errorf("declarativeApplyConfig(%q) = %q, %v; want %q", kind, cfg.Kind, ok, want)
+		}
+	}
+	if _, ok := declarativeApplyConfig("VBRUnknown"); ok {
+		t.Error("declarativeApplyConfig(VBRUnknown) should not be supported")
+	}
+}
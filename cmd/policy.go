@@ -0,0 +1,318 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/shapedthought/owlctl/config"
+	"github.com/shapedthought/owlctl/policy"
+	"github.com/shapedthought/owlctl/resources"
+	"github.com/spf13/cobra"
+)
+
+var (
+	policyRulesFile string
+	policyGroup     string
+)
+
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Evaluate policy rules against declarative specs",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Policy checks are offline; only spec variables need configuring
+		return configureSpecVariables(nil, nil)
+	},
+}
+
+var policyTestCmd = &cobra.Command{
+	Use:   "test [spec-file...]",
+	Short: "Check specs against policy rules (block/warn) without connecting to VBR",
+	Long: `Test evaluates policy rules (policy-rules.yaml) against specs as they would be applied.
+
+Specs are evaluated after merging: a spec in a group is merged with the group's profile
+and overlay chains, and a profile or overlay file is evaluated through every group spec
+that uses it. This means a PR that only changes an overlay is checked against the specs
+the overlay affects. Files that are not part of any group are evaluated as written,
+except profiles and overlays, which are reported as errors since there is nothing to check.
+
+Rule format:
+  rules:
+    - name: job-encryption-disabled
+      resource: VBRJob                  # Spec kind ("*" for any)
+      path: .spec.storage.advancedSettings.storageData.encryption.isEnabled
+      operator: equals                  # equals, exists, absent, lt, gt, in, regex
+      value: false
+      action: block                     # block or warn
+      message: Job encryption must not be disabled
+
+Except for absent, rules only trigger when the field is set.
+
+Examples:
+  # Check changed files in a pull request
+  owlctl policy test --rules policies/policy-rules.yaml $(git diff --name-only --diff-filter=d main -- '*.yaml')
+
+  # Check every merged spec in a group
+  owlctl policy test --group sql-tier
+
+  # Check specs by label
+  owlctl policy test -l "env=prod"
+
+Exit Codes:
+  0 - No rules triggered
+  1 - Error (invalid rules file, or a spec could not be loaded)
+  3 - Warn rules triggered
+  4 - Block rules triggered
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if (policyGroup != "" || labelSelector != "") && len(args) > 0 {
+			log.Fatal("Cannot use --group or --selector with spec file arguments")
+		}
+		if policyGroup == "" && labelSelector == "" && len(args) == 0 {
+			log.Fatal("Provide spec files, use --group, or use --selector")
+		}
+
+		rules, err := policy.LoadRules(policyRulesFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		var docs []policy.Document
+		var loadErrors []string
+		if policyGroup != "" || labelSelector != "" {
+			docs, loadErrors = policyGroupDocuments(policyGroup)
+		} else {
+			docs, loadErrors = policyFileDocuments(args)
+		}
+
+		os.Exit(reportPolicyResults(rules, docs, loadErrors))
+	},
+}
+
+// policyGroupDocuments loads every merged spec in a group (or selector match)
+func policyGroupDocuments(group string) ([]policy.Document, []string) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load owlctl.yaml: %v", err)
+	}
+	groupCfg, err := lookupGroup(cfg, group)
+	if err != nil {
+		log.Fatalf("Group error: %v", err)
+	}
+
+	targets, err := loadMergedGroupSpecs(cfg, groupCfg)
+	if err != nil {
+		log.Fatalf("Group error: %v", err)
+	}
+	if len(targets) == 0 {
+		noGroupSpecs(group, "resource")
+	}
+	return mergedPolicyDocuments(targets, "")
+}
+
+// policyFileDocuments loads the specs affected by the given files. Files used by a group, as
+// a spec or as a profile/overlay layer, are evaluated through that group's merge; other files
+// are evaluated as written, and files that no longer exist are skipped. A profile or overlay that no group uses has no spec to evaluate
+// and is reported as an error rather than passing unchecked.
+func policyFileDocuments(paths []string) ([]policy.Document, []string) {
+	var docs []policy.Document
+	var loadErrors []string
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		cfg = nil
+	}
+
+	// For each group, the requested files it uses: nil means every spec (a layer changed)
+	affected := map[string]map[string]bool{}
+	var groupNames []string
+	var standalone []string
+
+	for _, path := range paths {
+		// git diff --name-only also lists deleted files; there is nothing left to check
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			fmt.Fprintf(os.Stderr, "Note: %s does not exist (deleted?); skipped\n", path)
+			continue
+		}
+		abs := absPath(path)
+		used := false
+		if cfg != nil {
+			for _, name := range cfg.ListGroups() {
+				groupCfg, _ := cfg.GetGroup(name)
+				specs, layer := groupFileUse(cfg, groupCfg, abs)
+				if specs == "" && !layer {
+					continue
+				}
+				used = true
+				if _, seen := affected[name]; !seen {
+					groupNames = append(groupNames, name)
+					affected[name] = map[string]bool{}
+				}
+				if layer {
+					affected[name] = nil
+				} else if affected[name] != nil {
+					affected[name][specs] = true
+				}
+			}
+		}
+		if !used {
+			standalone = append(standalone, path)
+		}
+	}
+
+	// Standalone files first: loading a group changes the active spec variables
+	for _, path := range standalone {
+		spec, err := resources.LoadResourceSpec(path)
+		if err != nil {
+			loadErrors = append(loadErrors, fmt.Sprintf("%s: %v", path, err))
+			continue
+		}
+		if spec.Kind == resources.KindProfile || spec.Kind == resources.KindOverlay {
+			loadErrors = append(loadErrors, fmt.Sprintf("%s: %s not used by any group; nothing to evaluate", path, spec.Kind))
+			continue
+		}
+		doc, err := resources.SpecDocument(spec)
+		if err != nil {
+			loadErrors = append(loadErrors, fmt.Sprintf("%s: %v", path, err))
+			continue
+		}
+		docs = append(docs, policy.Document{Source: path, Kind: spec.Kind, Name: spec.Metadata.Name, Doc: doc})
+	}
+
+	for _, name := range groupNames {
+		groupCfg, _ := cfg.GetGroup(name)
+		targets, err := loadMergedGroupSpecs(cfg, groupCfg)
+		if err != nil {
+			loadErrors = append(loadErrors, fmt.Sprintf("group %s: %v", name, err))
+			continue
+		}
+		var selected []mergedGroupSpec
+		for _, t := range targets {
+			if affected[name] == nil || affected[name][t.SpecPath] {
+				selected = append(selected, t)
+			}
+		}
+		groupDocs, groupErrors := mergedPolicyDocuments(selected, name)
+		docs = append(docs, groupDocs...)
+		loadErrors = append(loadErrors, groupErrors...)
+	}
+	return docs, loadErrors
+}
+
+// groupFileUse reports how a group uses a file: as one of its specs (returning the spec path
+// as listed by the group) or as a profile/overlay layer
+func groupFileUse(cfg *config.VCLIConfig, groupCfg config.GroupConfig, abs string) (spec string, layer bool) {
+	profiles, overlays, _ := cfg.GroupLayers(groupCfg)
+	for _, p := range append(profiles, overlays...) {
+		if absPath(cfg.ResolvePath(p)) == abs {
+			return "", true
+		}
+	}
+	specs, err := cfg.ResolveGroupSpecs(groupCfg)
+	if err != nil {
+		return "", false
+	}
+	for _, s := range specs {
+		if absPath(cfg.ResolvePath(s)) == abs {
+			return s, false
+		}
+	}
+	return "", false
+}
+
+func mergedPolicyDocuments(targets []mergedGroupSpec, group string) ([]policy.Document, []string) {
+	var docs []policy.Document
+	var loadErrors []string
+	for _, t := range targets {
+		source := t.SpecPath
+		if group != "" {
+			source = fmt.Sprintf("%s, group %s", t.SpecPath, group)
+		}
+		if t.Error != nil {
+			loadErrors = append(loadErrors, fmt.Sprintf("%s: %v", source, t.Error))
+			continue
+		}
+		doc, err := resources.SpecDocument(t.Spec)
+		if err != nil {
+			loadErrors = append(loadErrors, fmt.Sprintf("%s: %v", source, err))
+			continue
+		}
+		docs = append(docs, policy.Document{
+			Source:     source,
+			Kind:       t.Spec.Kind,
+			Name:       t.Spec.Metadata.Name,
+			Doc:        doc,
+			Provenance: t.Provenance,
+		})
+	}
+	return docs, loadErrors
+}
+
+// reportPolicyResults prints triggered rules and returns the exit code: block results exit like
+// critical drift, warn results like warning drift
+func reportPolicyResults(rules *policy.RuleSet, docs []policy.Document, loadErrors []string) int {
+	fmt.Printf("Evaluating %d policy rules against %d specs\n\n", len(rules.Rules), len(docs))
+
+	results := rules.Evaluate(docs)
+	blocked, warned := 0, 0
+	for _, r := range results {
+		label := "⚠ WARN "
+		if r.Rule.Action == policy.ActionBlock {
+			label = "✗ BLOCK"
+			blocked++
+		} else {
+			warned++
+		}
+
+		fmt.Printf("%s [%s] %s\n", label, r.Rule.Name, r.Rule.Message)
+		fmt.Printf("    %s %q  (%s)\n", r.Kind, r.Name, r.Source)
+		if r.Rule.Operator == policy.OpAbsent {
+			fmt.Printf("    %s is not set\n", r.Resolved)
+		} else {
+			line := fmt.Sprintf("    %s = %v", r.Resolved, r.Value)
+			if r.SetBy != "" {
+				line += fmt.Sprintf("  (from %s)", r.SetBy)
+			}
+			fmt.Println(line)
+		}
+	}
+
+	for _, e := range loadErrors {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", e)
+	}
+
+	if len(results) > 0 {
+		fmt.Println()
+	}
+	fmt.Printf("Policy: %d blocked, %d warnings\n", blocked, warned)
+
+	switch {
+	case len(loadErrors) > 0:
+		return ExitError
+	case blocked > 0:
+		return ExitDriftCritical
+	case warned > 0:
+		return ExitDriftWarning
+	}
+	return ExitSuccess
+}
+
+func absPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	return abs
+}
+
+func init() {
+	policyTestCmd.Flags().StringVarP(&policyRulesFile, "rules", "r", "policies/policy-rules.yaml", "Policy rules file")
+	policyTestCmd.Flags().StringVar(&policyGroup, "group", "", "Evaluate every spec in a named group from owlctl.yaml")
+	addSelectorFlag(policyTestCmd, "Evaluate specs matching a label selector (e.g. env=prod)")
+
+	policyCmd.AddCommand(policyTestCmd)
+	rootCmd.AddCommand(policyCmd)
+}
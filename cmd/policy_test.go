@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/shapedthought/owlctl/policy"
+)
+
+func writePolicyTestFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPolicyFileDocuments(t *testing.T) {
+	dir := t.TempDir()
+	job := func(name string) string {
+		return "apiVersion: owlctl.veeam.com/v1\nkind: VBRJob\nmetadata:\n  name: " + name + "\nspec:\n  type: VSphereBackup\n  isDisabled: false\n"
+	}
+	writePolicyTestFile(t, dir, "a.yaml", job("Job A"))
+	writePolicyTestFile(t, dir, "b.yaml", job("Job B"))
+	standalone := writePolicyTestFile(t, dir, "c.yaml", job("Job C"))
+	overlay := writePolicyTestFile(t, dir, "disable.yaml",
+		"apiVersion: owlctl.veeam.com/v1\nkind: Overlay\nmetadata:\n  name: disable\nspec:\n  isDisabled: true\n")
+	configPath := writePolicyTestFile(t, dir, "owlctl.yaml",
+		"groups:\n  tier:\n    specs:\n      - a.yaml\n      - b.yaml\n    overlay: disable.yaml\n")
+	t.Setenv("OWLCTL_CONFIG", configPath)
+
+	tests := []struct {
+		name      string
+		paths     []string
+		wantNames []string
+	}{
+		{name: "group spec is merged", paths: []string{filepath.Join(dir, "a.yaml")}, wantNames: []string{"Job A"}},
+		{name: "overlay selects every group spec", paths: []string{overlay}, wantNames: []string{"Job A", "Job B"}},
+		{name: "file outside groups is loaded as written", paths: []string{standalone}, wantNames: []string{"Job C"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs, loadErrors := policyFileDocuments(tt.paths)
+			if len(loadErrors) > 0 {
+				t.Fatalf("load errors: %v", loadErrors)
+			}
+
+			var names []string
+			for _, d := range docs {
+				names = append(names, d.Name)
+				disabled := d.Doc["spec"].(map[string]interface{})["isDisabled"]
+				wantDisabled := d.Name != "Job C"
+				if disabled != wantDisabled {
+					t.Errorf("%s isDisabled = %v, want %v", d.Name, disabled, wantDisabled)
+				}
+				if wantDisabled && d.Provenance["isDisabled"] != "disable.yaml" {
+					t.Errorf("%s isDisabled provenance = %q, want disable.yaml", d.Name, d.Provenance["isDisabled"])
+				}
+			}
+			sort.Strings(names)
+			if len(names) != len(tt.wantNames) {
+				t.Fatalf("documents = %v, want %v", names, tt.wantNames)
+			}
+			for i := range names {
+				if names[i] != tt.wantNames[i] {
+					t.Errorf("documents = %v, want %v", names, tt.wantNames)
+				}
+			}
+		})
+	}
+}
+
+func TestPolicyFileDocuments_UnusedLayer(t *testing.T) {
+	dir := t.TempDir()
+	unused := writePolicyTestFile(t, dir, "unused.yaml",
+		"apiVersion: owlctl.veeam.com/v1\nkind: Overlay\nmetadata:\n  name: unused\nspec:\n  isDisabled: true\n")
+	configPath := writePolicyTestFile(t, dir, "owlctl.yaml", "groups: {}\n")
+	t.Setenv("OWLCTL_CONFIG", configPath)
+
+	docs, loadErrors := policyFileDocuments([]string{unused})
+	if len(docs) != 0 {
+		t.Errorf("documents = %+v, want none", docs)
+	}
+	if len(loadErrors) != 1 || !strings.Contains(loadErrors[0], "not used by any group") {
+		t.Errorf("load errors = %v, want an unused overlay error", loadErrors)
+	}
+}
+
+func TestPolicyFileDocuments_DeletedFile(t *testing.T) {
+	dir := t.TempDir()
+	job := writePolicyTestFile(t, dir, "job.yaml",
+		"apiVersion: owlctl.veeam.com/v1\nkind: VBRJob\nmetadata:\n  name: Job A\nspec:\n  type: VSphereBackup\n")
+	t.Setenv("OWLCTL_CONFIG", writePolicyTestFile(t, dir, "owlctl.yaml", "groups: {}\n"))
+
+	docs, loadErrors := policyFileDocuments([]string{job, filepath.Join(dir, "deleted.yaml")})
+	if len(loadErrors) > 0 {
+		t.Errorf("load errors = %v, want the deleted file skipped", loadErrors)
+	}
+	if len(docs) != 1 || docs[0].Name != "Job A" {
+		t.Errorf("documents = %+v, want Job A", docs)
+	}
+}
+
+func TestReportPolicyResults(t *testing.T) {
+	rules, err := policy.ParseRules([]byte(`rules:
+  - name: disabled
+    resource: VBRJob
+    path: .spec.isDisabled
+    operator: equals
+    value: true
+    action: block
+  - name: no-description
+    resource: VBRJob
+    path: .spec.description
+    operator: absent
+    action: warn
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := func(spec map[string]interface{}) policy.Document {
+		return policy.Document{Kind: "VBRJob", Name: "job", Doc: map[string]interface{}{"spec": spec}}
+	}
+
+	tests := []struct {
+		name       string
+		docs       []policy.Document
+		loadErrors []string
+		want       int
+	}{
+		{name: "clean", docs: []policy.Document{doc(map[string]interface{}{"description": "x"})}, want: ExitSuccess},
+		{name: "warn only", docs: []policy.Document{doc(map[string]interface{}{})}, want: ExitDriftWarning},
+		{name: "block", docs: []policy.Document{doc(map[string]interface{}{"isDisabled": true})}, want: ExitDriftCritical},
+		{name: "load error wins", docs: []policy.Document{doc(map[string]interface{}{"isDisabled": true})}, loadErrors: []string{"bad.yaml: boom"}, want: ExitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reportPolicyResults(rules, tt.docs, tt.loadErrors); got != tt.want {
+				t.Errorf("exit code = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,12 +1,20 @@
 package cmd
 
 import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
 	"github.com/shapedthought/owlctl/utils"
 	"github.com/shapedthought/owlctl/vhttp"
 	"github.com/spf13/cobra"
 )
 
-// var usi bool
+var (
+	postWait        bool
+	postWaitTimeout time.Duration
+)
 
 // postCmd represents the post command
 var postCmd = &cobra.Command{
@@ -14,31 +22,74 @@ var postCmd = &cobra.Command{
 	Short: "Sends a POST command to API",
 	Long: `Sends a POST commands to the selected profile.
 
-Payload needs to be in the JSON format.
+The payload can be JSON or YAML (detected from the content) and is read from a file
+with -f, or from stdin with -f - or --stdin.
 
 Note that owlctl does not type check the payload.
 
+Many VBR operations (job start, rescan, configuration backup) return a session that
+runs in the background. With --wait, owlctl polls the session until it finishes,
+showing progress, prints the finished session, and prints the session log if it failed.
+
 Commands:
 owlctl post jobs/c69eb538-5a07-4bd7-80cb-bdf5142eadd6/start
+owlctl post jobs/c69eb538-5a07-4bd7-80cb-bdf5142eadd6/start --wait
+owlctl post configBackup/backup --wait --timeout 30m
 owlctl post jobs -f job.json
+owlctl post jobs -f job.yaml
+cat job.json | owlctl post jobs --stdin
+owlctl post jobs -f job.json -q id
 
+Exit Codes (--wait):
+  0 - Session succeeded (or the response was not a session)
+  1 - Error (request failed, timeout)
+  7 - Session finished with Warning
+  8 - Session failed
 	`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		payload, err := readPayload(os.Stdin)
+		if err != nil {
+			log.Fatal(err)
+		}
 
-		profile := utils.GetCurrentProfile()
-		settings := utils.ReadSettings()
-
-		api_url := utils.GetAddress(profile, settings)
+		res := sendImperative("POST", args[0], payload)
 
-		vhttp.SendData(api_url, filename, args[0], "POST", profile, settings)
+		if postWait {
+			if session, ok := parseSessionResponse(res.Body); ok {
+				os.Exit(waitForPostSession(session))
+			}
+			fmt.Fprintln(os.Stderr, "Response is not a VBR session; nothing to wait for")
+		}
+		printImperativeResponse(res)
 
 	},
 }
 
+// waitForPostSession waits for a session started by post, prints the finished session and
+// returns the exit code for its result
+func waitForPostSession(session *vbrSession) int {
+	profile := utils.GetCurrentProfile()
+
+	// Progress goes to stderr so the finished session can be piped
+	finished, err := waitForSession(os.Stderr, sessionName(session), session.ID, postWaitTimeout, profile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	final := vhttp.GetData[interface{}]("sessions/"+session.ID, profile)
+	if err := printResponse(os.Stdout, final); err != nil {
+		log.Fatal(err)
+	}
+	return sessionExitCode(finished)
+}
+
 func init() {
-	postCmd.Flags().StringVarP(&filename, "file", "f", "", "payload in json format")
-	// postCmd.Flags().BoolVarP(&usi, "stdin", "s", false, "read payload from stdin")
-	// postCmd.MarkFlagRequired("filename")
+	addPayloadFlags(postCmd)
+	addQueryFlags(postCmd)
+	addOutputFlags(postCmd)
+	postCmd.Flags().BoolVar(&postWait, "wait", false, "Wait for a session returned by the request to finish and exit with its result")
+	postCmd.Flags().DurationVar(&postWaitTimeout, "timeout", 0, "Maximum time to wait with --wait (e.g. 2h; default: no limit)")
 	rootCmd.AddCommand(postCmd)
 
 }
@@ -3,57 +3,46 @@ package cmd
 import (
 	"log"
 	"os"
-	"strings"
 
-	"github.com/shapedthought/owlctl/utils"
-	"github.com/shapedthought/owlctl/vhttp"
 	"github.com/spf13/cobra"
 )
 
-var (filename string
-	//  usiP bool
-	)
-
-
-// postCmd represents the post command
+// putCmd represents the put command
 var putCmd = &cobra.Command{
 	Use:   "put",
 	Short: "Sends a PUT command to API",
 	Long: `Sends a PUT commands to the selected profile.
 
-Payload needs to be in the JSON format. PUTs always require a payload.
+The payload can be JSON or YAML (detected from the content) and is read from a file
+with -f, or from stdin with -f - or --stdin. PUTs always require a payload.
 
 Note that owlctl does not type check the payload.
 
 Commands:
-owlctl put jobs -f job.json
+owlctl put jobs/<job-id> -f job.json
+owlctl get jobs/<job-id> | jq '.description = "Updated"' | owlctl put jobs/<job-id> --stdin
 
 	`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-
-		// check if filename is set
-		if !strings.Contains(filename, ".json") {
-			log.Fatal("A JSON file is required for PUT commands")
+		payload, err := readPayload(os.Stdin)
+		if err != nil {
+			log.Fatal(err)
 		}
-		
-		profile := utils.GetCurrentProfile()
-		settings := utils.ReadSettings()
-
-		// With v1.0 profiles, credentials are always from environment variables
-		api_url := os.Getenv("OWLCTL_URL")
-		if api_url == "" {
-			log.Fatal("OWLCTL_URL environment variable not set")
+		if payload == nil {
+			log.Fatal("A payload is required for PUT commands (use -f <file> or --stdin)")
 		}
 
-		vhttp.SendData(api_url, filename, args[0], "PUT", profile, settings)
+		res := sendImperative("PUT", args[0], payload)
+		printImperativeResponse(res)
 
 	},
 }
 
 func init() {
-	putCmd.Flags().StringVarP(&filename, "file", "f", "", "payload in json format")
-	// putCmd.Flags().BoolVarP(&usiP, "stdin", "s", false, "read payload from stdin")
-	// putCmd.MarkFlagRequired("filename")
+	addPayloadFlags(putCmd)
+	addQueryFlags(putCmd)
+	addOutputFlags(putCmd)
 	rootCmd.AddCommand(putCmd)
 
 }
@@ -100,6 +100,17 @@ Examples:
 	},
 }
 
+// repoDiffConfig compares repositories against group or repository specs
+var repoDiffConfig = GroupDiffConfig{
+	Kind:         "VBRRepository",
+	DisplayName:  "repository",
+	PluralName:   "repositories",
+	FetchCurrent: fetchCurrentRepo,
+	IgnoreFields: repoIgnoreFields,
+	SeverityMap:  repoSeverityMap,
+	RemediateCmd: "owlctl repo apply --group %s",
+}
+
 var repoDiffCmd = &cobra.Command{
 	Use:   "diff [repo-name]",
 	Short: "Detect configuration drift from snapshot state",
@@ -113,34 +124,38 @@ Examples:
   # Check all repositories
   owlctl repo diff --all
 
+  # Compare against the repository specs in Git instead of state
+  owlctl repo diff --all --against specs
+
 Exit Codes:
   0 - No drift detected
   3 - Drift detected (INFO or WARNING)
   4 - Critical security drift detected
   1 - Error occurred`,
 	Run: func(cmd *cobra.Command, args []string) {
-		if repoDiffGroupName != "" {
+		if repoDiffGroupName != "" || (labelSelector != "" && !repoDiffAll) {
 			if repoDiffAll {
 				log.Fatal("Cannot use --group with --all")
 			}
 			if len(args) > 0 {
-				log.Fatal("Cannot use --group with a positional repository name argument")
+				log.Fatal("Cannot use --group or --selector with a positional repository name argument")
+			}
+			checkGroupAgainst()
+			diffGroupResource(repoDiffGroupName, repoDiffConfig)
+		} else if againstSpecs() {
+			if repoDiffAll {
+				diffAgainstSpecs(repoDiffConfig, "")
+			} else if len(args) > 0 {
+				diffAgainstSpecs(repoDiffConfig, args[0])
+			} else {
+				log.Fatal("Provide repository name or use --all")
 			}
-			diffGroupResource(repoDiffGroupName, GroupDiffConfig{
-				Kind:         "VBRRepository",
-				DisplayName:  "repository",
-				PluralName:   "repositories",
-				FetchCurrent: fetchCurrentRepo,
-				IgnoreFields: repoIgnoreFields,
-				SeverityMap:  repoSeverityMap,
-				RemediateCmd: "owlctl repo apply --group %s",
-			})
 		} else if repoDiffAll {
 			diffAllRepos()
 		} else if len(args) > 0 {
 			diffSingleRepo(args[0])
 		} else {
-			log.Fatal("Provide repository name, use --all, or use --group")
+			log.Fatal("Provide repository name, use --all, use --group, or use --selector")
 		}
 	},
 }
@@ -257,15 +272,18 @@ func diffSingleRepo(repoName string) {
 	drifts := detectDrift(resource.Spec, currentMap, repoIgnoreFields)
 	drifts = classifyDrifts(drifts, repoSeverityMap)
 	minSev := parseSeverityFlag()
+	drifts = applySeverityRules("VBRRepository", resource.Name, resource.Labels, drifts)
+	drifts = suppressAckedDrifts(resource.Name, drifts)
 	drifts = filterDriftsBySeverity(drifts, minSev)
 
 	if len(drifts) == 0 {
 		fmt.Println(noDriftMessage("Repository matches snapshot state.", minSev))
-		os.Exit(0)
+		ciExit(ExitSuccess)
 	}
 
 	// Display drift
 	printSecuritySummary(drifts)
+	reportDrifts("VBRRepository", repoName, drifts)
 	fmt.Println("Drift detected:")
 	for _, drift := range drifts {
 		printDriftWithSeverity(drift)
@@ -285,7 +303,7 @@ func diffSingleRepo(repoName string) {
 	// Show guidance based on origin
 	printRemediationGuidance(BuildRepoGuidance(repoName, resource.Origin))
 
-	os.Exit(exitCodeForDrifts(drifts))
+	ciExit(exitCodeForDrifts(drifts))
 }
 
 func diffAllRepos() {
@@ -298,7 +316,7 @@ func diffAllRepos() {
 	}
 
 	stateMgr := state.NewManager()
-	resources, err := stateMgr.ListResources("VBRRepository")
+	resources, err := listSelectedResources(stateMgr, "VBRRepository")
 	if err != nil {
 		log.Fatalf("Failed to load state: %v\n", err)
 	}
@@ -330,6 +348,8 @@ func diffAllRepos() {
 		// Detect, classify, filter
 		drifts := detectDrift(resource.Spec, currentMap, repoIgnoreFields)
 		drifts = classifyDrifts(drifts, repoSeverityMap)
+		drifts = applySeverityRules("VBRRepository", resource.Name, resource.Labels, drifts)
+		drifts = suppressAckedDrifts(resource.Name, drifts)
 		drifts = filterDriftsBySeverity(drifts, minSev)
 
 		// Show origin label for observed resources
@@ -341,6 +361,7 @@ func diffAllRepos() {
 		if len(drifts) > 0 {
 			maxSev := getMaxSeverity(drifts)
 			fmt.Printf("  %s %s%s: %d drifts detected\n", maxSev, resource.Name, originLabel, len(drifts))
+			reportDrifts("VBRRepository", resource.Name, drifts)
 			allDrifts = append(allDrifts, drifts...)
 			if resource.Origin == "observed" {
 				driftedObserved++
@@ -367,9 +388,9 @@ func diffAllRepos() {
 
 	totalDrifted := driftedApplied + driftedObserved
 	if totalDrifted > 0 {
-		os.Exit(exitCodeForDrifts(allDrifts))
+		ciExit(exitCodeForDrifts(allDrifts))
 	}
-	os.Exit(0)
+	ciExit(ExitSuccess)
 }
 
 // --- Repository Apply command ---
@@ -421,12 +442,12 @@ Exit Codes:
 `,
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if repoApplyGroupName != "" {
+		if repoApplyGroupName != "" || labelSelector != "" {
 			if len(args) > 0 {
-				log.Fatal("Cannot use --group with a positional spec file argument")
+				log.Fatal("Cannot use --group or --selector with a positional spec file argument")
 			}
 			if repoApplyOverlayFile != "" {
-				log.Fatal("Cannot use --group with --overlay (group defines its own overlay)")
+				log.Fatal("Cannot use --group or --selector with --overlay (group defines its own overlay)")
 			}
 			applyGroupResource(repoApplyGroupName, repoApplyConfig, repoApplyDryRun)
 		} else if len(args) > 0 {
@@ -441,7 +462,7 @@ Exit Codes:
 			if result.Error != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", result.Error)
 				outcome := DetermineApplyOutcome([]ApplyResult{result})
-				os.Exit(ExitCodeForOutcome(outcome))
+				ciExit(ExitCodeForOutcome(outcome))
 			}
 
 			if result.DryRun {
@@ -504,12 +525,12 @@ Exit Codes:
 `,
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		if sobrApplyGroupName != "" {
+		if sobrApplyGroupName != "" || labelSelector != "" {
 			if len(args) > 0 {
-				log.Fatal("Cannot use --group with a positional spec file argument")
+				log.Fatal("Cannot use --group or --selector with a positional spec file argument")
 			}
 			if sobrApplyOverlayFile != "" {
-				log.Fatal("Cannot use --group with --overlay (group defines its own overlay)")
+				log.Fatal("Cannot use --group or --selector with --overlay (group defines its own overlay)")
 			}
 			applyGroupResource(sobrApplyGroupName, sobrApplyConfig, sobrApplyDryRun)
 		} else if len(args) > 0 {
@@ -524,7 +545,7 @@ Exit Codes:
 			if result.Error != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", result.Error)
 				outcome := DetermineApplyOutcome([]ApplyResult{result})
-				os.Exit(ExitCodeForOutcome(outcome))
+				ciExit(ExitCodeForOutcome(outcome))
 			}
 
 			if result.DryRun {
@@ -561,6 +582,17 @@ Examples:
 	},
 }
 
+// sobrDiffConfig compares scale-out repositories against group or repository specs
+var sobrDiffConfig = GroupDiffConfig{
+	Kind:         "VBRScaleOutRepository",
+	DisplayName:  "scale-out repository",
+	PluralName:   "scale-out repositories",
+	FetchCurrent: fetchCurrentSobr,
+	IgnoreFields: sobrIgnoreFields,
+	SeverityMap:  sobrSeverityMap,
+	RemediateCmd: "owlctl repo sobr-apply --group %s",
+}
+
 var sobrDiffCmd = &cobra.Command{
 	Use:   "sobr-diff [sobr-name]",
 	Short: "Detect configuration drift for scale-out backup repositories",
@@ -574,34 +606,38 @@ Examples:
   # Check all SOBRs
   owlctl repo sobr-diff --all
 
+  # Compare against the SOBR specs in Git instead of state
+  owlctl repo sobr-diff --all --against specs
+
 Exit Codes:
   0 - No drift detected
   3 - Drift detected (INFO or WARNING)
   4 - Critical security drift detected
   1 - Error occurred`,
 	Run: func(cmd *cobra.Command, args []string) {
-		if sobrDiffGroupName != "" {
+		if sobrDiffGroupName != "" || (labelSelector != "" && !sobrDiffAll) {
 			if sobrDiffAll {
 				log.Fatal("Cannot use --group with --all")
 			}
 			if len(args) > 0 {
-				log.Fatal("Cannot use --group with a positional SOBR name argument")
+				log.Fatal("Cannot use --group or --selector with a positional SOBR name argument")
+			}
+			checkGroupAgainst()
+			diffGroupResource(sobrDiffGroupName, sobrDiffConfig)
+		} else if againstSpecs() {
+			if sobrDiffAll {
+				diffAgainstSpecs(sobrDiffConfig, "")
+			} else if len(args) > 0 {
+				diffAgainstSpecs(sobrDiffConfig, args[0])
+			} else {
+				log.Fatal("Provide SOBR name or use --all")
 			}
-			diffGroupResource(sobrDiffGroupName, GroupDiffConfig{
-				Kind:         "VBRScaleOutRepository",
-				DisplayName:  "scale-out repository",
-				PluralName:   "scale-out repositories",
-				FetchCurrent: fetchCurrentSobr,
-				IgnoreFields: sobrIgnoreFields,
-				SeverityMap:  sobrSeverityMap,
-				RemediateCmd: "owlctl repo sobr-apply --group %s",
-			})
 		} else if sobrDiffAll {
 			diffAllSobrs()
 		} else if len(args) > 0 {
 			diffSingleSobr(args[0])
 		} else {
-			log.Fatal("Provide SOBR name, use --all, or use --group")
+			log.Fatal("Provide SOBR name, use --all, use --group, or use --selector")
 		}
 	},
 }
@@ -709,14 +745,17 @@ func diffSingleSobr(sobrName string) {
 	drifts := detectDrift(resource.Spec, currentMap, sobrIgnoreFields)
 	drifts = classifyDrifts(drifts, sobrSeverityMap)
 	minSev := parseSeverityFlag()
+	drifts = applySeverityRules("VBRScaleOutRepository", resource.Name, resource.Labels, drifts)
+	drifts = suppressAckedDrifts(resource.Name, drifts)
 	drifts = filterDriftsBySeverity(drifts, minSev)
 
 	if len(drifts) == 0 {
 		fmt.Println(noDriftMessage("Scale-out repository matches snapshot state.", minSev))
-		os.Exit(0)
+		ciExit(ExitSuccess)
 	}
 
 	printSecuritySummary(drifts)
+	reportDrifts("VBRScaleOutRepository", sobrName, drifts)
 	fmt.Println("Drift detected:")
 	for _, drift := range drifts {
 		printDriftWithSeverity(drift)
@@ -736,7 +775,7 @@ func diffSingleSobr(sobrName string) {
 	// Show guidance based on origin
 	printRemediationGuidance(BuildSobrGuidance(sobrName, resource.Origin))
 
-	os.Exit(exitCodeForDrifts(drifts))
+	ciExit(exitCodeForDrifts(drifts))
 }
 
 func diffAllSobrs() {
@@ -749,7 +788,7 @@ func diffAllSobrs() {
 	}
 
 	stateMgr := state.NewManager()
-	resources, err := stateMgr.ListResources("VBRScaleOutRepository")
+	resources, err := listSelectedResources(stateMgr, "VBRScaleOutRepository")
 	if err != nil {
 		log.Fatalf("Failed to load state: %v\n", err)
 	}
@@ -780,6 +819,8 @@ func diffAllSobrs() {
 		// Detect, classify, filter
 		drifts := detectDrift(resource.Spec, currentMap, sobrIgnoreFields)
 		drifts = classifyDrifts(drifts, sobrSeverityMap)
+		drifts = applySeverityRules("VBRScaleOutRepository", resource.Name, resource.Labels, drifts)
+		drifts = suppressAckedDrifts(resource.Name, drifts)
 		drifts = filterDriftsBySeverity(drifts, minSev)
 
 		// Show origin label for observed resources
@@ -791,6 +832,7 @@ func diffAllSobrs() {
 		if len(drifts) > 0 {
 			maxSev := getMaxSeverity(drifts)
 			fmt.Printf("  %s %s%s: %d drifts detected\n", maxSev, resource.Name, originLabel, len(drifts))
+			reportDrifts("VBRScaleOutRepository", resource.Name, drifts)
 			allDrifts = append(allDrifts, drifts...)
 			if resource.Origin == "observed" {
 				driftedObserved++
@@ -817,9 +859,9 @@ func diffAllSobrs() {
 
 	totalDrifted := driftedApplied + driftedObserved
 	if totalDrifted > 0 {
-		os.Exit(exitCodeForDrifts(allDrifts))
+		ciExit(exitCodeForDrifts(allDrifts))
 	}
-	os.Exit(0)
+	ciExit(ExitSuccess)
 }
 
 // saveResourceToState is a shared helper for saving any resource type to state
@@ -838,8 +880,10 @@ func saveResourceToState(resourceType, name, id string, rawData json.RawMessage)
 
 	// Try to load existing resource to preserve history
 	var existingHistory []state.ResourceEvent
+	var existingLabels map[string]string
 	if existing, err := stateMgr.GetResource(name); err == nil {
 		existingHistory = existing.History
+		existingLabels = existing.Labels
 	}
 
 	resource := &state.Resource{
@@ -849,6 +893,7 @@ func saveResourceToState(resourceType, name, id string, rawData json.RawMessage)
 		LastApplied:   time.Now(),
 		LastAppliedBy: currentUser,
 		Origin:        "observed",
+		Labels:        existingLabels,
 		Spec:          spec,
 		History:       existingHistory,
 	}
@@ -902,7 +947,7 @@ Examples:
 			SupportsOverlay: true,
 		}
 
-		if repoExportAll {
+		if repoExportAll || labelSelector != "" {
 			exportAllResources(cfg, profile, repoExportDirectory, repoExportAsOverlay, repoExportBasePath)
 		} else if len(args) > 0 {
 			exportSingleResource(args[0], cfg, profile, repoExportOutput, repoExportAsOverlay, repoExportBasePath)
@@ -949,7 +994,7 @@ Examples:
 			SupportsOverlay: true,
 		}
 
-		if sobrExportAll {
+		if sobrExportAll || labelSelector != "" {
 			exportAllResources(cfg, profile, sobrExportDirectory, sobrExportAsOverlay, sobrExportBasePath)
 		} else if len(args) > 0 {
 			exportSingleResource(args[0], cfg, profile, sobrExportOutput, sobrExportAsOverlay, sobrExportBasePath)
@@ -998,6 +1043,7 @@ func init() {
 	repoExportCmd.Flags().StringVarP(&repoExportOutput, "output", "o", "", "Output file (default: stdout)")
 	repoExportCmd.Flags().StringVarP(&repoExportDirectory, "directory", "d", "", "Output directory for bulk export")
 	repoExportCmd.Flags().BoolVar(&repoExportAll, "all", false, "Export all repositories")
+	addSelectorFlag(repoExportCmd, "Export only resources whose labels recorded in state match a selector (implies --all)")
 	repoExportCmd.Flags().BoolVar(&repoExportAsOverlay, "as-overlay", false, "Export as overlay (minimal patch)")
 	repoExportCmd.Flags().StringVar(&repoExportBasePath, "base", "", "Base template to diff against (for overlay export)")
 
@@ -1005,24 +1051,33 @@ func init() {
 	sobrExportCmd.Flags().StringVarP(&sobrExportOutput, "output", "o", "", "Output file (default: stdout)")
 	sobrExportCmd.Flags().StringVarP(&sobrExportDirectory, "directory", "d", "", "Output directory for bulk export")
 	sobrExportCmd.Flags().BoolVar(&sobrExportAll, "all", false, "Export all scale-out repositories")
+	addSelectorFlag(sobrExportCmd, "Export only resources whose labels recorded in state match a selector (implies --all)")
 	sobrExportCmd.Flags().BoolVar(&sobrExportAsOverlay, "as-overlay", false, "Export as overlay (minimal patch)")
 	sobrExportCmd.Flags().StringVar(&sobrExportBasePath, "base", "", "Base template to diff against (for overlay export)")
 
 	repoSnapshotCmd.Flags().BoolVar(&repoSnapshotAll, "all", false, "Snapshot all repositories")
 	repoDiffCmd.Flags().BoolVar(&repoDiffAll, "all", false, "Check drift for all repositories in state")
 	repoDiffCmd.Flags().StringVar(&repoDiffGroupName, "group", "", "Check drift for all specs in named group (from owlctl.yaml)")
+	addSelectorFlag(repoDiffCmd, "Check drift for specs whose labels match a selector; with --all, filters state by recorded labels")
+	addAgainstFlag(repoDiffCmd)
 	addSeverityFlags(repoDiffCmd)
 	repoApplyCmd.Flags().BoolVar(&repoApplyDryRun, "dry-run", false, "Preview changes without applying them")
 	repoApplyCmd.Flags().StringVar(&repoApplyGroupName, "group", "", "Apply all specs in named group (from owlctl.yaml)")
+	addSelectorFlag(repoApplyCmd, "Apply specs whose labels match a selector; with --group, filters the group (e.g. env=prod)")
 	repoApplyCmd.Flags().StringVar(&repoApplyOverlayFile, "overlay", "", "Overlay file to merge with base configuration")
+	addApplyWaitFlags(repoApplyCmd)
 
 	sobrSnapshotCmd.Flags().BoolVar(&sobrSnapshotAll, "all", false, "Snapshot all scale-out repositories")
 	sobrDiffCmd.Flags().BoolVar(&sobrDiffAll, "all", false, "Check drift for all scale-out repositories in state")
 	sobrDiffCmd.Flags().StringVar(&sobrDiffGroupName, "group", "", "Check drift for all specs in named group (from owlctl.yaml)")
+	addSelectorFlag(sobrDiffCmd, "Check drift for specs whose labels match a selector; with --all, filters state by recorded labels")
+	addAgainstFlag(sobrDiffCmd)
 	addSeverityFlags(sobrDiffCmd)
 	sobrApplyCmd.Flags().BoolVar(&sobrApplyDryRun, "dry-run", false, "Preview changes without applying them")
 	sobrApplyCmd.Flags().StringVar(&sobrApplyGroupName, "group", "", "Apply all specs in named group (from owlctl.yaml)")
+	addSelectorFlag(sobrApplyCmd, "Apply specs whose labels match a selector; with --group, filters the group (e.g. env=prod)")
 	sobrApplyCmd.Flags().StringVar(&sobrApplyOverlayFile, "overlay", "", "Overlay file to merge with base configuration")
+	addApplyWaitFlags(sobrApplyCmd)
 
 	repoCmd.AddCommand(repoExportCmd)
 	repoCmd.AddCommand(repoSnapshotCmd)
@@ -0,0 +1,642 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/shapedthought/owlctl/config"
+	"github.com/shapedthought/owlctl/models"
+	"github.com/shapedthought/owlctl/resources"
+	"github.com/shapedthought/owlctl/state"
+	"github.com/shapedthought/owlctl/utils"
+	"github.com/shapedthought/owlctl/vhttp"
+	"github.com/spf13/cobra"
+)
+
+// rpoAnnotation is the spec annotation declaring a job's recovery point objective (e.g. "24h", "7d")
+const rpoAnnotation = "owlctl.veeam.com/rpo"
+
+var (
+	reportGroup  string
+	reportFormat string
+	reportSince  string
+	reportRPO    string
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Operational reports for managed resources",
+	Long: `Reports on the runtime outcome of jobs tracked in state or declared in a group.
+
+Subcommands:
+  owlctl report sessions
+  owlctl report sessions --group production --format csv
+  owlctl report restore-points --rpo 24h
+`,
+}
+
+var reportSessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "Report job session results",
+	Long: `Queries VBR job sessions for jobs tracked in state (or declared in a group) and
+aggregates success, warning and failure counts and the last successful run per job.
+
+RPO targets are read from the "owlctl.veeam.com/rpo" annotation on job specs: the
+group's merged specs with --group, otherwise the spec declaring each tracked job in
+owlctl.yaml groups or standalone spec files. --rpo sets the default target for jobs
+without an annotation.
+A job breaches its RPO when its last successful session is older than the target.
+
+Exit codes:
+  0 = No RPO breaches
+  3 = One or more RPO breaches
+  1 = Error
+
+Examples:
+  owlctl report sessions
+  owlctl report sessions --since 30d
+  owlctl report sessions --group production --format json
+  owlctl report sessions --rpo 24h --format csv > sessions.csv
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runSessionReport()
+	},
+}
+
+var reportRestorePointsCmd = &cobra.Command{
+	Use:   "restore-points",
+	Short: "Report restore points per job",
+	Long: `Queries VBR restore points for jobs tracked in state (or declared in a group) and
+reports the number of restore points, protected objects and the newest restore point per job.
+
+A job breaches its RPO when its newest restore point is older than the target declared
+in the "owlctl.veeam.com/rpo" annotation (or --rpo).
+
+Exit codes:
+  0 = No RPO breaches
+  3 = One or more RPO breaches
+  1 = Error
+
+Examples:
+  owlctl report restore-points
+  owlctl report restore-points --group production --rpo 24h
+  owlctl report restore-points --format json
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runRestorePointReport()
+	},
+}
+
+// reportTarget is a job to report on, with its resolved VBR ID and RPO target
+type reportTarget struct {
+	Name string
+	ID   string
+	RPO  time.Duration
+}
+
+// vbrSession is the subset of the VBR session model used for reporting and --wait
+type vbrSession struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	JobID        string `json:"jobId"`
+	SessionType  string `json:"sessionType"`
+	ResourceID   string `json:"resourceId"`
+	CreationTime string `json:"creationTime"`
+	EndTime      string `json:"endTime"`
+	State        string `json:"state"`
+	Progress     int    `json:"progressPercent"`
+	Result       struct {
+		Result  string `json:"result"`
+		Message string `json:"message"`
+	} `json:"result"`
+}
+
+// vbrRestorePoint is the subset of the VBR restore point model used for reporting
+type vbrRestorePoint struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	BackupID     string `json:"backupId"`
+	CreationTime string `json:"creationTime"`
+}
+
+// SessionReportRow is the aggregated session outcome for one job
+type SessionReportRow struct {
+	Job         string     `json:"job"`
+	Sessions    int        `json:"sessions"`
+	Success     int        `json:"success"`
+	Warning     int        `json:"warning"`
+	Failed      int        `json:"failed"`
+	LastResult  string     `json:"lastResult,omitempty"`
+	LastRun     *time.Time `json:"lastRun,omitempty"`
+	LastSuccess *time.Time `json:"lastSuccess,omitempty"`
+	RPO         string     `json:"rpo,omitempty"`
+	RPOBreach   bool       `json:"rpoBreach"`
+	Error       string     `json:"error,omitempty"`
+}
+
+// RestorePointReportRow is the aggregated restore point summary for one job
+type RestorePointReportRow struct {
+	Job           string     `json:"job"`
+	RestorePoints int        `json:"restorePoints"`
+	Objects       int        `json:"objects"`
+	Newest        *time.Time `json:"newest,omitempty"`
+	Oldest        *time.Time `json:"oldest,omitempty"`
+	RPO           string     `json:"rpo,omitempty"`
+	RPOBreach     bool       `json:"rpoBreach"`
+	Error         string     `json:"error,omitempty"`
+}
+
+func runSessionReport() {
+	profile, targets := resolveReportTargets()
+
+	window, err := parseRPODuration(reportSince)
+	if err != nil {
+		log.Fatalf("Invalid --since: %v", err)
+	}
+
+	now := time.Now()
+	var rows []SessionReportRow
+	for _, t := range targets {
+		if t.ID == "" {
+			rows = append(rows, SessionReportRow{Job: t.Name, RPO: formatRPO(t.RPO), Error: "not found in VBR"})
+			continue
+		}
+
+		// Look back far enough to find a success within the RPO window
+		lookback := window
+		if t.RPO > lookback {
+			lookback = t.RPO
+		}
+		sessions := fetchJobSessions(t.ID, now.Add(-lookback), profile)
+		rows = append(rows, aggregateSessions(t, sessions, now))
+	}
+
+	if err := writeSessionReport(os.Stdout, reportFormat, rows); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+
+	for _, r := range rows {
+		if r.RPOBreach {
+			os.Exit(ExitDriftWarning)
+		}
+	}
+}
+
+func runRestorePointReport() {
+	profile, targets := resolveReportTargets()
+
+	now := time.Now()
+	var rows []RestorePointReportRow
+	for _, t := range targets {
+		if t.ID == "" {
+			rows = append(rows, RestorePointReportRow{Job: t.Name, RPO: formatRPO(t.RPO), Error: "not found in VBR"})
+			continue
+		}
+		points := fetchJobRestorePoints(t.ID, profile)
+		rows = append(rows, aggregateRestorePoints(t, points, now))
+	}
+
+	if err := writeRestorePointReport(os.Stdout, reportFormat, rows); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+
+	for _, r := range rows {
+		if r.RPOBreach {
+			os.Exit(ExitDriftWarning)
+		}
+	}
+}
+
+// resolveReportTargets returns the jobs to report on, from --group specs or from state
+func resolveReportTargets() (models.Profile, []reportTarget) {
+	settings := utils.ReadSettings()
+	if settings.SelectedProfile != "vbr" {
+		log.Fatal("This command only works with VBR at the moment.")
+	}
+
+	switch reportFormat {
+	case "table", "json", "csv":
+	default:
+		log.Fatalf("Invalid --format: %s (use table, json, or csv)", reportFormat)
+	}
+
+	defaultRPO := time.Duration(0)
+	if reportRPO != "" {
+		d, err := parseRPODuration(reportRPO)
+		if err != nil {
+			log.Fatalf("Invalid --rpo: %v", err)
+		}
+		defaultRPO = d
+	}
+
+	if reportGroup != "" {
+		return resolveGroupReportTargets(reportGroup, defaultRPO)
+	}
+
+	profile := utils.GetCurrentProfile()
+	stateMgr := state.NewManager()
+	jobs, err := stateMgr.ListResources("VBRJob")
+	if err != nil {
+		log.Fatalf("Failed to load state: %v", err)
+	}
+	if len(jobs) == 0 {
+		fmt.Println("No jobs tracked in state. Run 'owlctl job snapshot --all' or use --group.")
+		os.Exit(ExitError)
+	}
+
+	tracked := make(map[string]bool, len(jobs))
+	for _, j := range jobs {
+		tracked[j.Name] = true
+	}
+	rpos := repositoryJobRPOs(tracked)
+
+	var targets []reportTarget
+	for _, j := range jobs {
+		rpo, ok := rpos[j.Name]
+		if !ok {
+			rpo = defaultRPO
+		}
+		targets = append(targets, reportTarget{Name: j.Name, ID: j.ID, RPO: rpo})
+	}
+	sort.Slice(targets, func(i, k int) bool { return targets[i].Name < targets[k].Name })
+	return profile, targets
+}
+
+// resolveGroupReportTargets returns the VBRJob specs in a group with their RPO annotations
+func resolveGroupReportTargets(group string, defaultRPO time.Duration) (models.Profile, []reportTarget) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load owlctl.yaml: %v", err)
+	}
+	cfg.WarnDeprecatedFields()
+
+	groupCfg, err := cfg.GetGroup(group)
+	if err != nil {
+		log.Fatalf("Group error: %v", err)
+	}
+
+	profile := activateGroupInstance(cfg, groupCfg)
+
+	specs, err := loadMergedGroupSpecs(cfg, groupCfg)
+	if err != nil {
+		log.Fatalf("Failed to load group %q: %v", group, err)
+	}
+
+	jobIDs := fetchJobIDsByName(profile)
+
+	var targets []reportTarget
+	for _, s := range specs {
+		if s.Error != nil {
+			log.Fatalf("%s: %v", s.SpecPath, s.Error)
+		}
+		if s.Spec.Kind != resources.KindVBRJob {
+			continue
+		}
+
+		rpo, err := specRPO(s.Spec, defaultRPO)
+		if err != nil {
+			log.Fatalf("%s: %v", s.SpecPath, err)
+		}
+		targets = append(targets, reportTarget{
+			Name: s.Spec.Metadata.Name,
+			ID:   jobIDs[s.Spec.Metadata.Name],
+			RPO:  rpo,
+		})
+	}
+
+	if len(targets) == 0 {
+		fmt.Printf("Group %q contains no VBRJob specs.\n", group)
+		os.Exit(ExitError)
+	}
+	return profile, targets
+}
+
+// repositoryJobRPOs returns the RPO annotations of the job specs declared in the repository
+// (merged group specs and standalone spec files) for the given state-tracked jobs. Without
+// owlctl.yaml there are no specs to read and every job uses --rpo.
+func repositoryJobRPOs(tracked map[string]bool) map[string]time.Duration {
+	rpos := make(map[string]time.Duration)
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return rpos
+	}
+
+	targets, _ := loadSpecTargets(cfg, jobDiffConfig)
+	for _, t := range targets {
+		if !tracked[t.Name] {
+			continue
+		}
+		if t.Error != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s: %v; using --rpo\n", t.Name, t.Error)
+			continue
+		}
+		if _, ok := t.Spec.Metadata.Annotations[rpoAnnotation]; !ok {
+			continue
+		}
+		rpo, err := specRPO(t.Spec, 0)
+		if err != nil {
+			log.Fatalf("%s (%s): %v", t.Name, t.Source, err)
+		}
+		rpos[t.Name] = rpo
+	}
+	return rpos
+}
+
+// specRPO returns the RPO declared in a spec's annotations, or the default if absent
+func specRPO(spec resources.ResourceSpec, defaultRPO time.Duration) (time.Duration, error) {
+	value, ok := spec.Metadata.Annotations[rpoAnnotation]
+	if !ok || value == "" {
+		return defaultRPO, nil
+	}
+	d, err := parseRPODuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s annotation: %w", rpoAnnotation, err)
+	}
+	return d, nil
+}
+
+// parseRPODuration parses a Go duration, additionally accepting a day suffix (e.g. "7d")
+func parseRPODuration(value string) (time.Duration, error) {
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	return d, nil
+}
+
+// formatRPO renders an RPO target, using days where the value is a whole number of days
+func formatRPO(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	if d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return d.String()
+}
+
+// --- VBR queries ---
+
+// fetchJobIDsByName returns a map of job name to VBR job ID
+func fetchJobIDsByName(profile models.Profile) map[string]string {
+	type JobsResponse struct {
+		Data []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"data"`
+	}
+
+	response := vhttp.GetData[JobsResponse]("jobs", profile)
+	ids := make(map[string]string, len(response.Data))
+	for _, job := range response.Data {
+		ids[job.Name] = job.ID
+	}
+	return ids
+}
+
+func fetchJobSessions(jobID string, since time.Time, profile models.Profile) []vbrSession {
+	type SessionsResponse struct {
+		Data []vbrSession `json:"data"`
+	}
+
+	query := url.Values{}
+	query.Set("jobIdFilter", jobID)
+	query.Set("createdAfterFilter", since.UTC().Format(time.RFC3339))
+	query.Set("orderColumn", "CreationTime")
+	query.Set("orderAsc", "false")
+
+	response := vhttp.GetData[SessionsResponse]("sessions?"+query.Encode(), profile)
+	return response.Data
+}
+
+func fetchJobRestorePoints(jobID string, profile models.Profile) []vbrRestorePoint {
+	type BackupsResponse struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	type RestorePointsResponse struct {
+		Data []vbrRestorePoint `json:"data"`
+	}
+
+	backups := vhttp.GetData[BackupsResponse]("backups?jobIdFilter="+url.QueryEscape(jobID), profile)
+
+	var points []vbrRestorePoint
+	for _, b := range backups.Data {
+		response := vhttp.GetData[RestorePointsResponse]("restorePoints?backupIdFilter="+url.QueryEscape(b.ID), profile)
+		points = append(points, response.Data...)
+	}
+	return points
+}
+
+// --- Aggregation ---
+
+// aggregateSessions summarises a job's sessions and evaluates its RPO against now
+func aggregateSessions(t reportTarget, sessions []vbrSession, now time.Time) SessionReportRow {
+	row := SessionReportRow{Job: t.Name, RPO: formatRPO(t.RPO)}
+
+	var lastRun time.Time
+	for _, s := range sessions {
+		created, err := time.Parse(time.RFC3339Nano, s.CreationTime)
+		if err != nil {
+			continue
+		}
+		row.Sessions++
+
+		switch s.Result.Result {
+		case "Success":
+			row.Success++
+		case "Warning":
+			row.Warning++
+		case "Failed":
+			row.Failed++
+		}
+
+		if created.After(lastRun) {
+			lastRun = created
+			row.LastResult = s.Result.Result
+		}
+
+		// Warnings still produce a restore point, so they count towards the RPO
+		if s.Result.Result == "Success" || s.Result.Result == "Warning" {
+			end := created
+			if e, err := time.Parse(time.RFC3339Nano, s.EndTime); err == nil {
+				end = e
+			}
+			if row.LastSuccess == nil || end.After(*row.LastSuccess) {
+				lastSuccess := end
+				row.LastSuccess = &lastSuccess
+			}
+		}
+	}
+
+	if !lastRun.IsZero() {
+		row.LastRun = &lastRun
+	}
+	if t.RPO > 0 {
+		row.RPOBreach = row.LastSuccess == nil || now.Sub(*row.LastSuccess) > t.RPO
+	}
+	return row
+}
+
+// aggregateRestorePoints summarises a job's restore points and evaluates its RPO against now
+func aggregateRestorePoints(t reportTarget, points []vbrRestorePoint, now time.Time) RestorePointReportRow {
+	row := RestorePointReportRow{Job: t.Name, RPO: formatRPO(t.RPO)}
+
+	objects := make(map[string]bool)
+	for _, p := range points {
+		created, err := time.Parse(time.RFC3339Nano, p.CreationTime)
+		if err != nil {
+			continue
+		}
+		row.RestorePoints++
+		objects[p.Name] = true
+
+		if row.Newest == nil || created.After(*row.Newest) {
+			newest := created
+			row.Newest = &newest
+		}
+		if row.Oldest == nil || created.Before(*row.Oldest) {
+			oldest := created
+			row.Oldest = &oldest
+		}
+	}
+	row.Objects = len(objects)
+
+	if t.RPO > 0 {
+		row.RPOBreach = row.Newest == nil || now.Sub(*row.Newest) > t.RPO
+	}
+	return row
+}
+
+// --- Output ---
+
+func writeSessionReport(w io.Writer, format string, rows []SessionReportRow) error {
+	if format == "json" {
+		return writeReportJSON(w, rows)
+	}
+
+	headers := []string{"JOB", "SESSIONS", "SUCCESS", "WARNING", "FAILED", "LAST RESULT", "LAST RUN", "LAST SUCCESS", "RPO", "STATUS"}
+	var records [][]string
+	for _, r := range rows {
+		records = append(records, []string{
+			r.Job,
+			strconv.Itoa(r.Sessions),
+			strconv.Itoa(r.Success),
+			strconv.Itoa(r.Warning),
+			strconv.Itoa(r.Failed),
+			valueOrDash(r.LastResult),
+			formatReportTime(r.LastRun),
+			formatReportTime(r.LastSuccess),
+			valueOrDash(r.RPO),
+			reportStatus(r.RPOBreach, r.Error),
+		})
+	}
+	return writeReportRecords(w, format, headers, records)
+}
+
+func writeRestorePointReport(w io.Writer, format string, rows []RestorePointReportRow) error {
+	if format == "json" {
+		return writeReportJSON(w, rows)
+	}
+
+	headers := []string{"JOB", "RESTORE POINTS", "OBJECTS", "NEWEST", "OLDEST", "RPO", "STATUS"}
+	var records [][]string
+	for _, r := range rows {
+		records = append(records, []string{
+			r.Job,
+			strconv.Itoa(r.RestorePoints),
+			strconv.Itoa(r.Objects),
+			formatReportTime(r.Newest),
+			formatReportTime(r.Oldest),
+			valueOrDash(r.RPO),
+			reportStatus(r.RPOBreach, r.Error),
+		})
+	}
+	return writeReportRecords(w, format, headers, records)
+}
+
+func writeReportJSON(w io.Writer, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// writeReportRecords writes rows as a table or CSV
+func writeReportRecords(w io.Writer, format string, headers []string, records [][]string) error {
+	if format == "csv" {
+		cw := csv.NewWriter(w)
+		if err := cw.Write(headers); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(records); err != nil {
+			return err
+		}
+		return cw.Error()
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	for _, r := range records {
+		fmt.Fprintln(tw, strings.Join(r, "\t"))
+	}
+	return tw.Flush()
+}
+
+func reportStatus(breach bool, errMsg string) string {
+	switch {
+	case errMsg != "":
+		return "ERROR: " + errMsg
+	case breach:
+		return "RPO BREACH"
+	default:
+		return "OK"
+	}
+}
+
+func formatReportTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func init() {
+	for _, c := range []*cobra.Command{reportSessionsCmd, reportRestorePointsCmd} {
+		c.Flags().StringVar(&reportGroup, "group", "", "Report on jobs declared in a group (from owlctl.yaml) instead of state")
+		c.Flags().StringVar(&reportFormat, "format", "table", "Output format: table, json, or csv")
+		c.Flags().StringVar(&reportRPO, "rpo", "", "Default RPO target for jobs without an annotation (e.g. 24h, 7d)")
+	}
+	reportSessionsCmd.Flags().StringVar(&reportSince, "since", "7d", "Session history window (e.g. 24h, 7d)")
+
+	reportCmd.AddCommand(reportSessionsCmd)
+	reportCmd.AddCommand(reportRestorePointsCmd)
+	rootCmd.AddCommand(reportCmd)
+}
@@ -0,0 +1,845 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shapedthought/owlctl/history"
+	"github.com/shapedthought/owlctl/state"
+	"github.com/spf13/cobra"
+)
+
+// complianceHistoryEvents is the number of recent state events shown per drifted resource
+const complianceHistoryEvents = 5
+
+// complianceTopFields is the number of fields in the drift history's top drifting fields
+const complianceTopFields = 5
+
+var (
+	complianceFormat   string
+	complianceOutput   string
+	complianceScanFile string
+	compliancePrevious []string
+	complianceSince    string
+)
+
+var reportComplianceCmd = &cobra.Command{
+	Use:   "compliance",
+	Short: "Generate a compliance report in Markdown or HTML",
+	Long: `Scans every resource kind for drift (as owlctl scan) and combines the results with
+state (last applied, applied by, history) and the security components into a
+compliance document with per-resource status, a severity summary, correlation
+incidents and, given previous reports, a trend section.
+
+Use --format json to save a report that later runs can compare against with
+--previous. Use --scan-file to build the report from a saved
+'owlctl scan --format json' result instead of scanning again.
+
+When scans are recorded in the drift history (driftHistory in owlctl.yaml), the
+report adds a drift history section for the --history-since window: how long
+open drift has lasted, mean time to remediation per resource and the fields that
+drift most often.
+
+The Markdown output can be written straight to a pipeline build summary, e.g.
+$GITHUB_STEP_SUMMARY or an Azure DevOps task.uploadsummary file.
+
+Exit codes (same as owlctl scan):
+  0 = No drift
+  3 = Drift detected (INFO or WARNING)
+  4 = Critical drift or incident detected
+  1 = Error
+
+Examples:
+  owlctl report compliance > compliance.md
+  owlctl report compliance --format html -o compliance.html
+  owlctl report compliance --format json -o reports/$(date +%F).json
+  owlctl report compliance --previous reports/2026-10-01.json --previous reports/2026-10-08.json
+  owlctl scan --format json > scan.json; owlctl report compliance --scan-file scan.json
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runComplianceReport()
+	},
+}
+
+// ComplianceReport is the document produced by report compliance. Its JSON form is the
+// input for --previous.
+type ComplianceReport struct {
+	GeneratedAt time.Time              `json:"generatedAt"`
+	Status      string                 `json:"status"` // PASS, WARN, FAIL, ERROR
+	ExitCode    int                    `json:"exitCode"`
+	Summary     ComplianceSummary      `json:"summary"`
+	Resources   []ComplianceResource   `json:"resources"`
+	Security    []ComplianceControl    `json:"security"`
+	Incidents   []ComplianceIncident   `json:"incidents,omitempty"`
+	Errors      []string               `json:"errors,omitempty"`
+	Trend       []ComplianceTrendPoint `json:"trend,omitempty"`
+	Changes     *ComplianceChanges     `json:"changes,omitempty"`
+	History     *ComplianceHistory     `json:"history,omitempty"`
+	Acks        []ComplianceAck        `json:"acks,omitempty"`
+}
+
+// ComplianceSummary counts resources by status and drifts by severity
+type ComplianceSummary struct {
+	Resources int `json:"resources"`
+	Compliant int `json:"compliant"`
+	Drifted   int `json:"drifted"`
+	Critical  int `json:"critical"`
+	Warning   int `json:"warning"`
+	Info      int `json:"info"`
+	Incidents int `json:"incidents"`
+}
+
+// ComplianceResource is the status of one resource with its state metadata
+type ComplianceResource struct {
+	Instance      string                `json:"instance,omitempty"`
+	Kind          string                `json:"kind"`
+	Name          string                `json:"name"`
+	Origin        string                `json:"origin,omitempty"`
+	Status        string                `json:"status"` // compliant, drifted
+	Severity      Severity              `json:"severity,omitempty"`
+	Drifts        []Drift               `json:"drifts,omitempty"`
+	LastApplied   *time.Time            `json:"lastApplied,omitempty"`
+	LastAppliedBy string                `json:"lastAppliedBy,omitempty"`
+	History       []state.ResourceEvent `json:"history,omitempty"`
+	Acknowledged  []Drift               `json:"acknowledged,omitempty"`
+}
+
+// ComplianceControl is the status of one security component
+type ComplianceControl struct {
+	Instance  string   `json:"instance,omitempty"`
+	Component string   `json:"component"`
+	Status    string   `json:"status"` // OK, DRIFTED, NOT SNAPSHOTTED, ERROR
+	Severity  Severity `json:"severity,omitempty"`
+}
+
+// ComplianceIncident is a correlation incident and the instance it was raised on
+type ComplianceIncident struct {
+	Instance string `json:"instance,omitempty"`
+	Incident
+}
+
+// ComplianceAck is a drift acknowledgement of a scanned instance, active or expired
+type ComplianceAck struct {
+	Instance string `json:"instance,omitempty"`
+	AckStatus
+}
+
+// ComplianceTrendPoint is the summary of one report in the trend section
+type ComplianceTrendPoint struct {
+	GeneratedAt time.Time         `json:"generatedAt"`
+	Status      string            `json:"status"`
+	Summary     ComplianceSummary `json:"summary"`
+}
+
+// ComplianceChanges lists the resources whose drift started or ended since the previous report
+type ComplianceChanges struct {
+	Since    time.Time `json:"since"`
+	NewDrift []string  `json:"newDrift,omitempty"`
+	Resolved []string  `json:"resolved,omitempty"`
+}
+
+// ComplianceHistory summarizes the drift history over the report window
+type ComplianceHistory struct {
+	Since       time.Time                 `json:"since"`
+	Episodes    int                       `json:"episodes"`
+	Resolved    int                       `json:"resolved"`
+	MTTRSeconds int64                     `json:"mttrSeconds,omitempty"`
+	Open        []history.Episode         `json:"open,omitempty"`
+	Resources   []history.ResourceSummary `json:"resources,omitempty"`
+	TopFields   []history.FieldSummary    `json:"topFields,omitempty"`
+}
+
+func runComplianceReport() {
+	switch complianceFormat {
+	case "markdown", "html", "json":
+	default:
+		log.Fatalf("Invalid --format: %s (use markdown, html, or json)", complianceFormat)
+	}
+	window, err := parseRPODuration(complianceSince)
+	if err != nil {
+		log.Fatalf("Invalid --history-since: %v", err)
+	}
+
+	var scan ScanReport
+	if complianceScanFile != "" {
+		data, err := os.ReadFile(complianceScanFile)
+		if err != nil {
+			log.Fatalf("Failed to read scan file: %v", err)
+		}
+		if err := json.Unmarshal(data, &scan); err != nil {
+			log.Fatalf("Failed to parse scan file %s: %v", complianceScanFile, err)
+		}
+	} else {
+		loadSeverityOverrides()
+		rules, err := loadCorrelationRules("")
+		if err != nil {
+			log.Fatal(err)
+		}
+		scan = collectScan(SeverityInfo, rules)
+	}
+
+	st, err := state.NewManager().Load()
+	if err != nil {
+		log.Fatalf("Failed to load state: %v", err)
+	}
+
+	var previous []ComplianceReport
+	for _, p := range compliancePrevious {
+		prev, err := loadComplianceReport(p)
+		if err != nil {
+			log.Fatal(err)
+		}
+		previous = append(previous, prev)
+	}
+
+	report := buildComplianceReport(scan, st, previous)
+
+	store, _ := driftHistoryStore()
+	records, err := store.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	report.History = complianceHistory(records, scan, report.GeneratedAt.Add(-window), report.GeneratedAt)
+
+	if complianceOutput == "" {
+		if err := writeComplianceReport(os.Stdout, complianceFormat, report); err != nil {
+			log.Fatalf("Failed to write report: %v", err)
+		}
+	} else {
+		f, err := os.Create(complianceOutput)
+		if err != nil {
+			log.Fatalf("Failed to create output file: %v", err)
+		}
+		err = writeComplianceReport(f, complianceFormat, report)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			log.Fatalf("Failed to write report: %v", err)
+		}
+		fmt.Fprintf(os.Stderr, "Compliance report written to %s (status: %s)\n", complianceOutput, report.Status)
+	}
+
+	ciOutput.scanReport(scan)
+	ciExit(report.ExitCode)
+}
+
+// loadComplianceReport reads a previous report saved with --format json
+func loadComplianceReport(path string) (ComplianceReport, error) {
+	var r ComplianceReport
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return r, fmt.Errorf("failed to read previous report: %w", err)
+	}
+	if err := json.Unmarshal(data, &r); err != nil {
+		return r, fmt.Errorf("failed to parse previous report %s: %w", path, err)
+	}
+	if r.GeneratedAt.IsZero() {
+		return r, fmt.Errorf("%s is not a compliance report (generate it with --format json)", path)
+	}
+	return r, nil
+}
+
+// buildComplianceReport combines scan results with state metadata and previous reports
+func buildComplianceReport(scan ScanReport, st *state.State, previous []ComplianceReport) ComplianceReport {
+	report := ComplianceReport{
+		GeneratedAt: scan.Timestamp,
+		ExitCode:    scan.ExitCode,
+		Resources:   []ComplianceResource{},
+		Security:    []ComplianceControl{},
+	}
+	if report.GeneratedAt.IsZero() {
+		report.GeneratedAt = time.Now().UTC()
+	}
+
+	securityKinds := make(map[string]bool)
+	for _, sc := range securityResources {
+		securityKinds[sc.Kind] = true
+	}
+
+	for _, inst := range scan.Instances {
+		if inst.Error != "" {
+			report.Errors = append(report.Errors, instanceLabel(inst.Name)+inst.Error)
+			continue
+		}
+
+		stateKey := inst.Name
+		if stateKey == "" {
+			stateKey = "default"
+		}
+
+		for _, kind := range inst.Kinds {
+			if kind.Error != "" {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s%s: %s", instanceLabel(inst.Name), kind.DisplayName, kind.Error))
+			}
+			if securityKinds[kind.Kind] {
+				report.Security = append(report.Security, complianceControl(inst.Name, kind))
+			}
+
+			for _, res := range kind.Resources {
+				if res.Error != "" {
+					report.Errors = append(report.Errors, fmt.Sprintf("%s%s / %s: %s", instanceLabel(inst.Name), kind.DisplayName, res.Name, res.Error))
+					continue
+				}
+				cr := ComplianceResource{
+					Instance:     inst.Name,
+					Kind:         kind.Kind,
+					Name:         res.Name,
+					Origin:       res.Origin,
+					Status:       "compliant",
+					Drifts:       res.Drifts,
+					Acknowledged: res.Acknowledged,
+				}
+				if len(res.Drifts) > 0 {
+					cr.Status = "drifted"
+					cr.Severity = getMaxSeverity(res.Drifts)
+				}
+				// Scan reports resources (including singletons) under their state key
+				if r, ok := st.GetResource(stateKey, res.Name); ok {
+					lastApplied := r.LastApplied
+					cr.LastApplied = &lastApplied
+					cr.LastAppliedBy = r.LastAppliedBy
+					cr.History = recentEvents(r.History, complianceHistoryEvents)
+				}
+				report.Resources = append(report.Resources, cr)
+			}
+		}
+
+		for _, incident := range inst.Incidents {
+			report.Incidents = append(report.Incidents, ComplianceIncident{Instance: inst.Name, Incident: incident})
+		}
+		for _, ack := range inst.Acks {
+			report.Acks = append(report.Acks, ComplianceAck{Instance: inst.Name, AckStatus: ack})
+		}
+	}
+
+	report.Summary = summarizeCompliance(report.Resources, len(report.Incidents))
+	report.Status = complianceStatus(report.ExitCode)
+
+	if len(previous) > 0 {
+		sort.Slice(previous, func(i, j int) bool { return previous[i].GeneratedAt.Before(previous[j].GeneratedAt) })
+		for _, p := range previous {
+			report.Trend = append(report.Trend, ComplianceTrendPoint{GeneratedAt: p.GeneratedAt, Status: p.Status, Summary: p.Summary})
+		}
+		report.Trend = append(report.Trend, ComplianceTrendPoint{GeneratedAt: report.GeneratedAt, Status: report.Status, Summary: report.Summary})
+		report.Changes = complianceChanges(previous[len(previous)-1], report)
+	}
+	return report
+}
+
+// complianceHistory summarizes the recorded drift of the scanned instances since the given
+// time. It returns nil when nothing was recorded for them.
+func complianceHistory(records []history.Record, scan ScanReport, since, now time.Time) *ComplianceHistory {
+	instances := make(map[string]bool)
+	for _, inst := range scan.Instances {
+		instances[inst.Name] = true
+	}
+	var kept []history.Record
+	for _, r := range records {
+		if instances[r.Instance] {
+			kept = append(kept, r)
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+
+	h := &ComplianceHistory{Since: since}
+	var episodes []history.Episode
+	for _, ep := range history.Episodes(kept, now) {
+		if !ep.Open() && ep.ResolvedAt.Before(since) {
+			continue
+		}
+		episodes = append(episodes, ep)
+		if ep.Open() {
+			h.Open = append(h.Open, ep)
+		}
+	}
+	mttr, resolved := history.MTTR(episodes)
+	h.Episodes = len(episodes)
+	h.Resolved = resolved
+	h.MTTRSeconds = int64(mttr / time.Second)
+	h.Resources = history.SummarizeResources(episodes)
+	h.TopFields = history.TopFields(episodes, complianceTopFields)
+	return h
+}
+
+func complianceControl(instance string, kind ScanKind) ComplianceControl {
+	c := ComplianceControl{Instance: instance, Component: kind.DisplayName, Status: "OK"}
+	switch {
+	case kind.Error != "":
+		c.Status = "ERROR"
+	case kind.Skipped != "":
+		c.Status = "NOT SNAPSHOTTED"
+	case kind.MaxSeverity != "":
+		c.Status = "DRIFTED"
+		c.Severity = kind.MaxSeverity
+	}
+	return c
+}
+
+func summarizeCompliance(resources []ComplianceResource, incidents int) ComplianceSummary {
+	s := ComplianceSummary{Resources: len(resources), Incidents: incidents}
+	for _, r := range resources {
+		if r.Status == "drifted" {
+			s.Drifted++
+		} else {
+			s.Compliant++
+		}
+		for _, d := range r.Drifts {
+			switch d.Severity {
+			case SeverityCritical:
+				s.Critical++
+			case SeverityWarning:
+				s.Warning++
+			default:
+				s.Info++
+			}
+		}
+	}
+	return s
+}
+
+// complianceStatus maps the scan exit code to the overall report status
+func complianceStatus(exitCode int) string {
+	switch exitCode {
+	case ExitSuccess:
+		return "PASS"
+	case ExitDriftWarning:
+		return "WARN"
+	case ExitDriftCritical:
+		return "FAIL"
+	default:
+		return "ERROR"
+	}
+}
+
+// complianceChanges compares drifted resources with the previous report
+func complianceChanges(prev, current ComplianceReport) *ComplianceChanges {
+	key := func(r ComplianceResource) string {
+		return instanceLabel(r.Instance) + r.Kind + "/" + r.Name
+	}
+	before := make(map[string]bool)
+	for _, r := range prev.Resources {
+		if r.Status == "drifted" {
+			before[key(r)] = true
+		}
+	}
+	now := make(map[string]bool)
+	for _, r := range current.Resources {
+		if r.Status == "drifted" {
+			now[key(r)] = true
+		}
+	}
+
+	changes := &ComplianceChanges{Since: prev.GeneratedAt}
+	for k := range now {
+		if !before[k] {
+			changes.NewDrift = append(changes.NewDrift, k)
+		}
+	}
+	for k := range before {
+		if !now[k] {
+			changes.Resolved = append(changes.Resolved, k)
+		}
+	}
+	sort.Strings(changes.NewDrift)
+	sort.Strings(changes.Resolved)
+	return changes
+}
+
+// recentEvents returns the first n events of a history, which state keeps newest first
+func recentEvents(events []state.ResourceEvent, n int) []state.ResourceEvent {
+	if len(events) > n {
+		return events[:n]
+	}
+	return events
+}
+
+func instanceLabel(name string) string {
+	if name == "" {
+		return ""
+	}
+	return name + ": "
+}
+
+// --- Rendering ---
+
+// reportBlock is one element of a rendered report: a heading, paragraph, list or table
+type reportBlock struct {
+	Heading int // heading level; 0 for other blocks
+	Text    string
+	Items   []string
+	Headers []string
+	Rows    [][]string
+}
+
+func writeComplianceReport(w io.Writer, format string, r ComplianceReport) error {
+	if format == "json" {
+		return writeReportJSON(w, r)
+	}
+	blocks := complianceBlocks(r)
+	if format == "html" {
+		return renderReportHTML(w, "owlctl Compliance Report", blocks)
+	}
+	return renderReportMarkdown(w, blocks)
+}
+
+// complianceBlocks lays out the report sections
+func complianceBlocks(r ComplianceReport) []reportBlock {
+	showInstance := false
+	for _, res := range r.Resources {
+		if res.Instance != "" {
+			showInstance = true
+		}
+	}
+	withInstance := func(instance string, cells ...string) []string {
+		if showInstance {
+			return append([]string{valueOrDash(instance)}, cells...)
+		}
+		return cells
+	}
+	headers := func(cells ...string) []string {
+		if showInstance {
+			return append([]string{"Instance"}, cells...)
+		}
+		return cells
+	}
+
+	blocks := []reportBlock{
+		{Heading: 1, Text: "owlctl Compliance Report"},
+		{Text: fmt.Sprintf("Generated %s. Status: %s.", r.GeneratedAt.UTC().Format("2006-01-02 15:04 UTC"), r.Status)},
+		{Heading: 2, Text: "Summary"},
+		{Headers: []string{"Metric", "Value"}, Rows: [][]string{
+			{"Status", r.Status},
+			{"Resources", strconv.Itoa(r.Summary.Resources)},
+			{"Compliant", strconv.Itoa(r.Summary.Compliant)},
+			{"Drifted", strconv.Itoa(r.Summary.Drifted)},
+			{"CRITICAL drifts", strconv.Itoa(r.Summary.Critical)},
+			{"WARNING drifts", strconv.Itoa(r.Summary.Warning)},
+			{"INFO drifts", strconv.Itoa(r.Summary.Info)},
+			{"Incidents", strconv.Itoa(r.Summary.Incidents)},
+		}},
+	}
+
+	if len(r.Errors) > 0 {
+		blocks = append(blocks, reportBlock{Heading: 2, Text: "Errors"}, reportBlock{Items: r.Errors})
+	}
+
+	if len(r.Incidents) > 0 {
+		blocks = append(blocks, reportBlock{Heading: 2, Text: "Incidents"})
+		for _, inc := range r.Incidents {
+			blocks = append(blocks, reportBlock{Heading: 3, Text: fmt.Sprintf("%s %s%s", inc.Severity, instanceLabel(inc.Instance), inc.Rule)})
+			if inc.Description != "" {
+				blocks = append(blocks, reportBlock{Text: inc.Description})
+			}
+			var rows [][]string
+			for _, d := range inc.Drifts {
+				rows = append(rows, []string{d.Kind + "/" + d.Resource, d.Drift.Path, driftChangeText(d.Drift), string(d.Drift.Severity)})
+			}
+			blocks = append(blocks, reportBlock{Headers: []string{"Resource", "Field", "Change", "Severity"}, Rows: rows})
+		}
+	}
+
+	if len(r.Security) > 0 {
+		var rows [][]string
+		for _, c := range r.Security {
+			status := c.Status
+			if c.Severity != "" {
+				status = fmt.Sprintf("%s (%s)", c.Status, c.Severity)
+			}
+			rows = append(rows, withInstance(c.Instance, c.Component, status))
+		}
+		blocks = append(blocks, reportBlock{Heading: 2, Text: "Security Controls"}, reportBlock{Headers: headers("Component", "Status"), Rows: rows})
+	}
+
+	blocks = append(blocks, reportBlock{Heading: 2, Text: "Resources"})
+	if len(r.Resources) == 0 {
+		blocks = append(blocks, reportBlock{Text: "No resources in state."})
+	} else {
+		var rows [][]string
+		for _, res := range r.Resources {
+			status := "Compliant"
+			if res.Status == "drifted" {
+				status = fmt.Sprintf("Drifted (%s)", res.Severity)
+			}
+			lastEvent := "-"
+			if len(res.History) > 0 {
+				lastEvent = fmt.Sprintf("%s %s", res.History[0].Action, res.History[0].Timestamp.UTC().Format("2006-01-02"))
+			}
+			rows = append(rows, withInstance(res.Instance, res.Kind, res.Name, valueOrDash(res.Origin), status, formatReportTime(res.LastApplied), valueOrDash(res.LastAppliedBy), lastEvent))
+		}
+		blocks = append(blocks, reportBlock{Headers: headers("Kind", "Resource", "Origin", "Status", "Last Applied", "By", "Last Event"), Rows: rows})
+	}
+
+	var drifted []ComplianceResource
+	for _, res := range r.Resources {
+		if res.Status == "drifted" {
+			drifted = append(drifted, res)
+		}
+	}
+	if len(drifted) > 0 {
+		blocks = append(blocks, reportBlock{Heading: 2, Text: "Drift Details"})
+		for _, res := range drifted {
+			blocks = append(blocks, reportBlock{Heading: 3, Text: fmt.Sprintf("%s%s/%s (%s)", instanceLabel(res.Instance), res.Kind, res.Name, res.Severity)})
+			var rows [][]string
+			for _, d := range res.Drifts {
+				rows = append(rows, []string{d.Path, driftChangeText(d), string(d.Severity)})
+			}
+			blocks = append(blocks, reportBlock{Headers: []string{"Field", "Change", "Severity"}, Rows: rows})
+			if len(res.History) > 0 {
+				var items []string
+				for _, e := range res.History {
+					item := fmt.Sprintf("%s %s by %s", e.Timestamp.UTC().Format("2006-01-02 15:04"), e.Action, valueOrDash(e.User))
+					if len(e.Fields) > 0 {
+						item += " (" + strings.Join(e.Fields, ", ") + ")"
+					}
+					items = append(items, item)
+				}
+				blocks = append(blocks, reportBlock{Text: "Recent history:"}, reportBlock{Items: items})
+			}
+		}
+	}
+
+	if len(r.Acks) > 0 {
+		var rows [][]string
+		for _, a := range r.Acks {
+			status := "Active"
+			if a.Status == "expired" {
+				status = "Expired"
+			}
+			rows = append(rows, withInstance(a.Instance, ackResourceLabel(a.Acknowledgement), a.Path, status, a.Until.UTC().Format("2006-01-02 15:04"), strconv.Itoa(a.Suppressed), valueOrDash(a.User), a.Reason))
+		}
+		blocks = append(blocks,
+			reportBlock{Heading: 2, Text: "Acknowledged Drift"},
+			reportBlock{Text: "Active acknowledgements suppress drift on the field until they expire. Expired acknowledgements no longer suppress drift."},
+			reportBlock{Headers: headers("Resource", "Field", "Status", "Until", "Suppressed", "By", "Reason"), Rows: rows},
+		)
+	}
+
+	if r.History != nil {
+		blocks = append(blocks, complianceHistoryBlocks(r.History)...)
+	}
+
+	if len(r.Trend) > 0 {
+		var rows [][]string
+		for _, t := range r.Trend {
+			rows = append(rows, []string{
+				t.GeneratedAt.UTC().Format("2006-01-02 15:04"),
+				t.Status,
+				strconv.Itoa(t.Summary.Resources),
+				strconv.Itoa(t.Summary.Drifted),
+				strconv.Itoa(t.Summary.Critical),
+				strconv.Itoa(t.Summary.Warning),
+				strconv.Itoa(t.Summary.Incidents),
+			})
+		}
+		blocks = append(blocks,
+			reportBlock{Heading: 2, Text: "Trend"},
+			reportBlock{Headers: []string{"Report", "Status", "Resources", "Drifted", "Critical", "Warning", "Incidents"}, Rows: rows},
+		)
+	}
+
+	if r.Changes != nil {
+		blocks = append(blocks, reportBlock{Heading: 3, Text: "Changes since " + r.Changes.Since.UTC().Format("2006-01-02 15:04")})
+		var items []string
+		for _, k := range r.Changes.NewDrift {
+			items = append(items, "New drift: "+k)
+		}
+		for _, k := range r.Changes.Resolved {
+			items = append(items, "Resolved: "+k)
+		}
+		if len(items) == 0 {
+			blocks = append(blocks, reportBlock{Text: "No change in drifted resources."})
+		} else {
+			blocks = append(blocks, reportBlock{Items: items})
+		}
+	}
+
+	return blocks
+}
+
+// complianceHistoryBlocks lays out the drift history section
+func complianceHistoryBlocks(h *ComplianceHistory) []reportBlock {
+	summary := fmt.Sprintf("Since %s: %d drift episodes, %d resolved.", h.Since.UTC().Format("2006-01-02"), h.Episodes, h.Resolved)
+	if h.Resolved > 0 {
+		summary += fmt.Sprintf(" Mean time to remediation: %s.", formatElapsed(time.Duration(h.MTTRSeconds)*time.Second))
+	}
+	blocks := []reportBlock{{Heading: 2, Text: "Drift History"}, {Text: summary}}
+
+	if len(h.Open) > 0 {
+		var rows [][]string
+		for _, ep := range h.Open {
+			rows = append(rows, []string{
+				instanceLabel(ep.Instance) + ep.Kind + "/" + ep.Resource,
+				ep.Field,
+				ep.Severity,
+				ep.FirstDrifted.UTC().Format("2006-01-02 15:04"),
+				formatElapsed(ep.Duration()),
+			})
+		}
+		blocks = append(blocks,
+			reportBlock{Heading: 3, Text: "Open Drift"},
+			reportBlock{Headers: []string{"Resource", "Field", "Severity", "First Drifted", "Drifted For"}, Rows: rows},
+		)
+	}
+
+	if len(h.Resources) > 0 {
+		var rows [][]string
+		for _, res := range h.Resources {
+			mttr := "-"
+			if res.MTTRSeconds > 0 {
+				mttr = formatElapsed(res.MTTR())
+			}
+			rows = append(rows, []string{instanceLabel(res.Instance) + res.Kind + "/" + res.Resource, strconv.Itoa(res.Episodes), strconv.Itoa(res.Open), mttr})
+		}
+		blocks = append(blocks,
+			reportBlock{Heading: 3, Text: "Time to Remediation"},
+			reportBlock{Headers: []string{"Resource", "Episodes", "Open", "MTTR"}, Rows: rows},
+		)
+	}
+
+	if len(h.TopFields) > 0 {
+		var rows [][]string
+		for _, f := range h.TopFields {
+			rows = append(rows, []string{f.Kind, f.Field, strconv.Itoa(f.Episodes), strconv.Itoa(f.Resources), formatElapsed(time.Duration(f.DriftedSeconds) * time.Second)})
+		}
+		blocks = append(blocks,
+			reportBlock{Heading: 3, Text: "Top Drifting Fields"},
+			reportBlock{Headers: []string{"Kind", "Field", "Episodes", "Resources", "Total Drifted"}, Rows: rows},
+		)
+	}
+	return blocks
+}
+
+// driftChangeText describes a drift's change in one line
+func driftChangeText(d Drift) string {
+	switch d.Action {
+	case "modified":
+		return fmt.Sprintf("%s -> %s", formatValue(d.State), formatValue(d.VBR))
+	case "removed":
+		return "removed from VBR"
+	case "added":
+		return "added in VBR: " + formatValue(d.VBR)
+	}
+	return d.Action
+}
+
+func renderReportMarkdown(w io.Writer, blocks []reportBlock) error {
+	var b strings.Builder
+	for _, blk := range blocks {
+		switch {
+		case blk.Heading > 0:
+			fmt.Fprintf(&b, "%s %s\n\n", strings.Repeat("#", blk.Heading), blk.Text)
+		case len(blk.Headers) > 0:
+			b.WriteString("| " + strings.Join(markdownCells(blk.Headers), " | ") + " |\n")
+			b.WriteString("|" + strings.Repeat(" --- |", len(blk.Headers)) + "\n")
+			for _, row := range blk.Rows {
+				b.WriteString("| " + strings.Join(markdownCells(row), " | ") + " |\n")
+			}
+			b.WriteString("\n")
+		case len(blk.Items) > 0:
+			for _, item := range blk.Items {
+				b.WriteString("- " + item + "\n")
+			}
+			b.WriteString("\n")
+		default:
+			b.WriteString(blk.Text + "\n\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownCells escapes table cell separators and line breaks
+func markdownCells(cells []string) []string {
+	out := make([]string, len(cells))
+	for i, c := range cells {
+		c = strings.ReplaceAll(c, "|", `\|`)
+		out[i] = strings.ReplaceAll(c, "\n", " ")
+	}
+	return out
+}
+
+// complianceCSS styles the standalone HTML report
+const complianceCSS = `body{font-family:-apple-system,Segoe UI,Helvetica,Arial,sans-serif;margin:2em;color:#1f2328}
+table{border-collapse:collapse;margin-bottom:1.5em}
+th,td{border:1px solid #d0d7de;padding:4px 10px;text-align:left}
+th{background:#f6f8fa}
+.critical,.fail,.error{color:#cf222e;font-weight:600}
+.warning,.warn{color:#9a6700;font-weight:600}
+.pass,.compliant,.ok{color:#1a7f37}`
+
+func renderReportHTML(w io.Writer, title string, blocks []reportBlock) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n<style>\n%s\n</style>\n</head>\n<body>\n", html.EscapeString(title), complianceCSS)
+	for _, blk := range blocks {
+		switch {
+		case blk.Heading > 0:
+			fmt.Fprintf(&b, "<h%d>%s</h%d>\n", blk.Heading, html.EscapeString(blk.Text), blk.Heading)
+		case len(blk.Headers) > 0:
+			b.WriteString("<table>\n<tr>")
+			for _, h := range blk.Headers {
+				b.WriteString("<th>" + html.EscapeString(h) + "</th>")
+			}
+			b.WriteString("</tr>\n")
+			for _, row := range blk.Rows {
+				b.WriteString("<tr>")
+				for _, c := range row {
+					if class := statusClass(c); class != "" {
+						fmt.Fprintf(&b, "<td class=\"%s\">%s</td>", class, html.EscapeString(c))
+					} else {
+						b.WriteString("<td>" + html.EscapeString(c) + "</td>")
+					}
+				}
+				b.WriteString("</tr>\n")
+			}
+			b.WriteString("</table>\n")
+		case len(blk.Items) > 0:
+			b.WriteString("<ul>\n")
+			for _, item := range blk.Items {
+				b.WriteString("<li>" + html.EscapeString(item) + "</li>\n")
+			}
+			b.WriteString("</ul>\n")
+		default:
+			b.WriteString("<p>" + html.EscapeString(blk.Text) + "</p>\n")
+		}
+	}
+	b.WriteString("</body>\n</html>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// statusClass returns the CSS class for status and severity cells
+func statusClass(cell string) string {
+	word, _, _ := strings.Cut(cell, " ")
+	switch strings.ToUpper(word) {
+	case "CRITICAL", "FAIL", "ERROR", "WARNING", "WARN", "PASS", "OK", "COMPLIANT":
+		return strings.ToLower(word)
+	case "DRIFTED":
+		if strings.Contains(cell, string(SeverityCritical)) {
+			return "critical"
+		}
+		return "warning"
+	}
+	return ""
+}
+
+func init() {
+	reportComplianceCmd.Flags().StringVar(&complianceFormat, "format", "markdown", "Output format: markdown, html, or json")
+	reportComplianceCmd.Flags().StringVarP(&complianceOutput, "output", "o", "", "Write the report to a file instead of stdout")
+	reportComplianceCmd.Flags().StringVar(&complianceScanFile, "scan-file", "", "Build the report from a saved 'owlctl scan --format json' result")
+	reportComplianceCmd.Flags().StringArrayVar(&compliancePrevious, "previous", nil, "Previous report (--format json) for the trend section; repeatable")
+	reportComplianceCmd.Flags().StringVar(&complianceSince, "history-since", "30d", "Drift history window for the drift history section (e.g. 7d, 90d)")
+	reportComplianceCmd.Flags().BoolVar(&scanAllInstances, "all-instances", false, "Scan every VBR instance in owlctl.yaml")
+	addSelectorFlag(reportComplianceCmd, "Only report on state resources whose labels match the selector (e.g. env=prod)")
+	reportCmd.AddCommand(reportComplianceCmd)
+}
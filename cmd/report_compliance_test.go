@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shapedthought/owlctl/state"
+)
+
+func complianceTestScan() ScanReport {
+	report := ScanReport{
+		Timestamp: time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC),
+		Instances: []ScanInstance{{Kinds: []ScanKind{
+			{Kind: "VBRJob", DisplayName: "Jobs", Resources: []ScanResource{
+				{Name: "Weekly", Origin: "applied"},
+				{Name: "Nightly", Origin: "applied", Drifts: []Drift{
+					{Path: "isDisabled", Action: "modified", State: false, VBR: true, Severity: SeverityCritical},
+					{Path: "description", Action: "modified", State: "a|b", VBR: "c", Severity: SeverityInfo},
+				}},
+			}},
+			{Kind: "VBRMalwareDetection", DisplayName: "Malware detection settings", Skipped: "no snapshot"},
+		}}},
+	}
+	report.finish()
+	return report
+}
+
+func complianceTestState() *state.State {
+	st := state.NewState()
+	applied := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	st.SetResource("default", &state.Resource{
+		Type: "VBRJob", Name: "Nightly", Origin: "applied", LastApplied: applied, LastAppliedBy: "alice",
+		History: []state.ResourceEvent{
+			{Action: "applied", Timestamp: applied, User: "alice", Fields: []string{"isDisabled"}},
+			{Action: "snapshotted", Timestamp: applied.Add(-time.Hour), User: "bob"},
+		},
+	})
+	return st
+}
+
+func TestBuildComplianceReport(t *testing.T) {
+	report := buildComplianceReport(complianceTestScan(), complianceTestState(), nil)
+
+	if report.Status != "FAIL" || report.ExitCode != ExitDriftCritical {
+		t.Errorf("Status = %s (exit %d), want FAIL (exit %d)", report.Status, report.ExitCode, ExitDriftCritical)
+	}
+	want := ComplianceSummary{Resources: 2, Compliant: 1, Drifted: 1, Critical: 1, Info: 1}
+	if report.Summary != want {
+		t.Errorf("Summary = %+v, want %+v", report.Summary, want)
+	}
+
+	var nightly ComplianceResource
+	for _, r := range report.Resources {
+		if r.Name == "Nightly" {
+			nightly = r
+		}
+	}
+	if nightly.Status != "drifted" || nightly.Severity != SeverityCritical || nightly.LastAppliedBy != "alice" {
+		t.Errorf("Unexpected Nightly entry: %+v", nightly)
+	}
+	if len(nightly.History) != 2 || nightly.History[0].Action != "applied" {
+		t.Errorf("History should keep state order (newest first), got %+v", nightly.History)
+	}
+
+	if len(report.Security) != 1 || report.Security[0].Status != "NOT SNAPSHOTTED" {
+		t.Errorf("Unexpected security controls: %+v", report.Security)
+	}
+}
+
+func TestBuildComplianceReport_Trend(t *testing.T) {
+	older := ComplianceReport{
+		GeneratedAt: time.Date(2026, 10, 4, 9, 0, 0, 0, time.UTC),
+		Status:      "PASS",
+	}
+	latest := ComplianceReport{
+		GeneratedAt: time.Date(2026, 10, 11, 9, 0, 0, 0, time.UTC),
+		Status:      "WARN",
+		Resources: []ComplianceResource{
+			{Kind: "VBRJob", Name: "Weekly", Status: "drifted"},
+			{Kind: "VBRJob", Name: "Nightly", Status: "compliant"},
+		},
+	}
+
+	// Passed out of order; the trend is sorted by date
+	report := buildComplianceReport(complianceTestScan(), state.NewState(), []ComplianceReport{latest, older})
+
+	if len(report.Trend) != 3 || report.Trend[0].Status != "PASS" || report.Trend[2].Status != "FAIL" {
+		t.Fatalf("Unexpected trend: %+v", report.Trend)
+	}
+	if report.Changes == nil || !report.Changes.Since.Equal(latest.GeneratedAt) {
+		t.Fatalf("Changes should compare against the latest previous report, got %+v", report.Changes)
+	}
+	if strings.Join(report.Changes.NewDrift, ",") != "VBRJob/Nightly" {
+		t.Errorf("NewDrift = %v", report.Changes.NewDrift)
+	}
+	if strings.Join(report.Changes.Resolved, ",") != "VBRJob/Weekly" {
+		t.Errorf("Resolved = %v", report.Changes.Resolved)
+	}
+}
+
+func TestWriteComplianceReport(t *testing.T) {
+	report := buildComplianceReport(complianceTestScan(), complianceTestState(), nil)
+
+	var md bytes.Buffer
+	if err := writeComplianceReport(&md, "markdown", report); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# owlctl Compliance Report",
+		"| Status | FAIL |",
+		"| VBRJob | Nightly | applied | Drifted (CRITICAL) |",
+		"### VBRJob/Nightly (CRITICAL)",
+		`| description | "a\|b" -> "c" | INFO |`,
+		"by alice (isDisabled)",
+		"| Malware detection settings | NOT SNAPSHOTTED |",
+	} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("Markdown missing %q:\n%s", want, md.String())
+		}
+	}
+
+	var out bytes.Buffer
+	if err := writeComplianceReport(&out, "html", report); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"<h1>owlctl Compliance Report</h1>",
+		`<td class="critical">Drifted (CRITICAL)</td>`,
+		"&#34;a|b&#34;",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("HTML missing %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shapedthought/owlctl/resources"
+)
+
+func testSession(created, end, result string) vbrSession {
+	s := vbrSession{CreationTime: created, EndTime: end}
+	s.Result.Result = result
+	return s
+}
+
+func TestParseRPODuration(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"24h", 24 * time.Hour, false},
+		{"7d", 7 * 24 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"xd", 0, true},
+		{"soon", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseRPODuration(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseRPODuration(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseRPODuration(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestSpecRPO(t *testing.T) {
+	spec := resources.ResourceSpec{
+		Metadata: resources.Metadata{Annotations: map[string]string{rpoAnnotation: "12h"}},
+	}
+	if got, _ := specRPO(spec, 24*time.Hour); got != 12*time.Hour {
+		t.Errorf("Annotation should override default, got %v", got)
+	}
+
+	if got, _ := specRPO(resources.ResourceSpec{}, 24*time.Hour); got != 24*time.Hour {
+		t.Errorf("Missing annotation should use default, got %v", got)
+	}
+
+	spec.Metadata.Annotations[rpoAnnotation] = "often"
+	if _, err := specRPO(spec, 0); err == nil {
+		t.Error("Expected error for invalid annotation")
+	}
+}
+
+func TestRepositoryJobRPOs(t *testing.T) {
+	dir := t.TempDir()
+	job := func(name, rpo string) string {
+		spec := "apiVersion: owlctl.veeam.com/v1\nkind: VBRJob\nmetadata:\n  name: " + name + "\n"
+		if rpo != "" {
+			spec += "  annotations:\n    owlctl.veeam.com/rpo: " + rpo + "\n"
+		}
+		return spec + "spec:\n  description: " + name + "\n"
+	}
+	for name, content := range map[string]string{
+		"grouped.yaml":    job("Grouped", "12h"),
+		"standalone.yaml": job("Standalone", "7d"),
+		"plain.yaml":      job("Plain", ""),
+		"untracked.yaml":  job("Untracked", "1h"),
+		"owlctl.yaml":     "groups:\n  prod:\n    specs:\n      - grouped.yaml\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("OWLCTL_CONFIG", filepath.Join(dir, "owlctl.yaml"))
+	t.Setenv("OWLCTL_ACTIVE_INSTANCE", "")
+	defer resources.SetSpecVariables(nil, false)
+
+	rpos := repositoryJobRPOs(map[string]bool{"Grouped": true, "Standalone": true, "Plain": true, "Missing": true})
+	want := map[string]time.Duration{"Grouped": 12 * time.Hour, "Standalone": 7 * 24 * time.Hour}
+	if len(rpos) != len(want) {
+		t.Fatalf("rpos = %v, want %v", rpos, want)
+	}
+	for name, d := range want {
+		if rpos[name] != d {
+			t.Errorf("rpos[%s] = %v, want %v", name, rpos[name], d)
+		}
+	}
+}
+
+func TestAggregateSessions(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	sessions := []vbrSession{
+		testSession("2026-03-10T02:00:00Z", "2026-03-10T02:30:00Z", "Failed"),
+		testSession("2026-03-09T02:00:00Z", "2026-03-09T02:20:00Z", "Warning"),
+		testSession("2026-03-08T02:00:00Z", "2026-03-08T02:15:00Z", "Success"),
+	}
+
+	row := aggregateSessions(reportTarget{Name: "DB Backup", RPO: 24 * time.Hour}, sessions, now)
+
+	if row.Sessions != 3 || row.Success != 1 || row.Warning != 1 || row.Failed != 1 {
+		t.Errorf("Unexpected counts: %+v", row)
+	}
+	if row.LastResult != "Failed" {
+		t.Errorf("LastResult = %q, want Failed", row.LastResult)
+	}
+	if row.LastSuccess == nil || !row.LastSuccess.Equal(time.Date(2026, 3, 9, 2, 20, 0, 0, time.UTC)) {
+		t.Errorf("LastSuccess = %v, want warning session end time", row.LastSuccess)
+	}
+	if !row.RPOBreach {
+		t.Error("Last success 33h ago should breach a 24h RPO")
+	}
+}
+
+func TestAggregateSessions_NoRPO(t *testing.T) {
+	row := aggregateSessions(reportTarget{Name: "Dev"}, nil, time.Now())
+	if row.RPOBreach {
+		t.Error("Jobs without an RPO target should never breach")
+	}
+	if row.RPO != "" {
+		t.Errorf("RPO = %q, want empty", row.RPO)
+	}
+}
+
+func TestAggregateRestorePoints(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	points := []vbrRestorePoint{
+		{Name: "vm-01", CreationTime: "2026-03-10T02:00:00Z"},
+		{Name: "vm-02", CreationTime: "2026-03-10T02:05:00Z"},
+		{Name: "vm-01", CreationTime: "2026-03-01T02:00:00Z"},
+	}
+
+	row := aggregateRestorePoints(reportTarget{Name: "Web", RPO: 24 * time.Hour}, points, now)
+
+	if row.RestorePoints != 3 || row.Objects != 2 {
+		t.Errorf("Unexpected counts: %+v", row)
+	}
+	if row.RPOBreach {
+		t.Error("Newest restore point within 24h should not breach")
+	}
+	if row.RPO != "1d" {
+		t.Errorf("RPO = %q, want 1d", row.RPO)
+	}
+}
+
+func TestWriteSessionReport_CSV(t *testing.T) {
+	rows := []SessionReportRow{
+		{Job: "Job, with comma", Sessions: 2, Success: 2, RPO: "1d"},
+		{Job: "Missing", Error: "not found in VBR"},
+	}
+
+	var buf bytes.Buffer
+	if err := writeSessionReport(&buf, "csv", rows); err != nil {
+		t.Fatalf("writeSessionReport failed: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.HasPrefix(out, "JOB,SESSIONS,SUCCESS") {
+		t.Errorf("Missing CSV header:\n%s", out)
+	}
+	if !strings.Contains(out, `"Job, with comma",2,2,0,0`) {
+		t.Errorf("Expected quoted job name in CSV:\n%s", out)
+	}
+	if !strings.Contains(out, "ERROR: not found in VBR") {
+		t.Errorf("Expected error status in CSV:\n%s", out)
+	}
+}
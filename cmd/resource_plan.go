@@ -0,0 +1,410 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/shapedthought/owlctl/config"
+	"github.com/shapedthought/owlctl/models"
+	"github.com/shapedthought/owlctl/remediation"
+	"github.com/shapedthought/owlctl/resources"
+	"github.com/shapedthought/owlctl/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	resourcePlanGroup string
+	resourcePlanOut   string
+)
+
+var resourcePlanCmd = &cobra.Command{
+	Use:   "plan [spec-file...]",
+	Short: "Plan changes for any declarative resources, optionally saving the plan",
+	Long: `Plan compares spec files, or every spec in a group, against live VBR and shows
+what apply would change. Every declarative kind is supported: jobs, repositories,
+scale-out repositories, KMS servers and the singleton settings resources.
+
+With --out the plan is saved to a file. "owlctl apply <plan-file>" then sends exactly
+the planned payloads, and refuses to run if any planned resource has changed in VBR
+since the plan was made. Plan files are checksummed; set OWLCTL_PLAN_KEY to sign them
+(apply then requires the same key).
+
+Examples:
+  # Plan individual specs of any kind
+  owlctl plan specs/jobs/sql.yaml specs/repos/default.yaml
+
+  # Plan every spec in a group (profile + spec + overlay chains)
+  owlctl plan --group sql-tier
+
+  # Plan specs by label
+  owlctl plan -l "env=prod"
+
+  # Save the plan, review it in CI, then apply it
+  owlctl plan --group sql-tier --out plan.bin
+  owlctl apply plan.bin
+
+Exit Codes:
+  0 - Plan succeeded
+  1 - One or more specs could not be planned (no plan file is written)
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if (resourcePlanGroup != "" || labelSelector != "") && len(args) > 0 {
+			log.Fatal("Cannot use --group or --selector with spec file arguments")
+		}
+		if resourcePlanGroup == "" && labelSelector == "" && len(args) == 0 {
+			log.Fatal("Provide spec files, use --group, or use --selector")
+		}
+		runResourcePlan(args)
+	},
+}
+
+var applyPlanCmd = &cobra.Command{
+	Use:   "apply <plan-file>",
+	Short: "Apply a plan saved by 'owlctl plan --out'",
+	Long: `Apply executes a saved plan exactly as it was planned.
+
+Before changing anything, every resource in the plan is fetched again from VBR. If any
+has been created, changed or deleted since the plan was made, nothing is applied and
+the plan must be made again. The plan must also target the same VBR server.
+
+Examples:
+  owlctl plan --group sql-tier --out plan.bin
+  owlctl apply plan.bin
+
+Exit Codes:
+  0 - All changes applied
+  1 - Error (invalid or stale plan, or every change failed)
+  5 - Partial apply (some changes failed)
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		applySavedPlan(args[0])
+	},
+}
+
+// declarativeApplyConfig returns the apply configuration for a declarative resource kind,
+// accepting the same kind aliases as the spec diff commands
+func declarativeApplyConfig(kind string) (ResourceApplyConfig, bool) {
+	kind = normalizeSpecKind(kind)
+	switch kind {
+	case resources.KindVBRJob:
+		return jobApplyConfig, true
+	case resources.KindVBRRepository:
+		return repoApplyConfig, true
+	case resources.KindVBRScaleOutRepository:
+		return sobrApplyConfig, true
+	case resources.KindVBRKmsServer:
+		return kmsApplyConfig, true
+	}
+	for _, sc := range singletonResources {
+		if sc.Kind == kind {
+			return singletonApplyConfig(sc), true
+		}
+	}
+	return ResourceApplyConfig{}, false
+}
+
+func runResourcePlan(args []string) {
+	settings := utils.ReadSettings()
+	if settings.SelectedProfile != "vbr" {
+		log.Fatal("This command only works with VBR at the moment.")
+	}
+
+	var targets []mergedGroupSpec
+	profile := utils.GetCurrentProfile()
+
+	if resourcePlanGroup != "" || labelSelector != "" {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			log.Fatalf("Failed to load owlctl.yaml: %v", err)
+		}
+		cfg.WarnDeprecatedFields()
+
+		groupCfg, err := lookupGroup(cfg, resourcePlanGroup)
+		if err != nil {
+			log.Fatalf("Group error: %v", err)
+		}
+		profile = activateGroupInstance(cfg, groupCfg)
+
+		targets, err = loadMergedGroupSpecs(cfg, groupCfg)
+		if err != nil {
+			log.Fatalf("Group error: %v", err)
+		}
+		if len(targets) == 0 {
+			noGroupSpecs(resourcePlanGroup, "resource")
+		}
+
+		fmt.Printf("Planning group: %s (%d specs)\n", groupLabel(resourcePlanGroup), len(targets))
+		if instanceFlag != "" {
+			fmt.Printf("  Instance: %s (from --instance flag)\n", instanceFlag)
+		} else if groupCfg.Instance != "" {
+			fmt.Printf("  Instance: %s\n", groupCfg.Instance)
+		}
+		fmt.Println()
+	} else {
+		for _, path := range args {
+			target := mergedGroupSpec{SpecPath: path}
+			target.Spec, target.Error = resources.LoadResourceSpec(path)
+			targets = append(targets, target)
+		}
+	}
+
+	// Pre-load remediation config once to avoid repeated disk I/O per spec
+	remediationCfg, remediationErr := remediation.LoadConfig()
+	if remediationErr != nil {
+		fmt.Printf("Warning: Failed to load remediation config: %v (using defaults)\n", remediationErr)
+	}
+
+	plan := savedPlan{
+		Version:   planFileVersion,
+		CreatedAt: time.Now().UTC(),
+		CreatedBy: currentUsername(),
+		Server:    os.Getenv("OWLCTL_URL"),
+		Instance:  os.Getenv("OWLCTL_ACTIVE_INSTANCE"),
+	}
+	failed := 0
+
+	for _, target := range targets {
+		if target.Error != nil {
+			fmt.Printf("✗ %s: %v\n", target.SpecPath, target.Error)
+			failed++
+			continue
+		}
+
+		planned, err := planResource(target, profile, remediationCfg)
+		if err != nil {
+			fmt.Printf("✗ %s %q: %v  (%s)\n", target.Spec.Kind, target.Spec.Metadata.Name, err, target.SpecPath)
+			failed++
+			continue
+		}
+		printPlannedChange(planned)
+		plan.Resources = append(plan.Resources, planned)
+	}
+
+	fmt.Println()
+	fmt.Println(planSummary(plan.Resources, failed))
+
+	if failed > 0 {
+		if resourcePlanOut != "" {
+			fmt.Printf("Plan has errors; %s was not written.\n", resourcePlanOut)
+		}
+		os.Exit(ExitError)
+	}
+
+	if resourcePlanOut != "" {
+		if err := writePlanFile(resourcePlanOut, plan, os.Getenv(planKeyEnv)); err != nil {
+			log.Fatalf("Failed to save plan: %v", err)
+		}
+		fmt.Printf("\nPlan saved to %s\n", resourcePlanOut)
+		fmt.Printf("  To apply exactly these changes:\n")
+		fmt.Printf("    owlctl apply %s\n", resourcePlanOut)
+	}
+}
+
+// planResource computes the planned change for one (merged) spec without modifying VBR
+func planResource(target mergedGroupSpec, profile models.Profile, remediationCfg *remediation.Config) (plannedChange, error) {
+	spec := target.Spec
+	spec.Kind = normalizeSpecKind(spec.Kind)
+	cfg, ok := declarativeApplyConfig(spec.Kind)
+	if !ok {
+		return plannedChange{}, fmt.Errorf("kind %s cannot be applied", spec.Kind)
+	}
+
+	// Jobs are sent with name matching metadata.name, as in applyVBRJob
+	if spec.Kind == resources.KindVBRJob && spec.Metadata.Name != "" {
+		withName := make(map[string]interface{}, len(spec.Spec)+1)
+		for k, v := range spec.Spec {
+			withName[k] = v
+		}
+		withName["name"] = spec.Metadata.Name
+		spec.Spec = withName
+	}
+
+	change, err := planResourceChange(spec, cfg, profile, remediationCfg, target.Provenance)
+	if err != nil {
+		return plannedChange{}, err
+	}
+
+	action := change.Action
+	if action == "update" && len(change.Changes) == 0 {
+		action = "no-op"
+	}
+
+	return plannedChange{
+		Kind:       spec.Kind,
+		Name:       spec.Metadata.Name,
+		SpecPath:   target.SpecPath,
+		Action:     action,
+		ResourceID: change.ResourceID,
+		Payload:    change.Payload,
+		Changes:    change.Changes,
+		Skipped:    change.Skipped,
+		LiveHash:   liveResourceHash(change.Live, cfg.IgnoreFields),
+		Spec:       spec,
+	}, nil
+}
+
+// printPlannedChange prints one resource of a plan with its field changes
+func printPlannedChange(p plannedChange) {
+	switch p.Action {
+	case "create":
+		fmt.Printf("+ %s %q will be created  (%s)\n", p.Kind, p.Name, p.SpecPath)
+	case "update":
+		fmt.Printf("~ %s %q will be updated  (%s)\n", p.Kind, p.Name, p.SpecPath)
+	default:
+		fmt.Printf("= %s %q is up to date  (%s)\n", p.Kind, p.Name, p.SpecPath)
+	}
+
+	for _, change := range p.Changes {
+		fmt.Printf("    ~ %s: %s -> %s%s\n", change.Path, applyFormatValue(change.OldValue), applyFormatValue(change.NewValue), changeSourceSuffix(change.Source))
+	}
+	for _, s := range p.Skipped {
+		if s.Reason != "" {
+			fmt.Printf("    skipped %s: %s\n", s.Path, s.Reason)
+		} else {
+			fmt.Printf("    skipped %s\n", s.Path)
+		}
+	}
+}
+
+// planSummary returns the one-line totals for a plan
+func planSummary(planned []plannedChange, failed int) string {
+	var create, update, unchanged int
+	for _, p := range planned {
+		switch p.Action {
+		case "create":
+			create++
+		case "update":
+			update++
+		default:
+			unchanged++
+		}
+	}
+	summary := fmt.Sprintf("Plan: %d to create, %d to update, %d unchanged", create, update, unchanged)
+	if failed > 0 {
+		summary += fmt.Sprintf(", %d failed", failed)
+	}
+	return summary
+}
+
+// applySavedPlan executes a plan file after checking it still matches live VBR state
+func applySavedPlan(path string) {
+	settings := utils.ReadSettings()
+	if settings.SelectedProfile != "vbr" {
+		log.Fatal("This command only works with VBR at the moment.")
+	}
+
+	plan, err := readPlanFile(path, os.Getenv(planKeyEnv))
+	if err != nil {
+		log.Fatalf("Invalid plan: %v", err)
+	}
+
+	// Reconnect to the instance the plan was made against, unless --instance says otherwise
+	if plan.Instance != "" && instanceFlag == "" && os.Getenv("OWLCTL_ACTIVE_INSTANCE") != plan.Instance {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			log.Fatalf("Failed to load owlctl.yaml: %v", err)
+		}
+		resolved, err := config.ResolveInstance(cfg, plan.Instance)
+		if err != nil {
+			log.Fatalf("Failed to resolve plan instance %q: %v", plan.Instance, err)
+		}
+		if err := config.ActivateInstance(resolved); err != nil {
+			log.Fatalf("Failed to activate plan instance %q: %v", plan.Instance, err)
+		}
+	}
+	if server := os.Getenv("OWLCTL_URL"); server != plan.Server {
+		log.Fatalf("Plan was made against %q, but the active connection is %q", plan.Server, server)
+	}
+	profile := utils.GetCurrentProfile()
+
+	fmt.Printf("Applying plan: %s (%d resources, planned %s by %s)\n", path, len(plan.Resources), plan.CreatedAt.Local().Format(time.RFC3339), plan.CreatedBy)
+
+	if stale := stalePlanResources(plan, profile, declarativeApplyConfig); len(stale) > 0 {
+		fmt.Println("\nLive VBR state no longer matches the plan:")
+		for _, s := range stale {
+			fmt.Printf("  - %s\n", s)
+		}
+		fmt.Println("\nNothing was applied. Run 'owlctl plan' again and review the new plan.")
+		ciExit(ExitError)
+	}
+
+	var results []ApplyResult
+	var created, updated, unchanged int
+	for _, r := range plan.Resources {
+		result := ApplyResult{ResourceName: r.Name, ResourceID: r.ResourceID, Changes: r.Changes, Skipped: r.Skipped}
+		if r.Action == "no-op" {
+			unchanged++
+			results = append(results, result)
+			continue
+		}
+
+		cfg, ok := declarativeApplyConfig(r.Kind)
+		if !ok {
+			// stalePlanResources rejects unknown kinds before anything is applied
+			log.Fatalf("%s %q: kind is not supported by this version of owlctl", r.Kind, r.Name)
+		}
+		fmt.Printf("\n%s %q (%s)\n", r.Kind, r.Name, r.SpecPath)
+		if r.Action == "update" {
+			printApplyChanges(r.Changes, r.Name, true)
+			printSkippedFields(r.Skipped)
+		} else {
+			fmt.Printf("Creating new %s: %s\n", r.Kind, r.Name)
+		}
+
+		id, err := executeResourceChange(resourceChange{Name: r.Name, Action: r.Action, ResourceID: r.ResourceID, Payload: r.Payload}, cfg, profile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s %q: %v\n", r.Kind, r.Name, err)
+			result.Error = err
+			reportApply(r.Kind, result)
+			results = append(results, result)
+			continue
+		}
+		result.ResourceID = id
+
+		action := "applied"
+		result.Action = "updated"
+		if r.Action == "create" {
+			action = "created"
+			result.Action = "created"
+			created++
+			fmt.Printf("Created %s with ID: %s\n", r.Kind, id)
+		} else {
+			updated++
+		}
+		reportApply(r.Kind, result)
+		if err := updateResourceStateWithAction(r.Spec, id, r.Kind, extractFieldNames(r.Changes), action); err != nil {
+			fmt.Printf("Warning: Failed to update state: %v\n", err)
+		}
+		results = append(results, result)
+	}
+
+	failed := len(results) - created - updated - unchanged
+	fmt.Printf("\nApply complete: %d created, %d updated, %d unchanged, %d failed\n", created, updated, unchanged, failed)
+
+	if len(results) == 0 {
+		return
+	}
+	ciExit(ExitCodeForOutcome(DetermineApplyOutcome(results)))
+}
+
+// currentUsername returns the OS user for plan and state records
+func currentUsername() string {
+	if usr, err := user.Current(); err == nil {
+		return usr.Username
+	}
+	return "unknown"
+}
+
+func init() {
+	resourcePlanCmd.Flags().StringVar(&resourcePlanGroup, "group", "", "Plan every spec in named group (from owlctl.yaml)")
+	resourcePlanCmd.Flags().StringVar(&resourcePlanOut, "out", "", "Save the plan to a file for 'owlctl apply <file>'")
+	addSelectorFlag(resourcePlanCmd, "Plan specs whose labels match a selector; with --group, filters the group (e.g. env=prod)")
+	addApplyWaitFlags(applyPlanCmd)
+
+	rootCmd.AddCommand(resourcePlanCmd)
+	rootCmd.AddCommand(applyPlanCmd)
+}
@@ -23,67 +23,82 @@ var rootCmd = &cobra.Command{
 	Short: "A CLI application for Veeam APIs",
 	Long:  `A CLI application that works with all Veeam APIs`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		// --instance: load config, resolve, and activate the instance
-		effectiveInstance := instanceFlag
-		if effectiveInstance == "" {
-			settings, err := utils.TryReadSettings()
-			if err != nil {
-				return fmt.Errorf("failed to read settings.json: %w (fix or remove the file, then retry)", err)
-			}
-			effectiveInstance = settings.DefaultInstance
+		if err := configureCIOutput(cmd); err != nil {
+			return err
 		}
-
-		if instanceFlag != "" && targetFlag != "" {
-			return fmt.Errorf("cannot use --instance and --target together")
+		if err := activateConnection(); err != nil {
+			return err
 		}
-		if effectiveInstance != "" && targetFlag != "" {
-			return fmt.Errorf("cannot use --target when a default instance is set — run 'owlctl instance unset' or use --instance explicitly")
+		return configureSpecVariables(nil, nil)
+	},
+	// Commands that fail or find drift exit through ciExit; this completes the rest
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		ciOutput.complete(ExitSuccess)
+	},
+}
+
+// activateConnection applies --instance (or the default instance) or the deprecated --target
+func activateConnection() error {
+	// --instance: load config, resolve, and activate the instance
+	effectiveInstance := instanceFlag
+	if effectiveInstance == "" {
+		settings, err := utils.TryReadSettings()
+		if err != nil {
+			return fmt.Errorf("failed to read settings.json: %w (fix or remove the file, then retry)", err)
 		}
+		effectiveInstance = settings.DefaultInstance
+	}
 
-		if effectiveInstance != "" {
-			cfg, err := config.LoadConfig()
-			if err != nil {
-				return fmt.Errorf("failed to load owlctl.yaml: %w", err)
-			}
+	if instanceFlag != "" && targetFlag != "" {
+		return fmt.Errorf("cannot use --instance and --target together")
+	}
+	if effectiveInstance != "" && targetFlag != "" {
+		return fmt.Errorf("cannot use --target when a default instance is set — run 'owlctl instance unset' or use --instance explicitly")
+	}
 
-			resolved, err := config.ResolveInstance(cfg, effectiveInstance)
-			if err != nil {
-				if instanceFlag == "" {
-					// came from DefaultInstance — give a helpful hint
-					return fmt.Errorf("default instance %q not found in owlctl.yaml — run 'owlctl instance unset' to clear", effectiveInstance)
-				}
-				return fmt.Errorf("--instance %q: %w", effectiveInstance, err)
-			}
+	if effectiveInstance != "" {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load owlctl.yaml: %w", err)
+		}
 
-			if err := config.ActivateInstance(resolved); err != nil {
-				return fmt.Errorf("failed to activate instance %q: %w", effectiveInstance, err)
+		resolved, err := config.ResolveInstance(cfg, effectiveInstance)
+		if err != nil {
+			if instanceFlag == "" {
+				// came from DefaultInstance — give a helpful hint
+				return fmt.Errorf("default instance %q not found in owlctl.yaml — run 'owlctl instance unset' to clear", effectiveInstance)
 			}
-			return nil
+			return fmt.Errorf("--instance %q: %w", effectiveInstance, err)
 		}
 
-		// --target (deprecated): only sets OWLCTL_URL
-		if targetFlag != "" {
-			fmt.Fprintln(os.Stderr, "Warning: --target is deprecated. Use --instance instead.")
-			fmt.Fprintln(os.Stderr, "         --instance supports product type, credentials, and per-instance token caching.")
+		if err := config.ActivateInstance(resolved); err != nil {
+			return fmt.Errorf("failed to activate instance %q: %w", effectiveInstance, err)
+		}
+		return nil
+	}
 
-			cfg, err := config.LoadConfig()
-			if err != nil {
-				return fmt.Errorf("failed to load owlctl.yaml: %w", err)
-			}
+	// --target (deprecated): only sets OWLCTL_URL
+	if targetFlag != "" {
+		fmt.Fprintln(os.Stderr, "Warning: --target is deprecated. Use --instance instead.")
+		fmt.Fprintln(os.Stderr, "         --instance supports product type, credentials, and per-instance token caching.")
 
-			target, err := cfg.GetTarget(targetFlag)
-			if err != nil {
-				return fmt.Errorf("--target %q: %w", targetFlag, err)
-			}
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load owlctl.yaml: %w", err)
+		}
 
-			if err := os.Setenv("OWLCTL_URL", target.URL); err != nil {
-				return fmt.Errorf("failed to set OWLCTL_URL: %w", err)
-			}
-			return nil
+		target, err := cfg.GetTarget(targetFlag)
+		if err != nil {
+			return fmt.Errorf("--target %q: %w", targetFlag, err)
 		}
 
+		if err := os.Setenv("OWLCTL_URL", target.URL); err != nil {
+			return fmt.Errorf("failed to set OWLCTL_URL: %w", err)
+		}
 		return nil
-	},
+	}
+
+	return nil
 }
 
 func Execute() {
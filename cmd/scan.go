@@ -0,0 +1,757 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/shapedthought/owlctl/config"
+	"github.com/shapedthought/owlctl/models"
+	"github.com/shapedthought/owlctl/state"
+	"github.com/shapedthought/owlctl/utils"
+	"github.com/shapedthought/owlctl/vhttp"
+	"github.com/spf13/cobra"
+)
+
+var (
+	scanAllInstances     bool
+	scanFormat           string
+	scanCorrelationRules string
+	scanNoCorrelation    bool
+)
+
+var scanCmd = &cobra.Command{
+	Use:   "scan",
+	Short: "Detect drift across every resource kind",
+	Long: `Runs drift detection for every resource kind in state and combines the results
+into one report with per-kind and overall severity.
+
+Kinds scanned: jobs, repositories, scale-out repositories, encryption passwords,
+KMS servers, the singleton settings resources (configuration backup, email,
+traffic rules, general options) and the security components. Singletons and
+security components without a snapshot are skipped.
+
+After the scan, correlation rules look for drift that matches together across
+resources (for example immutability reduced and retention cut) and raise a
+combined incident. Built-in rules cover common ransomware-preparation patterns;
+add or override rules in correlation-rules.yaml or with --correlation-rules.
+
+By default the active instance is scanned. Use --all-instances to scan every VBR
+instance in owlctl.yaml; each instance uses its own state.
+
+With driftHistory enabled in owlctl.yaml (or --record), the result is appended to
+the local drift history queried by 'owlctl drift history'.
+
+--remediate re-applies the stored state spec for drifted fields of resources
+with origin "applied" (resources created or updated by owlctl apply). Observed
+resources are never changed. It requires an explicit list of kinds and a severity
+threshold; fields skipped by remediation-config.yaml or known to be immutable are
+left as they are. Each remediated resource gets a "remediated" history event.
+Use --dry-run to list what would be re-applied. The exit code reflects the drift
+found by the scan, or 1 if a remediation failed.
+
+Examples:
+  owlctl scan
+  owlctl scan --security-only
+  owlctl scan --all-instances --format json > scan.json
+  owlctl scan --record
+  owlctl scan --remediate --remediate-kinds VBRJob --remediate-severity critical --dry-run
+
+Exit codes:
+  0 = No drift
+  3 = Drift detected (INFO or WARNING)
+  4 = Critical drift detected
+  1 = Error (an instance or kind could not be scanned, or a remediation failed)
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runScan()
+	},
+}
+
+func init() {
+	scanCmd.Flags().BoolVar(&scanAllInstances, "all-instances", false, "Scan every VBR instance in owlctl.yaml")
+	scanCmd.Flags().StringVar(&scanFormat, "format", "table", "Output format: table or json")
+	scanCmd.Flags().StringVar(&scanCorrelationRules, "correlation-rules", "", "Additional correlation rules file (YAML)")
+	scanCmd.Flags().BoolVar(&scanNoCorrelation, "no-correlation", false, "Skip correlation rules")
+	scanCmd.Flags().BoolVar(&scanRemediate, "remediate", false, "Re-apply the state spec for drifted fields of applied resources (requires --remediate-kinds and --remediate-severity)")
+	scanCmd.Flags().StringSliceVar(&scanRemediateKinds, "remediate-kinds", nil, "Kinds --remediate may change (e.g. VBRJob,VBRRepository)")
+	scanCmd.Flags().StringVar(&scanRemediateSeverity, "remediate-severity", "", "Only remediate drift at or above this severity: critical, warning, or info")
+	scanCmd.Flags().BoolVar(&scanDryRun, "dry-run", false, "With --remediate, show what would be re-applied without changing VBR")
+	scanCmd.Flags().BoolVar(&scanRecord, "record", false, "Record the scan in the drift history (always on when driftHistory is enabled in owlctl.yaml)")
+	addSeverityFlags(scanCmd)
+	addSelectorFlag(scanCmd, "Only scan state resources whose labels match the selector (e.g. env=prod)")
+	rootCmd.AddCommand(scanCmd)
+}
+
+// ScanReport is the combined result of a scan across one or more instances
+type ScanReport struct {
+	Timestamp   time.Time      `json:"timestamp"`
+	MinSeverity Severity       `json:"minSeverity"`
+	MaxSeverity Severity       `json:"maxSeverity,omitempty"`
+	ExitCode    int            `json:"exitCode"`
+	Instances   []ScanInstance `json:"instances"`
+}
+
+// ScanInstance holds the per-kind results for one instance. Name is empty when the scan
+// used the active connection without a named instance.
+type ScanInstance struct {
+	Name        string     `json:"name,omitempty"`
+	MaxSeverity Severity   `json:"maxSeverity,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	Skipped     string     `json:"skipped,omitempty"`
+	Kinds       []ScanKind `json:"kinds"`
+	Incidents   []Incident `json:"incidents,omitempty"`
+
+	Remediations []ScanRemediation `json:"remediations,omitempty"`
+	Acks         []AckStatus       `json:"acks,omitempty"`
+}
+
+// ScanKind holds the results for one resource kind
+type ScanKind struct {
+	Kind        string         `json:"kind"`
+	DisplayName string         `json:"displayName"`
+	MaxSeverity Severity       `json:"maxSeverity,omitempty"`
+	Skipped     string         `json:"skipped,omitempty"`
+	Error       string         `json:"error,omitempty"`
+	Resources   []ScanResource `json:"resources"`
+}
+
+// ScanResource is one checked resource and the drift found on it (empty when clean)
+type ScanResource struct {
+	Name   string  `json:"name"`
+	ID     string  `json:"id,omitempty"`
+	Origin string  `json:"origin,omitempty"`
+	Drifts []Drift `json:"drifts,omitempty"`
+
+	// RepositoryID is a job's backup repository in state. Correlation rules with
+	// sameRepository use it to link job drift to drift on that repository.
+	RepositoryID string `json:"repositoryId,omitempty"`
+
+	// Acknowledged is drift suppressed by an active acknowledgement. It is not counted as
+	// drift and does not affect severity or the exit code.
+	Acknowledged []Drift `json:"acknowledged,omitempty"`
+
+	// Error is set when the resource could not be checked; the rest of its kind still is
+	Error string `json:"error,omitempty"`
+}
+
+// scanKindConfig defines how one resource kind is scanned
+type scanKindConfig struct {
+	Kind        string
+	DisplayName string
+
+	// Scan returns the checked resources. A non-empty skip reason means the kind was not
+	// checked (e.g. no snapshot) and is not an error.
+	Scan func(stateMgr *state.Manager, profile models.Profile) (resources []ScanResource, skip string, err error)
+}
+
+// scanKinds returns the resource kinds checked by owlctl scan, in report order
+func scanKinds() []scanKindConfig {
+	kinds := []scanKindConfig{
+		{
+			Kind:        "VBRJob",
+			DisplayName: "Jobs",
+			Scan: func(stateMgr *state.Manager, profile models.Profile) ([]ScanResource, string, error) {
+				return scanStateResources(stateMgr, profile, "VBRJob", "jobs", fetchLiveObject, func(r *state.Resource, live map[string]interface{}) []Drift {
+					return detectJobDrift(r.Spec, live)
+				})
+			},
+		},
+		{
+			Kind:        "VBRRepository",
+			DisplayName: "Repositories",
+			Scan: func(stateMgr *state.Manager, profile models.Profile) ([]ScanResource, string, error) {
+				return scanStateResources(stateMgr, profile, "VBRRepository", "backupInfrastructure/repositories", fetchLiveObject, func(r *state.Resource, live map[string]interface{}) []Drift {
+					return classifyDrifts(detectDrift(r.Spec, live, repoIgnoreFields), repoSeverityMap)
+				})
+			},
+		},
+		{
+			Kind:        "VBRScaleOutRepository",
+			DisplayName: "Scale-out repositories",
+			Scan: func(stateMgr *state.Manager, profile models.Profile) ([]ScanResource, string, error) {
+				return scanStateResources(stateMgr, profile, "VBRScaleOutRepository", "backupInfrastructure/scaleOutRepositories", fetchLiveObject, func(r *state.Resource, live map[string]interface{}) []Drift {
+					return classifyDrifts(detectDrift(r.Spec, live, sobrIgnoreFields), sobrSeverityMap)
+				})
+			},
+		},
+		{
+			Kind:        "VBREncryptionPassword",
+			DisplayName: "Encryption passwords",
+			Scan: func(stateMgr *state.Manager, profile models.Profile) ([]ScanResource, string, error) {
+				return scanInventory(stateMgr, profile, "VBREncryptionPassword", fetchEncryptionPasswordInventory, encryptionIgnoreFields, encryptionSeverityMap)
+			},
+		},
+		{
+			Kind:        "VBRKmsServer",
+			DisplayName: "KMS servers",
+			Scan: func(stateMgr *state.Manager, profile models.Profile) ([]ScanResource, string, error) {
+				return scanInventory(stateMgr, profile, "VBRKmsServer", fetchKmsServerInventory, kmsIgnoreFields, kmsSeverityMap)
+			},
+		},
+	}
+
+	singletons := append(append([]SingletonResourceConfig{}, singletonResources...), securityResources...)
+	for _, sc := range singletons {
+		sc := sc
+		kinds = append(kinds, scanKindConfig{
+			Kind:        sc.Kind,
+			DisplayName: sc.DisplayName,
+			Scan: func(stateMgr *state.Manager, profile models.Profile) ([]ScanResource, string, error) {
+				return scanSingleton(stateMgr, profile, sc)
+			},
+		})
+	}
+	return kinds
+}
+
+func runScan() {
+	if scanFormat != "table" && scanFormat != "json" {
+		log.Fatalf("Invalid --format: %s (use table or json)", scanFormat)
+	}
+	loadSeverityOverrides()
+	minSev := parseSeverityFlag()
+	opts, err := parseRemediationFlags()
+	if err != nil {
+		log.Fatal(err)
+	}
+	scanRemediation = opts
+
+	var rules []CorrelationRule
+	if !scanNoCorrelation {
+		rules, err = loadCorrelationRules(scanCorrelationRules)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	report := collectScan(minSev, rules)
+
+	if scanFormat == "json" {
+		out, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			log.Fatalf("Failed to marshal scan report: %v", err)
+		}
+		fmt.Println(string(out))
+	} else {
+		printScanReport(os.Stdout, report)
+	}
+
+	ciOutput.scanReport(report)
+	ciExit(report.ExitCode)
+}
+
+// collectScan scans the active instance, or every instance with --all-instances, and
+// returns the finished report
+func collectScan(minSev Severity, rules []CorrelationRule) ScanReport {
+	report := ScanReport{Timestamp: time.Now().UTC(), MinSeverity: minSev}
+
+	if scanAllInstances {
+		if instanceFlag != "" {
+			log.Fatal("Cannot use --all-instances with --instance")
+		}
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			log.Fatalf("Failed to load owlctl.yaml: %v", err)
+		}
+		names := cfg.ListInstances()
+		if len(names) == 0 {
+			log.Fatal("No instances defined in owlctl.yaml")
+		}
+		// Each instance starts from the environment the process started with, not the
+		// previous instance's credentials, port and insecure setting
+		base := config.StartupInstanceEnv()
+		for _, name := range names {
+			report.Instances = append(report.Instances, scanNamedInstance(cfg, name, base, minSev, rules))
+		}
+	} else {
+		settings := utils.ReadSettings()
+		if settings.SelectedProfile != "vbr" {
+			log.Fatal("This command only works with VBR at the moment.")
+		}
+		inst := scanInstance(utils.GetCurrentProfile(), minSev, rules)
+		inst.Name = os.Getenv("OWLCTL_ACTIVE_INSTANCE")
+		report.Instances = append(report.Instances, inst)
+	}
+
+	report.finish()
+	emitScanEvents(report)
+	recordScan(report)
+	return report
+}
+
+// scanNamedInstance activates an instance from owlctl.yaml and scans it. Non-VBR instances
+// are skipped.
+func scanNamedInstance(cfg *config.VCLIConfig, name string, base config.InstanceEnv, minSev Severity, rules []CorrelationRule) ScanInstance {
+	skip, err := activateScanInstance(cfg, name, base)
+	switch {
+	case err != nil:
+		return ScanInstance{Name: name, Error: err.Error()}
+	case skip != "":
+		return ScanInstance{Name: name, Skipped: skip}
+	}
+
+	result := scanInstance(utils.GetCurrentProfile(), minSev, rules)
+	result.Name = name
+	return result
+}
+
+// activateScanInstance restores base and then activates a named instance, returning why
+// the instance is skipped if it cannot be scanned
+func activateScanInstance(cfg *config.VCLIConfig, name string, base config.InstanceEnv) (string, error) {
+	inst, err := cfg.GetInstance(name)
+	if err != nil {
+		return "", err
+	}
+	if inst.Product != "vbr" {
+		return fmt.Sprintf("product %q is not supported", inst.Product), nil
+	}
+
+	base.Restore()
+	resolved, err := config.ResolveInstance(cfg, name)
+	if err != nil {
+		return "", err
+	}
+	return "", config.ActivateInstance(resolved)
+}
+
+// scanInstance runs every kind against the active connection and its state, applies the
+// severity rules and sets acknowledged drift aside, then runs the correlation rules.
+// Correlation sees all unacknowledged drift; the severity filter applies afterwards.
+func scanInstance(profile models.Profile, minSev Severity, rules []CorrelationRule) ScanInstance {
+	stateMgr := state.NewManager()
+	var inst ScanInstance
+
+	for _, kc := range scanKinds() {
+		kind := ScanKind{Kind: kc.Kind, DisplayName: kc.DisplayName}
+		found, skip, err := kc.Scan(stateMgr, profile)
+		switch {
+		case err != nil:
+			kind.Error = err.Error()
+		case skip != "":
+			kind.Skipped = skip
+		}
+		kind.Resources = found
+		inst.Kinds = append(inst.Kinds, kind)
+	}
+	return finishScanInstance(stateMgr, profile, inst, minSev, rules)
+}
+
+// finishScanInstance applies severity rules and acknowledgements to scanned kinds,
+// correlates and remediates their drift, and then drops drift below minSev from the
+// report. Remediation sees the unfiltered drift, so fields hidden by --severity keep
+// their live values.
+func finishScanInstance(stateMgr *state.Manager, profile models.Profile, inst ScanInstance, minSev Severity, rules []CorrelationRule) ScanInstance {
+	applyScanSeverityRules(stateMgr, inst.Kinds)
+	acks, err := stateMgr.ListAcks()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not load drift acknowledgements: %v\n", err)
+	}
+	inst.Acks = applyScanAcks(acks, inst.Kinds, time.Now().UTC())
+
+	for _, incident := range correlateDrifts(rules, inst.Kinds) {
+		if severityRank(incident.Severity) >= severityRank(minSev) {
+			inst.Incidents = append(inst.Incidents, incident)
+		}
+	}
+	if scanRemediation != nil {
+		inst.Remediations = remediateKinds(stateMgr, profile, inst.Kinds, scanRemediation)
+	}
+	for i := range inst.Kinds {
+		for j := range inst.Kinds[i].Resources {
+			res := &inst.Kinds[i].Resources[j]
+			res.Drifts = filterDriftsBySeverity(res.Drifts, minSev)
+			res.Acknowledged = filterDriftsBySeverity(res.Acknowledged, minSev)
+		}
+	}
+	return inst
+}
+
+// scanStateResources checks each state resource of a kind against its live object at
+// endpoint/{id}. A resource deleted from VBR is CRITICAL drift, as in scanInventory; a
+// resource that cannot be fetched is an error on that resource only.
+func scanStateResources(stateMgr *state.Manager, profile models.Profile, kind, endpoint string, fetch func(string, models.Profile) (map[string]interface{}, error), detect func(r *state.Resource, live map[string]interface{}) []Drift) ([]ScanResource, string, error) {
+	stateResources, err := listSelectedResources(stateMgr, kind)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load state: %w", err)
+	}
+	if len(stateResources) == 0 {
+		return nil, "none in state", nil
+	}
+
+	var results []ScanResource
+	for _, r := range stateResources {
+		result := ScanResource{Name: r.Name, ID: r.ID, Origin: r.Origin, RepositoryID: extractNestedString(r.Spec, "storage", "backupRepositoryId")}
+		live, err := fetch(fmt.Sprintf("%s/%s", endpoint, r.ID), profile)
+		switch {
+		case errors.Is(err, errLiveNotFound):
+			result.Drifts = []Drift{{Path: "inventory", Action: "removed", State: r.Name, Severity: SeverityCritical}}
+		case err != nil:
+			result.Error = err.Error()
+		default:
+			result.Drifts = detect(r, live)
+		}
+		results = append(results, result)
+	}
+	return results, "", nil
+}
+
+// inventoryItem is a live resource from a list endpoint, keyed by ID
+type inventoryItem struct {
+	Name string
+	Spec map[string]interface{}
+}
+
+// scanInventory compares a kind whose live objects are fetched as one list. Resources
+// removed from VBR are CRITICAL and resources added since the snapshot are INFO, matching
+// encryption diff --all and kms-diff --all.
+func scanInventory(stateMgr *state.Manager, profile models.Profile, kind string, fetch func(models.Profile) (map[string]inventoryItem, error), ignore map[string]bool, sm SeverityMap) ([]ScanResource, string, error) {
+	stateResources, err := listSelectedResources(stateMgr, kind)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load state: %w", err)
+	}
+	if len(stateResources) == 0 {
+		return nil, "none in state", nil
+	}
+
+	live, err := fetch(profile)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var results []ScanResource
+	inState := make(map[string]bool)
+	for _, r := range stateResources {
+		inState[r.ID] = true
+		result := ScanResource{Name: r.Name, ID: r.ID, Origin: r.Origin}
+		if item, ok := live[r.ID]; ok {
+			result.Drifts = classifyDrifts(detectDrift(r.Spec, item.Spec, ignore), sm)
+		} else {
+			result.Drifts = []Drift{{Path: "inventory", Action: "removed", State: r.Name, Severity: SeverityCritical}}
+		}
+		results = append(results, result)
+	}
+
+	var added []string
+	for id := range live {
+		if !inState[id] {
+			added = append(added, id)
+		}
+	}
+	sort.Strings(added)
+	for _, id := range added {
+		results = append(results, ScanResource{
+			Name:   live[id].Name,
+			ID:     id,
+			Drifts: []Drift{{Path: "inventory", Action: "added", VBR: live[id].Name, Severity: SeverityInfo}},
+		})
+	}
+	return results, "", nil
+}
+
+// scanSingleton compares a singleton's snapshot with its live value. Singletons that were
+// never snapshotted are skipped.
+func scanSingleton(stateMgr *state.Manager, profile models.Profile, sc SingletonResourceConfig) ([]ScanResource, string, error) {
+	stateEntry, err := stateMgr.GetResource(sc.StateKey)
+	if err != nil {
+		return nil, "no snapshot", nil
+	}
+	if stateEntry.Type != sc.Kind {
+		return nil, "", fmt.Errorf("state entry '%s' is not a %s resource (type: %s)", sc.StateKey, sc.Kind, stateEntry.Type)
+	}
+
+	var live map[string]interface{}
+	if sc.Fetch != nil {
+		live, err = sc.Fetch(profile)
+	} else {
+		live, err = fetchLiveObject(sc.Endpoint, profile)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	return []ScanResource{{
+		Name:   sc.StateKey,
+		Origin: stateEntry.Origin,
+		Drifts: detectSingletonDrift(sc, stateEntry.Spec, live),
+	}}, "", nil
+}
+
+// fetchLiveObject GETs a single object, returning errors instead of exiting so one failing
+// instance does not end a multi-instance scan
+func fetchLiveObject(endpoint string, profile models.Profile) (map[string]interface{}, error) {
+	body, err := fetchLive(endpoint, profile)
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return nil, fmt.Errorf("failed to parse %s response: %w", endpoint, err)
+	}
+	return obj, nil
+}
+
+// errLiveNotFound is returned by fetchLive when VBR has no object at the endpoint
+var errLiveNotFound = errors.New("not found in VBR")
+
+func fetchLive(endpoint string, profile models.Profile) ([]byte, error) {
+	res, err := vhttp.Send("GET", endpoint, nil, profile)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", errLiveNotFound, endpoint)
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return nil, res.Error()
+	}
+	return res.Body, nil
+}
+
+// fetchEncryptionPasswordInventory lists encryption passwords through the model type so the
+// fields match the snapshot format
+func fetchEncryptionPasswordInventory(profile models.Profile) (map[string]inventoryItem, error) {
+	body, err := fetchLive("encryptionPasswords", profile)
+	if err != nil {
+		return nil, err
+	}
+	var list models.VbrEncryptionPasswordList
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse encryption passwords: %w", err)
+	}
+	items := make(map[string]inventoryItem)
+	for _, p := range list.Data {
+		spec, err := toSpecMap(p)
+		if err != nil {
+			return nil, fmt.Errorf("encryption password '%s': %w", p.Hint, err)
+		}
+		items[p.ID] = inventoryItem{Name: p.Hint, Spec: spec}
+	}
+	return items, nil
+}
+
+// fetchKmsServerInventory lists KMS servers through the model type so the fields match the
+// snapshot format
+func fetchKmsServerInventory(profile models.Profile) (map[string]inventoryItem, error) {
+	body, err := fetchLive("kmsServers", profile)
+	if err != nil {
+		return nil, err
+	}
+	var list models.VbrKmsServerList
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse KMS servers: %w", err)
+	}
+	items := make(map[string]inventoryItem)
+	for _, k := range list.Data {
+		spec, err := toSpecMap(k)
+		if err != nil {
+			return nil, fmt.Errorf("KMS server '%s': %w", k.Name, err)
+		}
+		items[k.ID] = inventoryItem{Name: k.Name, Spec: spec}
+	}
+	return items, nil
+}
+
+// toSpecMap round-trips a model through JSON into a generic map
+func toSpecMap(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// drifts returns every drift in the kind
+func (k ScanKind) drifts() []Drift {
+	var all []Drift
+	for _, r := range k.Resources {
+		all = append(all, r.Drifts...)
+	}
+	return all
+}
+
+// erroredCount returns the number of resources that could not be checked
+func (k ScanKind) erroredCount() int {
+	n := 0
+	for _, r := range k.Resources {
+		if r.Error != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// driftedCount returns the number of resources with drift
+func (k ScanKind) driftedCount() int {
+	n := 0
+	for _, r := range k.Resources {
+		if len(r.Drifts) > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// finish computes the per-kind, per-instance and overall severities and the exit code.
+// Incidents count as drift at their severity. Errors, including failed remediations, take
+// precedence over drift.
+func (r *ScanReport) finish() {
+	var all []Drift
+	failed := false
+	for i := range r.Instances {
+		inst := &r.Instances[i]
+		if inst.Error != "" {
+			failed = true
+		}
+		var instDrifts []Drift
+		for j := range inst.Kinds {
+			kind := &inst.Kinds[j]
+			if kind.Error != "" || kind.erroredCount() > 0 {
+				failed = true
+			}
+			if drifts := kind.drifts(); len(drifts) > 0 {
+				kind.MaxSeverity = getMaxSeverity(drifts)
+				instDrifts = append(instDrifts, drifts...)
+			}
+		}
+		for _, incident := range inst.Incidents {
+			instDrifts = append(instDrifts, Drift{Path: incident.Rule, Severity: incident.Severity})
+		}
+		for _, rem := range inst.Remediations {
+			if rem.Action == "failed" {
+				failed = true
+			}
+		}
+		if len(instDrifts) > 0 {
+			inst.MaxSeverity = getMaxSeverity(instDrifts)
+			all = append(all, instDrifts...)
+		}
+	}
+
+	if len(all) > 0 {
+		r.MaxSeverity = getMaxSeverity(all)
+	}
+	r.ExitCode = exitCodeForDrifts(all)
+	if failed {
+		r.ExitCode = ExitError
+	}
+}
+
+// printScanReport prints a per-kind table for each instance, then the drift details and
+// an overall summary
+func printScanReport(w io.Writer, r ScanReport) {
+	clean, drifted, driftCount, incidents := 0, 0, 0, 0
+	var all []Drift
+
+	for i, inst := range r.Instances {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		if inst.Name != "" {
+			fmt.Fprintf(w, "Instance: %s\n", inst.Name)
+		}
+		if inst.Error != "" {
+			fmt.Fprintf(w, "  Error: %s\n", inst.Error)
+			continue
+		}
+		if inst.Skipped != "" {
+			fmt.Fprintf(w, "  Skipped: %s\n", inst.Skipped)
+			continue
+		}
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "KIND\tCHECKED\tDRIFTED\tSEVERITY")
+		for _, kind := range inst.Kinds {
+			status := "-"
+			switch {
+			case kind.Error != "", kind.MaxSeverity == "" && kind.erroredCount() > 0:
+				status = "ERROR"
+			case kind.MaxSeverity != "":
+				status = string(kind.MaxSeverity)
+			case kind.Skipped != "":
+				status = "skipped (" + kind.Skipped + ")"
+			}
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%s\n", kind.DisplayName, len(kind.Resources), kind.driftedCount(), status)
+		}
+		tw.Flush()
+
+		for _, kind := range inst.Kinds {
+			if kind.Error != "" {
+				fmt.Fprintf(w, "\n%s: Error: %s\n", kind.DisplayName, kind.Error)
+			}
+			for _, res := range kind.Resources {
+				if res.Error != "" {
+					fmt.Fprintf(w, "\n%s / %s: Error: %s\n", kind.DisplayName, res.Name, res.Error)
+					continue
+				}
+				if len(res.Drifts) == 0 {
+					clean++
+					continue
+				}
+				drifted++
+				driftCount += len(res.Drifts)
+				all = append(all, res.Drifts...)
+
+				originLabel := ""
+				if res.Origin == "observed" {
+					originLabel = " (observed)"
+				}
+				fmt.Fprintf(w, "\n%s %s / %s%s: %d drifts detected\n", getMaxSeverity(res.Drifts), kind.DisplayName, res.Name, originLabel, len(res.Drifts))
+				for _, d := range res.Drifts {
+					fmt.Fprintln(w, formatDriftLine(d))
+				}
+			}
+		}
+
+		for _, incident := range inst.Incidents {
+			incidents++
+			printIncident(w, incident)
+		}
+		printScanAcks(w, inst)
+		printRemediations(w, inst.Remediations)
+	}
+
+	fmt.Fprintf(w, "\nSummary:\n")
+	if len(r.Instances) > 1 {
+		fmt.Fprintf(w, "  - %d instances scanned\n", len(r.Instances))
+	}
+	fmt.Fprintf(w, "  - %d resources clean, %d drifted (%d drifts)\n", clean, drifted, driftCount)
+	if incidents > 0 {
+		fmt.Fprintf(w, "  - %d incidents raised by correlation rules\n", incidents)
+	}
+	if len(all) > 0 {
+		fmt.Fprintf(w, "  - Highest severity: %s\n", r.MaxSeverity)
+	} else if r.MinSeverity != SeverityInfo {
+		fmt.Fprintf(w, "  - No %s or higher drift detected (lower severity drifts may exist)\n", r.MinSeverity)
+	} else {
+		fmt.Fprintf(w, "  - No drift detected\n")
+	}
+	if r.ExitCode == ExitError {
+		fmt.Fprintf(w, "  - Some kinds or resources could not be scanned; see errors above\n")
+	}
+}
+
+// printIncident prints a correlation incident with its contributing drifts
+func printIncident(w io.Writer, incident Incident) {
+	fmt.Fprintf(w, "\n%s INCIDENT %s", incident.Severity, incident.Rule)
+	if incident.Description != "" {
+		fmt.Fprintf(w, ": %s", incident.Description)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "  Resources: %s\n", strings.Join(incident.Resources, ", "))
+	for _, d := range incident.Drifts {
+		fmt.Fprintf(w, "%s [%s/%s]\n", formatDriftLine(d.Drift), d.Kind, d.Resource)
+	}
+}
@@ -0,0 +1,311 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/shapedthought/owlctl/models"
+	"github.com/shapedthought/owlctl/remediation"
+	"github.com/shapedthought/owlctl/resources"
+	"github.com/shapedthought/owlctl/state"
+)
+
+var (
+	scanRemediate         bool
+	scanRemediateKinds    []string
+	scanRemediateSeverity string
+	scanDryRun            bool
+)
+
+// scanRemediation is set when scan runs with --remediate
+var scanRemediation *remediationOptions
+
+// remediationOptions controls which drift scan --remediate re-applies
+type remediationOptions struct {
+	Kinds       map[string]bool
+	MinSeverity Severity
+	DryRun      bool
+	Config      *remediation.Config
+	// ConfigFor looks up the apply configuration for a kind
+	ConfigFor func(kind string) (ResourceApplyConfig, bool)
+}
+
+// ScanRemediation is the outcome of remediating one drifted resource
+type ScanRemediation struct {
+	Kind     string         `json:"kind"`
+	Resource string         `json:"resource"`
+	Action   string         `json:"action"` // "remediated", "would-remediate", "skipped", "failed"
+	Fields   []string       `json:"fields,omitempty"`
+	Skipped  []SkippedField `json:"skipped,omitempty"`
+	Reason   string         `json:"reason,omitempty"`
+	Error    string         `json:"error,omitempty"`
+}
+
+// remediableKinds returns the kinds scan --remediate can re-apply: those with a declarative
+// apply configuration
+func remediableKinds() []string {
+	kinds := []string{
+		resources.KindVBRJob,
+		resources.KindVBRRepository,
+		resources.KindVBRScaleOutRepository,
+		resources.KindVBRKmsServer,
+	}
+	for _, sc := range singletonResources {
+		kinds = append(kinds, sc.Kind)
+	}
+	return kinds
+}
+
+// parseRemediationFlags validates the --remediate flags. Remediation changes VBR, so both
+// the kinds and the severity threshold must be given explicitly.
+func parseRemediationFlags() (*remediationOptions, error) {
+	if !scanRemediate {
+		if len(scanRemediateKinds) > 0 || scanRemediateSeverity != "" || scanDryRun {
+			return nil, fmt.Errorf("--remediate-kinds, --remediate-severity and --dry-run require --remediate")
+		}
+		return nil, nil
+	}
+	if len(scanRemediateKinds) == 0 {
+		return nil, fmt.Errorf("--remediate requires --remediate-kinds (one or more of: %s)", strings.Join(remediableKinds(), ", "))
+	}
+	if scanRemediateSeverity == "" {
+		return nil, fmt.Errorf("--remediate requires --remediate-severity (critical, warning, or info)")
+	}
+
+	opts := &remediationOptions{Kinds: make(map[string]bool), DryRun: scanDryRun, ConfigFor: declarativeApplyConfig}
+	switch strings.ToLower(scanRemediateSeverity) {
+	case "critical":
+		opts.MinSeverity = SeverityCritical
+	case "warning":
+		opts.MinSeverity = SeverityWarning
+	case "info":
+		opts.MinSeverity = SeverityInfo
+	default:
+		return nil, fmt.Errorf("invalid --remediate-severity: %s (use critical, warning, or info)", scanRemediateSeverity)
+	}
+
+	for _, k := range scanRemediateKinds {
+		kind := ""
+		for _, rk := range remediableKinds() {
+			if strings.EqualFold(k, rk) {
+				kind = rk
+			}
+		}
+		if kind == "" {
+			return nil, fmt.Errorf("kind %q cannot be remediated (use: %s)", k, strings.Join(remediableKinds(), ", "))
+		}
+		opts.Kinds[kind] = true
+	}
+
+	cfg, err := remediation.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load remediation config: %w", err)
+	}
+	opts.Config = cfg
+	return opts, nil
+}
+
+// remediateKinds re-applies drifted fields on the allowed kinds of one instance
+func remediateKinds(stateMgr *state.Manager, profile models.Profile, kinds []ScanKind, opts *remediationOptions) []ScanRemediation {
+	var results []ScanRemediation
+	for _, kind := range kinds {
+		if !opts.Kinds[kind.Kind] {
+			continue
+		}
+		for _, res := range kind.Resources {
+			if rem, ok := remediateResource(stateMgr, profile, kind.Kind, res, opts); ok {
+				results = append(results, rem)
+			}
+		}
+	}
+	return results
+}
+
+// remediateResource re-applies the stored state values of a resource's drifted fields at or
+// above the threshold. It returns false when the resource has no such drift.
+//
+// The payload is the state spec with every other drifted field, including acknowledged
+// drift, set back to its live value, so the apply changes only the selected fields. It goes through the same planning as
+// apply, so FieldPolicy and KnownImmutableFields still decide which fields are sent.
+// res must hold all of the resource's drift, not only the drift shown at --severity.
+func remediateResource(stateMgr *state.Manager, profile models.Profile, kind string, res ScanResource, opts *remediationOptions) (ScanRemediation, bool) {
+	rem := ScanRemediation{Kind: kind, Resource: res.Name}
+
+	var selected, others []Drift
+	for _, d := range res.Drifts {
+		if severityRank(d.Severity) >= severityRank(opts.MinSeverity) {
+			selected = append(selected, d)
+		} else {
+			others = append(others, d)
+		}
+	}
+	if len(selected) == 0 {
+		return rem, false
+	}
+	// Acknowledged drift is accepted as it is in VBR
+	others = append(others, res.Acknowledged...)
+
+	if res.Origin != "applied" {
+		rem.Action = "skipped"
+		rem.Reason = fmt.Sprintf("origin is %q; only applied resources are remediated", valueOrDash(res.Origin))
+		return rem, true
+	}
+
+	stateEntry, err := stateMgr.GetResource(res.Name)
+	if err != nil {
+		return remediationFailed(rem, err, opts), true
+	}
+	spec, err := toSpecMap(stateEntry.Spec)
+	if err != nil {
+		return remediationFailed(rem, err, opts), true
+	}
+
+	wanted := 0
+	for _, d := range selected {
+		switch {
+		case d.Path == "inventory":
+			rem.Action = "skipped"
+			rem.Reason = fmt.Sprintf("resource %s in VBR", d.Action)
+			return rem, true
+		case d.Action == "added":
+			rem.Skipped = append(rem.Skipped, SkippedField{Path: d.Path, Reason: "Field added in VBR; apply cannot remove fields"})
+		default:
+			wanted++
+		}
+	}
+	for _, d := range others {
+		switch d.Action {
+		case "modified":
+			setSpecPath(spec, d.Path, d.VBR)
+		case "removed":
+			deleteSpecPath(spec, d.Path)
+		}
+	}
+	if wanted == 0 {
+		rem.Action = "skipped"
+		rem.Reason = "no drifted field can be re-applied"
+		return rem, true
+	}
+
+	cfg, ok := opts.ConfigFor(kind)
+	if !ok {
+		rem.Action = "skipped"
+		rem.Reason = "kind cannot be applied"
+		return rem, true
+	}
+	resourceSpec := resources.ResourceSpec{
+		APIVersion: resources.APIVersion,
+		Kind:       kind,
+		Metadata:   resources.Metadata{Name: res.Name, Labels: stateEntry.Labels},
+		Spec:       spec,
+	}
+	change, err := planResourceChange(resourceSpec, cfg, profile, opts.Config, nil)
+	if err != nil {
+		return remediationFailed(rem, err, opts), true
+	}
+	if change.Action == "create" {
+		rem.Action = "skipped"
+		rem.Reason = "resource not found in VBR; remediation does not create resources"
+		return rem, true
+	}
+
+	rem.Fields = extractFieldNames(change.Changes)
+	rem.Skipped = append(rem.Skipped, change.Skipped...)
+	if len(rem.Fields) == 0 {
+		rem.Action = "skipped"
+		rem.Reason = "all drifted fields are skipped by remediation policy"
+		return rem, true
+	}
+
+	result := ApplyResult{ResourceName: res.Name, ResourceID: change.ResourceID, Changes: change.Changes, Skipped: rem.Skipped, DryRun: opts.DryRun}
+	if opts.DryRun {
+		rem.Action = "would-remediate"
+		reportApply(kind, result)
+		return rem, true
+	}
+
+	if _, err := executeResourceChange(change, cfg, profile); err != nil {
+		return remediationFailed(rem, err, opts), true
+	}
+	rem.Action = "remediated"
+	result.Action = "remediated"
+	reportApply(kind, result)
+
+	if err := recordRemediation(stateMgr, stateEntry, rem.Fields); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %s/%s remediated but state not updated: %v\n", kind, res.Name, err)
+	}
+	return rem, true
+}
+
+func remediationFailed(rem ScanRemediation, err error, opts *remediationOptions) ScanRemediation {
+	rem.Action = "failed"
+	rem.Error = err.Error()
+	reportApply(rem.Kind, ApplyResult{ResourceName: rem.Resource, Error: err, DryRun: opts.DryRun})
+	return rem
+}
+
+// recordRemediation adds a "remediated" event to the resource's history. The spec is kept:
+// remediation restored VBR to it.
+func recordRemediation(stateMgr *state.Manager, r *state.Resource, fields []string) error {
+	user := currentUsername()
+	r.LastApplied = time.Now()
+	r.LastAppliedBy = user
+	r.AddEvent(state.NewEventWithFields("remediated", user, fields, false))
+	return stateMgr.UpdateResource(r)
+}
+
+// setSpecPath sets a dotted field path in a spec, creating intermediate maps
+func setSpecPath(spec map[string]interface{}, path string, value interface{}) {
+	parts := strings.Split(path, ".")
+	m := spec
+	for _, p := range parts[:len(parts)-1] {
+		next, ok := m[p].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			m[p] = next
+		}
+		m = next
+	}
+	m[parts[len(parts)-1]] = value
+}
+
+// deleteSpecPath removes a dotted field path from a spec
+func deleteSpecPath(spec map[string]interface{}, path string) {
+	parts := strings.Split(path, ".")
+	m := spec
+	for _, p := range parts[:len(parts)-1] {
+		next, ok := m[p].(map[string]interface{})
+		if !ok {
+			return
+		}
+		m = next
+	}
+	delete(m, parts[len(parts)-1])
+}
+
+// printRemediations prints the remediation outcome for an instance
+func printRemediations(w io.Writer, rems []ScanRemediation) {
+	if len(rems) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nRemediation:\n")
+	for _, r := range rems {
+		name := r.Kind + "/" + r.Resource
+		switch r.Action {
+		case "remediated":
+			fmt.Fprintf(w, "  ✓ %s: re-applied %s\n", name, strings.Join(r.Fields, ", "))
+		case "would-remediate":
+			fmt.Fprintf(w, "  ~ %s: would re-apply %s (dry run)\n", name, strings.Join(r.Fields, ", "))
+		case "failed":
+			fmt.Fprintf(w, "  ✗ %s: %s\n", name, r.Error)
+		default:
+			fmt.Fprintf(w, "  - %s: skipped (%s)\n", name, r.Reason)
+		}
+		for _, s := range r.Skipped {
+			fmt.Fprintf(w, "      skipped %s: %s\n", s.Path, s.Reason)
+		}
+	}
+}
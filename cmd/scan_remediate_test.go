@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shapedthought/owlctl/models"
+	"github.com/shapedthought/owlctl/remediation"
+	"github.com/shapedthought/owlctl/state"
+)
+
+func TestParseRemediationFlags(t *testing.T) {
+	defer func() {
+		scanRemediate, scanRemediateKinds, scanRemediateSeverity, scanDryRun = false, nil, "", false
+	}()
+	t.Setenv("OWLCTL_SETTINGS_PATH", t.TempDir())
+
+	tests := []struct {
+		name      string
+		remediate bool
+		kinds     []string
+		severity  string
+		dryRun    bool
+		wantErr   string
+	}{
+		{name: "off", remediate: false},
+		{name: "flags without remediate", kinds: []string{"VBRJob"}, wantErr: "require --remediate"},
+		{name: "no kinds", remediate: true, severity: "critical", wantErr: "--remediate-kinds"},
+		{name: "no severity", remediate: true, kinds: []string{"VBRJob"}, wantErr: "--remediate-severity"},
+		{name: "bad severity", remediate: true, kinds: []string{"VBRJob"}, severity: "high", wantErr: "invalid --remediate-severity"},
+		{name: "unsupported kind", remediate: true, kinds: []string{"VBREncryptionPassword"}, severity: "critical", wantErr: "cannot be remediated"},
+		{name: "valid", remediate: true, kinds: []string{"vbrjob", "VBREmailSettings"}, severity: "Warning", dryRun: true},
+	}
+	for _, tt := range tests {
+		scanRemediate, scanRemediateKinds, scanRemediateSeverity, scanDryRun = tt.remediate, tt.kinds, tt.severity, tt.dryRun
+		opts, err := parseRemediationFlags()
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: error = %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if tt.name == "off" && opts != nil {
+			t.Errorf("%s: expected no options", tt.name)
+		}
+		if tt.name == "valid" {
+			want := map[string]bool{"VBRJob": true, "VBREmailSettings": true}
+			if !reflect.DeepEqual(opts.Kinds, want) || opts.MinSeverity != SeverityWarning || !opts.DryRun || opts.Config == nil {
+				t.Errorf("Unexpected options: %+v", opts)
+			}
+		}
+	}
+}
+
+func TestRemediateResource(t *testing.T) {
+	t.Setenv("OWLCTL_SETTINGS_PATH", t.TempDir())
+	t.Setenv("OWLCTL_ACTIVE_INSTANCE", "")
+	noValidate = true
+	defer func() { noValidate = false }()
+
+	stateMgr := state.NewManager()
+	if err := stateMgr.UpdateResource(&state.Resource{
+		Type: "VBRJob", Name: "Nightly", ID: "1", Origin: "applied", LastApplied: time.Now(),
+		Spec: map[string]interface{}{
+			"isDisabled":  false,
+			"description": "managed",
+			"storage":     map[string]interface{}{"retentionPolicy": map[string]interface{}{"quantity": 14.0}},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	live := `{"id":"1","name":"Nightly","isDisabled":true,"description":"edited","storage":{"retentionPolicy":{"quantity":7}},"schedule":{"daily":true}}`
+	configFor := func(kind string) (ResourceApplyConfig, bool) {
+		return ResourceApplyConfig{
+			Kind:         "VBRJob",
+			IgnoreFields: map[string]bool{"id": true},
+			FetchCurrent: func(name string, profile models.Profile) (json.RawMessage, string, error) {
+				return json.RawMessage(live), "1", nil
+			},
+		}, kind == "VBRJob"
+	}
+	res := ScanResource{Name: "Nightly", Origin: "applied", Drifts: []Drift{
+		{Path: "isDisabled", Action: "modified", State: false, VBR: true, Severity: SeverityCritical},
+		{Path: "storage.retentionPolicy.quantity", Action: "modified", State: 14.0, VBR: 7.0, Severity: SeverityWarning},
+		{Path: "description", Action: "modified", State: "managed", VBR: "edited", Severity: SeverityInfo},
+		{Path: "schedule", Action: "added", VBR: map[string]interface{}{"daily": true}, Severity: SeverityWarning},
+	}}
+	opts := &remediationOptions{
+		Kinds:       map[string]bool{"VBRJob": true},
+		MinSeverity: SeverityWarning,
+		DryRun:      true,
+		Config:      &remediation.Config{Job: map[string]remediation.FieldPolicy{}},
+		ConfigFor:   configFor,
+	}
+
+	rem, ok := remediateResource(stateMgr, models.Profile{}, "VBRJob", res, opts)
+	if !ok || rem.Action != "would-remediate" {
+		t.Fatalf("Unexpected remediation: %+v", rem)
+	}
+	if want := []string{"isDisabled", "storage.retentionPolicy.quantity"}; !reflect.DeepEqual(rem.Fields, want) {
+		t.Errorf("Fields = %v, want %v (INFO drift below the threshold must not be re-applied)", rem.Fields, want)
+	}
+	if len(rem.Skipped) != 1 || rem.Skipped[0].Path != "schedule" {
+		t.Errorf("Fields added in VBR should be skipped: %+v", rem.Skipped)
+	}
+
+	// Acknowledged drift is kept at its live value
+	acked := res
+	acked.Drifts, acked.Acknowledged = res.Drifts[1:], res.Drifts[:1]
+	rem, _ = remediateResource(stateMgr, models.Profile{}, "VBRJob", acked, opts)
+	if want := []string{"storage.retentionPolicy.quantity"}; !reflect.DeepEqual(rem.Fields, want) {
+		t.Errorf("Fields = %v, want %v (acknowledged drift must not be re-applied)", rem.Fields, want)
+	}
+
+	// FieldPolicy skip leaves only the fields the policy allows
+	opts.Config.Job["isDisabled"] = remediation.PolicySkip
+	opts.MinSeverity = SeverityCritical
+	rem, _ = remediateResource(stateMgr, models.Profile{}, "VBRJob", res, opts)
+	if rem.Action != "skipped" || len(rem.Skipped) != 1 || rem.Skipped[0].Path != "isDisabled" {
+		t.Errorf("Policy-skipped drift should not be remediated: %+v", rem)
+	}
+
+	res.Origin = "observed"
+	if rem, _ := remediateResource(stateMgr, models.Profile{}, "VBRJob", res, opts); rem.Action != "skipped" || !strings.Contains(rem.Reason, "observed") {
+		t.Errorf("Observed resources must not be remediated: %+v", rem)
+	}
+
+	res.Drifts = res.Drifts[2:3]
+	if _, ok := remediateResource(stateMgr, models.Profile{}, "VBRJob", res, opts); ok {
+		t.Error("Resources without drift at the threshold should not be listed")
+	}
+}
+
+func TestRecordRemediation(t *testing.T) {
+	t.Setenv("OWLCTL_SETTINGS_PATH", t.TempDir())
+	t.Setenv("OWLCTL_ACTIVE_INSTANCE", "")
+
+	stateMgr := state.NewManager()
+	r := &state.Resource{Type: "VBRJob", Name: "Nightly", Origin: "applied", Spec: map[string]interface{}{"isDisabled": false}}
+	r.AddEvent(state.NewEvent("applied", "alice"))
+	if err := stateMgr.UpdateResource(r); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := recordRemediation(stateMgr, r, []string{"isDisabled"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := stateMgr.GetResource("Nightly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.History) != 2 || got.History[0].Action != "remediated" || got.History[0].Fields[0] != "isDisabled" {
+		t.Errorf("Expected a remediated event first, got %+v", got.History)
+	}
+	if got.Spec["isDisabled"] != false {
+		t.Errorf("Remediation must keep the state spec, got %+v", got.Spec)
+	}
+}
+
+func TestSetAndDeleteSpecPath(t *testing.T) {
+	spec := map[string]interface{}{"storage": map[string]interface{}{"retention": 14.0}}
+	setSpecPath(spec, "storage.retention", 7.0)
+	setSpecPath(spec, "schedule.daily.enabled", true)
+	deleteSpecPath(spec, "storage.retention")
+	deleteSpecPath(spec, "missing.path")
+
+	want := map[string]interface{}{
+		"storage":  map[string]interface{}{},
+		"schedule": map[string]interface{}{"daily": map[string]interface{}{"enabled": true}},
+	}
+	if !reflect.DeepEqual(spec, want) {
+		t.Errorf("spec = %v, want %v", spec, want)
+	}
+}
+
+func TestFinishScanInstance_RemediationKeepsFilteredDrift(t *testing.T) {
+	t.Setenv("OWLCTL_SETTINGS_PATH", t.TempDir())
+	t.Setenv("OWLCTL_ACTIVE_INSTANCE", "")
+	noValidate = true
+	defer func() { noValidate, scanRemediation = false, nil }()
+
+	stateMgr := state.NewManager()
+	if err := stateMgr.UpdateResource(&state.Resource{
+		Type: "VBRJob", Name: "Nightly", ID: "1", Origin: "applied", LastApplied: time.Now(),
+		Spec: map[string]interface{}{"isDisabled": false, "description": "managed"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	live := `{"id":"1","name":"Nightly","isDisabled":true,"description":"edited"}`
+	scanRemediation = &remediationOptions{
+		Kinds:       map[string]bool{"VBRJob": true},
+		MinSeverity: SeverityCritical,
+		DryRun:      true,
+		Config:      &remediation.Config{Job: map[string]remediation.FieldPolicy{}},
+		ConfigFor: func(kind string) (ResourceApplyConfig, bool) {
+			return ResourceApplyConfig{
+				Kind:         "VBRJob",
+				IgnoreFields: map[string]bool{"id": true},
+				FetchCurrent: func(name string, profile models.Profile) (json.RawMessage, string, error) {
+					return json.RawMessage(live), "1", nil
+				},
+			}, true
+		},
+	}
+
+	// --severity warning hides the INFO description drift from the report
+	inst := ScanInstance{Kinds: []ScanKind{{Kind: "VBRJob", Resources: []ScanResource{{
+		Name: "Nightly", ID: "1", Origin: "applied", Drifts: []Drift{
+			{Path: "isDisabled", Action: "modified", State: false, VBR: true, Severity: SeverityCritical},
+			{Path: "description", Action: "modified", State: "managed", VBR: "edited", Severity: SeverityInfo},
+		},
+	}}}}}
+	inst = finishScanInstance(stateMgr, models.Profile{}, inst, SeverityWarning, nil)
+
+	if got := inst.Kinds[0].Resources[0].Drifts; len(got) != 1 || got[0].Path != "isDisabled" {
+		t.Errorf("Reported drifts = %+v, want only isDisabled", got)
+	}
+	if len(inst.Remediations) != 1 {
+		t.Fatalf("Remediations = %+v, want one", inst.Remediations)
+	}
+	if want := []string{"isDisabled"}; !reflect.DeepEqual(inst.Remediations[0].Fields, want) {
+		t.Errorf("Fields = %v, want %v (filtered INFO drift must keep its live value)", inst.Remediations[0].Fields, want)
+	}
+}
@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shapedthought/owlctl/config"
+	"github.com/shapedthought/owlctl/models"
+	"github.com/shapedthought/owlctl/state"
+	"github.com/shapedthought/owlctl/utils"
+)
+
+func TestScanInventory(t *testing.T) {
+	cleanup := setupStateWithRepos(t, map[string]*state.Resource{
+		"kms-a": {Type: "VBRKmsServer", ID: "a", Name: "kms-a", Origin: "observed", Spec: map[string]interface{}{"description": "old"}},
+		"kms-b": {Type: "VBRKmsServer", ID: "b", Name: "kms-b", Origin: "observed", Spec: map[string]interface{}{"description": "same"}},
+	})
+	defer cleanup()
+
+	fetch := func(models.Profile) (map[string]inventoryItem, error) {
+		return map[string]inventoryItem{
+			"a": {Name: "kms-a", Spec: map[string]interface{}{"description": "new"}},
+			"c": {Name: "kms-c", Spec: map[string]interface{}{}},
+		}, nil
+	}
+
+	results, skip, err := scanInventory(state.NewManager(), models.Profile{}, "VBRKmsServer", fetch, nil, SeverityMap{"description": SeverityWarning})
+	if err != nil || skip != "" {
+		t.Fatalf("scanInventory: skip=%q err=%v", skip, err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 resources, got %d: %+v", len(results), results)
+	}
+
+	byName := make(map[string]ScanResource)
+	for _, r := range results {
+		byName[r.Name] = r
+	}
+	if d := byName["kms-a"].Drifts; len(d) != 1 || d[0].Severity != SeverityWarning {
+		t.Errorf("kms-a: expected one WARNING drift, got %+v", d)
+	}
+	if d := byName["kms-b"].Drifts; len(d) != 1 || d[0].Action != "removed" || d[0].Severity != SeverityCritical {
+		t.Errorf("kms-b: expected CRITICAL removal, got %+v", d)
+	}
+	if d := byName["kms-c"].Drifts; len(d) != 1 || d[0].Action != "added" || d[0].Severity != SeverityInfo {
+		t.Errorf("kms-c: expected INFO addition, got %+v", d)
+	}
+}
+
+func TestScanStateResources_ContinuesAfterFailures(t *testing.T) {
+	cleanup := setupStateWithRepos(t, map[string]*state.Resource{
+		"deleted":     {Type: "VBRJob", ID: "1", Name: "deleted", Origin: "applied", Spec: map[string]interface{}{"description": "a"}},
+		"unreachable": {Type: "VBRJob", ID: "2", Name: "unreachable", Origin: "applied", Spec: map[string]interface{}{"description": "b"}},
+		"drifted":     {Type: "VBRJob", ID: "3", Name: "drifted", Origin: "applied", Spec: map[string]interface{}{"description": "c"}},
+	})
+	defer cleanup()
+
+	fetch := func(endpoint string, profile models.Profile) (map[string]interface{}, error) {
+		switch endpoint {
+		case "jobs/1":
+			return nil, fmt.Errorf("%w: %s", errLiveNotFound, endpoint)
+		case "jobs/2":
+			return nil, errors.New("HTTP 500")
+		}
+		return map[string]interface{}{"description": "changed"}, nil
+	}
+	detect := func(r *state.Resource, live map[string]interface{}) []Drift {
+		return classifyDrifts(detectDrift(r.Spec, live, nil), nil)
+	}
+
+	results, skip, err := scanStateResources(state.NewManager(), models.Profile{}, "VBRJob", "jobs", fetch, detect)
+	if err != nil || skip != "" {
+		t.Fatalf("scanStateResources: skip=%q err=%v", skip, err)
+	}
+	byName := make(map[string]ScanResource)
+	for _, r := range results {
+		byName[r.Name] = r
+	}
+	if len(byName) != 3 {
+		t.Fatalf("Expected all 3 jobs to be checked, got %+v", results)
+	}
+	if d := byName["deleted"].Drifts; len(d) != 1 || d[0].Path != "inventory" || d[0].Action != "removed" || d[0].Severity != SeverityCritical {
+		t.Errorf("deleted: expected CRITICAL inventory removal, got %+v", d)
+	}
+	if r := byName["unreachable"]; r.Error == "" || len(r.Drifts) != 0 {
+		t.Errorf("unreachable: expected an error on the resource, got %+v", r)
+	}
+	if d := byName["drifted"].Drifts; len(d) != 1 || d[0].Path != "description" {
+		t.Errorf("drifted: expected description drift, got %+v", d)
+	}
+
+	report := ScanReport{Instances: []ScanInstance{{Kinds: []ScanKind{{Kind: "VBRJob", DisplayName: "Jobs", Resources: results}}}}}
+	report.finish()
+	if report.ExitCode != ExitError || report.Instances[0].Kinds[0].Error != "" {
+		t.Errorf("ExitCode = %d, kind error = %q; want exit 1 without failing the kind", report.ExitCode, report.Instances[0].Kinds[0].Error)
+	}
+	var out bytes.Buffer
+	printScanReport(&out, report)
+	if !strings.Contains(out.String(), "Jobs / unreachable: Error: HTTP 500") {
+		t.Errorf("Resource error not printed:\n%s", out.String())
+	}
+}
+
+func TestScanInventory_NoneInState(t *testing.T) {
+	cleanup := setupStateWithRepos(t, map[string]*state.Resource{})
+	defer cleanup()
+
+	fetch := func(models.Profile) (map[string]inventoryItem, error) {
+		t.Fatal("fetch should not be called when state is empty")
+		return nil, nil
+	}
+	_, skip, err := scanInventory(state.NewManager(), models.Profile{}, "VBRKmsServer", fetch, nil, nil)
+	if err != nil || skip == "" {
+		t.Errorf("Expected a skip reason, got skip=%q err=%v", skip, err)
+	}
+}
+
+func TestScanReportFinish(t *testing.T) {
+	tests := []struct {
+		name     string
+		report   ScanReport
+		wantCode int
+		wantMax  Severity
+	}{
+		{
+			name: "clean",
+			report: ScanReport{Instances: []ScanInstance{{Kinds: []ScanKind{
+				{Kind: "VBRJob", Resources: []ScanResource{{Name: "job"}}},
+			}}}},
+			wantCode: ExitSuccess,
+		},
+		{
+			name: "warning",
+			report: ScanReport{Instances: []ScanInstance{{Kinds: []ScanKind{
+				{Kind: "VBRJob", Resources: []ScanResource{{Name: "job", Drifts: []Drift{{Severity: SeverityWarning}}}}},
+			}}}},
+			wantCode: ExitDriftWarning,
+			wantMax:  SeverityWarning,
+		},
+		{
+			name: "critical on second instance",
+			report: ScanReport{Instances: []ScanInstance{
+				{Name: "a", Kinds: []ScanKind{{Kind: "VBRJob", Resources: []ScanResource{{Name: "job", Drifts: []Drift{{Severity: SeverityInfo}}}}}}},
+				{Name: "b", Kinds: []ScanKind{{Kind: "VBRRepository", Resources: []ScanResource{{Name: "repo", Drifts: []Drift{{Severity: SeverityCritical}}}}}}},
+			}},
+			wantCode: ExitDriftCritical,
+			wantMax:  SeverityCritical,
+		},
+		{
+			name: "kind error wins over drift",
+			report: ScanReport{Instances: []ScanInstance{{Kinds: []ScanKind{
+				{Kind: "VBRJob", Resources: []ScanResource{{Name: "job", Drifts: []Drift{{Severity: SeverityCritical}}}}},
+				{Kind: "VBRKmsServer", Error: "HTTP 500"},
+			}}}},
+			wantCode: ExitError,
+			wantMax:  SeverityCritical,
+		},
+		{
+			name:     "instance error",
+			report:   ScanReport{Instances: []ScanInstance{{Name: "a", Error: "OWLCTL_A_USERNAME is not set"}}},
+			wantCode: ExitError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.report.finish()
+			if tt.report.ExitCode != tt.wantCode {
+				t.Errorf("ExitCode = %d, want %d", tt.report.ExitCode, tt.wantCode)
+			}
+			if tt.report.MaxSeverity != tt.wantMax {
+				t.Errorf("MaxSeverity = %q, want %q", tt.report.MaxSeverity, tt.wantMax)
+			}
+		})
+	}
+}
+
+func TestPrintScanReport(t *testing.T) {
+	report := ScanReport{MinSeverity: SeverityInfo, Instances: []ScanInstance{{Name: "prod", Kinds: []ScanKind{
+		{Kind: "VBRJob", DisplayName: "Jobs", Resources: []ScanResource{
+			{Name: "Clean Job"},
+			{Name: "Nightly", Origin: "applied", Drifts: []Drift{{Path: "isDisabled", Action: "modified", State: false, VBR: true, Severity: SeverityCritical}}},
+		}},
+		{Kind: "VBRConfigurationBackup", DisplayName: "Configuration backup settings", Skipped: "no snapshot"},
+	}}}}
+	report.finish()
+
+	var buf bytes.Buffer
+	printScanReport(&buf, report)
+	out := buf.String()
+
+	for _, want := range []string{
+		"Instance: prod",
+		"Jobs",
+		"skipped (no snapshot)",
+		"CRITICAL Jobs / Nightly: 1 drifts detected",
+		"CRITICAL ~ isDisabled: false (state) -> true (VBR)",
+		"1 resources clean, 1 drifted (1 drifts)",
+		"Highest severity: CRITICAL",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestActivateScanInstance_NoCarryOver(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("OWLCTL_SETTINGS_PATH", dir)
+	profiles := `{"version": "1.0", "currentProfile": "vbr", "profiles": {"vbr": {"product": "vbr", "port": 9419}}}`
+	if err := os.WriteFile(filepath.Join(dir, "profiles.json"), []byte(profiles), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("OWLCTL_USERNAME", "shared-user")
+	t.Setenv("OWLCTL_PASSWORD", "shared-pass")
+	t.Setenv("OWLCTL_PROD_USERNAME", "prod-user")
+	t.Setenv("OWLCTL_PROD_PASSWORD", "prod-pass")
+	t.Setenv("OWLCTL_URL", "")
+	t.Setenv("OWLCTL_KEYCHAIN_KEY", "")
+	t.Setenv("OWLCTL_ACTIVE_INSTANCE", "")
+	t.Setenv("OWLCTL_ACTIVE_PRODUCT", "")
+
+	insecure := true
+	cfg := &config.VCLIConfig{Instances: map[string]config.InstanceConfig{
+		"prod": {Product: "vbr", URL: "https://prod", CredentialRef: "PROD", Port: 9500, Insecure: &insecure},
+		"dr":   {Product: "vbr", URL: "https://dr"},
+	}}
+	base := config.SaveInstanceEnv()
+	defer base.Restore()
+
+	if skip, err := activateScanInstance(cfg, "prod", base); skip != "" || err != nil {
+		t.Fatalf("activate prod: skip=%q err=%v", skip, err)
+	}
+	if got := os.Getenv("OWLCTL_USERNAME"); got != "prod-user" {
+		t.Fatalf("prod username = %q, want prod-user", got)
+	}
+
+	if skip, err := activateScanInstance(cfg, "dr", base); skip != "" || err != nil {
+		t.Fatalf("activate dr: skip=%q err=%v", skip, err)
+	}
+	if got := os.Getenv("OWLCTL_USERNAME"); got != "shared-user" {
+		t.Errorf("dr username = %q, want shared-user", got)
+	}
+	if got := os.Getenv("OWLCTL_PASSWORD"); got != "shared-pass" {
+		t.Errorf("dr password = %q, want shared-pass", got)
+	}
+	if got := utils.GetCurrentProfile().Port; got != 9419 {
+		t.Errorf("dr port = %d, want the profile default 9419", got)
+	}
+	if utils.ReadSettings().ApiNotSecure {
+		t.Error("dr inherited insecure from prod")
+	}
+	if got := os.Getenv("OWLCTL_ACTIVE_INSTANCE"); got != "dr" {
+		t.Errorf("active instance = %q, want dr", got)
+	}
+}
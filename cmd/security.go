@@ -0,0 +1,431 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/shapedthought/owlctl/models"
+	"github.com/shapedthought/owlctl/resources"
+	"github.com/shapedthought/owlctl/state"
+	"github.com/shapedthought/owlctl/utils"
+	"github.com/shapedthought/owlctl/vhttp"
+	"github.com/spf13/cobra"
+)
+
+var securityComponent string
+
+// privilegedRoles are VBR roles whose assignment is treated as a CRITICAL security event
+var privilegedRoles = []string{"backup administrator", "security administrator"}
+
+// malwareDetectionResource tracks malware detection (signature, encryption and file index) settings
+var malwareDetectionResource = SingletonResourceConfig{
+	Kind:          resources.KindVBRMalwareDetection,
+	StateKey:      "SecurityMalwareDetection",
+	Endpoint:      "malwareDetection/settings",
+	CommandName:   "malware-detection",
+	DisplayName:   "Malware detection settings",
+	IgnoreFields:  securityIgnoreFields,
+	SeverityMap:   malwareDetectionSeverityMap,
+	EnhanceDrifts: enhanceSecurityToggleSeverity,
+}
+
+// securitySettingsResource tracks four-eyes authorization, MFA enforcement and session settings
+var securitySettingsResource = SingletonResourceConfig{
+	Kind:          resources.KindVBRSecuritySettings,
+	StateKey:      "SecuritySettings",
+	Endpoint:      "security/settings",
+	CommandName:   "settings",
+	DisplayName:   "Security settings",
+	IgnoreFields:  securityIgnoreFields,
+	SeverityMap:   securitySettingsSeverityMap,
+	EnhanceDrifts: enhanceSecurityToggleSeverity,
+}
+
+// userRolesResource tracks VBR users and their role assignments, keyed by user name
+var userRolesResource = SingletonResourceConfig{
+	Kind:          resources.KindVBRUserRoles,
+	StateKey:      "SecurityUserRoles",
+	Endpoint:      "security/users",
+	CommandName:   "users",
+	DisplayName:   "Users and roles",
+	IgnoreFields:  securityIgnoreFields,
+	SeverityMap:   userRolesSeverityMap,
+	EnhanceDrifts: enhanceUserRoleDriftSeverity,
+	Fetch: func(profile models.Profile) (map[string]interface{}, error) {
+		return normalizeUserRoles(vhttp.GetData[json.RawMessage]("security/users", profile))
+	},
+}
+
+// syslogServersResource tracks syslog event forwarding destinations, keyed by server name
+var syslogServersResource = SingletonResourceConfig{
+	Kind:          resources.KindVBRSyslogServers,
+	StateKey:      "SecuritySyslogServers",
+	Endpoint:      "generalOptions/syslogServers",
+	CommandName:   "syslog",
+	DisplayName:   "Syslog servers",
+	IgnoreFields:  securityIgnoreFields,
+	SeverityMap:   syslogServersSeverityMap,
+	EnhanceDrifts: enhanceSyslogDriftSeverity,
+	Fetch: func(profile models.Profile) (map[string]interface{}, error) {
+		return normalizeSyslogServers(vhttp.GetData[json.RawMessage]("generalOptions/syslogServers", profile))
+	},
+}
+
+// securityResources lists the components covered by the security command group
+var securityResources = []SingletonResourceConfig{
+	malwareDetectionResource,
+	securitySettingsResource,
+	userRolesResource,
+	syslogServersResource,
+}
+
+var securityCmd = &cobra.Command{
+	Use:   "security",
+	Short: "VBR security configuration drift monitoring",
+	Long: `Snapshot and diff VBR security-related configuration using declarative state.
+
+Components:
+  malware-detection  Malware detection settings
+  settings           Four-eyes authorization, MFA enforcement
+  users              Users and role assignments
+  syslog             Syslog servers
+
+Changes that weaken security — four-eyes or MFA disabled, malware detection
+turned off, a new Backup Administrator added, a syslog server removed — are
+classified as CRITICAL.
+
+Subcommands:
+  owlctl security snapshot
+  owlctl security diff
+  owlctl security diff --component users
+  owlctl security diff --severity critical
+`,
+}
+
+var securitySnapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Snapshot security configuration to state",
+	Long: `Fetches the current VBR security configuration and saves it to state.json.
+
+Use --component to snapshot a single component.
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		snapshotSecurity(selectSecurityComponents(securityComponent))
+	},
+}
+
+var securityDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Detect drift in security configuration",
+	Long: `Compares the snapshotted security configuration in state against live VBR.
+
+Components without a snapshot are skipped. Use --component to diff a single component.
+
+Exit codes:
+  0 = No drift
+  3 = Drift detected (INFO or WARNING)
+  4 = Critical drift detected
+  1 = Error
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		diffSecurity(selectSecurityComponents(securityComponent))
+	},
+}
+
+// selectSecurityComponents returns all security components, or the one matching name
+func selectSecurityComponents(name string) []SingletonResourceConfig {
+	if name == "" {
+		return securityResources
+	}
+	for _, sc := range securityResources {
+		if sc.CommandName == name {
+			return []SingletonResourceConfig{sc}
+		}
+	}
+
+	var names []string
+	for _, sc := range securityResources {
+		names = append(names, sc.CommandName)
+	}
+	log.Fatalf("Unknown security component: %s (use %s)", name, strings.Join(names, ", "))
+	return nil
+}
+
+func snapshotSecurity(components []SingletonResourceConfig) {
+	settings := utils.ReadSettings()
+	profile := utils.GetCurrentProfile()
+
+	if settings.SelectedProfile != "vbr" {
+		log.Fatal("This command only works with VBR at the moment.")
+	}
+
+	for _, sc := range components {
+		spec, err := fetchSingleton(sc, profile)
+		if err != nil {
+			log.Fatalf("Failed to fetch %s: %v", lowerFirst(sc.DisplayName), err)
+		}
+
+		if err := saveSingletonToState(sc, spec); err != nil {
+			log.Fatalf("Failed to save %s state: %v", lowerFirst(sc.DisplayName), err)
+		}
+		fmt.Printf("  Snapshot saved: %s\n", sc.DisplayName)
+	}
+
+	stateMgr := state.NewManager()
+	fmt.Printf("\nSecurity snapshot saved.\nState: %s\n", stateMgr.GetStatePath())
+}
+
+func diffSecurity(components []SingletonResourceConfig) {
+	loadSeverityOverrides()
+	settings := utils.ReadSettings()
+	profile := utils.GetCurrentProfile()
+
+	if settings.SelectedProfile != "vbr" {
+		log.Fatal("This command only works with VBR at the moment.")
+	}
+
+	stateMgr := state.NewManager()
+	minSev := parseSeverityFlag()
+
+	type componentDrift struct {
+		name   string
+		drifts []Drift
+	}
+
+	var results []componentDrift
+	var allDrifts []Drift
+	checked := 0
+
+	for _, sc := range components {
+		stateEntry, err := stateMgr.GetResource(sc.StateKey)
+		if err != nil {
+			fmt.Printf("Skipping %s: no snapshot found.\n", lowerFirst(sc.DisplayName))
+			continue
+		}
+		if stateEntry.Type != sc.Kind {
+			log.Fatalf("State entry '%s' is not a %s resource (type: %s).", sc.StateKey, sc.Kind, stateEntry.Type)
+		}
+
+		liveSpec, err := fetchSingleton(sc, profile)
+		if err != nil {
+			log.Fatalf("Failed to fetch %s: %v", lowerFirst(sc.DisplayName), err)
+		}
+		checked++
+
+		drifts := detectSingletonDrift(sc, stateEntry.Spec, liveSpec)
+		drifts = applySeverityRules(sc.Kind, sc.StateKey, stateEntry.Labels, drifts)
+		drifts = suppressAckedDrifts(sc.StateKey, drifts)
+		drifts = filterDriftsBySeverity(drifts, minSev)
+		if len(drifts) > 0 {
+			results = append(results, componentDrift{name: sc.DisplayName, drifts: drifts})
+			reportDrifts(sc.Kind, sc.StateKey, drifts)
+			allDrifts = append(allDrifts, drifts...)
+		}
+	}
+
+	if checked == 0 {
+		fmt.Println("No security snapshot found. Run 'owlctl security snapshot' first.")
+		os.Exit(ExitError)
+	}
+
+	if len(allDrifts) == 0 {
+		fmt.Println(noDriftMessage("Security configuration matches state.", minSev))
+		ciExit(ExitSuccess)
+	}
+
+	printSecuritySummary(allDrifts)
+	for _, r := range results {
+		fmt.Printf("%s drift:\n", r.name)
+		for _, d := range r.drifts {
+			printDriftWithSeverity(d)
+		}
+		fmt.Println()
+	}
+
+	ciExit(exitCodeForDrifts(allDrifts))
+}
+
+// --- Normalisation ---
+
+// normalizeUserRoles converts the VBR user list into a map keyed by user name,
+// with role names sorted so that ordering changes are not reported as drift.
+func normalizeUserRoles(raw json.RawMessage) (map[string]interface{}, error) {
+	var list struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse user list: %w", err)
+	}
+
+	users := make(map[string]interface{})
+	for _, u := range list.Data {
+		name := toString(u["name"])
+		if name == "" {
+			continue
+		}
+
+		var roles []string
+		if rawRoles, ok := u["roles"].([]interface{}); ok {
+			for _, r := range rawRoles {
+				switch role := r.(type) {
+				case string:
+					roles = append(roles, role)
+				case map[string]interface{}:
+					if n := toString(role["name"]); n != "" {
+						roles = append(roles, n)
+					}
+				}
+			}
+		}
+		sort.Strings(roles)
+
+		roleList := make([]interface{}, len(roles))
+		for i, r := range roles {
+			roleList[i] = r
+		}
+
+		entry := map[string]interface{}{"roles": roleList}
+		if t, ok := u["type"]; ok {
+			entry["type"] = t
+		}
+		if sa, ok := u["isServiceAccount"]; ok {
+			entry["isServiceAccount"] = sa
+		}
+		users[name] = entry
+	}
+	return users, nil
+}
+
+// normalizeSyslogServers converts the VBR syslog server list into a map keyed by server name
+func normalizeSyslogServers(raw json.RawMessage) (map[string]interface{}, error) {
+	var list struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse syslog server list: %w", err)
+	}
+
+	servers := make(map[string]interface{})
+	for _, s := range list.Data {
+		name := toString(s["serverName"])
+		if name == "" {
+			name = toString(s["name"])
+		}
+		if name == "" {
+			continue
+		}
+		delete(s, "id")
+		servers[name] = s
+	}
+	return servers, nil
+}
+
+// --- Severity enhancement ---
+
+// isSecurityToggle reports whether the last path segment is an enable/disable flag
+func isSecurityToggle(path string) bool {
+	parts := strings.Split(path, ".")
+	last := parts[len(parts)-1]
+	return last == "enabled" || (strings.HasPrefix(last, "is") && strings.HasSuffix(last, "Enabled"))
+}
+
+// enhanceSecurityToggleSeverity marks protective settings being switched off as CRITICAL
+// and switched on as INFO.
+func enhanceSecurityToggleSeverity(drifts []Drift) []Drift {
+	for i := range drifts {
+		if drifts[i].Action != "modified" || !isSecurityToggle(drifts[i].Path) {
+			continue
+		}
+		if _, ok := drifts[i].VBR.(bool); !ok {
+			continue
+		}
+
+		// CRITICAL if disabled; INFO if enabled
+		if !toBool(drifts[i].VBR) {
+			drifts[i].Severity = SeverityCritical
+		} else {
+			drifts[i].Severity = SeverityInfo
+		}
+	}
+	return drifts
+}
+
+// enhanceUserRoleDriftSeverity escalates new or elevated privileged role assignments to CRITICAL
+func enhanceUserRoleDriftSeverity(drifts []Drift) []Drift {
+	for i := range drifts {
+		d := &drifts[i]
+		switch d.Action {
+		case "added":
+			// New user: CRITICAL if privileged, otherwise WARNING
+			if entry, ok := d.VBR.(map[string]interface{}); ok {
+				if hasPrivilegedRole(entry["roles"]) {
+					d.Severity = SeverityCritical
+				} else {
+					d.Severity = SeverityWarning
+				}
+			}
+		case "removed":
+			if _, ok := d.State.(map[string]interface{}); ok {
+				d.Severity = SeverityWarning
+			}
+		case "modified":
+			if strings.HasSuffix(d.Path, ".roles") {
+				if hasPrivilegedRole(d.VBR) && !hasPrivilegedRole(d.State) {
+					d.Severity = SeverityCritical
+				} else {
+					d.Severity = SeverityWarning
+				}
+			}
+		}
+	}
+	return drifts
+}
+
+// enhanceSyslogDriftSeverity marks removed syslog destinations as CRITICAL and new ones as INFO
+func enhanceSyslogDriftSeverity(drifts []Drift) []Drift {
+	for i := range drifts {
+		d := &drifts[i]
+		switch d.Action {
+		case "removed":
+			if _, ok := d.State.(map[string]interface{}); ok {
+				d.Severity = SeverityCritical
+			}
+		case "added":
+			if _, ok := d.VBR.(map[string]interface{}); ok {
+				d.Severity = SeverityInfo
+			}
+		}
+	}
+	return drifts
+}
+
+// hasPrivilegedRole reports whether a role list contains a privileged VBR role
+func hasPrivilegedRole(v interface{}) bool {
+	roles, ok := v.([]interface{})
+	if !ok {
+		return false
+	}
+	for _, r := range roles {
+		name := strings.ToLower(toString(r))
+		for _, p := range privilegedRoles {
+			if strings.Contains(name, p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func init() {
+	securitySnapshotCmd.Flags().StringVar(&securityComponent, "component", "", "Snapshot a single component (malware-detection, settings, users, syslog)")
+	securityDiffCmd.Flags().StringVar(&securityComponent, "component", "", "Diff a single component (malware-detection, settings, users, syslog)")
+	addSeverityFlags(securityDiffCmd)
+
+	securityCmd.AddCommand(securitySnapshotCmd)
+	securityCmd.AddCommand(securityDiffCmd)
+	rootCmd.AddCommand(securityCmd)
+}
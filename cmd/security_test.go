@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNormalizeUserRoles(t *testing.T) {
+	raw := json.RawMessage(`{"data":[
+		{"id":"1","name":"CORP\\alice","type":"User","roles":[{"name":"Veeam Restore Operator"},{"name":"Veeam Backup Viewer"}]},
+		{"id":"2","name":"CORP\\bob","type":"User","roles":["Veeam Backup Administrator"]}
+	]}`)
+
+	users, err := normalizeUserRoles(raw)
+	if err != nil {
+		t.Fatalf("normalizeUserRoles failed: %v", err)
+	}
+	if len(users) != 2 {
+		t.Fatalf("Expected 2 users, got %d", len(users))
+	}
+
+	alice := users["CORP\\alice"].(map[string]interface{})
+	roles := alice["roles"].([]interface{})
+	if roles[0] != "Veeam Backup Viewer" || roles[1] != "Veeam Restore Operator" {
+		t.Errorf("Expected sorted role names, got %v", roles)
+	}
+	if _, ok := alice["id"]; ok {
+		t.Error("User id should not be stored")
+	}
+}
+
+func TestUserRoleDrift_NewBackupAdministrator(t *testing.T) {
+	stateSpec := map[string]interface{}{
+		"CORP\\alice": map[string]interface{}{"roles": []interface{}{"Veeam Restore Operator"}},
+	}
+	liveSpec := map[string]interface{}{
+		"CORP\\alice":   map[string]interface{}{"roles": []interface{}{"Veeam Restore Operator"}},
+		"CORP\\mallory": map[string]interface{}{"roles": []interface{}{"Veeam Backup Administrator"}},
+	}
+
+	drifts := detectSingletonDrift(userRolesResource, stateSpec, liveSpec)
+	if len(drifts) != 1 {
+		t.Fatalf("Expected 1 drift, got %d: %+v", len(drifts), drifts)
+	}
+	if drifts[0].Action != "added" || drifts[0].Severity != SeverityCritical {
+		t.Errorf("New Backup Administrator should be CRITICAL, got %+v", drifts[0])
+	}
+}
+
+func TestUserRoleDrift_NonPrivilegedUserAdded(t *testing.T) {
+	drifts := enhanceUserRoleDriftSeverity([]Drift{
+		{Path: "CORP\\carol", Action: "added", VBR: map[string]interface{}{"roles": []interface{}{"Veeam Backup Viewer"}}},
+	})
+	if drifts[0].Severity != SeverityWarning {
+		t.Errorf("Non-privileged user added should be WARNING, got %s", drifts[0].Severity)
+	}
+}
+
+func TestUserRoleDrift_RoleEscalation(t *testing.T) {
+	drifts := enhanceUserRoleDriftSeverity([]Drift{
+		{
+			Path:   "CORP\\alice.roles",
+			Action: "modified",
+			State:  []interface{}{"Veeam Restore Operator"},
+			VBR:    []interface{}{"Veeam Restore Operator", "Veeam Security Administrator"},
+		},
+	})
+	if drifts[0].Severity != SeverityCritical {
+		t.Errorf("Privileged role granted should be CRITICAL, got %s", drifts[0].Severity)
+	}
+}
+
+func TestSecuritySettingsDrift_FourEyesDisabled(t *testing.T) {
+	stateSpec := map[string]interface{}{"isFourEyesAuthorizationEnabled": true, "isMfaEnabled": true}
+	liveSpec := map[string]interface{}{"isFourEyesAuthorizationEnabled": false, "isMfaEnabled": true}
+
+	drifts := detectSingletonDrift(securitySettingsResource, stateSpec, liveSpec)
+	if len(drifts) != 1 || drifts[0].Severity != SeverityCritical {
+		t.Fatalf("Four-eyes disabled should be a single CRITICAL drift, got %+v", drifts)
+	}
+}
+
+func TestMalwareDetectionDrift_Enabled(t *testing.T) {
+	stateSpec := map[string]interface{}{"encryptionDetection": map[string]interface{}{"isEnabled": false}}
+	liveSpec := map[string]interface{}{"encryptionDetection": map[string]interface{}{"isEnabled": true}}
+
+	drifts := detectSingletonDrift(malwareDetectionResource, stateSpec, liveSpec)
+	if len(drifts) != 1 || drifts[0].Severity != SeverityInfo {
+		t.Fatalf("Malware detection enabled should be INFO, got %+v", drifts)
+	}
+}
+
+func TestSyslogDrift_ServerRemoved(t *testing.T) {
+	raw := json.RawMessage(`{"data":[{"id":"s1","serverName":"siem.corp.local","port":514,"protocol":"UDP"}]}`)
+	stateSpec, err := normalizeSyslogServers(raw)
+	if err != nil {
+		t.Fatalf("normalizeSyslogServers failed: %v", err)
+	}
+
+	drifts := detectSingletonDrift(syslogServersResource, stateSpec, map[string]interface{}{})
+	if len(drifts) != 1 {
+		t.Fatalf("Expected 1 drift, got %d", len(drifts))
+	}
+	if drifts[0].Path != "siem.corp.local" || drifts[0].Severity != SeverityCritical {
+		t.Errorf("Syslog server removal should be CRITICAL, got %+v", drifts[0])
+	}
+}
+
+func TestSelectSecurityComponents(t *testing.T) {
+	if got := selectSecurityComponents(""); len(got) != len(securityResources) {
+		t.Errorf("Expected all %d components, got %d", len(securityResources), len(got))
+	}
+	got := selectSecurityComponents("users")
+	if len(got) != 1 || got[0].StateKey != "SecurityUserRoles" {
+		t.Errorf("Expected users component, got %+v", got)
+	}
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/shapedthought/owlctl/config"
+	"github.com/shapedthought/owlctl/resources"
+	"github.com/shapedthought/owlctl/state"
+	"github.com/spf13/cobra"
+)
+
+// labelSelector is the -l/--selector flag shared by apply, diff, plan, export and state list
+var labelSelector string
+
+// addSelectorFlag registers the shared -l/--selector flag on a command
+func addSelectorFlag(c *cobra.Command, usage string) {
+	c.Flags().StringVarP(&labelSelector, "selector", "l", "", usage)
+}
+
+// parseLabelSelector parses the -l/--selector flag, exiting on invalid syntax
+func parseLabelSelector() resources.Selector {
+	sel, err := resources.ParseSelector(labelSelector)
+	if err != nil {
+		log.Fatalf("Invalid --selector: %v", err)
+	}
+	return sel
+}
+
+// lookupGroup returns the group config for a group operation. With --selector the selector
+// is ANDed onto the named group; with no group name an ad-hoc group is built from the
+// selector alone, searching every spec under the owlctl.yaml directory.
+func lookupGroup(cfg *config.VCLIConfig, group string) (config.GroupConfig, error) {
+	if group == "" {
+		if labelSelector == "" {
+			return config.GroupConfig{}, fmt.Errorf("no group or selector given")
+		}
+		return config.GroupConfig{Selector: labelSelector}, nil
+	}
+
+	groupCfg, err := cfg.GetGroup(group)
+	if err != nil {
+		return config.GroupConfig{}, err
+	}
+	if labelSelector != "" {
+		if groupCfg.Selector != "" {
+			groupCfg.Selector += "," + labelSelector
+		} else {
+			groupCfg.Selector = labelSelector
+		}
+	}
+	return groupCfg, nil
+}
+
+// groupLabel returns the display name for a group operation
+func groupLabel(group string) string {
+	switch {
+	case group == "":
+		return fmt.Sprintf("selector %q", labelSelector)
+	case labelSelector != "":
+		return fmt.Sprintf("%s (selector %q)", group, labelSelector)
+	default:
+		return group
+	}
+}
+
+// remediateCommand renders a "--group %s" remediation template for a group operation,
+// substituting or appending the selector when one was used.
+func remediateCommand(template, group string) string {
+	if group == "" {
+		return strings.Replace(template, "--group %s", fmt.Sprintf("-l %q", labelSelector), 1)
+	}
+	cmd := fmt.Sprintf(template, group)
+	if labelSelector != "" {
+		cmd += fmt.Sprintf(" -l %q", labelSelector)
+	}
+	return cmd
+}
+
+// filterResourcesBySelector returns the state resources whose recorded labels match the selector
+func filterResourcesBySelector(resourceList []*state.Resource, sel resources.Selector) []*state.Resource {
+	if sel.Empty() {
+		return resourceList
+	}
+	var matched []*state.Resource
+	for _, r := range resourceList {
+		if sel.Matches(r.Labels) {
+			matched = append(matched, r)
+		}
+	}
+	return matched
+}
+
+// listSelectedResources lists state resources of a type, filtered by the -l/--selector flag
+func listSelectedResources(stateMgr *state.Manager, resourceType string) ([]*state.Resource, error) {
+	resourceList, err := stateMgr.ListResources(resourceType)
+	if err != nil {
+		return nil, err
+	}
+	return filterResourcesBySelector(resourceList, parseLabelSelector()), nil
+}
+
+// selectSpecsByLabel returns the paths of every spec of the given kind under the owlctl.yaml
+// directory whose labels match the -l/--selector flag, along with the loaded specs.
+func selectSpecsByLabel(kind string) ([]string, []resources.ResourceSpec) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load owlctl.yaml: %v", err)
+	}
+
+	candidates, err := cfg.ResolveGroupSpecs(config.GroupConfig{Selector: labelSelector})
+	if err != nil {
+		log.Fatalf("Failed to resolve specs: %v", err)
+	}
+
+	var paths []string
+	var specs []resources.ResourceSpec
+	for _, c := range candidates {
+		path := cfg.ResolvePath(c)
+		spec, err := resources.LoadResourceSpec(path)
+		if err != nil {
+			log.Fatalf("Failed to load spec %s: %v", c, err)
+		}
+		if spec.Kind == kind {
+			paths = append(paths, path)
+			specs = append(specs, spec)
+		}
+	}
+
+	if len(specs) == 0 {
+		log.Fatalf("No %s specs match selector %q", kind, labelSelector)
+	}
+	return paths, specs
+}
+
+// selectedStateNames returns the names of state resources of a type whose recorded labels
+// match the -l/--selector flag, or nil when no selector was given.
+func selectedStateNames(resourceType string) map[string]bool {
+	if labelSelector == "" {
+		return nil
+	}
+	resourceList, err := listSelectedResources(state.NewManager(), resourceType)
+	if err != nil {
+		log.Fatalf("Failed to load state: %v", err)
+	}
+	names := make(map[string]bool, len(resourceList))
+	for _, r := range resourceList {
+		names[r.Name] = true
+	}
+	return names
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/shapedthought/owlctl/config"
+	"github.com/shapedthought/owlctl/resources"
+	"github.com/shapedthought/owlctl/state"
+)
+
+func TestLookupGroup(t *testing.T) {
+	cfg := &config.VCLIConfig{
+		Groups: map[string]config.GroupConfig{
+			"prod":     {Specs: []string{"a.yaml"}},
+			"selected": {Selector: "env=prod"},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		group        string
+		selector     string
+		wantSelector string
+		wantErr      bool
+	}{
+		{name: "named group without selector", group: "prod", wantSelector: ""},
+		{name: "named group with selector", group: "prod", selector: "tier=gold", wantSelector: "tier=gold"},
+		{name: "selector ANDed onto group selector", group: "selected", selector: "tier=gold", wantSelector: "env=prod,tier=gold"},
+		{name: "ad-hoc group from selector", selector: "env=dev", wantSelector: "env=dev"},
+		{name: "no group or selector", wantErr: true},
+		{name: "unknown group", group: "missing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labelSelector = tt.selector
+			defer func() { labelSelector = "" }()
+
+			got, err := lookupGroup(cfg, tt.group)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Selector != tt.wantSelector {
+				t.Errorf("Selector = %q, want %q", got.Selector, tt.wantSelector)
+			}
+		})
+	}
+}
+
+func TestRemediateCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		group    string
+		selector string
+		want     string
+	}{
+		{name: "group only", group: "prod", want: "owlctl repo apply --group prod"},
+		{name: "group and selector", group: "prod", selector: "env=prod", want: `owlctl repo apply --group prod -l "env=prod"`},
+		{name: "selector only", selector: "env=prod", want: `owlctl repo apply -l "env=prod"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labelSelector = tt.selector
+			defer func() { labelSelector = "" }()
+
+			if got := remediateCommand("owlctl repo apply --group %s", tt.group); got != tt.want {
+				t.Errorf("remediateCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterResourcesBySelector(t *testing.T) {
+	resourceList := []*state.Resource{
+		{Name: "prod-gold", Labels: map[string]string{"env": "prod", "tier": "gold"}},
+		{Name: "prod-bronze", Labels: map[string]string{"env": "prod", "tier": "bronze"}},
+		{Name: "observed"},
+	}
+
+	tests := []struct {
+		selector string
+		want     []string
+	}{
+		{selector: "", want: []string{"prod-gold", "prod-bronze", "observed"}},
+		{selector: "env=prod", want: []string{"prod-gold", "prod-bronze"}},
+		{selector: "tier in (gold,silver)", want: []string{"prod-gold"}},
+		{selector: "!env", want: []string{"observed"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			sel, err := resources.ParseSelector(tt.selector)
+			if err != nil {
+				t.Fatalf("ParseSelector: %v", err)
+			}
+			got := filterResourcesBySelector(resourceList, sel)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d resources, want %d", len(got), len(tt.want))
+			}
+			for i, r := range got {
+				if r.Name != tt.want[i] {
+					t.Errorf("resource[%d] = %q, want %q", i, r.Name, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/shapedthought/owlctl/models"
+	"github.com/shapedthought/owlctl/vhttp"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var (
+	applyWait        bool
+	applyWaitTimeout time.Duration
+)
+
+// sessionPollInterval is how often a waited-on session is polled for progress
+var sessionPollInterval = 10 * time.Second
+
+// createWaitTimeout bounds the wait for a created resource's ID when the apply was not run
+// with --wait and no --timeout was given
+var createWaitTimeout = 30 * time.Minute
+
+// fetchSession returns the current state of a session
+var fetchSession = func(sessionID string, profile models.Profile) vbrSession {
+	return vhttp.GetData[vbrSession]("sessions/"+sessionID, profile)
+}
+
+// sessionLogLines is how many session log records are printed when a session fails
+const sessionLogLines = 20
+
+// addApplyWaitFlags registers --wait and --timeout on a declarative apply command
+func addApplyWaitFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&applyWait, "wait", false, "Wait for VBR sessions started by the apply to finish; a failed session fails the apply")
+	cmd.Flags().DurationVar(&applyWaitTimeout, "timeout", 0, "Maximum time to wait for each session with --wait (e.g. 30m; default: no limit), and for a created resource's ID without --wait (default: 30m)")
+}
+
+// vbrSessionLog is the VBR session log model (GET sessions/{id}/logs)
+type vbrSessionLog struct {
+	Records []struct {
+		Status    string `json:"status"`
+		StartTime string `json:"startTime"`
+		Title     string `json:"title"`
+	} `json:"records"`
+	TotalRecords int `json:"totalRecords"`
+}
+
+// parseSessionResponse returns the session when a response body is a VBR session model, as
+// returned by asynchronous operations such as job start, rescan or repository edits
+func parseSessionResponse(body []byte) (*vbrSession, bool) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, false
+	}
+	for _, key := range []string{"id", "state", "sessionType"} {
+		if _, ok := fields[key]; !ok {
+			return nil, false
+		}
+	}
+
+	var session vbrSession
+	if err := json.Unmarshal(body, &session); err != nil || session.ID == "" || session.State == "" {
+		return nil, false
+	}
+	return &session, true
+}
+
+// waitForSession polls sessions/{id} until the session stops, showing progress on w: a
+// progress bar on a terminal, otherwise a line per progress change. If the session fails,
+// its log is printed. A zero timeout waits indefinitely.
+func waitForSession(w io.Writer, label, sessionID string, timeout time.Duration, profile models.Profile) (*vbrSession, error) {
+	start := time.Now()
+	progress := newSessionProgress(w, label)
+
+	for {
+		session := fetchSession(sessionID, profile)
+
+		if session.State == "Stopped" {
+			progress.stop()
+			fmt.Fprintf(w, "  %s: %s (%s)\n", label, sessionResultLabel(session.Result.Result), time.Since(start).Round(time.Second))
+			if session.Result.Message != "" && session.Result.Result != "Success" {
+				fmt.Fprintf(w, "    %s\n", session.Result.Message)
+			}
+			if session.Result.Result == "Failed" {
+				printSessionLog(w, sessionID, profile)
+			}
+			return &session, nil
+		}
+
+		progress.update(session.State, session.Progress)
+
+		if timeout > 0 && time.Since(start) >= timeout {
+			progress.stop()
+			return nil, fmt.Errorf("timed out after %s waiting for session %s", timeout, sessionID)
+		}
+		time.Sleep(sessionPollInterval)
+	}
+}
+
+// sessionProgress shows a session's progress as a bar or as lines
+type sessionProgress struct {
+	w            io.Writer
+	label        string
+	bar          *pterm.ProgressbarPrinter
+	lastProgress int
+}
+
+func newSessionProgress(w io.Writer, label string) *sessionProgress {
+	p := &sessionProgress{w: w, label: label, lastProgress: -1}
+	if f, ok := w.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		bar, err := pterm.DefaultProgressbar.WithTotal(100).WithTitle(label).WithWriter(w).WithRemoveWhenDone(true).Start()
+		if err == nil {
+			p.bar = bar
+		}
+	}
+	return p
+}
+
+func (p *sessionProgress) update(state string, percent int) {
+	if percent == p.lastProgress {
+		return
+	}
+	if p.bar != nil {
+		p.bar.UpdateTitle(fmt.Sprintf("%s (%s)", p.label, state))
+		if delta := percent - p.bar.Current; delta > 0 {
+			p.bar.Add(delta)
+		}
+	} else {
+		fmt.Fprintf(p.w, "  %s: %s %d%%\n", p.label, state, percent)
+	}
+	p.lastProgress = percent
+}
+
+func (p *sessionProgress) stop() {
+	if p.bar != nil {
+		p.bar.Stop()
+		p.bar = nil
+	}
+}
+
+// printSessionLog prints the last records of a session's log. Failures to fetch the log are
+// reported but don't change the outcome.
+func printSessionLog(w io.Writer, sessionID string, profile models.Profile) {
+	res, err := vhttp.Send("GET", "sessions/"+sessionID+"/logs", nil, profile)
+	if err == nil && (res.StatusCode < 200 || res.StatusCode >= 300) {
+		err = res.Error()
+	}
+	var log vbrSessionLog
+	if err == nil {
+		err = json.Unmarshal(res.Body, &log)
+	}
+	if err != nil {
+		fmt.Fprintf(w, "    (could not fetch session log: %v)\n", err)
+		return
+	}
+
+	records := log.Records
+	if len(records) > sessionLogLines {
+		fmt.Fprintf(w, "    Session log (last %d of %d records):\n", sessionLogLines, len(records))
+		records = records[len(records)-sessionLogLines:]
+	} else {
+		fmt.Fprintln(w, "    Session log:")
+	}
+	for _, r := range records {
+		fmt.Fprintf(w, "      %s %s %s\n", sessionLogIcon(r.Status), formatSessionLogTime(r.StartTime), r.Title)
+	}
+}
+
+func sessionLogIcon(status string) string {
+	switch status {
+	case "Succeeded":
+		return "✓"
+	case "Warning":
+		return "⚠"
+	case "Failed":
+		return "✗"
+	}
+	return "•"
+}
+
+func formatSessionLogTime(s string) string {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return s
+	}
+	return t.Local().Format("15:04:05")
+}
+
+func sessionResultLabel(result string) string {
+	if result == "" || result == "None" {
+		return "finished"
+	}
+	return result
+}
+
+// sessionExitCode maps a finished session's result to an exit code
+func sessionExitCode(session *vbrSession) int {
+	switch session.Result.Result {
+	case "Failed":
+		return ExitSessionFailed
+	case "Warning":
+		return ExitSessionWarning
+	}
+	return ExitSuccess
+}
+
+// waitForApplySession waits for a session started by a declarative apply, up to timeout (zero
+// waits indefinitely). A failed session is an error; a warning is reported but the apply
+// succeeds.
+func waitForApplySession(label string, session *vbrSession, timeout time.Duration, profile models.Profile) (*vbrSession, error) {
+	fmt.Printf("Waiting for session: %s\n", sessionName(session))
+	finished, err := waitForSession(os.Stdout, label, session.ID, timeout, profile)
+	if err != nil {
+		return nil, err
+	}
+	if finished.Result.Result == "Failed" {
+		return finished, fmt.Errorf("session %s failed: %s", sessionName(finished), finished.Result.Message)
+	}
+	return finished, nil
+}
+
+func sessionName(session *vbrSession) string {
+	if session.Name != "" {
+		return session.Name
+	}
+	return session.ID
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shapedthought/owlctl/models"
+)
+
+func TestParseSessionResponse(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		wantID string
+		wantOK bool
+	}{
+		{
+			name:   "session model",
+			body:   `{"id": "s1", "name": "Infrastructure Item Editing", "sessionType": "Infrastructure", "state": "Starting", "progressPercent": 0, "resourceId": "r1", "result": {"result": "None"}}`,
+			wantID: "s1",
+			wantOK: true,
+		},
+		{name: "job model", body: `{"id": "j1", "name": "SQL Backup", "type": "VSphereBackup", "isDisabled": false}`},
+		{name: "missing sessionType", body: `{"id": "s1", "state": "Working"}`},
+		{name: "empty state", body: `{"id": "s1", "state": "", "sessionType": "BackupJob"}`},
+		{name: "not json", body: `OK`},
+		{name: "empty body", body: ``},
+		{name: "array", body: `[{"id": "s1", "state": "Working", "sessionType": "BackupJob"}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, ok := parseSessionResponse([]byte(tt.body))
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && session.ID != tt.wantID {
+				t.Errorf("ID = %q, want %q", session.ID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestSessionExitCode(t *testing.T) {
+	tests := []struct {
+		result string
+		want   int
+	}{
+		{"Success", ExitSuccess},
+		{"None", ExitSuccess},
+		{"Warning", ExitSessionWarning},
+		{"Failed", ExitSessionFailed},
+	}
+	for _, tt := range tests {
+		var s vbrSession
+		s.Result.Result = tt.result
+		if got := sessionExitCode(&s); got != tt.want {
+			t.Errorf("sessionExitCode(%s) = %d, want %d", tt.result, got, tt.want)
+		}
+	}
+}
+
+func TestSessionProgressLines(t *testing.T) {
+	var buf bytes.Buffer
+	p := newSessionProgress(&buf, "SQL Backup")
+	p.update("Starting", 0)
+	p.update("Working", 0)
+	p.update("Working", 40)
+	p.update("Working", 40)
+	p.stop()
+
+	want := "  SQL Backup: Starting 0%\n  SQL Backup: Working 40%\n"
+	if buf.String() != want {
+		t.Errorf("output =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+// stubSessions replaces fetchSession with a sequence of session states; the last one repeats
+func stubSessions(t *testing.T, states ...vbrSession) {
+	t.Helper()
+	origFetch, origPoll := fetchSession, sessionPollInterval
+	t.Cleanup(func() { fetchSession, sessionPollInterval = origFetch, origPoll })
+	sessionPollInterval = time.Millisecond
+	fetchSession = func(sessionID string, profile models.Profile) vbrSession {
+		s := states[0]
+		if len(states) > 1 {
+			states = states[1:]
+		}
+		return s
+	}
+}
+
+func TestCreatedResourceID_WaitsForSession(t *testing.T) {
+	working := vbrSession{ID: "s1", State: "Working", Progress: 50}
+	stopped := vbrSession{ID: "s1", State: "Stopped", ResourceID: "repo-1"}
+	stopped.Result.Result = "Success"
+	stubSessions(t, working, stopped)
+
+	body := `{"id": "s1", "name": "Infrastructure Item Editing", "sessionType": "Infrastructure", "state": "Starting", "result": {"result": "None"}}`
+	id, err := createdResourceID("Repo", []byte(body), models.Profile{})
+	if err != nil || id != "repo-1" {
+		t.Errorf("createdResourceID() = %q, %v; want the finished session's resource ID", id, err)
+	}
+}
+
+func TestCreatedResourceID_WaitIsBounded(t *testing.T) {
+	stubSessions(t, vbrSession{ID: "s1", State: "Working"})
+	origTimeout := createWaitTimeout
+	defer func() { createWaitTimeout = origTimeout }()
+	createWaitTimeout = 5 * time.Millisecond
+
+	body := `{"id": "s1", "sessionType": "Infrastructure", "state": "Starting"}`
+	_, err := createdResourceID("Repo", []byte(body), models.Profile{})
+	if err == nil || !strings.Contains(err.Error(), "timed out") || !strings.Contains(err.Error(), "run apply again") {
+		t.Errorf("err = %v, want a timeout that says how to record the resource", err)
+	}
+}
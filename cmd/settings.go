@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"github.com/shapedthought/owlctl/resources"
+)
+
+// emailSettingsResource defines the global email notification (SMTP) settings singleton
+var emailSettingsResource = SingletonResourceConfig{
+	Kind:           resources.KindVBREmailSettings,
+	StateKey:       "EmailSettings",
+	Endpoint:       "generalOptions/emailSettings",
+	CommandName:    "email-settings",
+	DisplayName:    "Email notification settings",
+	IgnoreFields:   emailSettingsIgnoreFields,
+	SeverityMap:    emailSettingsSeverityMap,
+	EnhanceDrifts:  enhanceEmailSettingsDriftSeverity,
+	PreserveFields: []string{"password"},
+}
+
+// trafficRulesResource defines the network traffic throttling/encryption rules singleton
+var trafficRulesResource = SingletonResourceConfig{
+	Kind:         resources.KindVBRTrafficRules,
+	StateKey:     "TrafficRules",
+	Endpoint:     "trafficRules",
+	CommandName:  "traffic-rules",
+	DisplayName:  "Network traffic rules",
+	IgnoreFields: trafficRulesIgnoreFields,
+	SeverityMap:  trafficRulesSeverityMap,
+}
+
+// generalOptionsResource defines the global options singleton (history retention, notifications, event forwarding)
+var generalOptionsResource = SingletonResourceConfig{
+	Kind:           resources.KindVBRGeneralOptions,
+	StateKey:       "GeneralOptions",
+	Endpoint:       "generalOptions",
+	CommandName:    "general-options",
+	DisplayName:    "General options",
+	IgnoreFields:   generalOptionsIgnoreFields,
+	SeverityMap:    generalOptionsSeverityMap,
+	PreserveFields: []string{"emailSettings"},
+}
+
+// singletonResources lists every singleton settings resource managed by owlctl
+var singletonResources = []SingletonResourceConfig{
+	configBackupResource,
+	emailSettingsResource,
+	trafficRulesResource,
+	generalOptionsResource,
+}
+
+var emailSettingsCmd = newSingletonCmd(emailSettingsResource, "VBR email notification settings management", `Manage VBR global email notification (SMTP) settings using declarative state.
+
+Email settings are a singleton resource — one set of settings per VBR server.
+The SMTP password is never stored in state or exported; apply keeps the password
+configured in VBR.
+
+Disabling notifications or failure alerts is classified as WARNING drift.
+
+Subcommands:
+  owlctl email-settings snapshot
+  owlctl email-settings diff
+  owlctl email-settings diff --security-only
+  owlctl email-settings export -o email-settings.yaml
+  owlctl email-settings apply email-settings.yaml --dry-run
+`)
+
+var trafficRulesCmd = newSingletonCmd(trafficRulesResource, "VBR network traffic rules management", `Manage VBR network traffic throttling and encryption rules using declarative state.
+
+Traffic rules are a singleton resource — one rule set per VBR server.
+
+Subcommands:
+  owlctl traffic-rules snapshot
+  owlctl traffic-rules diff
+  owlctl traffic-rules export -o traffic-rules.yaml
+  owlctl traffic-rules apply traffic-rules.yaml --dry-run
+`)
+
+var generalOptionsCmd = newSingletonCmd(generalOptionsResource, "VBR general options management", `Manage VBR global options (session history retention, storage notifications,
+event forwarding) using declarative state.
+
+General options are a singleton resource — one set of settings per VBR server.
+Email settings are managed separately with 'owlctl email-settings'.
+
+Subcommands:
+  owlctl general-options snapshot
+  owlctl general-options diff
+  owlctl general-options export -o general-options.yaml
+  owlctl general-options apply general-options.yaml --dry-run
+`)
+
+// enhanceEmailSettingsDriftSeverity downgrades notification drifts to INFO when
+// notifications were turned on rather than off.
+func enhanceEmailSettingsDriftSeverity(drifts []Drift) []Drift {
+	for i := range drifts {
+		if drifts[i].Action != "modified" {
+			continue
+		}
+
+		switch drifts[i].Path {
+		case "isEnabled", "notifyOnFailure", "notifyOnWarning":
+			// WARNING if disabled; INFO if enabled
+			if !toBool(drifts[i].VBR) {
+				drifts[i].Severity = SeverityWarning
+			} else {
+				drifts[i].Severity = SeverityInfo
+			}
+		}
+	}
+	return drifts
+}
+
+func init() {
+	rootCmd.AddCommand(emailSettingsCmd)
+	rootCmd.AddCommand(trafficRulesCmd)
+	rootCmd.AddCommand(generalOptionsCmd)
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/shapedthought/owlctl/models"
+	"github.com/shapedthought/owlctl/resources"
+	"github.com/shapedthought/owlctl/state"
+)
+
+func TestEnhanceEmailSettingsDriftSeverity_NotificationsDisabled(t *testing.T) {
+	drifts := []Drift{
+		{Path: "isEnabled", Action: "modified", State: true, VBR: false, Severity: SeverityInfo},
+		{Path: "notifyOnFailure", Action: "modified", State: true, VBR: false, Severity: SeverityInfo},
+	}
+
+	result := enhanceEmailSettingsDriftSeverity(drifts)
+	for _, d := range result {
+		if d.Severity != SeverityWarning {
+			t.Errorf("%s disabled should be WARNING, got %s", d.Path, d.Severity)
+		}
+	}
+}
+
+func TestEnhanceEmailSettingsDriftSeverity_NotificationsEnabled(t *testing.T) {
+	drifts := []Drift{
+		{Path: "notifyOnFailure", Action: "modified", State: false, VBR: true, Severity: SeverityWarning},
+	}
+
+	result := enhanceEmailSettingsDriftSeverity(drifts)
+	if result[0].Severity != SeverityInfo {
+		t.Errorf("notifyOnFailure enabled should be INFO, got %s", result[0].Severity)
+	}
+}
+
+func TestDetectSingletonDrift_IgnoresPassword(t *testing.T) {
+	stateSpec := map[string]interface{}{"notifyOnFailure": true, "password": "old"}
+	liveSpec := map[string]interface{}{"notifyOnFailure": false, "password": "new"}
+
+	drifts := detectSingletonDrift(emailSettingsResource, stateSpec, liveSpec)
+	if len(drifts) != 1 {
+		t.Fatalf("Expected 1 drift, got %d: %+v", len(drifts), drifts)
+	}
+	if drifts[0].Path != "notifyOnFailure" || drifts[0].Severity != SeverityWarning {
+		t.Errorf("Expected WARNING drift on notifyOnFailure, got %+v", drifts[0])
+	}
+}
+
+func TestConvertSingletonToYAML(t *testing.T) {
+	data := map[string]interface{}{"id": "rules-1", "useMultipleStreamsPerJob": true}
+
+	out, err := convertSingletonToYAML(trafficRulesResource, data)
+	if err != nil {
+		t.Fatalf("convertSingletonToYAML failed: %v", err)
+	}
+
+	yamlStr := string(out)
+	for _, want := range []string{"kind: VBRTrafficRules", "name: TrafficRules", "useMultipleStreamsPerJob: true", "owlctl traffic-rules apply"} {
+		if !strings.Contains(yamlStr, want) {
+			t.Errorf("Expected output to contain %q:\n%s", want, yamlStr)
+		}
+	}
+	if strings.Contains(yamlStr, "rules-1") {
+		t.Errorf("Ignored field id should not be exported:\n%s", yamlStr)
+	}
+}
+
+func TestSaveSingletonToState_StripsPassword(t *testing.T) {
+	t.Setenv("OWLCTL_SETTINGS_PATH", t.TempDir())
+	t.Setenv("OWLCTL_ACTIVE_INSTANCE", "")
+
+	spec := map[string]interface{}{"isEnabled": true, "userName": "smtp", "password": "secret"}
+	if err := saveSingletonToState(emailSettingsResource, spec); err != nil {
+		t.Fatalf("saveSingletonToState failed: %v", err)
+	}
+
+	r, err := state.NewManager().GetResource("EmailSettings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r.Spec["password"]; ok {
+		t.Errorf("password stored in state: %+v", r.Spec)
+	}
+	if r.Spec["userName"] != "smtp" {
+		t.Errorf("userName = %v, want smtp", r.Spec["userName"])
+	}
+}
+
+func TestSingletonApply_KeepsLivePassword(t *testing.T) {
+	noValidate = true
+	defer func() { noValidate = false }()
+
+	cfg := singletonApplyConfig(emailSettingsResource)
+	cfg.FetchCurrent = func(name string, profile models.Profile) (json.RawMessage, string, error) {
+		return json.RawMessage(`{"isEnabled":true,"userName":"smtp","password":"secret"}`), emailSettingsResource.Endpoint, nil
+	}
+	spec := resources.ResourceSpec{
+		Kind:     resources.KindVBREmailSettings,
+		Metadata: resources.Metadata{Name: "EmailSettings"},
+		Spec:     map[string]interface{}{"isEnabled": false, "userName": "smtp"},
+	}
+
+	change, err := planResourceChange(spec, cfg, models.Profile{}, nil, nil)
+	if err != nil {
+		t.Fatalf("planResourceChange failed: %v", err)
+	}
+	if change.Payload["password"] != "secret" {
+		t.Errorf("PUT payload password = %v, want the live value", change.Payload["password"])
+	}
+	if change.Payload["isEnabled"] != false {
+		t.Errorf("isEnabled = %v, want false from the spec", change.Payload["isEnabled"])
+	}
+	for _, c := range change.Changes {
+		if c.Path == "password" {
+			t.Errorf("password reported as a change: %+v", c)
+		}
+	}
+}
@@ -18,14 +18,32 @@ import (
 //	  "repository": { "type": "CRITICAL" },
 //	  "sobr": { "isEnabled": "CRITICAL" },
 //	  "encryption": { "hint": "WARNING" },
-//	  "kms": { "type": "CRITICAL" }
+//	  "kms": { "type": "CRITICAL" },
+//	  "emailSettings": { "notifyOnFailure": "CRITICAL" },
+//	  "rules": [
+//	    { "kind": "VBRJob", "selector": "env=lab", "path": "isDisabled", "severity": "INFO" }
+//	  ]
 //	}
+//
+// The per-kind maps apply everywhere; rules are scoped to resources (see SeverityRule).
 type severityConfigFile struct {
 	Job        map[string]string `json:"job,omitempty"`
 	Repository map[string]string `json:"repository,omitempty"`
 	Sobr       map[string]string `json:"sobr,omitempty"`
 	Encryption map[string]string `json:"encryption,omitempty"`
 	Kms        map[string]string `json:"kms,omitempty"`
+
+	ConfigBackup   map[string]string `json:"configBackup,omitempty"`
+	EmailSettings  map[string]string `json:"emailSettings,omitempty"`
+	TrafficRules   map[string]string `json:"trafficRules,omitempty"`
+	GeneralOptions map[string]string `json:"generalOptions,omitempty"`
+
+	MalwareDetection map[string]string `json:"malwareDetection,omitempty"`
+	SecuritySettings map[string]string `json:"securitySettings,omitempty"`
+	UserRoles        map[string]string `json:"userRoles,omitempty"`
+	SyslogServers    map[string]string `json:"syslogServers,omitempty"`
+
+	Rules []SeverityRule `json:"rules,omitempty"`
 }
 
 var severityOverridesLoaded bool
@@ -58,8 +76,22 @@ func loadSeverityOverrides() {
 	applySeverityOverrides(config.Repository, repoSeverityMap)
 	applySeverityOverrides(config.Sobr, sobrSeverityMap)
 	applySeverityOverrides(config.Encryption, encryptionSeverityMap)
+	applySeverityOverrides(config.ConfigBackup, configBackupSeverityMap)
+	applySeverityOverrides(config.EmailSettings, emailSettingsSeverityMap)
+	applySeverityOverrides(config.TrafficRules, trafficRulesSeverityMap)
+	applySeverityOverrides(config.GeneralOptions, generalOptionsSeverityMap)
+	applySeverityOverrides(config.MalwareDetection, malwareDetectionSeverityMap)
+	applySeverityOverrides(config.SecuritySettings, securitySettingsSeverityMap)
+	applySeverityOverrides(config.UserRoles, userRolesSeverityMap)
+	applySeverityOverrides(config.SyslogServers, syslogServersSeverityMap)
 	applySeverityOverrides(config.Kms, kmsSeverityMap)
 
+	rules, warnings := compileSeverityRules(config.Rules)
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
+	severityRules = rules
+
 	severityOverridesLoaded = true
 }
 
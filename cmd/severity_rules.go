@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/shapedthought/owlctl/config"
+	"github.com/shapedthought/owlctl/resources"
+	"github.com/shapedthought/owlctl/state"
+)
+
+// SeverityRule sets the severity of matching drifts on matching resources. It is the
+// scoped counterpart of the per-kind field maps in severity-config.json: rules are applied
+// after a drift is classified (including the built-in directional rules for jobs), and a
+// later matching rule overrides an earlier one. Empty fields match anything.
+//
+// Example:
+//
+//	{
+//	  "rules": [
+//	    { "instance": "lab", "severity": "INFO" },
+//	    { "kind": "VBRJob", "selector": "env=prod", "path": "storage.retentionPolicy.quantity",
+//	      "change": "decreased", "severity": "CRITICAL" },
+//	    { "kind": "VBRJob", "group": "sql-tier", "resource": "SQL-*", "path": "schedule", "severity": "CRITICAL" }
+//	  ]
+//	}
+type SeverityRule struct {
+	// Kind is the resource kind (e.g. VBRJob)
+	Kind string `json:"kind,omitempty"`
+	// Instance is a named instance from owlctl.yaml
+	Instance string `json:"instance,omitempty"`
+	// Group is a group from owlctl.yaml; the resource must be one of its specs
+	Group string `json:"group,omitempty"`
+	// Resource is a resource name pattern; * and ? are wildcards
+	Resource string `json:"resource,omitempty"`
+	// Selector is a label selector matched against the resource's labels
+	Selector string `json:"selector,omitempty"`
+	// Path is a dotted field path; it also matches fields below it, and * matches one segment
+	Path string `json:"path,omitempty"`
+	// Action is modified, added or removed
+	Action string `json:"action,omitempty"`
+	// Change is the direction of the change: decreased, increased, disabled (became false)
+	// or enabled (became true)
+	Change string `json:"change,omitempty"`
+	// Severity is the severity to assign: CRITICAL, WARNING or INFO
+	Severity string `json:"severity"`
+
+	selector resources.Selector
+}
+
+// driftTarget identifies the resource a set of drifts belongs to
+type driftTarget struct {
+	Instance string
+	Kind     string
+	Name     string
+	Labels   map[string]string
+}
+
+// severityRules holds the valid rules loaded from severity-config.json
+var severityRules []SeverityRule
+
+// severityGroupMembers caches the kind/name pairs of each group's specs
+var severityGroupMembers = make(map[string]map[string]bool)
+
+// compileSeverityRules validates rules, returning the usable ones and a warning for each
+// rule that is skipped
+func compileSeverityRules(rules []SeverityRule) ([]SeverityRule, []string) {
+	var valid []SeverityRule
+	var warnings []string
+	for i, r := range rules {
+		r.Severity = strings.ToUpper(r.Severity)
+		switch Severity(r.Severity) {
+		case SeverityCritical, SeverityWarning, SeverityInfo:
+		default:
+			warnings = append(warnings, fmt.Sprintf("severity rule %d: unknown severity %q (use CRITICAL, WARNING, or INFO)", i+1, r.Severity))
+			continue
+		}
+		switch r.Change {
+		case "", "decreased", "increased", "disabled", "enabled":
+		default:
+			warnings = append(warnings, fmt.Sprintf("severity rule %d: unknown change %q (use decreased, increased, disabled, or enabled)", i+1, r.Change))
+			continue
+		}
+		switch r.Action {
+		case "", "modified", "added", "removed":
+		default:
+			warnings = append(warnings, fmt.Sprintf("severity rule %d: unknown action %q (use modified, added, or removed)", i+1, r.Action))
+			continue
+		}
+		if _, err := path.Match(r.Resource, ""); err != nil {
+			warnings = append(warnings, fmt.Sprintf("severity rule %d: invalid resource pattern %q", i+1, r.Resource))
+			continue
+		}
+		sel, err := resources.ParseSelector(r.Selector)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("severity rule %d: invalid selector: %v", i+1, err))
+			continue
+		}
+		r.selector = sel
+		valid = append(valid, r)
+	}
+	return valid, warnings
+}
+
+// matchesTarget reports whether the rule's resource scope includes the target
+func (r SeverityRule) matchesTarget(t driftTarget) bool {
+	kind := normalizeSpecKind(t.Kind)
+	if r.Kind != "" && normalizeSpecKind(r.Kind) != kind {
+		return false
+	}
+	if r.Instance != "" && r.Instance != t.Instance {
+		return false
+	}
+	if r.Resource != "" {
+		if ok, _ := path.Match(r.Resource, t.Name); !ok {
+			return false
+		}
+	}
+	if !r.selector.Empty() && !r.selector.Matches(t.Labels) {
+		return false
+	}
+	if r.Group != "" && !groupMembers(r.Group)[kind+"/"+t.Name] {
+		return false
+	}
+	return true
+}
+
+// matchesDrift reports whether the rule's field conditions match a drift
+func (r SeverityRule) matchesDrift(d Drift) bool {
+	if r.Path != "" && !fieldPathMatches(r.Path, d.Path) {
+		return false
+	}
+	if r.Action != "" && r.Action != d.Action {
+		return false
+	}
+	if r.Change != "" && !driftChangeIs(d, r.Change) {
+		return false
+	}
+	return true
+}
+
+// applySeverityRulesTo sets the severity of each drift from the last rule that matches it
+func applySeverityRulesTo(rules []SeverityRule, t driftTarget, drifts []Drift) []Drift {
+	var scoped []SeverityRule
+	for _, r := range rules {
+		if r.matchesTarget(t) {
+			scoped = append(scoped, r)
+		}
+	}
+	for i := range drifts {
+		for _, r := range scoped {
+			if r.matchesDrift(drifts[i]) {
+				drifts[i].Severity = Severity(r.Severity)
+			}
+		}
+	}
+	return drifts
+}
+
+// applySeverityRules applies the configured severity rules to a resource's drifts on the
+// active instance
+func applySeverityRules(kind, name string, labels map[string]string, drifts []Drift) []Drift {
+	if len(severityRules) == 0 || len(drifts) == 0 {
+		return drifts
+	}
+	t := driftTarget{Instance: os.Getenv("OWLCTL_ACTIVE_INSTANCE"), Kind: kind, Name: name, Labels: labels}
+	return applySeverityRulesTo(severityRules, t, drifts)
+}
+
+// groupMembers returns the kind/name pairs of a group's specs. A group that cannot be
+// resolved has no members; the failure is reported once.
+func groupMembers(group string) map[string]bool {
+	if members, ok := severityGroupMembers[group]; ok {
+		return members
+	}
+	members, err := loadGroupMembers(group)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: severity rules for group %q ignored: %v\n", group, err)
+	}
+	severityGroupMembers[group] = members
+	return members
+}
+
+func loadGroupMembers(group string) (map[string]bool, error) {
+	members := make(map[string]bool)
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return members, err
+	}
+	groupCfg, err := cfg.GetGroup(group)
+	if err != nil {
+		return members, err
+	}
+	specs, err := cfg.ResolveGroupSpecs(groupCfg)
+	if err != nil {
+		return members, err
+	}
+	for _, p := range specs {
+		spec, err := resources.LoadResourceSpec(cfg.ResolvePath(p))
+		if err != nil {
+			return members, fmt.Errorf("failed to load spec %s: %w", p, err)
+		}
+		members[normalizeSpecKind(spec.Kind)+"/"+spec.Metadata.Name] = true
+	}
+	return members, nil
+}
+
+// applyScanSeverityRules applies the severity rules to every scanned resource, using the
+// labels recorded in state
+func applyScanSeverityRules(stateMgr *state.Manager, kinds []ScanKind) {
+	if len(severityRules) == 0 {
+		return
+	}
+	stateResources, err := stateMgr.ListResources("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: severity rules not applied: %v\n", err)
+		return
+	}
+	labels := make(map[string]map[string]string, len(stateResources))
+	for _, r := range stateResources {
+		labels[r.Name] = r.Labels
+	}
+	for i := range kinds {
+		for j := range kinds[i].Resources {
+			res := &kinds[i].Resources[j]
+			res.Drifts = applySeverityRules(kinds[i].Kind, res.Name, labels[res.Name], res.Drifts)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompileSeverityRules(t *testing.T) {
+	rules, warnings := compileSeverityRules([]SeverityRule{
+		{Path: "isDisabled", Severity: "critical"},
+		{Path: "isDisabled", Severity: "HIGH"},
+		{Path: "storage", Change: "reduced", Severity: "CRITICAL"},
+		{Action: "changed", Severity: "INFO"},
+		{Resource: "SQL-[", Severity: "INFO"},
+		{Selector: "env in (prod", Severity: "INFO"},
+	})
+	if len(rules) != 1 || rules[0].Severity != "CRITICAL" {
+		t.Errorf("Expected one valid rule with an upper-case severity, got %+v", rules)
+	}
+	if len(warnings) != 5 {
+		t.Fatalf("Expected a warning per invalid rule, got %v", warnings)
+	}
+	for i, want := range []string{"unknown severity", "unknown change", "unknown action", "invalid resource pattern", "invalid selector"} {
+		if !strings.Contains(warnings[i], want) || !strings.Contains(warnings[i], "rule") {
+			t.Errorf("warning %d = %q, want %q", i, warnings[i], want)
+		}
+	}
+}
+
+func TestApplySeverityRules(t *testing.T) {
+	defer func() { severityGroupMembers = make(map[string]map[string]bool) }()
+	severityGroupMembers = map[string]map[string]bool{"sql-tier": {"VBRJob/SQL-01": true}}
+
+	rules, warnings := compileSeverityRules([]SeverityRule{
+		{Instance: "lab", Severity: "INFO"},
+		{Kind: "VBRJob", Selector: "env=prod", Path: "storage.retentionPolicy.quantity", Change: "increased", Severity: "INFO"},
+		{Kind: "VBRJob", Selector: "env=prod", Path: "storage.retentionPolicy", Change: "decreased", Severity: "CRITICAL"},
+		{Kind: "VBRJob", Group: "sql-tier", Resource: "SQL-*", Path: "schedule", Severity: "CRITICAL"},
+		{Kind: "VBRRepository", Path: "description", Severity: "CRITICAL"},
+	})
+	if len(warnings) > 0 {
+		t.Fatal(warnings)
+	}
+	drifts := func() []Drift {
+		return []Drift{
+			{Path: "storage.retentionPolicy.quantity", Action: "modified", State: 14.0, VBR: 7.0, Severity: SeverityWarning},
+			{Path: "schedule.daily.localTime", Action: "modified", State: "22:00", VBR: "23:00", Severity: SeverityWarning},
+			{Path: "description", Action: "modified", State: "a", VBR: "b", Severity: SeverityInfo},
+		}
+	}
+	severities := func(ds []Drift) string {
+		var s []string
+		for _, d := range ds {
+			s = append(s, string(d.Severity))
+		}
+		return strings.Join(s, ",")
+	}
+
+	tests := []struct {
+		name   string
+		target driftTarget
+		want   string
+	}{
+		{"prod label, group member", driftTarget{Instance: "prod", Kind: "VBRJob", Name: "SQL-01", Labels: map[string]string{"env": "prod"}}, "CRITICAL,CRITICAL,INFO"},
+		{"not in group", driftTarget{Instance: "prod", Kind: "VBRJob", Name: "SQL-02", Labels: map[string]string{"env": "prod"}}, "CRITICAL,WARNING,INFO"},
+		{"dev label", driftTarget{Instance: "prod", Kind: "VBRJob", Name: "SQL-01", Labels: map[string]string{"env": "dev"}}, "WARNING,CRITICAL,INFO"},
+		{"lab instance", driftTarget{Instance: "lab", Kind: "VBRJob", Name: "Lab-01"}, "INFO,INFO,INFO"},
+		{"other kind", driftTarget{Instance: "prod", Kind: "VBRRepository", Name: "SQL-01", Labels: map[string]string{"env": "prod"}}, "WARNING,WARNING,CRITICAL"},
+	}
+	for _, tt := range tests {
+		if got := severities(applySeverityRulesTo(rules, tt.target, drifts())); got != tt.want {
+			t.Errorf("%s: severities = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	// Later rules override earlier ones: the increased rule only applies to increases
+	increased := []Drift{{Path: "storage.retentionPolicy.quantity", Action: "modified", State: 7.0, VBR: 14.0, Severity: SeverityWarning}}
+	prod := driftTarget{Kind: "VBRJob", Name: "Nightly", Labels: map[string]string{"env": "prod"}}
+	if got := applySeverityRulesTo(rules, prod, increased); got[0].Severity != SeverityInfo {
+		t.Errorf("Increased retention severity = %s, want INFO", got[0].Severity)
+	}
+}
+
+func TestSeverityRules_SOBRAlias(t *testing.T) {
+	defer func() { severityGroupMembers = make(map[string]map[string]bool) }()
+	severityGroupMembers = make(map[string]map[string]bool)
+
+	dir := t.TempDir()
+	sobr := "apiVersion: owlctl.veeam.com/v1\nkind: VBRSOBR\nmetadata:\n  name: SOBR-01\nspec:\n  description: x\n"
+	if err := os.WriteFile(filepath.Join(dir, "sobr.yaml"), []byte(sobr), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := "groups:\n  storage:\n    specs:\n      - sobr.yaml\n"
+	if err := os.WriteFile(filepath.Join(dir, "owlctl.yaml"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("OWLCTL_CONFIG", filepath.Join(dir, "owlctl.yaml"))
+
+	rules, warnings := compileSeverityRules([]SeverityRule{
+		{Group: "storage", Path: "description", Severity: "CRITICAL"},
+		{Kind: "VBRSOBR", Path: "immutabilityMode", Severity: "CRITICAL"},
+	})
+	if len(warnings) > 0 {
+		t.Fatal(warnings)
+	}
+	// Scan and diff report scale-out repositories under the canonical kind
+	target := driftTarget{Kind: "VBRScaleOutRepository", Name: "SOBR-01"}
+	got := applySeverityRulesTo(rules, target, []Drift{
+		{Path: "description", Action: "modified", State: "a", VBR: "b", Severity: SeverityInfo},
+		{Path: "immutabilityMode", Action: "modified", State: "a", VBR: "b", Severity: SeverityInfo},
+	})
+	for _, d := range got {
+		if d.Severity != SeverityCritical {
+			t.Errorf("%s severity = %s, want CRITICAL", d.Path, d.Severity)
+		}
+	}
+}
+
+func TestLoadSeverityOverridesRules(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("OWLCTL_SETTINGS_PATH", dir)
+	data := `{"rules": [{"kind": "VBRJob", "selector": "env=lab", "path": "isDisabled", "severity": "info"}, {"severity": "bogus"}]}`
+	if err := os.WriteFile(filepath.Join(dir, "severity-config.json"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	severityOverridesLoaded = false
+	defer func() { severityOverridesLoaded, severityRules = false, nil }()
+
+	loadSeverityOverrides()
+	if len(severityRules) != 1 || severityRules[0].Severity != "INFO" {
+		t.Fatalf("Expected the valid rule to be loaded, got %+v", severityRules)
+	}
+
+	t.Setenv("OWLCTL_ACTIVE_INSTANCE", "")
+	got := applySeverityRules("VBRJob", "Lab-01", map[string]string{"env": "lab"}, []Drift{{Path: "isDisabled", Action: "modified", State: false, VBR: true, Severity: SeverityCritical}})
+	if got[0].Severity != SeverityInfo {
+		t.Errorf("Severity = %s, want INFO", got[0].Severity)
+	}
+}
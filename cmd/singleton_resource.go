@@ -0,0 +1,405 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/shapedthought/owlctl/models"
+	"github.com/shapedthought/owlctl/resources"
+	"github.com/shapedthought/owlctl/state"
+	"github.com/shapedthought/owlctl/utils"
+	"github.com/shapedthought/owlctl/vhttp"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// SingletonResourceConfig defines a VBR settings resource that exists exactly once per server
+// (configuration backup, email settings, traffic rules, general options).
+// Singletons are stored in state under a fixed key and are read and written via a single
+// endpoint with no ID in the path.
+type SingletonResourceConfig struct {
+	// Kind is the resource kind used in YAML specs and state (e.g., "VBRConfigurationBackup")
+	Kind string
+
+	// StateKey is the fixed state key for the resource (e.g., "ConfigurationBackup")
+	StateKey string
+
+	// Endpoint is the VBR API endpoint used for both GET and PUT (e.g., "configBackup")
+	Endpoint string
+
+	// CommandName is the CLI command name (e.g., "config-backup")
+	CommandName string
+
+	// DisplayName is the human-readable name used in output (e.g., "Configuration backup settings")
+	DisplayName string
+
+	// IgnoreFields are runtime fields excluded from drift detection, export and apply
+	IgnoreFields map[string]bool
+
+	// SeverityMap classifies drift fields by severity
+	SeverityMap SeverityMap
+
+	// EnhanceDrifts optionally adjusts severities based on the direction of change.
+	// If nil, severities from SeverityMap are used as-is.
+	EnhanceDrifts func(drifts []Drift) []Drift
+
+	// PreserveFields are ignored fields that apply sends back with their live value. The
+	// endpoint's PUT replaces the whole object, so leaving them out of the payload would
+	// clear them (e.g. the SMTP password, which is never stored in specs or state).
+	PreserveFields []string
+
+	// Fetch optionally replaces the default GET of Endpoint, for resources whose
+	// API response must be normalised before it is stored or compared (e.g. user lists).
+	Fetch func(profile models.Profile) (map[string]interface{}, error)
+}
+
+// singletonCmdFlags holds the per-command flag values for a singleton command tree
+type singletonCmdFlags struct {
+	exportOutput string
+	applyOverlay string
+	applyDryRun  bool
+}
+
+// newSingletonCmd builds the snapshot/diff/export/apply command tree for a singleton resource
+func newSingletonCmd(sc SingletonResourceConfig, short, long string) *cobra.Command {
+	flags := &singletonCmdFlags{}
+
+	parent := &cobra.Command{
+		Use:   sc.CommandName,
+		Short: short,
+		Long:  long,
+	}
+
+	snapshot := &cobra.Command{
+		Use:   "snapshot",
+		Short: fmt.Sprintf("Snapshot %s to state", lowerFirst(sc.DisplayName)),
+		Long:  fmt.Sprintf("Fetches the current VBR %s and saves them to state.json.", lowerFirst(sc.DisplayName)),
+		Run: func(cmd *cobra.Command, args []string) {
+			snapshotSingleton(sc)
+		},
+	}
+
+	diff := &cobra.Command{
+		Use:   "diff",
+		Short: fmt.Sprintf("Detect drift in %s", lowerFirst(sc.DisplayName)),
+		Long: fmt.Sprintf(`Compares the snapshotted %s in state against the live VBR configuration.
+With --against specs, compares against the spec files in the repository instead.
+
+Exit codes:
+  0 = No drift
+  3 = Drift detected (INFO or WARNING)
+  4 = Critical drift detected
+  1 = Error
+`, lowerFirst(sc.DisplayName)),
+		Run: func(cmd *cobra.Command, args []string) {
+			if againstSpecs() {
+				diffAgainstSpecs(singletonDiffConfig(sc), "")
+			} else {
+				diffSingleton(sc)
+			}
+		},
+	}
+	addSeverityFlags(diff)
+	addAgainstFlag(diff)
+
+	export := &cobra.Command{
+		Use:   "export",
+		Short: fmt.Sprintf("Export %s to YAML", lowerFirst(sc.DisplayName)),
+		Long:  fmt.Sprintf("Fetches the current VBR %s and exports them as a declarative YAML spec.", lowerFirst(sc.DisplayName)),
+		Run: func(cmd *cobra.Command, args []string) {
+			exportSingleton(sc, flags.exportOutput)
+		},
+	}
+	export.Flags().StringVarP(&flags.exportOutput, "output", "o", "", "Output file path (default: stdout)")
+
+	apply := &cobra.Command{
+		Use:   "apply <file>",
+		Short: fmt.Sprintf("Apply %s from a YAML file", lowerFirst(sc.DisplayName)),
+		Long: fmt.Sprintf(`Applies %s from a declarative YAML spec to VBR.
+
+Uses PUT /api/v1/%s to update settings.
+
+Supports --overlay for environment-specific overrides and --dry-run to preview changes.
+`, lowerFirst(sc.DisplayName), sc.Endpoint),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			applySingleton(sc, args[0], flags.applyOverlay, flags.applyDryRun)
+		},
+	}
+	apply.Flags().StringVar(&flags.applyOverlay, "overlay", "", "Overlay file to merge with the spec before applying")
+	apply.Flags().BoolVar(&flags.applyDryRun, "dry-run", false, "Preview changes without applying")
+	addApplyWaitFlags(apply)
+
+	parent.AddCommand(snapshot, diff, export, apply)
+	return parent
+}
+
+// fetchSingleton retrieves the live singleton resource from VBR as a map
+func fetchSingleton(sc SingletonResourceConfig, profile models.Profile) (map[string]interface{}, error) {
+	if sc.Fetch != nil {
+		return sc.Fetch(profile)
+	}
+
+	rawData := vhttp.GetData[json.RawMessage](sc.Endpoint, profile)
+
+	var spec map[string]interface{}
+	if err := json.Unmarshal(rawData, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse %s response: %w", sc.Endpoint, err)
+	}
+	return spec, nil
+}
+
+// singletonApplyConfig returns the ResourceApplyConfig used to apply a singleton resource
+func singletonApplyConfig(sc SingletonResourceConfig) ResourceApplyConfig {
+	return ResourceApplyConfig{
+		Kind:         sc.Kind,
+		Endpoint:     sc.Endpoint,
+		IgnoreFields: sc.IgnoreFields,
+		Mode:         ApplyUpdateOnly,
+		Singleton:    true,
+		FetchCurrent: func(name string, profile models.Profile) (json.RawMessage, string, error) {
+			rawData := vhttp.GetData[json.RawMessage](sc.Endpoint, profile)
+			return rawData, sc.Endpoint, nil
+		},
+		PreparePayload: func(spec, existing map[string]interface{}) (map[string]interface{}, error) {
+			return preserveLiveFields(spec, existing, sc.PreserveFields), nil
+		},
+	}
+}
+
+// preserveLiveFields copies fields from the live resource into an update payload
+func preserveLiveFields(payload, existing map[string]interface{}, fields []string) map[string]interface{} {
+	for _, f := range fields {
+		if v, ok := existing[f]; ok {
+			payload[f] = v
+		}
+	}
+	return payload
+}
+
+// singletonDiffConfig returns the GroupDiffConfig used to compare a singleton resource against its spec
+func singletonDiffConfig(sc SingletonResourceConfig) GroupDiffConfig {
+	return GroupDiffConfig{
+		Kind:        sc.Kind,
+		DisplayName: lowerFirst(sc.DisplayName),
+		PluralName:  lowerFirst(sc.DisplayName),
+		StateKey:    sc.StateKey,
+		FetchCurrent: func(name string, profile models.Profile) (json.RawMessage, string, error) {
+			spec, err := fetchSingleton(sc, profile)
+			if err != nil {
+				return nil, "", err
+			}
+			rawData, err := json.Marshal(spec)
+			return rawData, sc.Endpoint, err
+		},
+		DetectDrift: func(desired, live map[string]interface{}) []Drift {
+			return detectSingletonDrift(sc, desired, live)
+		},
+	}
+}
+
+// detectSingletonDrift compares a singleton's state spec against its live value and classifies the result
+func detectSingletonDrift(sc SingletonResourceConfig, stateSpec, liveSpec map[string]interface{}) []Drift {
+	drifts := detectDrift(stateSpec, liveSpec, sc.IgnoreFields)
+	drifts = classifyDrifts(drifts, sc.SeverityMap)
+	if sc.EnhanceDrifts != nil {
+		drifts = sc.EnhanceDrifts(drifts)
+	}
+	return drifts
+}
+
+func snapshotSingleton(sc SingletonResourceConfig) {
+	settings := utils.ReadSettings()
+	profile := utils.GetCurrentProfile()
+
+	if settings.SelectedProfile != "vbr" {
+		log.Fatal("This command only works with VBR at the moment.")
+	}
+
+	spec, err := fetchSingleton(sc, profile)
+	if err != nil {
+		log.Fatalf("Failed to fetch %s: %v", lowerFirst(sc.DisplayName), err)
+	}
+
+	if err := saveSingletonToState(sc, spec); err != nil {
+		log.Fatalf("Failed to save %s state: %v", lowerFirst(sc.DisplayName), err)
+	}
+
+	stateMgr := state.NewManager()
+	fmt.Printf("%s snapshot saved.\nState: %s\n", sc.DisplayName, stateMgr.GetStatePath())
+}
+
+// saveSingletonToState stores a singleton resource under its fixed state key with origin
+// "observed". Ignored fields, such as credentials, are not stored.
+func saveSingletonToState(sc SingletonResourceConfig, spec map[string]interface{}) error {
+	stateMgr := state.NewManager()
+	removeIgnoreFields(spec, sc.IgnoreFields)
+
+	currentUser := "unknown"
+	if usr, err := user.Current(); err == nil {
+		currentUser = usr.Username
+	}
+
+	var existingHistory []state.ResourceEvent
+	var existingLabels map[string]string
+	if existing, err := stateMgr.GetResource(sc.StateKey); err == nil {
+		existingHistory = existing.History
+		existingLabels = existing.Labels
+	}
+
+	resource := &state.Resource{
+		Type:          sc.Kind,
+		ID:            sc.Endpoint,
+		Name:          sc.StateKey,
+		LastApplied:   time.Now(),
+		LastAppliedBy: currentUser,
+		Origin:        "observed",
+		Labels:        existingLabels,
+		Spec:          spec,
+		History:       existingHistory,
+	}
+
+	resource.AddEvent(state.NewEvent("snapshotted", currentUser))
+
+	if err := stateMgr.UpdateResource(resource); err != nil {
+		return fmt.Errorf("failed to update state: %w", err)
+	}
+
+	return nil
+}
+
+func diffSingleton(sc SingletonResourceConfig) {
+	loadSeverityOverrides()
+	settings := utils.ReadSettings()
+	profile := utils.GetCurrentProfile()
+
+	if settings.SelectedProfile != "vbr" {
+		log.Fatal("This command only works with VBR at the moment.")
+	}
+
+	stateMgr := state.NewManager()
+	stateEntry, err := stateMgr.GetResource(sc.StateKey)
+	if err != nil {
+		fmt.Printf("No snapshot found for %s. Run 'owlctl %s snapshot' first.\n", lowerFirst(sc.DisplayName), sc.CommandName)
+		os.Exit(ExitError)
+	}
+
+	if stateEntry.Type != sc.Kind {
+		log.Fatalf("State entry '%s' is not a %s resource (type: %s).", sc.StateKey, sc.Kind, stateEntry.Type)
+	}
+
+	liveSpec, err := fetchSingleton(sc, profile)
+	if err != nil {
+		log.Fatalf("Failed to fetch %s: %v", lowerFirst(sc.DisplayName), err)
+	}
+
+	drifts := detectSingletonDrift(sc, stateEntry.Spec, liveSpec)
+	minSev := parseSeverityFlag()
+	drifts = applySeverityRules(sc.Kind, sc.StateKey, stateEntry.Labels, drifts)
+	drifts = suppressAckedDrifts(sc.StateKey, drifts)
+	drifts = filterDriftsBySeverity(drifts, minSev)
+
+	if len(drifts) == 0 {
+		fmt.Println(noDriftMessage(fmt.Sprintf("%s match state.", sc.DisplayName), minSev))
+		ciExit(ExitSuccess)
+	}
+
+	printSecuritySummary(drifts)
+	reportDrifts(sc.Kind, sc.StateKey, drifts)
+	fmt.Printf("%s drift:\n", sc.DisplayName)
+	for _, d := range drifts {
+		printDriftWithSeverity(d)
+	}
+
+	ciExit(exitCodeForDrifts(drifts))
+}
+
+func exportSingleton(sc SingletonResourceConfig, output string) {
+	settings := utils.ReadSettings()
+	profile := utils.GetCurrentProfile()
+
+	if settings.SelectedProfile != "vbr" {
+		log.Fatal("This command only works with VBR at the moment.")
+	}
+
+	spec, err := fetchSingleton(sc, profile)
+	if err != nil {
+		log.Fatalf("Failed to fetch %s: %v", lowerFirst(sc.DisplayName), err)
+	}
+
+	yamlContent, err := convertSingletonToYAML(sc, spec)
+	if err != nil {
+		log.Fatalf("Failed to marshal to YAML: %v", err)
+	}
+
+	if output != "" {
+		if err := os.WriteFile(output, yamlContent, 0644); err != nil {
+			log.Fatalf("Failed to write file: %v", err)
+		}
+		fmt.Printf("%s exported to %s\n", sc.DisplayName, output)
+	} else {
+		fmt.Print(string(yamlContent))
+	}
+}
+
+// convertSingletonToYAML strips runtime fields and renders a singleton as a declarative YAML spec
+func convertSingletonToYAML(sc SingletonResourceConfig, spec map[string]interface{}) ([]byte, error) {
+	removeIgnoreFields(spec, sc.IgnoreFields)
+
+	resourceSpec := resources.ResourceSpec{
+		APIVersion: "owlctl.veeam.com/v1",
+		Kind:       sc.Kind,
+		Metadata: resources.Metadata{
+			Name: sc.StateKey,
+		},
+		Spec: spec,
+	}
+
+	header := fmt.Sprintf("# VBR %s\n# Singleton resource — one per VBR server\n#\n# Apply with: owlctl %s apply <file>\n\n", sc.DisplayName, sc.CommandName)
+
+	yamlBytes, err := yaml.Marshal(resourceSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(header), yamlBytes...), nil
+}
+
+func applySingleton(sc SingletonResourceConfig, specFile, overlay string, dryRun bool) {
+	settings := utils.ReadSettings()
+	profile := utils.GetCurrentProfile()
+
+	if settings.SelectedProfile != "vbr" {
+		log.Fatal("This command only works with VBR at the moment.")
+	}
+
+	result := applyWithOptionalOverlay(specFile, overlay, singletonApplyConfig(sc), profile, dryRun)
+
+	if result.Error != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", result.Error)
+		outcome := DetermineApplyOutcome([]ApplyResult{result})
+		ciExit(ExitCodeForOutcome(outcome))
+	}
+
+	if result.DryRun {
+		return // dry-run output already printed
+	}
+
+	fmt.Printf("\nSuccessfully updated %s.\n", lowerFirst(sc.DisplayName))
+}
+
+// lowerFirst lower-cases the first letter of a display name for use mid-sentence
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	b := []byte(s)
+	if b[0] >= 'A' && b[0] <= 'Z' {
+		b[0] += 'a' - 'A'
+	}
+	return string(b)
+}
@@ -34,6 +34,9 @@ Examples:
 
   # List resources for a specific instance
   owlctl state list --instance vbr-prod
+
+  # List resources whose labels match a selector
+  owlctl state list -l "env=prod,tier in (gold,silver)"
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		listStateResources()
@@ -81,7 +84,7 @@ var stateHistoryCmd = &cobra.Command{
 	Long: `Display the audit trail of actions taken on a resource.
 
 Each entry shows:
-- Action type (snapshotted, created, applied)
+- Action type (snapshotted, created, applied, acknowledged)
 - Timestamp
 - User who performed the action
 - Fields changed (for apply/create actions)
@@ -101,6 +104,7 @@ Examples:
 
 func listStateResources() {
 	stateMgr := state.NewManager()
+	selector := parseLabelSelector()
 
 	if !stateMgr.StateExists() {
 		fmt.Println("No state file found. Run a snapshot or apply command to initialise state.")
@@ -160,6 +164,9 @@ func listStateResources() {
 
 		for _, resName := range resourceNames {
 			r := inst.Resources[resName]
+			if !selector.Matches(r.Labels) {
+				continue
+			}
 			lastApplied := r.LastApplied.Format("2006-01-02 15:04")
 			if r.LastApplied.IsZero() {
 				lastApplied = "-"
@@ -175,6 +182,9 @@ func listStateResources() {
 	if filterInstance != "" {
 		fmt.Printf(" in instance '%s'", filterInstance)
 	}
+	if labelSelector != "" {
+		fmt.Printf(" matching selector %q", labelSelector)
+	}
 	fmt.Printf(" (state v%d: %s)\n", st.Version, stateMgr.GetStatePath())
 }
 
@@ -237,6 +247,17 @@ func showStateResource(resourceName string) {
 	fmt.Printf("Type:     %s\n", found.Type)
 	fmt.Printf("ID:       %s\n", found.ID)
 	fmt.Printf("Origin:   %s\n", found.Origin)
+	if len(found.Labels) > 0 {
+		keys := make([]string, 0, len(found.Labels))
+		for k := range found.Labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fmt.Println("Labels:")
+		for _, k := range keys {
+			fmt.Printf("  %s=%s\n", k, found.Labels[k])
+		}
+	}
 	if !found.LastApplied.IsZero() {
 		fmt.Printf("Last Applied: %s by %s\n", found.LastApplied.Format("2006-01-02 15:04:05"), found.LastAppliedBy)
 	}
@@ -289,12 +310,17 @@ func showResourceHistory(resourceName string) {
 		} else {
 			fmt.Printf("  %s - %s by %s\n", timestamp, event.Action, event.User)
 		}
+		if event.Note != "" {
+			fmt.Printf("      %s\n", event.Note)
+		}
 	}
 
 	fmt.Printf("\nShowing %d event(s). Max retained: %d\n", len(resource.History), state.DefaultMaxHistoryEvents)
 }
 
 func init() {
+	addSelectorFlag(stateListCmd, "Only list resources whose labels match a selector (e.g. env=prod)")
+
 	stateCmd.AddCommand(stateListCmd)
 	stateCmd.AddCommand(stateShowCmd)
 	stateCmd.AddCommand(statePathCmd)
@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/shapedthought/owlctl/config"
+	"github.com/shapedthought/owlctl/resources"
+	"github.com/spf13/cobra"
+)
+
+var (
+	validateGroup string
+	validateKind  string
+	schemaDir     string
+	noValidate    bool
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [spec-file...]",
+	Short: "Check spec files against the JSON Schema for their kind",
+	Long: `Validate checks spec files offline against the JSON Schema for their kind, catching
+misspelt fields, wrong types and invalid values before anything is sent to VBR.
+No connection to VBR is needed.
+
+Profile and Overlay files are checked against the resource kind they most resemble,
+or against --kind when given. With --group, every spec in the group is validated
+after merging with the group's profile and overlay chains.
+
+The same checks run automatically before every apply and plan. Use --no-validate to
+skip them if a schema rejects a field VBR accepts.
+
+Examples:
+  # Validate individual files
+  owlctl validate specs/jobs/*.yaml
+
+  # Validate an overlay as a job overlay
+  owlctl validate overlays/prod.yaml --kind VBRJob
+
+  # Validate every merged spec in a group
+  owlctl validate --group sql-tier
+
+Exit Codes:
+  0 - All specs are valid
+  1 - One or more specs are invalid or could not be read
+`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Validation is offline; only spec variables need configuring
+		return configureSpecVariables(nil, nil)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if validateGroup != "" && len(args) > 0 {
+			log.Fatal("Cannot use --group with spec file arguments")
+		}
+		if validateGroup == "" && len(args) == 0 {
+			log.Fatal("Provide spec files or use --group")
+		}
+		if validateGroup != "" {
+			runValidateGroup(validateGroup)
+			return
+		}
+		runValidateFiles(args)
+	},
+}
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Work with the JSON Schemas for spec files",
+	Long: `The JSON Schemas used by "owlctl validate" are built into owlctl. Export them to get
+completion and inline errors in editors that support JSON Schema.`,
+}
+
+var schemaExportCmd = &cobra.Command{
+	Use:   "export [kind]",
+	Short: "Export the JSON Schema for one kind, or write all of them to a directory",
+	Long: `Export prints the JSON Schema for a kind to stdout, or with no kind writes
+<Kind>.schema.json for every kind to --dir.
+
+Examples:
+  # Print the job schema
+  owlctl schema export VBRJob > VBRJob.schema.json
+
+  # Write every schema to ./schemas
+  owlctl schema export -d schemas
+
+Reference an exported schema from a spec file for editor support (YAML language server):
+  # yaml-language-server: $schema=./schemas/VBRJob.schema.json
+`,
+	Args: cobra.MaximumNArgs(1),
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 1 {
+			data, err := resources.Schema(args[0])
+			if err != nil {
+				log.Fatalf("%v (supported kinds: %s)", err, strings.Join(resources.SchemaKinds, ", "))
+			}
+			os.Stdout.Write(data)
+			return
+		}
+
+		if err := os.MkdirAll(schemaDir, 0755); err != nil {
+			log.Fatalf("Failed to create %s: %v", schemaDir, err)
+		}
+		for _, kind := range resources.SchemaKinds {
+			data, err := resources.Schema(kind)
+			if err != nil {
+				log.Fatal(err)
+			}
+			path := filepath.Join(schemaDir, resources.SchemaFileName(kind))
+			if err := os.WriteFile(path, data, 0644); err != nil {
+				log.Fatalf("Failed to write %s: %v", path, err)
+			}
+			fmt.Printf("Wrote %s\n", path)
+		}
+	},
+}
+
+func runValidateFiles(paths []string) {
+	invalid := 0
+	for _, path := range paths {
+		errs, err := resources.ValidateSpecFile(path, validateKind)
+		if err != nil {
+			errs = resources.ValidationErrors{{Message: err.Error()}}
+		}
+		if !printValidationResult(path, errs, nil) {
+			invalid++
+		}
+	}
+	finishValidation(len(paths), invalid)
+}
+
+func runValidateGroup(group string) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load owlctl.yaml: %v", err)
+	}
+	groupCfg, err := lookupGroup(cfg, group)
+	if err != nil {
+		log.Fatalf("Group error: %v", err)
+	}
+
+	targets, err := loadMergedGroupSpecs(cfg, groupCfg)
+	if err != nil {
+		log.Fatalf("Group error: %v", err)
+	}
+	if len(targets) == 0 {
+		noGroupSpecs(group, "resource")
+	}
+
+	fmt.Printf("Validating group: %s (%d specs)\n\n", groupLabel(group), len(targets))
+
+	invalid := 0
+	for _, t := range targets {
+		var errs resources.ValidationErrors
+		if t.Error != nil {
+			errs = resources.ValidationErrors{{Message: t.Error.Error()}}
+		} else {
+			errs = resources.ValidateResourceSpec(t.Spec)
+		}
+		if !printValidationResult(t.SpecPath, errs, t.Provenance) {
+			invalid++
+		}
+	}
+	finishValidation(len(targets), invalid)
+}
+
+// printValidationResult prints a spec's validation errors and reports whether it was valid
+func printValidationResult(label string, errs resources.ValidationErrors, provenance map[string]string) bool {
+	if len(errs) == 0 {
+		fmt.Printf("✓ %s\n", label)
+		return true
+	}
+	fmt.Printf("✗ %s\n", label)
+	for _, line := range validationErrorLines(errs, provenance) {
+		fmt.Printf("    %s\n", line)
+	}
+	return false
+}
+
+func finishValidation(total, invalid int) {
+	fmt.Println()
+	if invalid > 0 {
+		fmt.Printf("%d of %d specs invalid\n", invalid, total)
+		os.Exit(ExitError)
+	}
+	fmt.Printf("All %d specs valid\n", total)
+}
+
+// validateSpec checks a loaded or merged spec against its schema before it is planned or
+// applied. Errors name the file that set each invalid field when provenance is known.
+func validateSpec(spec resources.ResourceSpec, provenance map[string]string) error {
+	if noValidate {
+		return nil
+	}
+	errs := resources.ValidateResourceSpec(spec)
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("spec failed schema validation (use --no-validate to skip):\n  %s",
+		strings.Join(validationErrorLines(errs, provenance), "\n  "))
+}
+
+// validationErrorLines formats validation errors, annotating each with its source file
+func validationErrorLines(errs resources.ValidationErrors, provenance map[string]string) []string {
+	lines := make([]string, len(errs))
+	for i, e := range errs {
+		lines[i] = e.String()
+		if source := validationErrorSource(e.Path, provenance); source != "" {
+			lines[i] += fmt.Sprintf(" (from %s)", source)
+		}
+	}
+	return lines
+}
+
+var arrayIndexPattern = regexp.MustCompile(`\[\d+\]`)
+
+// validationErrorSource finds the file that set the field at an error path. Provenance is
+// keyed by spec field path without array indexes, so the nearest recorded ancestor is used.
+func validationErrorSource(path string, provenance map[string]string) string {
+	if len(provenance) == 0 {
+		return ""
+	}
+	field, ok := strings.CutPrefix(arrayIndexPattern.ReplaceAllString(path, ""), "spec.")
+	if !ok {
+		return ""
+	}
+	for field != "" {
+		if source := resources.ProvenanceFor(provenance, field); source != "" {
+			return source
+		}
+		i := strings.LastIndex(field, ".")
+		if i < 0 {
+			break
+		}
+		field = field[:i]
+	}
+	return ""
+}
+
+func init() {
+	validateCmd.Flags().StringVar(&validateGroup, "group", "", "Validate every spec in a named group from owlctl.yaml (after merging)")
+	validateCmd.Flags().StringVar(&validateKind, "kind", "", "Resource kind to check Profile and Overlay files against")
+	schemaExportCmd.Flags().StringVarP(&schemaDir, "dir", "d", ".", "Directory to write schemas to when no kind is given")
+	rootCmd.PersistentFlags().BoolVar(&noValidate, "no-validate", false, "Skip schema validation of specs before plan and apply")
+
+	schemaCmd.AddCommand(schemaExportCmd)
+	rootCmd.AddCommand(validateCmd)
+	rootCmd.AddCommand(schemaCmd)
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shapedthought/owlctl/resources"
+)
+
+func TestValidationErrorSource(t *testing.T) {
+	provenance := map[string]string{
+		"storage.retentionPolicy.quantity": "overlays/prod.yaml",
+		"virtualMachines.includes":         "specs/sql.yaml",
+	}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"spec.storage.retentionPolicy.quantity", "overlays/prod.yaml"},
+		{"spec.virtualMachines.includes[1].hostname", "specs/sql.yaml"},
+		{"spec.description", ""},
+		{"metadata.name", ""},
+	}
+	for _, tt := range tests {
+		if got := validationErrorSource(tt.path, provenance); got != tt.want {
+			t.Errorf("validationErrorSource(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestValidateSpec(t *testing.T) {
+	spec := resources.ResourceSpec{
+		APIVersion: resources.APIVersion,
+		Kind:       resources.KindVBRJob,
+		Metadata:   resources.Metadata{Name: "SQL Backup"},
+		Spec: map[string]interface{}{
+			"type":    "VSphereBackup",
+			"storage": map[string]interface{}{"retentionPolicy": map[string]interface{}{"quantiy": 30}},
+		},
+	}
+	provenance := map[string]string{"storage.retentionPolicy.quantiy": "overlays/prod.yaml"}
+
+	err := validateSpec(spec, provenance)
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	if !strings.Contains(err.Error(), `spec.storage.retentionPolicy.quantiy: unknown field (did you mean "quantity"?) (from overlays/prod.yaml)`) {
+		t.Errorf("error = %v", err)
+	}
+
+	noValidate = true
+	defer func() { noValidate = false }()
+	if err := validateSpec(spec, provenance); err != nil {
+		t.Errorf("--no-validate should skip validation, got %v", err)
+	}
+}
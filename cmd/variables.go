@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/shapedthought/owlctl/config"
+	"github.com/shapedthought/owlctl/resources"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	varFlags   []string
+	varFiles   []string
+	strictVars bool
+)
+
+// configureSpecVariables sets the ${var} values used by every spec load. Layers, lowest to
+// highest precedence: owlctl.yaml vars, the active instance's vars, the group's vars,
+// --var-file files (in order), then --var flags. Environment variables are consulted for
+// names that are not set by any layer.
+func configureSpecVariables(cfg *config.VCLIConfig, groupCfg *config.GroupConfig) error {
+	if cfg == nil {
+		// Spec variables are optional; a missing or broken owlctl.yaml is reported by the
+		// commands that need it
+		loaded, err := config.LoadConfig()
+		if err != nil {
+			loaded = &config.VCLIConfig{}
+		}
+		cfg = loaded
+	}
+
+	// A group's instance applies unless --instance overrides it, even when the command
+	// doesn't connect (e.g. group render)
+	instance := os.Getenv("OWLCTL_ACTIVE_INSTANCE")
+	if groupCfg != nil && groupCfg.Instance != "" && instanceFlag == "" {
+		instance = groupCfg.Instance
+	}
+	vars := cfg.Variables(instance, groupCfg)
+
+	for _, path := range varFiles {
+		fileVars, err := loadVarFile(path)
+		if err != nil {
+			return err
+		}
+		for k, v := range fileVars {
+			vars[k] = v
+		}
+	}
+
+	for _, kv := range varFlags {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return fmt.Errorf("invalid --var %q: expected name=value", kv)
+		}
+		vars[strings.TrimSpace(name)] = value
+	}
+
+	resources.SetSpecVariables(vars, strictVars || cfg.StrictVars)
+	return nil
+}
+
+// applyGroupVariables layers a group's vars (and its instance's vars) over the spec variables
+func applyGroupVariables(cfg *config.VCLIConfig, groupCfg config.GroupConfig) {
+	if err := configureSpecVariables(cfg, &groupCfg); err != nil {
+		log.Fatalf("Variable error: %v", err)
+	}
+}
+
+// loadVarFile reads a YAML or JSON file of name: value pairs
+func loadVarFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read --var-file %s: %w", path, err)
+	}
+
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse --var-file %s: %w", path, err)
+	}
+
+	vars := make(map[string]string, len(raw))
+	for k, v := range raw {
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+			return nil, fmt.Errorf("--var-file %s: variable %q must be a scalar value", path, k)
+		case nil:
+			vars[k] = ""
+		default:
+			vars[k] = fmt.Sprint(v)
+		}
+	}
+	return vars, nil
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringArrayVar(&varFlags, "var", nil, "Set a spec variable (name=value); repeatable")
+	rootCmd.PersistentFlags().StringArrayVar(&varFiles, "var-file", nil, "YAML/JSON file of spec variables; repeatable")
+	rootCmd.PersistentFlags().BoolVar(&strictVars, "strict-vars", false, "Fail when a spec references an undefined variable")
+}
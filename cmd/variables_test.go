@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shapedthought/owlctl/config"
+	"github.com/shapedthought/owlctl/resources"
+)
+
+func TestLoadVarFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	vars, err := loadVarFile(write("vars.yaml", "site: london\ndays: 30\nenabled: true\nempty:\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{"site": "london", "days": "30", "enabled": "true", "empty": ""}
+	for k, v := range want {
+		if vars[k] != v {
+			t.Errorf("vars[%q] = %q, want %q", k, vars[k], v)
+		}
+	}
+
+	vars, err = loadVarFile(write("vars.json", `{"site": "paris", "days": 7}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if vars["site"] != "paris" || vars["days"] != "7" {
+		t.Errorf("json vars = %v", vars)
+	}
+
+	if _, err := loadVarFile(write("nested.yaml", "site:\n  name: london\n")); err == nil || !strings.Contains(err.Error(), "scalar") {
+		t.Errorf("error = %v, want scalar error", err)
+	}
+	if _, err := loadVarFile(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestConfigureSpecVariables_Precedence(t *testing.T) {
+	dir := t.TempDir()
+	varFile := filepath.Join(dir, "vars.yaml")
+	if err := os.WriteFile(varFile, []byte("site: file\ntier: file\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	origFlags, origFiles := varFlags, varFiles
+	defer func() { varFlags, varFiles = origFlags, origFiles }()
+	varFiles = []string{varFile}
+	varFlags = []string{"tier=flag"}
+
+	cfg := &config.VCLIConfig{Vars: map[string]string{"site": "config", "region": "config"}}
+	if err := configureSpecVariables(cfg, &config.GroupConfig{Vars: map[string]string{"site": "group"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	defer resources.SetSpecVariables(nil, false)
+
+	specPath := filepath.Join(dir, "spec.yaml")
+	specYAML := "apiVersion: owlctl.veeam.com/v1\nkind: VBRJob\nmetadata:\n  name: job\nspec:\n  region: ${region}\n  site: ${site}\n  tier: ${tier}\n"
+	if err := os.WriteFile(specPath, []byte(specYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	spec, err := resources.LoadResourceSpec(specPath)
+	if err != nil {
+		t.Fatalf("LoadResourceSpec: %v", err)
+	}
+
+	tests := map[string]string{"region": "config", "site": "file", "tier": "flag"}
+	for name, want := range tests {
+		if got := spec.Spec[name]; got != want {
+			t.Errorf("%s = %v, want %q", name, got, want)
+		}
+	}
+
+	varFlags = []string{"novalue"}
+	if err := configureSpecVariables(cfg, nil); err == nil || !strings.Contains(err.Error(), "name=value") {
+		t.Errorf("error = %v, want invalid --var error", err)
+	}
+}
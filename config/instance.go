@@ -57,6 +57,59 @@ func ResolveInstance(cfg *VCLIConfig, name string) (*ResolvedInstance, error) {
 	return resolved, nil
 }
 
+// instanceEnvVars are the environment variables ActivateInstance sets
+var instanceEnvVars = []string{
+	"OWLCTL_URL",
+	"OWLCTL_USERNAME",
+	"OWLCTL_PASSWORD",
+	"OWLCTL_KEYCHAIN_KEY",
+	"OWLCTL_ACTIVE_INSTANCE",
+	"OWLCTL_ACTIVE_PRODUCT",
+}
+
+// InstanceEnv is a saved copy of the environment variables ActivateInstance sets. A nil
+// value means the variable was unset.
+type InstanceEnv map[string]*string
+
+// startupEnv is the instance environment as it was when the process started, before the
+// default instance or --instance was activated
+var startupEnv = SaveInstanceEnv()
+
+// SaveInstanceEnv returns the current values of the environment variables ActivateInstance sets
+func SaveInstanceEnv() InstanceEnv {
+	env := make(InstanceEnv, len(instanceEnvVars))
+	for _, name := range instanceEnvVars {
+		if value, ok := os.LookupEnv(name); ok {
+			env[name] = &value
+		} else {
+			env[name] = nil
+		}
+	}
+	return env
+}
+
+// StartupInstanceEnv returns the instance environment as it was when the process started
+func StartupInstanceEnv() InstanceEnv {
+	return startupEnv
+}
+
+// Restore undoes ActivateInstance: it resets the environment variables to the saved values
+// and clears the port and settings overrides and the in-process token cache. Call it before
+// activating instances in turn, so one instance's credentials, port or insecure setting are
+// not carried over to the next.
+func (e InstanceEnv) Restore() {
+	auth.ClearProcessTokenCache()
+	for _, name := range instanceEnvVars {
+		if value := e[name]; value != nil {
+			os.Setenv(name, *value)
+		} else {
+			os.Unsetenv(name)
+		}
+	}
+	utils.ClearProfilePortOverride()
+	utils.ClearSettingsOverride()
+}
+
 // ActivateInstance sets process-global state so that existing vhttp/auth code
 // picks up this instance's connection parameters without any call-site changes.
 //
@@ -3,6 +3,8 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 )
 
@@ -387,3 +389,97 @@ groups:
 		t.Errorf("Expected first spec to be explicit, got %s", specs[0])
 	}
 }
+
+func TestGroupLabelSelector(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "owlctl-selector-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	files := map[string]string{
+		"specs/jobs/sql-prod.yaml":  "kind: VBRJob\nmetadata:\n  name: sql-prod\n  labels:\n    env: prod\n    tier: gold\n",
+		"specs/jobs/web-prod.yaml":  "kind: VBRJob\nmetadata:\n  name: web-prod\n  labels:\n    env: prod\n    tier: bronze\n",
+		"specs/jobs/sql-dev.yaml":   "kind: VBRJob\nmetadata:\n  name: sql-dev\n  labels:\n    env: dev\n    tier: gold\n",
+		"profiles/gold.yaml":        "kind: VBRJobProfile\nmetadata:\n  name: gold\n  labels:\n    env: prod\n",
+		"ci/pipeline.yaml":          "stages: [build]\n",
+		".hidden/skip.yaml":         "kind: VBRJob\nmetadata:\n  name: hidden\n  labels:\n    env: prod\n",
+		"specs/repos/repo-prod.yml": "kind: VBRRepository\nmetadata:\n  name: repo-prod\n  labels:\n    env: prod\n",
+	}
+	for rel, content := range files {
+		path := filepath.Join(tmpDir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", rel, err)
+		}
+	}
+
+	configYAML := `groups:
+  prod:
+    selector: env=prod
+  gold-prod:
+    selector: "tier in (gold,silver)"
+    matchLabels:
+      env: prod
+  filtered-dir:
+    specsDir: specs/jobs
+    selector: env!=prod
+  bad:
+    selector: "tier in (gold"
+`
+	configPath := filepath.Join(tmpDir, "owlctl.yaml")
+	if err := os.WriteFile(configPath, []byte(configYAML), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := LoadConfigFrom(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	tests := []struct {
+		group string
+		want  []string
+	}{
+		{"prod", []string{"specs/jobs/sql-prod.yaml", "specs/jobs/web-prod.yaml", "specs/repos/repo-prod.yml"}},
+		{"gold-prod", []string{"specs/jobs/sql-prod.yaml"}},
+		{"filtered-dir", []string{"specs/jobs/sql-dev.yaml"}},
+	}
+
+	for _, tt := range tests {
+		group, _ := cfg.GetGroup(tt.group)
+		got, err := cfg.ResolveGroupSpecs(group)
+		if err != nil {
+			t.Errorf("%s: ResolveGroupSpecs failed: %v", tt.group, err)
+			continue
+		}
+		for i := range got {
+			got[i] = filepath.ToSlash(got[i])
+		}
+		sort.Strings(got)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: got %v, want %v", tt.group, got, tt.want)
+		}
+	}
+
+	bad, _ := cfg.GetGroup("bad")
+	if _, err := cfg.ResolveGroupSpecs(bad); err == nil {
+		t.Error("Expected error for invalid group selector")
+	}
+
+	// ResourceSpecFiles skips profiles, other YAML and hidden directories
+	all, err := cfg.ResourceSpecFiles()
+	if err != nil {
+		t.Fatalf("ResourceSpecFiles failed: %v", err)
+	}
+	for i := range all {
+		all[i] = filepath.ToSlash(all[i])
+	}
+	sort.Strings(all)
+	want := "specs/jobs/sql-dev.yaml,specs/jobs/sql-prod.yaml,specs/jobs/web-prod.yaml,specs/repos/repo-prod.yml"
+	if strings.Join(all, ",") != want {
+		t.Errorf("ResourceSpecFiles() = %v, want %s", all, want)
+	}
+}
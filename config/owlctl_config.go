@@ -5,7 +5,9 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 
+	"github.com/shapedthought/owlctl/resources"
 	"gopkg.in/yaml.v3"
 )
 
@@ -26,6 +28,18 @@ type VCLIConfig struct {
 	// Targets maps target names to their VBR server connection configuration
 	Targets map[string]TargetConfig `yaml:"targets,omitempty"`
 
+	// Vars are ${var} values substituted into every spec. Instance and group vars override them.
+	Vars map[string]string `yaml:"vars,omitempty"`
+
+	// StrictVars makes a reference to an undefined variable an error instead of leaving it as-is
+	StrictVars bool `yaml:"strictVars,omitempty"`
+
+	// EventSinks forward drift, apply and state change events to a SIEM
+	EventSinks []EventSinkConfig `yaml:"eventSinks,omitempty"`
+
+	// DriftHistory records every scan in a local drift history for owlctl drift history
+	DriftHistory DriftHistoryConfig `yaml:"driftHistory,omitempty"`
+
 	// ConfigDir is the directory containing the owlctl.yaml file.
 	// Populated during load, not serialized.
 	ConfigDir string `yaml:"-"`
@@ -64,6 +78,9 @@ type InstanceConfig struct {
 
 	// Description is a human-readable description of the instance
 	Description string `yaml:"description,omitempty"`
+
+	// Vars are ${var} values substituted into specs applied to this instance
+	Vars map[string]string `yaml:"vars,omitempty"`
 }
 
 // TargetConfig defines a named VBR server connection
@@ -89,12 +106,129 @@ type GroupConfig struct {
 	// Overlay is the path to an Overlay YAML file (policy patch)
 	Overlay string `yaml:"overlay,omitempty"`
 
+	// Profiles is an ordered list of Profile files layered under the specs, after Profile.
+	// Later profiles override earlier ones (e.g. org baseline, then region, then site).
+	Profiles []string `yaml:"profiles,omitempty"`
+
+	// Overlays is an ordered list of Overlay files applied on top of the specs, after Overlay.
+	// Later overlays override earlier ones.
+	Overlays []string `yaml:"overlays,omitempty"`
+
 	// Specs is the list of spec file paths in this group
 	Specs []string `yaml:"specs,omitempty"`
 
 	// SpecsDir is a directory path; all *.yaml files in it are used as specs.
 	// If both Specs and SpecsDir are set, they are combined.
 	SpecsDir string `yaml:"specsDir,omitempty"`
+
+	// Selector is a label selector (e.g. "env=prod,tier in (gold,silver)") matched against
+	// each spec's own metadata.labels. If Specs and SpecsDir are both empty, every resource
+	// spec under the owlctl.yaml directory is searched.
+	Selector string `yaml:"selector,omitempty"`
+
+	// MatchLabels selects specs whose labels equal all of the given values.
+	// Combined with Selector when both are set.
+	MatchLabels map[string]string `yaml:"matchLabels,omitempty"`
+
+	// Vars are ${var} values substituted into the group's specs, profile and overlay.
+	// They override instance and top-level vars.
+	Vars map[string]string `yaml:"vars,omitempty"`
+}
+
+// HasLabelSelector returns true if the group selects specs by label
+func (g GroupConfig) HasLabelSelector() bool {
+	return g.Selector != "" || len(g.MatchLabels) > 0
+}
+
+// LabelSelector returns the combined Selector and MatchLabels for the group
+func (g GroupConfig) LabelSelector() (resources.Selector, error) {
+	sel, err := resources.ParseSelector(g.Selector)
+	if err != nil {
+		return resources.Selector{}, err
+	}
+	return sel.And(resources.SelectorFromLabels(g.MatchLabels)), nil
+}
+
+// GroupLayers returns the group's profile and overlay chains in merge order: Profile then
+// Profiles, and Overlay then Overlays. A file listed more than once would be applied twice
+// (a cycle in the chain), so only its first occurrence is kept and a warning is returned.
+func (c *VCLIConfig) GroupLayers(g GroupConfig) (profiles, overlays, warnings []string) {
+	seen := make(map[string]string)
+	add := func(chain []string, layerType, path string) []string {
+		if path == "" {
+			return chain
+		}
+		key := filepath.Clean(c.ResolvePath(path))
+		if prev, ok := seen[key]; ok {
+			if prev == layerType {
+				warnings = append(warnings, fmt.Sprintf("%s %s is listed more than once (cycle in layer chain); only the first occurrence is applied", layerType, path))
+			} else {
+				warnings = append(warnings, fmt.Sprintf("%s %s is also listed as a %s; ignoring it as %s", layerType, path, prev, layerType))
+			}
+			return chain
+		}
+		seen[key] = layerType
+		return append(chain, path)
+	}
+
+	profiles = add(profiles, "profile", g.Profile)
+	for _, p := range g.Profiles {
+		profiles = add(profiles, "profile", p)
+	}
+	overlays = add(overlays, "overlay", g.Overlay)
+	for _, o := range g.Overlays {
+		overlays = add(overlays, "overlay", o)
+	}
+	return profiles, overlays, warnings
+}
+
+// EventSinkConfig defines a destination for drift, apply and state change events
+type EventSinkConfig struct {
+	// Name identifies the sink in warnings
+	Name string `yaml:"name,omitempty"`
+
+	// Type is "syslog" (RFC 5424 over the network) or "file" (one event per line)
+	Type string `yaml:"type"`
+
+	// Format is the event body: "text" (syslog default), "cef", or "json" (file default)
+	Format string `yaml:"format,omitempty"`
+
+	// Network is the syslog transport: "udp" (default), "tcp", or "tls"
+	Network string `yaml:"network,omitempty"`
+
+	// Address is the syslog receiver as host:port
+	Address string `yaml:"address,omitempty"`
+
+	// Facility is the syslog facility name (default "local0")
+	Facility string `yaml:"facility,omitempty"`
+
+	// AppName overrides the syslog APP-NAME (default "owlctl")
+	AppName string `yaml:"appName,omitempty"`
+
+	// CAFile is a PEM bundle used to verify a TLS receiver instead of the system roots
+	CAFile string `yaml:"caFile,omitempty"`
+
+	// Insecure skips TLS certificate verification
+	Insecure bool `yaml:"insecure,omitempty"`
+
+	// Path is the file written by a file sink, relative to owlctl.yaml
+	Path string `yaml:"path,omitempty"`
+
+	// MinSeverity drops events below this severity (INFO, WARNING, ERROR, CRITICAL)
+	MinSeverity string `yaml:"minSeverity,omitempty"`
+
+	// Events limits the forwarded event types ("drift", "apply", "state"); empty forwards all
+	Events []string `yaml:"events,omitempty"`
+}
+
+// DriftHistoryConfig controls the local drift history store
+type DriftHistoryConfig struct {
+	// Enabled records the result of every scan
+	Enabled bool `yaml:"enabled,omitempty"`
+
+	// Path is the history file, relative to owlctl.yaml (default drift-history.jsonl in
+	// the settings directory)
+	Path string `yaml:"path,omitempty"`
 }
 
 // EnvironmentConfig defines settings for a specific environment
@@ -293,9 +427,148 @@ func (c *VCLIConfig) ListInstances() []string {
 	return names
 }
 
+// Variables returns the spec variables for an instance and group, layering top-level vars,
+// then the instance's vars, then the group's vars. Pass "" and nil to skip a layer.
+func (c *VCLIConfig) Variables(instance string, group *GroupConfig) map[string]string {
+	vars := make(map[string]string, len(c.Vars))
+	for k, v := range c.Vars {
+		vars[k] = v
+	}
+	if inst, ok := c.Instances[instance]; ok {
+		for k, v := range inst.Vars {
+			vars[k] = v
+		}
+	}
+	if group != nil {
+		for k, v := range group.Vars {
+			vars[k] = v
+		}
+	}
+	return vars
+}
+
 // ResolveGroupSpecs returns the effective specs list for a group,
 // combining Specs and SpecsDir (glob *.yaml from the resolved directory).
+// If the group has a label selector, only specs whose labels match are returned.
 func (c *VCLIConfig) ResolveGroupSpecs(group GroupConfig) ([]string, error) {
+	specs, err := c.resolveGroupSpecPaths(group)
+	if err != nil {
+		return nil, err
+	}
+	if !group.HasLabelSelector() {
+		return specs, nil
+	}
+
+	sel, err := group.LabelSelector()
+	if err != nil {
+		return nil, fmt.Errorf("invalid group selector: %w", err)
+	}
+
+	// Label-only groups search the config directory, which may contain unrelated YAML
+	searched := len(group.Specs) == 0 && group.SpecsDir == ""
+	if searched {
+		specs, err = c.findSpecFiles()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var selected []string
+	for _, spec := range specs {
+		header, err := readSpecHeader(c.ResolvePath(spec))
+		if err != nil {
+			if searched {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read labels from %s: %w", spec, err)
+		}
+		if searched && !resources.IsResourceKind(header.Kind) {
+			continue
+		}
+		if sel.Matches(header.Metadata.Labels) {
+			selected = append(selected, spec)
+		}
+	}
+	return selected, nil
+}
+
+// specHeader is the subset of a spec file needed for label selection
+type specHeader struct {
+	Kind     string `yaml:"kind"`
+	Metadata struct {
+		Labels map[string]string `yaml:"labels"`
+	} `yaml:"metadata"`
+}
+
+func readSpecHeader(path string) (specHeader, error) {
+	var header specHeader
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return header, err
+	}
+	if err := yaml.Unmarshal(data, &header); err != nil {
+		return header, err
+	}
+	return header, nil
+}
+
+// findSpecFiles returns every *.yaml/*.yml file under the config directory (excluding
+// hidden directories and owlctl.yaml itself), relative to ConfigDir.
+func (c *VCLIConfig) findSpecFiles() ([]string, error) {
+	root := c.ConfigDir
+	if root == "" {
+		root = "."
+	}
+
+	var files []string
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := filepath.Ext(path)
+		if (ext != ".yaml" && ext != ".yml") || d.Name() == DefaultConfigName {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			rel = path
+		}
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search for specs in %q: %w", root, err)
+	}
+	return files, nil
+}
+
+// ResourceSpecFiles returns every resource spec under the config directory, relative to
+// ConfigDir. YAML files that are not resource specs (profiles, overlays, other config) are
+// skipped.
+func (c *VCLIConfig) ResourceSpecFiles() ([]string, error) {
+	files, err := c.findSpecFiles()
+	if err != nil {
+		return nil, err
+	}
+	var specs []string
+	for _, f := range files {
+		header, err := readSpecHeader(c.ResolvePath(f))
+		if err != nil || !resources.IsResourceKind(header.Kind) {
+			continue
+		}
+		specs = append(specs, f)
+	}
+	return specs, nil
+}
+
+// resolveGroupSpecPaths combines Specs and SpecsDir without label filtering
+func (c *VCLIConfig) resolveGroupSpecPaths(group GroupConfig) ([]string, error) {
 	specs := make([]string, len(group.Specs))
 	copy(specs, group.Specs)
 
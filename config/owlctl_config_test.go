@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -452,3 +453,120 @@ func TestHasDeprecatedFields(t *testing.T) {
 		t.Error("Expected HasDeprecatedFields=true")
 	}
 }
+
+func TestVariables(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "owlctl.yaml")
+	configYAML := `vars:
+  site: london
+  retention: 14
+  tier: standard
+instances:
+  vbr-dr:
+    product: vbr
+    url: vbr-dr.example.com
+    vars:
+      site: dublin
+groups:
+  sql:
+    specs: [sql.yaml]
+    vars:
+      tier: gold
+`
+	if err := os.WriteFile(configPath, []byte(configYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfigFrom(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	group := cfg.Groups["sql"]
+	tests := []struct {
+		name     string
+		instance string
+		group    *GroupConfig
+		want     map[string]string
+	}{
+		{"top-level only", "", nil, map[string]string{"site": "london", "retention": "14", "tier": "standard"}},
+		{"instance overrides top-level", "vbr-dr", nil, map[string]string{"site": "dublin", "retention": "14", "tier": "standard"}},
+		{"group overrides instance", "vbr-dr", &group, map[string]string{"site": "dublin", "retention": "14", "tier": "gold"}},
+		{"unknown instance ignored", "missing", &group, map[string]string{"site": "london", "retention": "14", "tier": "gold"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cfg.Variables(tt.instance, tt.group)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Variables() = %v, want %v", got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("Variables()[%q] = %q, want %q", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestGroupLayers(t *testing.T) {
+	cfg := &VCLIConfig{ConfigDir: "/project"}
+
+	tests := []struct {
+		name         string
+		group        GroupConfig
+		wantProfiles []string
+		wantOverlays []string
+		wantWarnings int
+	}{
+		{
+			name:         "single profile and overlay",
+			group:        GroupConfig{Profile: "profiles/gold.yaml", Overlay: "overlays/prod.yaml"},
+			wantProfiles: []string{"profiles/gold.yaml"},
+			wantOverlays: []string{"overlays/prod.yaml"},
+		},
+		{
+			name: "chains follow the singular fields",
+			group: GroupConfig{
+				Profile:  "profiles/org.yaml",
+				Profiles: []string{"profiles/emea.yaml", "profiles/london.yaml"},
+				Overlays: []string{"overlays/compliance.yaml", "overlays/team.yaml"},
+			},
+			wantProfiles: []string{"profiles/org.yaml", "profiles/emea.yaml", "profiles/london.yaml"},
+			wantOverlays: []string{"overlays/compliance.yaml", "overlays/team.yaml"},
+		},
+		{
+			name: "repeated layer is a cycle",
+			group: GroupConfig{
+				Profiles: []string{"profiles/org.yaml", "profiles/emea.yaml", "./profiles/org.yaml"},
+			},
+			wantProfiles: []string{"profiles/org.yaml", "profiles/emea.yaml"},
+			wantWarnings: 1,
+		},
+		{
+			name: "file used as profile and overlay",
+			group: GroupConfig{
+				Profile:  "shared.yaml",
+				Overlays: []string{"/project/shared.yaml"},
+			},
+			wantProfiles: []string{"shared.yaml"},
+			wantWarnings: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profiles, overlays, warnings := cfg.GroupLayers(tt.group)
+			if strings.Join(profiles, ",") != strings.Join(tt.wantProfiles, ",") {
+				t.Errorf("profiles = %v, want %v", profiles, tt.wantProfiles)
+			}
+			if strings.Join(overlays, ",") != strings.Join(tt.wantOverlays, ",") {
+				t.Errorf("overlays = %v, want %v", overlays, tt.wantOverlays)
+			}
+			if len(warnings) != tt.wantWarnings {
+				t.Errorf("warnings = %v, want %d", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shapedthought/owlctl/config"
+)
+
+// Event types
+const (
+	TypeDrift = "drift"
+	TypeApply = "apply"
+	TypeState = "state"
+)
+
+// Event severities. CRITICAL, WARNING and INFO match drift severities; ERROR marks failures.
+const (
+	SeverityCritical = "CRITICAL"
+	SeverityError    = "ERROR"
+	SeverityWarning  = "WARNING"
+	SeverityInfo     = "INFO"
+)
+
+// Event is a drift, apply or state change forwarded to event sinks
+type Event struct {
+	Time     time.Time   `json:"time"`
+	Type     string      `json:"type"`   // drift, apply, state
+	Action   string      `json:"action"` // drift: modified/added/removed; apply: created/updated/failed; state: snapshotted/applied/...
+	Severity string      `json:"severity"`
+	Instance string      `json:"instance,omitempty"`
+	Kind     string      `json:"kind,omitempty"`
+	Resource string      `json:"resource,omitempty"`
+	Field    string      `json:"field,omitempty"`
+	Expected interface{} `json:"expected,omitempty"` // Value in state or spec
+	Actual   interface{} `json:"actual,omitempty"`   // Value in VBR
+	User     string      `json:"user,omitempty"`
+	Message  string      `json:"message"`
+}
+
+// Sink delivers events to one destination
+type Sink interface {
+	Send(e Event) error
+	Close() error
+}
+
+// severityRank orders severities for MinSeverity filtering
+func severityRank(s string) int {
+	switch strings.ToUpper(s) {
+	case SeverityCritical:
+		return 4
+	case SeverityError:
+		return 3
+	case SeverityWarning:
+		return 2
+	case SeverityInfo:
+		return 1
+	}
+	return 0
+}
+
+// filteredSink applies a sink's MinSeverity and Events settings
+type filteredSink struct {
+	name   string
+	sink   Sink
+	minSev int
+	types  map[string]bool
+}
+
+func (f filteredSink) accepts(e Event) bool {
+	if severityRank(e.Severity) < f.minSev {
+		return false
+	}
+	return len(f.types) == 0 || f.types[e.Type]
+}
+
+// Dispatcher sends each event to every configured sink that accepts it
+type Dispatcher struct {
+	sinks []filteredSink
+}
+
+// New creates a Dispatcher from the eventSinks configured in owlctl.yaml. File sink paths
+// must already be resolved.
+func New(cfgs []config.EventSinkConfig) (*Dispatcher, error) {
+	d := &Dispatcher{}
+	for i, c := range cfgs {
+		name := c.Name
+		if name == "" {
+			name = fmt.Sprintf("eventSinks[%d]", i)
+		}
+
+		fs := filteredSink{name: name}
+		if c.MinSeverity != "" {
+			fs.minSev = severityRank(c.MinSeverity)
+			if fs.minSev == 0 {
+				return nil, fmt.Errorf("event sink %s: invalid minSeverity %q (use INFO, WARNING, ERROR, or CRITICAL)", name, c.MinSeverity)
+			}
+		}
+		for _, t := range c.Events {
+			switch t {
+			case TypeDrift, TypeApply, TypeState:
+			default:
+				return nil, fmt.Errorf("event sink %s: invalid event type %q (use drift, apply, or state)", name, t)
+			}
+			if fs.types == nil {
+				fs.types = make(map[string]bool)
+			}
+			fs.types[t] = true
+		}
+
+		sink, err := newSink(c)
+		if err != nil {
+			d.Close()
+			return nil, fmt.Errorf("event sink %s: %w", name, err)
+		}
+		fs.sink = sink
+		d.sinks = append(d.sinks, fs)
+	}
+	return d, nil
+}
+
+func newSink(c config.EventSinkConfig) (Sink, error) {
+	switch c.Type {
+	case "syslog":
+		return NewSyslogSink(c)
+	case "file":
+		return NewFileSink(c)
+	case "":
+		return nil, fmt.Errorf("type is required (syslog or file)")
+	}
+	return nil, fmt.Errorf("unknown type %q (use syslog or file)", c.Type)
+}
+
+// Emit sends an event to every sink that accepts it. A failing sink does not stop delivery
+// to the others; their errors are returned together.
+func (d *Dispatcher) Emit(e Event) error {
+	if d == nil {
+		return nil
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	var errs []error
+	for _, s := range d.sinks {
+		if !s.accepts(e) {
+			continue
+		}
+		if err := s.sink.Send(e); err != nil {
+			errs = append(errs, fmt.Errorf("event sink %s: %w", s.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes every sink
+func (d *Dispatcher) Close() error {
+	if d == nil {
+		return nil
+	}
+	var errs []error
+	for _, s := range d.sinks {
+		if s.sink == nil {
+			continue
+		}
+		if err := s.sink.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Len returns the number of configured sinks
+func (d *Dispatcher) Len() int {
+	if d == nil {
+		return 0
+	}
+	return len(d.sinks)
+}
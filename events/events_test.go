@@ -0,0 +1,295 @@
+package events
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shapedthought/owlctl/config"
+)
+
+func testEvent() Event {
+	return Event{
+		Time:     time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC),
+		Type:     TypeDrift,
+		Action:   "modified",
+		Severity: SeverityCritical,
+		Instance: "prod",
+		Kind:     "VBRJob",
+		Resource: `Nightly "SQL"`,
+		Field:    "isDisabled",
+		Expected: false,
+		Actual:   true,
+		Message:  "CRITICAL drift on VBRJob/Nightly isDisabled: false -> true",
+	}
+}
+
+// rfc5424 matches <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID SD MSG
+var rfc5424 = regexp.MustCompile(`^<(\d+)>1 (\S+) (\S+) (\S+) (\d+) (\S+) (\[.*\]) (.*)$`)
+
+func TestSyslogSink_UDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	sink, err := NewSyslogSink(config.EventSinkConfig{Type: "syslog", Address: conn.LocalAddr().String(), Facility: "auth"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	if err := sink.Send(testEvent()); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := rfc5424.FindStringSubmatch(string(buf[:n]))
+	if m == nil {
+		t.Fatalf("Not an RFC 5424 message: %q", buf[:n])
+	}
+	// auth (4) * 8 + critical (2)
+	if m[1] != "34" {
+		t.Errorf("PRI = %s, want 34", m[1])
+	}
+	if m[2] != "2026-10-18T09:30:00.000000Z" || m[4] != "owlctl" || m[6] != "drift" {
+		t.Errorf("Unexpected header: %q", m[0])
+	}
+	if !strings.Contains(m[7], `resource="Nightly \"SQL\""`) || !strings.Contains(m[7], `severity="CRITICAL"`) {
+		t.Errorf("Unexpected structured data: %s", m[7])
+	}
+	if m[8] != testEvent().Message {
+		t.Errorf("MSG = %q", m[8])
+	}
+}
+
+func TestSyslogSink_TCPFraming(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	received := readFramedMessages(t, ln, 2)
+
+	sink, err := NewSyslogSink(config.EventSinkConfig{Type: "syslog", Network: "tcp", Address: ln.Addr().String(), Format: FormatJSON})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	for i := 0; i < 2; i++ {
+		if err := sink.Send(testEvent()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	msgs := <-received
+	if len(msgs) != 2 {
+		t.Fatalf("Expected 2 framed messages, got %d", len(msgs))
+	}
+	m := rfc5424.FindStringSubmatch(msgs[1])
+	if m == nil {
+		t.Fatalf("Not an RFC 5424 message: %q", msgs[1])
+	}
+	var e Event
+	if err := json.Unmarshal([]byte(m[8]), &e); err != nil || e.Field != "isDisabled" {
+		t.Errorf("MSG should be the JSON event, got %q (%v)", m[8], err)
+	}
+}
+
+func TestSyslogSink_TLS(t *testing.T) {
+	cert, caFile := testCertificate(t)
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	received := readFramedMessages(t, ln, 1)
+
+	sink, err := NewSyslogSink(config.EventSinkConfig{Type: "syslog", Network: "tls", Address: ln.Addr().String(), CAFile: caFile, Format: FormatCEF})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	if err := sink.Send(testEvent()); err != nil {
+		t.Fatal(err)
+	}
+
+	msgs := <-received
+	if len(msgs) != 1 || !strings.Contains(msgs[0], "CEF:0|Veeam Community|owlctl|1|drift:modified|Configuration drift|9|") {
+		t.Errorf("Unexpected messages: %q", msgs)
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	dir := t.TempDir()
+	d, err := New([]config.EventSinkConfig{
+		{Type: "file", Path: filepath.Join(dir, "logs", "events.json")},
+		{Type: "file", Format: FormatCEF, Path: filepath.Join(dir, "events.cef"), MinSeverity: "warning", Events: []string{TypeDrift}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	info := testEvent()
+	info.Severity = SeverityInfo
+	apply := Event{Type: TypeApply, Action: "updated", Severity: SeverityWarning, Kind: "VBRJob", Resource: "Nightly", Message: "VBRJob/Nightly updated"}
+	for _, e := range []Event{testEvent(), info, apply} {
+		if err := d.Emit(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	jsonLines := readLines(t, filepath.Join(dir, "logs", "events.json"))
+	if len(jsonLines) != 3 {
+		t.Errorf("JSON sink should receive every event, got %d", len(jsonLines))
+	}
+
+	cefLines := readLines(t, filepath.Join(dir, "events.cef"))
+	if len(cefLines) != 1 {
+		t.Fatalf("CEF sink should only receive WARNING+ drift, got %q", cefLines)
+	}
+	for _, want := range []string{
+		"rt=1792315800000",
+		"cs2Label=kind cs2=VBRJob",
+		"cs5Label=expected cs5=false cs6Label=actual cs6=true",
+		"msg=CRITICAL drift on VBRJob/Nightly isDisabled: false -> true",
+	} {
+		if !strings.Contains(cefLines[0], want) {
+			t.Errorf("CEF line missing %q: %s", want, cefLines[0])
+		}
+	}
+}
+
+func TestFormatCEF_Escaping(t *testing.T) {
+	e := Event{Type: TypeApply, Action: "failed", Severity: SeverityError, Message: "a=b\nc\\d"}
+	line := formatCEF(e)
+	if !strings.HasSuffix(line, `msg=a\=b\nc\\d`) || !strings.Contains(line, "|Apply failed|7|") {
+		t.Errorf("Unexpected CEF: %s", line)
+	}
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	tests := map[string]config.EventSinkConfig{
+		"missing type":     {Address: "localhost:514"},
+		"unknown type":     {Type: "kafka"},
+		"syslog address":   {Type: "syslog"},
+		"syslog network":   {Type: "syslog", Network: "quic", Address: "localhost:514"},
+		"syslog facility":  {Type: "syslog", Address: "localhost:514", Facility: "local9"},
+		"file path":        {Type: "file"},
+		"file format":      {Type: "file", Path: "x", Format: "text"},
+		"min severity":     {Type: "file", Path: "x", MinSeverity: "HIGH"},
+		"event type":       {Type: "file", Path: "x", Events: []string{"session"}},
+		"missing ca file":  {Type: "syslog", Network: "tls", Address: "localhost:6514", CAFile: "/nonexistent.pem"},
+		"tls address form": {Type: "syslog", Network: "tls", Address: "localhost"},
+	}
+	for name, c := range tests {
+		if _, err := New([]config.EventSinkConfig{c}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// readFramedMessages accepts one connection and returns n octet-counted messages
+func readFramedMessages(t *testing.T, ln net.Listener, n int) <-chan []string {
+	t.Helper()
+	ch := make(chan []string, 1)
+	go func() {
+		var msgs []string
+		defer func() { ch <- msgs }()
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		r := bufio.NewReader(conn)
+		for len(msgs) < n {
+			lenStr, err := r.ReadString(' ')
+			if err != nil {
+				return
+			}
+			size, err := strconv.Atoi(strings.TrimSpace(lenStr))
+			if err != nil {
+				return
+			}
+			buf := make([]byte, size)
+			if _, err := io.ReadFull(r, buf); err != nil {
+				return
+			}
+			msgs = append(msgs, string(buf))
+		}
+	}()
+	return ch
+}
+
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+// testCertificate creates a self-signed certificate for 127.0.0.1 and writes it as a CA file
+func testCertificate(t *testing.T) (tls.Certificate, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, certPEM, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return cert, caFile
+}
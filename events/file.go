@@ -0,0 +1,55 @@
+package events
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/shapedthought/owlctl/config"
+)
+
+// FileSink appends one CEF or JSON event per line to a file, for collection by a SIEM agent
+type FileSink struct {
+	path   string
+	format string
+}
+
+// NewFileSink creates a file sink. The file and its directory are created on the first event.
+func NewFileSink(c config.EventSinkConfig) (*FileSink, error) {
+	if c.Path == "" {
+		return nil, fmt.Errorf("path is required for file sinks")
+	}
+	format := c.Format
+	if format == "" {
+		format = FormatJSON
+	}
+	if format != FormatJSON && format != FormatCEF {
+		return nil, fmt.Errorf("invalid format %q for file sinks (use json or cef)", c.Format)
+	}
+	return &FileSink{path: c.Path, format: format}, nil
+}
+
+// Send appends an event to the file
+func (f *FileSink) Send(e Event) error {
+	line, err := formatBody(f.format, e)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(line + "\n"); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Close is a no-op; the file is opened per event so concurrent runs interleave whole lines
+func (f *FileSink) Close() error {
+	return nil
+}
@@ -0,0 +1,128 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Event body formats
+const (
+	FormatText = "text"
+	FormatCEF  = "cef"
+	FormatJSON = "json"
+)
+
+// cefSeverity maps an event severity to the CEF 0-10 scale
+func cefSeverity(s string) int {
+	switch s {
+	case SeverityCritical:
+		return 9
+	case SeverityError:
+		return 7
+	case SeverityWarning:
+		return 5
+	}
+	return 3
+}
+
+// title is a short, stable event name for CEF and summaries
+func title(e Event) string {
+	switch e.Type {
+	case TypeDrift:
+		return "Configuration drift"
+	case TypeApply:
+		if e.Action == "failed" {
+			return "Apply failed"
+		}
+		return "Resource applied"
+	case TypeState:
+		return "State " + e.Action
+	}
+	return e.Type
+}
+
+// formatBody renders an event in the given format
+func formatBody(format string, e Event) (string, error) {
+	switch format {
+	case FormatText:
+		return e.Message, nil
+	case FormatJSON:
+		data, err := json.Marshal(e)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	case FormatCEF:
+		return formatCEF(e), nil
+	}
+	return "", fmt.Errorf("unknown format %q (use text, cef, or json)", format)
+}
+
+// formatCEF renders an event as an ArcSight Common Event Format record
+func formatCEF(e Event) string {
+	header := []string{
+		"CEF:0",
+		"Veeam Community",
+		"owlctl",
+		"1",
+		cefHeader(e.Type + ":" + e.Action),
+		cefHeader(title(e)),
+		strconv.Itoa(cefSeverity(e.Severity)),
+	}
+
+	ext := []string{
+		"rt=" + strconv.FormatInt(e.Time.UnixMilli(), 10),
+		"act=" + cefValue(e.Action),
+	}
+	add := func(key, value string) {
+		if value != "" {
+			ext = append(ext, key+"="+cefValue(value))
+		}
+	}
+	addLabeled := func(n int, label, value string) {
+		if value != "" {
+			ext = append(ext, fmt.Sprintf("cs%dLabel=%s cs%d=%s", n, label, n, cefValue(value)))
+		}
+	}
+	add("suser", e.User)
+	addLabeled(1, "instance", e.Instance)
+	addLabeled(2, "kind", e.Kind)
+	addLabeled(3, "resource", e.Resource)
+	addLabeled(4, "field", e.Field)
+	addLabeled(5, "expected", valueString(e.Expected))
+	addLabeled(6, "actual", valueString(e.Actual))
+	add("msg", e.Message)
+
+	return strings.Join(header, "|") + "|" + strings.Join(ext, " ")
+}
+
+// valueString renders a drift value compactly
+func valueString(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+// cefHeader escapes a CEF header field
+func cefHeader(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// cefValue escapes a CEF extension value
+func cefValue(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "=", `\=`)
+	s = strings.ReplaceAll(s, "\r", `\r`)
+	return strings.ReplaceAll(s, "\n", `\n`)
+}
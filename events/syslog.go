@@ -0,0 +1,221 @@
+package events
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shapedthought/owlctl/config"
+)
+
+// sdID is the RFC 5424 structured data ID for owlctl parameters. 32473 is the private
+// enterprise number reserved for documentation (RFC 5612).
+const sdID = "owlctl@32473"
+
+const syslogDialTimeout = 10 * time.Second
+
+// syslogFacilities maps facility names to RFC 5424 facility codes
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5,
+	"lpr": 6, "news": 7, "uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// syslogSeverity maps an event severity to an RFC 5424 severity code
+func syslogSeverity(s string) int {
+	switch s {
+	case SeverityCritical:
+		return 2 // Critical
+	case SeverityError:
+		return 3 // Error
+	case SeverityWarning:
+		return 4 // Warning
+	}
+	return 6 // Informational
+}
+
+// SyslogSink sends RFC 5424 messages over UDP, TCP or TLS. TCP and TLS use octet-counting
+// framing (RFC 6587, RFC 5425).
+type SyslogSink struct {
+	network   string
+	address   string
+	format    string
+	facility  int
+	appName   string
+	hostname  string
+	tlsConfig *tls.Config
+	conn      net.Conn
+}
+
+// NewSyslogSink creates a syslog sink. The connection is opened on the first event.
+func NewSyslogSink(c config.EventSinkConfig) (*SyslogSink, error) {
+	s := &SyslogSink{
+		network: c.Network,
+		address: c.Address,
+		format:  c.Format,
+		appName: c.AppName,
+	}
+	if s.network == "" {
+		s.network = "udp"
+	}
+	if s.network != "udp" && s.network != "tcp" && s.network != "tls" {
+		return nil, fmt.Errorf("invalid network %q (use udp, tcp, or tls)", c.Network)
+	}
+	if s.address == "" {
+		return nil, fmt.Errorf("address is required for syslog sinks")
+	}
+	if s.format == "" {
+		s.format = FormatText
+	}
+	if _, err := formatBody(s.format, Event{}); err != nil {
+		return nil, err
+	}
+	if s.appName == "" {
+		s.appName = "owlctl"
+	}
+
+	facility := c.Facility
+	if facility == "" {
+		facility = "local0"
+	}
+	code, ok := syslogFacilities[strings.ToLower(facility)]
+	if !ok {
+		return nil, fmt.Errorf("unknown facility %q", c.Facility)
+	}
+	s.facility = code
+
+	s.hostname = "-"
+	if h, err := os.Hostname(); err == nil && h != "" {
+		s.hostname = h
+	}
+
+	if s.network == "tls" {
+		host, _, err := net.SplitHostPort(s.address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q: %w", s.address, err)
+		}
+		s.tlsConfig = &tls.Config{ServerName: host, InsecureSkipVerify: c.Insecure, MinVersion: tls.VersionTLS12}
+		if c.CAFile != "" {
+			pem, err := os.ReadFile(c.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read caFile: %w", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in caFile %s", c.CAFile)
+			}
+			s.tlsConfig.RootCAs = pool
+		}
+	}
+	return s, nil
+}
+
+// Send writes one event, reconnecting once if a stream connection was dropped
+func (s *SyslogSink) Send(e Event) error {
+	msg, err := s.message(e)
+	if err != nil {
+		return err
+	}
+	if s.network != "udp" {
+		msg = strconv.Itoa(len(msg)) + " " + msg
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := s.connect(); err != nil {
+			return err
+		}
+		_, err := s.conn.Write([]byte(msg))
+		if err == nil {
+			return nil
+		}
+		s.conn.Close()
+		s.conn = nil
+		if attempt > 0 || s.network == "udp" {
+			return err
+		}
+	}
+}
+
+func (s *SyslogSink) connect() error {
+	if s.conn != nil {
+		return nil
+	}
+	var conn net.Conn
+	var err error
+	if s.network == "tls" {
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: syslogDialTimeout}, "tcp", s.address, s.tlsConfig)
+	} else {
+		conn, err = net.DialTimeout(s.network, s.address, syslogDialTimeout)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", s.address, err)
+	}
+	s.conn = conn
+	return nil
+}
+
+// message renders an event as an RFC 5424 message:
+// <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [STRUCTURED-DATA] MSG
+func (s *SyslogSink) message(e Event) (string, error) {
+	body, err := formatBody(s.format, e)
+	if err != nil {
+		return "", err
+	}
+	pri := s.facility*8 + syslogSeverity(e.Severity)
+	return fmt.Sprintf("<%d>1 %s %s %s %d %s %s %s",
+		pri,
+		e.Time.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		s.hostname,
+		s.appName,
+		os.Getpid(),
+		e.Type,
+		structuredData(e),
+		body,
+	), nil
+}
+
+// structuredData renders the event's fields as an RFC 5424 SD-ELEMENT
+func structuredData(e Event) string {
+	params := []struct{ name, value string }{
+		{"type", e.Type},
+		{"action", e.Action},
+		{"severity", e.Severity},
+		{"instance", e.Instance},
+		{"kind", e.Kind},
+		{"resource", e.Resource},
+		{"field", e.Field},
+		{"user", e.User},
+	}
+	var b strings.Builder
+	b.WriteString("[" + sdID)
+	for _, p := range params {
+		if p.value != "" {
+			fmt.Fprintf(&b, ` %s="%s"`, p.name, sdEscape(p.value))
+		}
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+// sdEscape escapes an SD-PARAM value
+func sdEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return strings.ReplaceAll(s, "]", `\]`)
+}
+
+// Close closes the connection
+func (s *SyslogSink) Close() error {
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
@@ -4,6 +4,7 @@ go 1.24.0
 
 require (
 	github.com/99designs/keyring v1.2.2
+	github.com/jmespath/go-jmespath v0.4.0
 	github.com/pterm/pterm v0.12.82
 	github.com/spf13/cobra v1.10.2
 	golang.org/x/term v0.39.0
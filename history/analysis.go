@@ -0,0 +1,248 @@
+package history
+
+import (
+	"sort"
+	"time"
+)
+
+// Episode is one continuous period during which a field was drifted: from the first scan
+// that reported it until the first later scan that found it clean.
+type Episode struct {
+	Instance     string     `json:"instance,omitempty"`
+	Kind         string     `json:"kind"`
+	Resource     string     `json:"resource"`
+	Field        string     `json:"field"`
+	Severity     string     `json:"severity"` // highest severity seen during the episode
+	FirstDrifted time.Time  `json:"firstDrifted"`
+	LastSeen     time.Time  `json:"lastSeen"`
+	ResolvedAt   *time.Time `json:"resolvedAt,omitempty"`
+	Scans        int        `json:"scans"`
+
+	// DurationSeconds runs until ResolvedAt, or until the analysis time for open episodes
+	DurationSeconds int64 `json:"durationSeconds"`
+}
+
+// Open reports whether the field was still drifted at the last scan
+func (e Episode) Open() bool {
+	return e.ResolvedAt == nil
+}
+
+// Duration returns how long the field stayed drifted
+func (e Episode) Duration() time.Duration {
+	return time.Duration(e.DurationSeconds) * time.Second
+}
+
+// ResourceSummary aggregates the episodes of one resource
+type ResourceSummary struct {
+	Instance string `json:"instance,omitempty"`
+	Kind     string `json:"kind"`
+	Resource string `json:"resource"`
+	Episodes int    `json:"episodes"`
+	Open     int    `json:"open"`
+
+	// MTTRSeconds is the mean time to remediation over resolved episodes (0 when none)
+	MTTRSeconds int64 `json:"mttrSeconds,omitempty"`
+}
+
+// MTTR returns the mean time to remediation
+func (r ResourceSummary) MTTR() time.Duration {
+	return time.Duration(r.MTTRSeconds) * time.Second
+}
+
+// FieldSummary aggregates the episodes of one field across resources of a kind
+type FieldSummary struct {
+	Kind           string `json:"kind"`
+	Field          string `json:"field"`
+	Episodes       int    `json:"episodes"`
+	Resources      int    `json:"resources"`
+	Open           int    `json:"open"`
+	DriftedSeconds int64  `json:"driftedSeconds"` // total time drifted across episodes
+}
+
+// severityRank orders severities; unknown values rank lowest
+func severityRank(s string) int {
+	switch s {
+	case "CRITICAL":
+		return 3
+	case "WARNING":
+		return 2
+	case "INFO":
+		return 1
+	}
+	return 0
+}
+
+type resourceKey struct {
+	instance, kind, resource string
+}
+
+// Episodes reconstructs drift episodes from records, ordered by when they started. now
+// is used for the duration of episodes that are still open.
+//
+// A field missing from a later record closes its episode, unless the record's scan filtered
+// out the episode's severity: a --severity critical scan says nothing about WARNING drift.
+func Episodes(records []Record, now time.Time) []Episode {
+	sorted := append([]Record(nil), records...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+
+	var episodes []*Episode
+	open := make(map[resourceKey]map[string]*Episode)
+
+	for _, r := range sorted {
+		key := resourceKey{r.Instance, r.Kind, r.Resource}
+		fields := open[key]
+		if fields == nil {
+			fields = make(map[string]*Episode)
+			open[key] = fields
+		}
+
+		seen := make(map[string]bool)
+		for _, d := range r.Drifts {
+			seen[d.Path] = true
+			ep, ok := fields[d.Path]
+			if !ok {
+				ep = &Episode{
+					Instance:     r.Instance,
+					Kind:         r.Kind,
+					Resource:     r.Resource,
+					Field:        d.Path,
+					FirstDrifted: r.Time,
+				}
+				fields[d.Path] = ep
+				episodes = append(episodes, ep)
+			}
+			ep.LastSeen = r.Time
+			ep.Scans++
+			if severityRank(d.Severity) > severityRank(ep.Severity) {
+				ep.Severity = d.Severity
+			}
+		}
+
+		for path, ep := range fields {
+			if seen[path] || severityRank(ep.Severity) < severityRank(r.MinSeverity) {
+				continue
+			}
+			resolved := r.Time
+			ep.ResolvedAt = &resolved
+			delete(fields, path)
+		}
+	}
+
+	result := make([]Episode, len(episodes))
+	for i, ep := range episodes {
+		end := now
+		if ep.ResolvedAt != nil {
+			end = *ep.ResolvedAt
+		}
+		ep.DurationSeconds = int64(end.Sub(ep.FirstDrifted) / time.Second)
+		result[i] = *ep
+	}
+	return result
+}
+
+// SummarizeResources returns per-resource episode counts and MTTR, ordered by kind and name
+func SummarizeResources(episodes []Episode) []ResourceSummary {
+	index := make(map[resourceKey]int)
+	var summaries []ResourceSummary
+	resolvedTotal := make(map[resourceKey]int64)
+	resolvedCount := make(map[resourceKey]int64)
+
+	for _, ep := range episodes {
+		key := resourceKey{ep.Instance, ep.Kind, ep.Resource}
+		i, ok := index[key]
+		if !ok {
+			i = len(summaries)
+			index[key] = i
+			summaries = append(summaries, ResourceSummary{Instance: ep.Instance, Kind: ep.Kind, Resource: ep.Resource})
+		}
+		summaries[i].Episodes++
+		if ep.Open() {
+			summaries[i].Open++
+		} else {
+			resolvedTotal[key] += ep.DurationSeconds
+			resolvedCount[key]++
+		}
+	}
+
+	for key, i := range index {
+		if n := resolvedCount[key]; n > 0 {
+			summaries[i].MTTRSeconds = resolvedTotal[key] / n
+		}
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		a, b := summaries[i], summaries[j]
+		if a.Instance != b.Instance {
+			return a.Instance < b.Instance
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Resource < b.Resource
+	})
+	return summaries
+}
+
+// MTTR returns the mean time to remediation over all resolved episodes, and how many
+// episodes it covers
+func MTTR(episodes []Episode) (time.Duration, int) {
+	var total int64
+	n := 0
+	for _, ep := range episodes {
+		if !ep.Open() {
+			total += ep.DurationSeconds
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, 0
+	}
+	return time.Duration(total/int64(n)) * time.Second, n
+}
+
+// TopFields returns the fields with the most episodes (then the longest total drift),
+// limited to n entries when n > 0
+func TopFields(episodes []Episode, n int) []FieldSummary {
+	type fieldKey struct{ kind, field string }
+	index := make(map[fieldKey]int)
+	resources := make(map[fieldKey]map[resourceKey]bool)
+	var summaries []FieldSummary
+
+	for _, ep := range episodes {
+		key := fieldKey{ep.Kind, ep.Field}
+		i, ok := index[key]
+		if !ok {
+			i = len(summaries)
+			index[key] = i
+			resources[key] = make(map[resourceKey]bool)
+			summaries = append(summaries, FieldSummary{Kind: ep.Kind, Field: ep.Field})
+		}
+		summaries[i].Episodes++
+		summaries[i].DriftedSeconds += ep.DurationSeconds
+		if ep.Open() {
+			summaries[i].Open++
+		}
+		resources[key][resourceKey{ep.Instance, ep.Kind, ep.Resource}] = true
+	}
+	for key, i := range index {
+		summaries[i].Resources = len(resources[key])
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		a, b := summaries[i], summaries[j]
+		if a.Episodes != b.Episodes {
+			return a.Episodes > b.Episodes
+		}
+		if a.DriftedSeconds != b.DriftedSeconds {
+			return a.DriftedSeconds > b.DriftedSeconds
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Field < b.Field
+	})
+	if n > 0 && len(summaries) > n {
+		summaries = summaries[:n]
+	}
+	return summaries
+}
@@ -0,0 +1,135 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var t0 = time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+func day(n int) time.Time {
+	return t0.Add(time.Duration(n) * 24 * time.Hour)
+}
+
+func record(at time.Time, resource string, drifts ...Field) Record {
+	return Record{Time: at, Kind: "VBRJob", Resource: resource, MinSeverity: "INFO", Drifts: drifts}
+}
+
+func TestStoreAppendAndLoad(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "nested", DefaultFileName))
+
+	if records, err := store.Load(); err != nil || records != nil {
+		t.Fatalf("Missing file should be empty history, got %v, %v", records, err)
+	}
+
+	// Appended out of order; Load sorts by time
+	if err := store.Append([]Record{record(day(2), "Nightly")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Append([]Record{record(day(1), "Nightly", Field{Path: "isDisabled", Action: "modified", Severity: "CRITICAL", State: false, VBR: true})}); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || !records[0].Time.Equal(day(1)) || len(records[0].Drifts) != 1 {
+		t.Fatalf("Unexpected records: %+v", records)
+	}
+	if records[0].Drifts[0].VBR != true {
+		t.Errorf("Drift values should round-trip, got %+v", records[0].Drifts[0])
+	}
+}
+
+func TestStoreLoad_TruncatedLastLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultFileName)
+	data := `{"time":"2026-10-01T09:00:00Z","kind":"VBRJob","resource":"Nightly"}` + "\n" + `{"time":"2026-10-02T09:`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	records, err := NewStore(path).Load()
+	if err != nil || len(records) != 1 {
+		t.Errorf("Truncated last line should be ignored, got %d records, %v", len(records), err)
+	}
+
+	if err := os.WriteFile(path, []byte("not json\n"+data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewStore(path).Load(); err == nil {
+		t.Error("A corrupt line before the end should be an error")
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	t.Setenv("OWLCTL_SETTINGS_PATH", "/tmp/owlctl-settings")
+	if got := DefaultPath(); got != filepath.Join("/tmp/owlctl-settings", DefaultFileName) {
+		t.Errorf("DefaultPath() = %s", got)
+	}
+}
+
+func TestEpisodes(t *testing.T) {
+	disabled := Field{Path: "isDisabled", Action: "modified", Severity: "CRITICAL"}
+	retention := Field{Path: "storage.retentionPolicy.quantity", Action: "modified", Severity: "WARNING"}
+
+	records := []Record{
+		record(day(0), "Nightly"),
+		record(day(1), "Nightly", disabled),
+		record(day(2), "Nightly", disabled, retention),
+		record(day(3), "Nightly", retention),                                        // isDisabled fixed after 2 days
+		record(day(5), "Nightly", disabled),                                         // drifts again; retention fixed after 3 days
+		record(day(1), "Weekly", retention),                                         // never fixed
+		{Time: day(6), Kind: "VBRJob", Resource: "Weekly", MinSeverity: "CRITICAL"}, // filtered scan
+	}
+	now := day(10)
+	episodes := Episodes(records, now)
+
+	if len(episodes) != 4 {
+		t.Fatalf("Expected 4 episodes, got %d: %+v", len(episodes), episodes)
+	}
+	first := episodes[0]
+	if first.Resource != "Nightly" || first.Field != "isDisabled" || !first.FirstDrifted.Equal(day(1)) ||
+		first.Open() || !first.ResolvedAt.Equal(day(3)) || first.Duration() != 48*time.Hour || first.Scans != 2 {
+		t.Errorf("Unexpected first episode: %+v", first)
+	}
+
+	var weekly, recurrence Episode
+	for _, ep := range episodes {
+		if ep.Resource == "Weekly" {
+			weekly = ep
+		}
+		if ep.Field == "isDisabled" && ep.FirstDrifted.Equal(day(5)) {
+			recurrence = ep
+		}
+	}
+	if !weekly.Open() || weekly.Duration() != 9*24*time.Hour {
+		t.Errorf("A CRITICAL-only scan must not resolve WARNING drift: %+v", weekly)
+	}
+	if !recurrence.Open() || recurrence.Duration() != 5*24*time.Hour {
+		t.Errorf("Drift after a fix should start a new episode: %+v", recurrence)
+	}
+
+	mttr, resolved := MTTR(episodes)
+	if resolved != 2 || mttr != 60*time.Hour {
+		t.Errorf("MTTR = %s over %d, want 60h over 2", mttr, resolved)
+	}
+
+	summaries := SummarizeResources(episodes)
+	if len(summaries) != 2 || summaries[0].Resource != "Nightly" || summaries[0].Episodes != 3 ||
+		summaries[0].Open != 1 || summaries[0].MTTR() != 60*time.Hour {
+		t.Errorf("Unexpected resource summaries: %+v", summaries)
+	}
+	if summaries[1].MTTRSeconds != 0 || summaries[1].Open != 1 {
+		t.Errorf("Weekly has no resolved episodes: %+v", summaries[1])
+	}
+
+	fields := TopFields(episodes, 1)
+	if len(fields) != 1 || fields[0].Field != "storage.retentionPolicy.quantity" || fields[0].Resources != 2 {
+		t.Errorf("Unexpected top fields: %+v", fields)
+	}
+	if all := TopFields(episodes, 0); len(all) != 2 || all[1].Field != "isDisabled" || all[1].Open != 1 {
+		t.Errorf("Unexpected field summaries: %+v", all)
+	}
+}
@@ -0,0 +1,138 @@
+// Package history persists drift scan results so drift can be tracked over time.
+//
+// Each scan appends one record per checked resource to a JSONL file. A record with no
+// drifts means the resource was clean in that scan, which is what closes a drift episode.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// DefaultFileName is the history file created in the settings directory
+const DefaultFileName = "drift-history.jsonl"
+
+// Record is the result of checking one resource in one scan
+type Record struct {
+	Time     time.Time `json:"time"`
+	Instance string    `json:"instance,omitempty"`
+	Kind     string    `json:"kind"`
+	Resource string    `json:"resource"`
+
+	// MinSeverity is the scan's severity filter. Drift below it was not reported, so its
+	// absence does not mean it was fixed.
+	MinSeverity string  `json:"minSeverity,omitempty"`
+	Drifts      []Field `json:"drifts,omitempty"`
+}
+
+// Field is one drifted field in a record
+type Field struct {
+	Path     string      `json:"path"`
+	Action   string      `json:"action"`
+	Severity string      `json:"severity"`
+	State    interface{} `json:"state,omitempty"`
+	VBR      interface{} `json:"vbr,omitempty"`
+}
+
+// Store is an append-only JSONL file of records
+type Store struct {
+	path string
+}
+
+// NewStore returns a store for the given file. An empty path uses DefaultPath.
+func NewStore(path string) *Store {
+	if path == "" {
+		path = DefaultPath()
+	}
+	return &Store{path: path}
+}
+
+// DefaultPath returns drift-history.jsonl in OWLCTL_SETTINGS_PATH, or ~/.owlctl/
+func DefaultPath() string {
+	if settingsPath := os.Getenv("OWLCTL_SETTINGS_PATH"); settingsPath != "" {
+		return filepath.Join(settingsPath, DefaultFileName)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".owlctl", DefaultFileName)
+	}
+	return DefaultFileName
+}
+
+// Path returns the file the store reads and writes
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Append writes records to the end of the file in a single write
+func (s *Store) Append(records []Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	var buf []byte
+	for _, r := range records {
+		line, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("failed to encode history record: %w", err)
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open drift history: %w", err)
+	}
+	if _, err := f.Write(buf); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write drift history: %w", err)
+	}
+	return f.Close()
+}
+
+// Load reads every record, oldest first. A missing file is an empty history. A partially
+// written last line (from an interrupted run) is ignored.
+func (s *Store) Load() ([]Record, error) {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open drift history: %w", err)
+	}
+	defer f.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	lineNo := 0
+	var pending error
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		if pending != nil {
+			return nil, pending
+		}
+		var r Record
+		if err := json.Unmarshal(line, &r); err != nil {
+			pending = fmt.Errorf("%s line %d: %w", s.path, lineNo, err)
+			continue
+		}
+		records = append(records, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read drift history: %w", err)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Time.Before(records[j].Time) })
+	return records, nil
+}
@@ -0,0 +1,312 @@
+// Package policy evaluates policy rules (policy-rules.yaml) against declarative specs.
+package policy
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/shapedthought/owlctl/resources"
+	"gopkg.in/yaml.v3"
+)
+
+// Operator is the condition under which a rule triggers
+type Operator string
+
+const (
+	OpEquals Operator = "equals" // Field equals value
+	OpExists Operator = "exists" // Field is set
+	OpAbsent Operator = "absent" // Field is not set
+	OpLT     Operator = "lt"     // Field is a number less than value
+	OpGT     Operator = "gt"     // Field is a number greater than value
+	OpIn     Operator = "in"     // Field equals one of the values in a list
+	OpRegex  Operator = "regex"  // Field, as a string, matches the regular expression in value
+)
+
+// Action is what a triggered rule does to the outcome
+type Action string
+
+const (
+	ActionBlock Action = "block" // Fail the check
+	ActionWarn  Action = "warn"  // Pass with warnings
+)
+
+// Rule is a single policy rule
+type Rule struct {
+	Name     string      `yaml:"name"`
+	Resource string      `yaml:"resource"` // Spec kind the rule applies to; empty or "*" for any kind
+	Path     string      `yaml:"path"`     // yq-style path, e.g. .spec.storage.retentionPolicy.quantity
+	Operator Operator    `yaml:"operator"`
+	Value    interface{} `yaml:"value"`
+	Action   Action      `yaml:"action"`
+	Message  string      `yaml:"message"`
+
+	pattern *regexp.Regexp
+}
+
+// RuleSet is the content of a policy rules file
+type RuleSet struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Document is a spec to evaluate, usually a merged group spec
+type Document struct {
+	Source string                 // File the spec came from, for reporting
+	Kind   string                 // Spec kind
+	Name   string                 // metadata.name
+	Doc    map[string]interface{} // The whole spec document (apiVersion, kind, metadata, spec)
+
+	// Provenance maps spec field paths to the profile, spec or overlay file that set them (optional)
+	Provenance map[string]string
+}
+
+// Result is a rule that triggered for a document
+type Result struct {
+	Rule     Rule
+	Source   string
+	Kind     string
+	Name     string
+	Value    interface{} // Field value; nil for absent
+	Resolved string      // Path as evaluated, from the document root
+	SetBy    string      // File that set the field, when the document has provenance
+}
+
+// LoadRules reads and checks a policy rules file
+func LoadRules(path string) (*RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy rules: %w", err)
+	}
+	return ParseRules(data)
+}
+
+// ParseRules parses and checks policy rules
+func ParseRules(data []byte) (*RuleSet, error) {
+	var rs RuleSet
+	if err := yaml.Unmarshal(data, &rs); err != nil {
+		return nil, fmt.Errorf("failed to parse policy rules: %w", err)
+	}
+
+	var problems []string
+	for i := range rs.Rules {
+		if err := rs.Rules[i].check(); err != nil {
+			name := rs.Rules[i].Name
+			if name == "" {
+				name = fmt.Sprintf("rules[%d]", i)
+			}
+			problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid policy rules:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return &rs, nil
+}
+
+// check validates a rule and compiles its pattern
+func (r *Rule) check() error {
+	if r.Path == "" {
+		return fmt.Errorf("path is required")
+	}
+	switch r.Action {
+	case ActionBlock, ActionWarn:
+	default:
+		return fmt.Errorf("action must be block or warn, got %q", r.Action)
+	}
+
+	switch r.Operator {
+	case OpEquals, OpExists, OpAbsent:
+	case OpLT, OpGT:
+		if _, ok := toNumber(r.Value); !ok {
+			return fmt.Errorf("%s requires a numeric value, got %v", r.Operator, r.Value)
+		}
+	case OpIn:
+		if _, ok := r.Value.([]interface{}); !ok {
+			return fmt.Errorf("in requires a list value, got %v", r.Value)
+		}
+	case OpRegex:
+		expr, ok := r.Value.(string)
+		if !ok {
+			return fmt.Errorf("regex requires a string value, got %v", r.Value)
+		}
+		pattern, err := regexp.Compile(expr)
+		if err != nil {
+			return fmt.Errorf("invalid regex: %w", err)
+		}
+		r.pattern = pattern
+	default:
+		return fmt.Errorf("unknown operator %q (expected equals, exists, absent, lt, gt, in or regex)", r.Operator)
+	}
+	return nil
+}
+
+// AppliesTo reports whether a rule applies to a spec kind
+func (r Rule) AppliesTo(kind string) bool {
+	if r.Resource == "" || r.Resource == "*" {
+		return true
+	}
+	return normalizeKind(r.Resource) == normalizeKind(kind)
+}
+
+// Evaluate runs every rule against every document and returns the rules that triggered,
+// blocking results first
+func (rs *RuleSet) Evaluate(docs []Document) []Result {
+	var results []Result
+	for _, doc := range docs {
+		for _, rule := range rs.Rules {
+			if !rule.AppliesTo(doc.Kind) {
+				continue
+			}
+			resolved := resolvePath(rule.Path)
+			value, present := lookup(doc.Doc, resolved)
+			if rule.triggers(value, present) {
+				result := Result{
+					Rule:     rule,
+					Source:   doc.Source,
+					Kind:     doc.Kind,
+					Name:     doc.Name,
+					Value:    value,
+					Resolved: strings.ReplaceAll(strings.Join(resolved, "."), ".[", "["),
+				}
+				if field, ok := strings.CutPrefix(result.Resolved, "spec."); ok && present {
+					result.SetBy = resources.ProvenanceFor(doc.Provenance, field)
+				}
+				results = append(results, result)
+			}
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Rule.Action == ActionBlock && results[j].Rule.Action != ActionBlock
+	})
+	return results
+}
+
+// triggers reports whether a rule fires for a field value. Apart from absent, rules only fire
+// for fields that are set: a spec or overlay that doesn't set a field leaves it unchanged.
+func (r Rule) triggers(value interface{}, present bool) bool {
+	if r.Operator == OpAbsent {
+		return !present
+	}
+	if !present {
+		return false
+	}
+
+	switch r.Operator {
+	case OpExists:
+		return true
+	case OpEquals:
+		return valuesEqual(value, r.Value)
+	case OpIn:
+		for _, candidate := range r.Value.([]interface{}) {
+			if valuesEqual(value, candidate) {
+				return true
+			}
+		}
+		return false
+	case OpLT, OpGT:
+		actual, ok := toNumber(value)
+		if !ok {
+			return false
+		}
+		limit, _ := toNumber(r.Value)
+		if r.Operator == OpLT {
+			return actual < limit
+		}
+		return actual > limit
+	case OpRegex:
+		if r.pattern == nil {
+			return false
+		}
+		switch value.(type) {
+		case map[string]interface{}, []interface{}:
+			return false
+		}
+		return r.pattern.MatchString(fmt.Sprint(value))
+	}
+	return false
+}
+
+// resolvePath splits a yq-style path into segments from the document root. Paths that don't
+// start with a top-level document field are taken as relative to spec.
+func resolvePath(path string) []string {
+	path = strings.TrimPrefix(path, ".")
+	path = strings.ReplaceAll(path, "[", ".[")
+
+	var segments []string
+	for _, s := range strings.Split(path, ".") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+	if len(segments) > 0 {
+		switch segments[0] {
+		case "apiVersion", "kind", "metadata", "spec", "mergePatch", "jsonPatch":
+			return segments
+		}
+	}
+	return append([]string{"spec"}, segments...)
+}
+
+// lookup walks a document by path segments. "[n]" segments index arrays.
+func lookup(doc map[string]interface{}, segments []string) (interface{}, bool) {
+	var current interface{} = doc
+	for _, segment := range segments {
+		if strings.HasPrefix(segment, "[") && strings.HasSuffix(segment, "]") {
+			list, ok := current.([]interface{})
+			if !ok {
+				return nil, false
+			}
+			index, err := strconv.Atoi(segment[1 : len(segment)-1])
+			if err != nil || index < 0 || index >= len(list) {
+				return nil, false
+			}
+			current = list[index]
+			continue
+		}
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = m[segment]
+		if !ok {
+			return nil, false
+		}
+	}
+	// An explicit null is "not set"
+	return current, current != nil
+}
+
+// valuesEqual compares a spec value with a rule value, treating numbers by value and
+// everything else by its string form (so "false" in a rule matches a boolean false)
+func valuesEqual(a, b interface{}) bool {
+	if x, ok := toNumber(a); ok {
+		if y, ok := toNumber(b); ok {
+			return x == y
+		}
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func toNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func normalizeKind(kind string) string {
+	if kind == resources.KindVBRSOBR {
+		return resources.KindVBRScaleOutRepository
+	}
+	return kind
+}
@@ -0,0 +1,157 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+)
+
+func testDocument() Document {
+	return Document{
+		Source: "specs/sql.yaml",
+		Kind:   "VBRJob",
+		Name:   "SQL Backup",
+		Doc: map[string]interface{}{
+			"kind":     "VBRJob",
+			"metadata": map[string]interface{}{"name": "SQL Backup"},
+			"spec": map[string]interface{}{
+				"description": "Nightly SQL",
+				"isDisabled":  false,
+				"storage": map[string]interface{}{
+					"retentionPolicy": map[string]interface{}{"type": "Days", "quantity": 7.0},
+				},
+				"virtualMachines": map[string]interface{}{
+					"includes": []interface{}{map[string]interface{}{"name": "sql-01"}},
+				},
+				"schedule": map[string]interface{}{"daily": nil},
+			},
+		},
+		Provenance: map[string]string{"storage.retentionPolicy.quantity": "overlays/dev.yaml"},
+	}
+}
+
+func TestRuleTriggers(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+		want bool
+	}{
+		{"equals bool", "path: .spec.isDisabled\noperator: equals\nvalue: false", true},
+		{"equals string form", "path: .spec.isDisabled\noperator: equals\nvalue: \"false\"", true},
+		{"equals number across types", "path: .spec.storage.retentionPolicy.quantity\noperator: equals\nvalue: 7", true},
+		{"equals mismatch", "path: .spec.storage.retentionPolicy.type\noperator: equals\nvalue: RestorePoints", false},
+		{"exists", "path: .spec.description\noperator: exists", true},
+		{"exists on missing field", "path: .spec.storage.gfsPolicy\noperator: exists", false},
+		{"null counts as missing", "path: .spec.schedule.daily\noperator: exists", false},
+		{"absent", "path: .spec.storage.gfsPolicy\noperator: absent", true},
+		{"absent on set field", "path: .spec.description\noperator: absent", false},
+		{"lt", "path: .spec.storage.retentionPolicy.quantity\noperator: lt\nvalue: 14", true},
+		{"lt not met", "path: .spec.storage.retentionPolicy.quantity\noperator: lt\nvalue: 7", false},
+		{"gt", "path: .spec.storage.retentionPolicy.quantity\noperator: gt\nvalue: 3", true},
+		{"gt on non-number", "path: .spec.description\noperator: gt\nvalue: 3", false},
+		{"in", "path: .spec.storage.retentionPolicy.type\noperator: in\nvalue: [Days, Weeks]", true},
+		{"in not met", "path: .spec.storage.retentionPolicy.type\noperator: in\nvalue: [RestorePoints]", false},
+		{"regex", "path: .spec.description\noperator: regex\nvalue: \"(?i)^nightly\"", true},
+		{"regex not met", "path: .spec.description\noperator: regex\nvalue: \"^Weekly\"", false},
+		{"relative to spec", "path: storage.retentionPolicy.type\noperator: equals\nvalue: Days", true},
+		{"array index", "path: .spec.virtualMachines.includes[0].name\noperator: equals\nvalue: sql-01", true},
+		{"array index out of range", "path: .spec.virtualMachines.includes[3].name\noperator: exists", false},
+		{"metadata path", "path: .metadata.name\noperator: regex\nvalue: SQL", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := ParseRules([]byte("rules:\n  - name: r\n    resource: VBRJob\n    action: block\n    " + strings.ReplaceAll(tt.rule, "\n", "\n    ")))
+			if err != nil {
+				t.Fatalf("ParseRules: %v", err)
+			}
+			results := rules.Evaluate([]Document{testDocument()})
+			if got := len(results) == 1; got != tt.want {
+				t.Errorf("triggered = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	rules, err := ParseRules([]byte(`rules:
+  - name: short-retention
+    resource: VBRJob
+    path: .spec.storage.retentionPolicy.quantity
+    operator: lt
+    value: 14
+    action: warn
+    message: Retention below 14 days
+  - name: job-disabled
+    resource: VBRJob
+    path: .spec.isDisabled
+    operator: equals
+    value: false
+    action: block
+  - name: sobr-only
+    resource: VBRSOBR
+    path: .spec.description
+    operator: exists
+    action: block
+  - name: any-kind
+    resource: "*"
+    path: .spec.owner
+    operator: absent
+    action: warn
+`))
+	if err != nil {
+		t.Fatalf("ParseRules: %v", err)
+	}
+
+	sobr := Document{Kind: "VBRScaleOutRepository", Doc: map[string]interface{}{"spec": map[string]interface{}{"description": "x", "owner": "team"}}}
+	results := rules.Evaluate([]Document{testDocument(), sobr})
+
+	var names []string
+	for _, r := range results {
+		names = append(names, r.Rule.Name)
+	}
+	want := []string{"job-disabled", "sobr-only", "short-retention", "any-kind"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Fatalf("results = %v, want %v (blocking first)", names, want)
+	}
+
+	retention := results[2]
+	if retention.Resolved != "spec.storage.retentionPolicy.quantity" || retention.Value != 7.0 {
+		t.Errorf("result = %+v", retention)
+	}
+	if retention.SetBy != "overlays/dev.yaml" {
+		t.Errorf("SetBy = %q, want overlays/dev.yaml", retention.SetBy)
+	}
+}
+
+func TestParseRules_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		wantErr string
+	}{
+		{"unknown operator", "path: .spec.x\noperator: matches\naction: block", "unknown operator"},
+		{"unknown action", "path: .spec.x\noperator: exists\naction: fail", "action must be block or warn"},
+		{"missing path", "operator: exists\naction: warn", "path is required"},
+		{"lt without number", "path: .spec.x\noperator: lt\nvalue: many\naction: warn", "numeric value"},
+		{"in without list", "path: .spec.x\noperator: in\nvalue: a\naction: warn", "list value"},
+		{"bad regex", "path: .spec.x\noperator: regex\nvalue: \"(\"\naction: warn", "invalid regex"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRules([]byte("rules:\n  - name: bad\n    " + strings.ReplaceAll(tt.rule, "\n", "\n    ")))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseRules_ExampleFile(t *testing.T) {
+	rules, err := LoadRules("../examples/pipelines/policies/policy-rules.yaml")
+	if err != nil {
+		t.Fatalf("LoadRules: %v", err)
+	}
+	if len(rules.Rules) == 0 {
+		t.Error("expected rules in example file")
+	}
+}
@@ -0,0 +1,279 @@
+package resources
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Layer sources used for provenance when a layer has no explicit Source
+const (
+	SourceProfile = "profile"
+	SourceSpec    = "spec"
+	SourceOverlay = "overlay"
+)
+
+// MergeLayer is one profile or overlay in a group's merge chain.
+// Source names the layer in provenance and warnings (usually its path in owlctl.yaml).
+type MergeLayer struct {
+	Source string
+	Spec   ResourceSpec
+}
+
+// LayerConflict records a field set by one layer and changed by a later layer of the same
+// chain (profile over profile, or overlay over overlay). The later layer wins.
+type LayerConflict struct {
+	Path     string
+	Layer    string
+	Previous string
+	From     interface{}
+	To       interface{}
+}
+
+// MergeChainResult is the outcome of MergeChain
+type MergeChainResult struct {
+	Spec ResourceSpec
+	// Provenance maps each leaf field path (dotted, relative to spec; arrays are leaves)
+	// to the Source of the layer that last set it.
+	Provenance map[string]string
+	// Conflicts lists fields overridden between layers of the same chain
+	Conflicts []LayerConflict
+}
+
+// MergeChain merges a spec with ordered profile and overlay chains:
+// profiles[0] -> profiles[1] -> ... -> spec -> overlays[0] -> overlays[1] -> ...
+// Each layer overrides everything before it. The spec's Kind, APIVersion and Metadata.Name
+// are always preserved; labels and annotations are merged additively in the same order.
+// Overlay merge patches and JSON patches run after that overlay's deep merge.
+func MergeChain(spec ResourceSpec, specSource string, profiles, overlays []MergeLayer, opts MergeOptions) (MergeChainResult, error) {
+	if !IsResourceKind(spec.Kind) {
+		return MergeChainResult{}, fmt.Errorf("spec has non-resource kind: %s", spec.Kind)
+	}
+	if specSource == "" {
+		specSource = SourceSpec
+	}
+
+	// Preserve identity from spec
+	result := ResourceSpec{
+		APIVersion: spec.APIVersion,
+		Kind:       spec.Kind,
+		Metadata:   Metadata{Name: spec.Metadata.Name},
+		Spec:       map[string]interface{}{},
+	}
+	tracker := newProvenanceTracker()
+
+	// Step 1: Profiles form the base, each later profile overriding earlier ones
+	for i, profile := range profiles {
+		source := layerSource(profile.Source, SourceProfile, i, len(profiles))
+		if profile.Spec.Kind != KindProfile {
+			return MergeChainResult{}, fmt.Errorf("%s has invalid kind: %s (expected %s)", source, profile.Spec.Kind, KindProfile)
+		}
+		layerOpts, err := layerMergeOptions(opts, spec.Kind, profile.Spec.Metadata.Annotations)
+		if err != nil {
+			return MergeChainResult{}, err
+		}
+		merged, err := DeepMergeMapsWithOptions(result.Spec, profile.Spec.Spec, layerOpts)
+		if err != nil {
+			return MergeChainResult{}, fmt.Errorf("failed to merge %s: %w", source, err)
+		}
+		tracker.record(merged, profile.Spec.Spec, source, SourceProfile)
+		result.Spec = merged
+		result.Metadata.Labels = mergeMaps(result.Metadata.Labels, profile.Spec.Metadata.Labels)
+		result.Metadata.Annotations = mergeMaps(result.Metadata.Annotations, profile.Spec.Metadata.Annotations)
+	}
+
+	// Step 2: Spec over the profiles (profile provides defaults, spec wins)
+	specOpts, err := layerMergeOptions(opts, spec.Kind, spec.Metadata.Annotations)
+	if err != nil {
+		return MergeChainResult{}, err
+	}
+	merged, err := DeepMergeMapsWithOptions(result.Spec, spec.Spec, specOpts)
+	if err != nil {
+		return MergeChainResult{}, fmt.Errorf("failed to merge profile into spec: %w", err)
+	}
+	tracker.record(merged, spec.Spec, specSource, SourceSpec)
+	result.Spec = merged
+	result.Metadata.Labels = mergeMaps(result.Metadata.Labels, spec.Metadata.Labels)
+	result.Metadata.Annotations = mergeMaps(result.Metadata.Annotations, spec.Metadata.Annotations)
+
+	// Step 3: Overlays on top, each later overlay overriding earlier ones
+	for i, overlay := range overlays {
+		source := layerSource(overlay.Source, SourceOverlay, i, len(overlays))
+		if overlay.Spec.Kind != KindOverlay {
+			return MergeChainResult{}, fmt.Errorf("%s has invalid kind: %s (expected %s)", source, overlay.Spec.Kind, KindOverlay)
+		}
+		layerOpts, err := layerMergeOptions(opts, spec.Kind, overlay.Spec.Metadata.Annotations)
+		if err != nil {
+			return MergeChainResult{}, err
+		}
+		merged, err := DeepMergeMapsWithOptions(result.Spec, overlay.Spec.Spec, layerOpts)
+		if err != nil {
+			return MergeChainResult{}, fmt.Errorf("failed to merge %s into spec: %w", source, err)
+		}
+
+		// Merge patch and JSON patch operations run after the deep merge
+		merged, err = applyOverlayPatches(merged, overlay.Spec)
+		if err != nil {
+			return MergeChainResult{}, fmt.Errorf("failed to apply %s patches: %w", source, err)
+		}
+		tracker.record(merged, overlay.Spec.Spec, source, SourceOverlay)
+		result.Spec = merged
+		result.Metadata.Labels = mergeMaps(result.Metadata.Labels, overlay.Spec.Metadata.Labels)
+		result.Metadata.Annotations = mergeMaps(result.Metadata.Annotations, overlay.Spec.Metadata.Annotations)
+	}
+
+	// Drop $patch directives left over from layers that had nothing to patch
+	result.Spec = stripPatchDirectives(result.Spec).(map[string]interface{})
+
+	// Keep nil label/annotation maps when no layer set any
+	if len(result.Metadata.Labels) == 0 {
+		result.Metadata.Labels = copyStringMap(spec.Metadata.Labels)
+	}
+	if len(result.Metadata.Annotations) == 0 {
+		result.Metadata.Annotations = copyStringMap(spec.Metadata.Annotations)
+	}
+
+	return MergeChainResult{
+		Spec:       result,
+		Provenance: tracker.provenance(result.Spec),
+		Conflicts:  tracker.conflicts,
+	}, nil
+}
+
+// layerSource returns the layer's Source, or a generic name ("profile", "overlay 2") if unset
+func layerSource(source, layerType string, index, count int) string {
+	if source != "" {
+		return source
+	}
+	if count == 1 {
+		return layerType
+	}
+	return fmt.Sprintf("%s %d", layerType, index+1)
+}
+
+// provenanceTracker attributes leaf fields to the layer that last set them
+type provenanceTracker struct {
+	values    map[string]interface{}
+	sources   map[string]string
+	types     map[string]string
+	conflicts []LayerConflict
+}
+
+func newProvenanceTracker() *provenanceTracker {
+	return &provenanceTracker{
+		values:  map[string]interface{}{},
+		sources: map[string]string{},
+		types:   map[string]string{},
+	}
+}
+
+// record attributes every leaf that a layer changed, or explicitly set, to that layer.
+// Overriding a different value set by an earlier layer of the same type is a conflict.
+func (t *provenanceTracker) record(merged, layerSpec map[string]interface{}, source, layerType string) {
+	after := FlattenSpec(merged)
+	declared := FlattenSpec(layerSpec)
+
+	paths := make([]string, 0, len(after))
+	for path := range after {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		value := after[path]
+		before, existed := t.values[path]
+		_, set := declared[path]
+		changed := !existed || !patchValuesEqual(before, value)
+		if !changed && !set {
+			continue
+		}
+		if changed && existed && t.types[path] == layerType && t.sources[path] != source && layerType != SourceSpec {
+			t.conflicts = append(t.conflicts, LayerConflict{
+				Path:     path,
+				Layer:    source,
+				Previous: t.sources[path],
+				From:     before,
+				To:       value,
+			})
+		}
+		t.sources[path] = source
+		t.types[path] = layerType
+	}
+	t.values = after
+}
+
+// provenance returns the sources for the leaves present in the final spec
+func (t *provenanceTracker) provenance(final map[string]interface{}) map[string]string {
+	result := make(map[string]string)
+	for path := range FlattenSpec(final) {
+		if source, ok := t.sources[path]; ok {
+			result[path] = source
+		}
+	}
+	return result
+}
+
+// FlattenSpec returns the leaf values of a spec keyed by dotted field path.
+// Maps are descended into; arrays and scalars are leaves. Empty maps are kept as leaves.
+func FlattenSpec(spec map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	flattenInto(result, "", spec)
+	return result
+}
+
+func flattenInto(result map[string]interface{}, path string, value map[string]interface{}) {
+	for k, v := range value {
+		child := joinFieldPath(path, k)
+		if m, ok := v.(map[string]interface{}); ok && len(m) > 0 {
+			flattenInto(result, child, m)
+			continue
+		}
+		result[child] = v
+	}
+}
+
+// ProvenanceFor returns the layer that set a field. For a map field it returns the layers of
+// its leaves, comma-separated in sorted order.
+func ProvenanceFor(provenance map[string]string, path string) string {
+	if source, ok := provenance[path]; ok {
+		return source
+	}
+	seen := map[string]bool{}
+	var sources []string
+	for p, source := range provenance {
+		if strings.HasPrefix(p, path+".") && !seen[source] {
+			seen[source] = true
+			sources = append(sources, source)
+		}
+	}
+	sort.Strings(sources)
+	return strings.Join(sources, ", ")
+}
+
+// MergeYAMLFilesWithProvenance is MergeYAMLFiles that also reports, for each leaf field of the
+// merged spec, whether the base file or the overlay file set it
+func MergeYAMLFilesWithProvenance(basePath, overlayPath string, opts MergeOptions) (ResourceSpec, map[string]string, error) {
+	baseSpec, err := LoadResourceSpec(basePath)
+	if err != nil {
+		return ResourceSpec{}, nil, fmt.Errorf("failed to load base spec: %w", err)
+	}
+
+	overlaySpec, err := LoadResourceSpec(overlayPath)
+	if err != nil {
+		return ResourceSpec{}, nil, fmt.Errorf("failed to load overlay spec: %w", err)
+	}
+
+	if baseSpec.Kind != overlaySpec.Kind {
+		return ResourceSpec{}, nil, fmt.Errorf("kind mismatch: base=%s, overlay=%s", baseSpec.Kind, overlaySpec.Kind)
+	}
+
+	merged, err := MergeResourceSpecs(baseSpec, overlaySpec, opts)
+	if err != nil {
+		return ResourceSpec{}, nil, err
+	}
+
+	tracker := newProvenanceTracker()
+	tracker.record(baseSpec.Spec, baseSpec.Spec, basePath, SourceSpec)
+	tracker.record(merged.Spec, overlaySpec.Spec, overlayPath, SourceOverlay)
+	return merged, tracker.provenance(merged.Spec), nil
+}
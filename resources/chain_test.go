@@ -0,0 +1,232 @@
+package resources
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func chainLayer(source, kind string, labels map[string]string, spec map[string]interface{}) MergeLayer {
+	return MergeLayer{
+		Source: source,
+		Spec: ResourceSpec{
+			APIVersion: "owlctl.veeam.com/v1",
+			Kind:       kind,
+			Metadata:   Metadata{Name: source, Labels: labels},
+			Spec:       spec,
+		},
+	}
+}
+
+func TestMergeChain_OrderAndProvenance(t *testing.T) {
+	profiles := []MergeLayer{
+		chainLayer("org.yaml", KindProfile, map[string]string{"tier": "bronze", "org": "acme"}, map[string]interface{}{
+			"description": "org default",
+			"storage": map[string]interface{}{
+				"compression": "Optimal",
+				"retention":   map[string]interface{}{"type": "Days", "quantity": 7},
+			},
+		}),
+		chainLayer("emea.yaml", KindProfile, map[string]string{"tier": "silver"}, map[string]interface{}{
+			"storage": map[string]interface{}{
+				"retention": map[string]interface{}{"quantity": 14},
+			},
+		}),
+	}
+	overlays := []MergeLayer{
+		chainLayer("compliance.yaml", KindOverlay, nil, map[string]interface{}{
+			"storage":    map[string]interface{}{"retention": map[string]interface{}{"quantity": 30}},
+			"isDisabled": false,
+		}),
+		chainLayer("team.yaml", KindOverlay, map[string]string{"tier": "gold"}, map[string]interface{}{
+			"storage": map[string]interface{}{"retention": map[string]interface{}{"quantity": 21}},
+		}),
+	}
+	spec := ResourceSpec{
+		APIVersion: "owlctl.veeam.com/v1",
+		Kind:       KindVBRJob,
+		Metadata:   Metadata{Name: "sql", Labels: map[string]string{"app": "sql"}},
+		Spec: map[string]interface{}{
+			"description": "SQL job",
+			"isDisabled":  true,
+		},
+	}
+
+	result, err := MergeChain(spec, "sql.yaml", profiles, overlays, DefaultMergeOptions())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Spec.Metadata.Name != "sql" || result.Spec.Kind != KindVBRJob {
+		t.Errorf("identity = %s/%s, want VBRJob/sql", result.Spec.Kind, result.Spec.Metadata.Name)
+	}
+	wantLabels := map[string]string{"app": "sql", "org": "acme", "tier": "gold"}
+	for k, v := range wantLabels {
+		if result.Spec.Metadata.Labels[k] != v {
+			t.Errorf("label %s = %q, want %q", k, result.Spec.Metadata.Labels[k], v)
+		}
+	}
+
+	retention := assertMap(t, assertMap(t, result.Spec.Spec, "storage"), "retention")
+	if retention["quantity"] != 21 {
+		t.Errorf("quantity = %v, want 21 (last overlay wins)", retention["quantity"])
+	}
+
+	wantProvenance := map[string]string{
+		"description":                "sql.yaml",
+		"isDisabled":                 "compliance.yaml",
+		"storage.compression":        "org.yaml",
+		"storage.retention.type":     "org.yaml",
+		"storage.retention.quantity": "team.yaml",
+	}
+	if len(result.Provenance) != len(wantProvenance) {
+		t.Errorf("Provenance = %v, want %v", result.Provenance, wantProvenance)
+	}
+	for path, want := range wantProvenance {
+		if got := result.Provenance[path]; got != want {
+			t.Errorf("Provenance[%s] = %q, want %q", path, got, want)
+		}
+	}
+
+	// Conflicts are only reported between peers: emea over org, team over compliance
+	if len(result.Conflicts) != 2 {
+		t.Fatalf("Conflicts = %+v, want 2", result.Conflicts)
+	}
+	for _, c := range result.Conflicts {
+		if c.Path != "storage.retention.quantity" {
+			t.Errorf("conflict path = %q", c.Path)
+		}
+		switch c.Layer {
+		case "emea.yaml":
+			if c.Previous != "org.yaml" || c.From != 7 || c.To != 14 {
+				t.Errorf("profile conflict = %+v", c)
+			}
+		case "team.yaml":
+			if c.Previous != "compliance.yaml" || c.From != 30 || c.To != 21 {
+				t.Errorf("overlay conflict = %+v", c)
+			}
+		default:
+			t.Errorf("unexpected conflict %+v", c)
+		}
+	}
+}
+
+func TestMergeChain_SameValueIsNotConflict(t *testing.T) {
+	profiles := []MergeLayer{
+		chainLayer("a.yaml", KindProfile, nil, map[string]interface{}{"compression": "Optimal"}),
+		chainLayer("b.yaml", KindProfile, nil, map[string]interface{}{"compression": "Optimal"}),
+	}
+	spec := ResourceSpec{Kind: KindVBRJob, Metadata: Metadata{Name: "job"}, Spec: map[string]interface{}{}}
+
+	result, err := MergeChain(spec, "", profiles, nil, DefaultMergeOptions())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Conflicts) != 0 {
+		t.Errorf("Conflicts = %+v, want none", result.Conflicts)
+	}
+	// The later layer re-declared the field, so it is credited with it
+	if result.Provenance["compression"] != "b.yaml" {
+		t.Errorf("Provenance[compression] = %q, want b.yaml", result.Provenance["compression"])
+	}
+}
+
+func TestMergeChain_PatchProvenance(t *testing.T) {
+	overlay := chainLayer("patch.yaml", KindOverlay, nil, nil)
+	overlay.Spec.JSONPatch = []JSONPatchOperation{{Op: "replace", Path: "/retention", Value: 30}}
+	spec := ResourceSpec{Kind: KindVBRJob, Metadata: Metadata{Name: "job"}, Spec: map[string]interface{}{"retention": 7}}
+
+	result, err := MergeChain(spec, "job.yaml", nil, []MergeLayer{overlay}, DefaultMergeOptions())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Spec.Spec["retention"] != 30 || result.Provenance["retention"] != "patch.yaml" {
+		t.Errorf("retention = %v from %q, want 30 from patch.yaml", result.Spec.Spec["retention"], result.Provenance["retention"])
+	}
+}
+
+func TestMergeChain_InvalidLayerKind(t *testing.T) {
+	spec := ResourceSpec{Kind: KindVBRJob, Metadata: Metadata{Name: "job"}, Spec: map[string]interface{}{}}
+	profiles := []MergeLayer{
+		chainLayer("ok.yaml", KindProfile, nil, nil),
+		chainLayer("wrong.yaml", KindOverlay, nil, nil),
+	}
+
+	_, err := MergeChain(spec, "", profiles, nil, DefaultMergeOptions())
+	if err == nil || !strings.Contains(err.Error(), "wrong.yaml has invalid kind") {
+		t.Errorf("error = %v, want invalid kind for wrong.yaml", err)
+	}
+}
+
+func TestProvenanceFor(t *testing.T) {
+	provenance := map[string]string{
+		"storage.retention.quantity": "team.yaml",
+		"storage.retention.type":     "org.yaml",
+		"storage.compression":        "org.yaml",
+		"description":                "job.yaml",
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"description", "job.yaml"},
+		{"storage.retention.quantity", "team.yaml"},
+		{"storage.retention", "org.yaml, team.yaml"},
+		{"storage", "org.yaml, team.yaml"},
+		{"schedule", ""},
+	}
+	for _, tt := range tests {
+		if got := ProvenanceFor(provenance, tt.path); got != tt.want {
+			t.Errorf("ProvenanceFor(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestMergeYAMLFilesWithProvenance(t *testing.T) {
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "base.yaml")
+	overlayPath := filepath.Join(dir, "prod.yaml")
+	base := `apiVersion: owlctl.veeam.com/v1
+kind: VBRJob
+metadata:
+  name: job
+spec:
+  description: nightly
+  storage:
+    retention: {type: Days, quantity: 7}
+`
+	overlay := `apiVersion: owlctl.veeam.com/v1
+kind: VBRJob
+metadata:
+  name: job
+spec:
+  storage:
+    retention: {quantity: 30}
+`
+	if err := os.WriteFile(basePath, []byte(base), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(overlayPath, []byte(overlay), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	merged, provenance, err := MergeYAMLFilesWithProvenance(basePath, overlayPath, DefaultMergeOptions())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if q := assertMap(t, assertMap(t, merged.Spec, "storage"), "retention")["quantity"]; q != 30 {
+		t.Errorf("quantity = %v, want 30", q)
+	}
+	want := map[string]string{
+		"description":                basePath,
+		"storage.retention.type":     basePath,
+		"storage.retention.quantity": overlayPath,
+	}
+	for path, source := range want {
+		if provenance[path] != source {
+			t.Errorf("provenance[%s] = %q, want %q", path, provenance[path], source)
+		}
+	}
+}
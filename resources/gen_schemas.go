@@ -0,0 +1,32 @@
+//go:build ignore
+
+// gen_schemas writes the JSON Schema for each kind to schemas/<Kind>.schema.json.
+// Run with "go generate ./resources".
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/shapedthought/owlctl/resources"
+)
+
+func main() {
+	if err := os.MkdirAll("schemas", 0755); err != nil {
+		log.Fatal(err)
+	}
+	for _, kind := range resources.SchemaKinds {
+		schema, err := resources.GenerateSchema(kind)
+		if err != nil {
+			log.Fatal(err)
+		}
+		data, err := resources.MarshalSchema(schema)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join("schemas", resources.SchemaFileName(kind)), data, 0644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
@@ -9,6 +9,13 @@ const (
 	KindVBREncryptionPassword     = "VBREncryptionPassword"
 	KindVBRKmsServer              = "VBRKmsServer"
 	KindVBRConfigurationBackup    = "VBRConfigurationBackup"
+	KindVBREmailSettings          = "VBREmailSettings"
+	KindVBRTrafficRules           = "VBRTrafficRules"
+	KindVBRGeneralOptions         = "VBRGeneralOptions"
+	KindVBRMalwareDetection       = "VBRMalwareDetection"
+	KindVBRSecuritySettings       = "VBRSecuritySettings"
+	KindVBRUserRoles              = "VBRUserRoles"
+	KindVBRSyslogServers          = "VBRSyslogServers"
 	KindProfile                   = "Profile"
 	KindOverlay                   = "Overlay"
 )
@@ -22,7 +29,9 @@ func IsMixinKind(kind string) bool {
 // IsResourceKind returns true if the kind represents a VBR resource type.
 func IsResourceKind(kind string) bool {
 	switch kind {
-	case KindVBRJob, KindVBRRepository, KindVBRSOBR, KindVBRScaleOutRepository, KindVBREncryptionPassword, KindVBRKmsServer, KindVBRConfigurationBackup:
+	case KindVBRJob, KindVBRRepository, KindVBRSOBR, KindVBRScaleOutRepository, KindVBREncryptionPassword, KindVBRKmsServer, KindVBRConfigurationBackup,
+		KindVBREmailSettings, KindVBRTrafficRules, KindVBRGeneralOptions,
+		KindVBRMalwareDetection, KindVBRSecuritySettings, KindVBRUserRoles, KindVBRSyslogServers:
 		return true
 	default:
 		return false
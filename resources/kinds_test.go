@@ -44,6 +44,14 @@ func TestIsResourceKind(t *testing.T) {
 		{resources.KindVBRScaleOutRepository, true},
 		{resources.KindVBREncryptionPassword, true},
 		{resources.KindVBRKmsServer, true},
+		{resources.KindVBRConfigurationBackup, true},
+		{resources.KindVBREmailSettings, true},
+		{resources.KindVBRTrafficRules, true},
+		{resources.KindVBRGeneralOptions, true},
+		{resources.KindVBRMalwareDetection, true},
+		{resources.KindVBRSecuritySettings, true},
+		{resources.KindVBRUserRoles, true},
+		{resources.KindVBRSyslogServers, true},
 		{resources.KindProfile, false},
 		{resources.KindOverlay, false},
 		{"Unknown", false},
@@ -13,14 +13,22 @@ const (
 	StrategyMerge MergeStrategy = "merge"
 	// StrategyReplace replaces base value with overlay value
 	StrategyReplace MergeStrategy = "replace"
+	// StrategyKeyed matches arrays of objects by a merge key and merges matching elements.
+	// Arrays without a known key are replaced.
+	StrategyKeyed MergeStrategy = "keyed"
 )
 
 // MergeOptions configures the merge behavior
 type MergeOptions struct {
-	// ArrayStrategy determines how arrays are merged ("merge" or "replace")
+	// ArrayStrategy determines how arrays are merged ("merge", "replace" or "keyed")
 	ArrayStrategy MergeStrategy
 	// NullMeansDelete treats explicit null values as deletion markers
 	NullMeansDelete bool
+	// Kind selects the per-kind default merge keys used by StrategyKeyed (see MergeKeysForKind)
+	Kind string
+	// MergeKeys maps array field paths (e.g. "virtualMachines.includes") to the element field
+	// used to match entries. Paths listed here are always merged by key, whatever ArrayStrategy is.
+	MergeKeys map[string]string
 }
 
 // DefaultMergeOptions returns sensible defaults for strategic merge
@@ -52,23 +60,39 @@ func MergeResourceSpecs(base, overlay ResourceSpec, opts MergeOptions) (Resource
 		result.Metadata.Annotations = mergeMaps(base.Metadata.Annotations, overlay.Metadata.Annotations)
 	}
 
+	layerOpts, err := layerMergeOptions(opts, base.Kind, overlay.Metadata.Annotations)
+	if err != nil {
+		return result, err
+	}
+
 	// Deep merge the spec
-	merged, err := mergeValues(base.Spec, overlay.Spec, opts)
+	merged, err := mergeValues(base.Spec, overlay.Spec, layerOpts)
 	if err != nil {
 		return result, fmt.Errorf("failed to merge specs: %w", err)
 	}
 
-	if mergedMap, ok := merged.(map[string]interface{}); ok {
-		result.Spec = mergedMap
-	} else {
+	mergedMap, ok := merged.(map[string]interface{})
+	if !ok {
 		return result, fmt.Errorf("merged spec is not a map: %T", merged)
 	}
 
+	// Merge patch and JSON patch operations run after the deep merge
+	mergedMap, err = applyOverlayPatches(mergedMap, overlay)
+	if err != nil {
+		return result, fmt.Errorf("failed to apply overlay patches: %w", err)
+	}
+	result.Spec = stripPatchDirectives(mergedMap).(map[string]interface{})
+
 	return result, nil
 }
 
 // mergeValues performs deep merge of two values based on their types
 func mergeValues(base, overlay interface{}, opts MergeOptions) (interface{}, error) {
+	return mergeValuesAt(base, overlay, opts, "")
+}
+
+// mergeValuesAt merges two values found at the given field path (used to look up merge keys)
+func mergeValuesAt(base, overlay interface{}, opts MergeOptions, path string) (interface{}, error) {
 	// If overlay is nil or zero value, keep base
 	if overlay == nil {
 		if opts.NullMeansDelete {
@@ -79,7 +103,7 @@ func mergeValues(base, overlay interface{}, opts MergeOptions) (interface{}, err
 
 	// If base is nil, use overlay
 	if base == nil {
-		return overlay, nil
+		return stripPatchDirectives(overlay), nil
 	}
 
 	baseVal := reflect.ValueOf(base)
@@ -87,15 +111,15 @@ func mergeValues(base, overlay interface{}, opts MergeOptions) (interface{}, err
 
 	// If types don't match, overlay replaces base
 	if baseVal.Type() != overlayVal.Type() {
-		return overlay, nil
+		return stripPatchDirectives(overlay), nil
 	}
 
 	// Handle based on type
 	switch baseVal.Kind() {
 	case reflect.Map:
-		return mergeMapsInterface(base, overlay, opts)
+		return mergeMapsAt(base, overlay, opts, path)
 	case reflect.Slice:
-		return mergeSlices(base, overlay, opts)
+		return mergeSlicesAt(base, overlay, opts, path)
 	default:
 		// For primitives, overlay wins
 		return overlay, nil
@@ -104,6 +128,11 @@ func mergeValues(base, overlay interface{}, opts MergeOptions) (interface{}, err
 
 // mergeMapsInterface merges two map[string]interface{} values
 func mergeMapsInterface(base, overlay interface{}, opts MergeOptions) (interface{}, error) {
+	return mergeMapsAt(base, overlay, opts, "")
+}
+
+// mergeMapsAt merges two map[string]interface{} values found at the given field path
+func mergeMapsAt(base, overlay interface{}, opts MergeOptions, path string) (interface{}, error) {
 	baseMap, ok := base.(map[string]interface{})
 	if !ok {
 		return nil, fmt.Errorf("base is not map[string]interface{}: %T", base)
@@ -125,7 +154,7 @@ func mergeMapsInterface(base, overlay interface{}, opts MergeOptions) (interface
 	for k, overlayValue := range overlayMap {
 		if baseValue, exists := baseMap[k]; exists {
 			// Key exists in both - merge recursively
-			merged, err := mergeValues(baseValue, overlayValue, opts)
+			merged, err := mergeValuesAt(baseValue, overlayValue, opts, joinFieldPath(path, k))
 			if err != nil {
 				return nil, fmt.Errorf("failed to merge key %s: %w", k, err)
 			}
@@ -137,7 +166,7 @@ func mergeMapsInterface(base, overlay interface{}, opts MergeOptions) (interface
 			}
 		} else {
 			// Key only in overlay - add it
-			result[k] = overlayValue
+			result[k] = stripPatchDirectives(overlayValue)
 		}
 	}
 
@@ -146,7 +175,21 @@ func mergeMapsInterface(base, overlay interface{}, opts MergeOptions) (interface
 
 // mergeSlices merges two slice values based on the array strategy
 func mergeSlices(base, overlay interface{}, opts MergeOptions) (interface{}, error) {
-	if opts.ArrayStrategy == StrategyReplace {
+	return mergeSlicesAt(base, overlay, opts, "")
+}
+
+// mergeSlicesAt merges two slice values found at the given field path. Arrays of objects
+// with a merge key (or carrying $patch directives) are merged element by element.
+func mergeSlicesAt(base, overlay interface{}, opts MergeOptions, path string) (interface{}, error) {
+	baseList, baseOK := base.([]interface{})
+	overlayList, overlayOK := overlay.([]interface{})
+	if baseOK && overlayOK {
+		if merged, handled, err := mergeKeyedSlices(baseList, overlayList, opts, path); handled || err != nil {
+			return merged, err
+		}
+	}
+
+	if opts.ArrayStrategy == StrategyReplace || opts.ArrayStrategy == StrategyKeyed {
 		// Simple replacement
 		return overlay, nil
 	}
@@ -217,118 +260,42 @@ func ApplyGroupMergeFromSpec(spec ResourceSpec, profilePath, overlayPath string,
 		return ResourceSpec{}, fmt.Errorf("spec has non-resource kind: %s", spec.Kind)
 	}
 
-	// Preserve identity from spec
-	result := ResourceSpec{
-		APIVersion: spec.APIVersion,
-		Kind:       spec.Kind,
-		Metadata: Metadata{
-			Name:        spec.Metadata.Name,
-			Labels:      copyStringMap(spec.Metadata.Labels),
-			Annotations: copyStringMap(spec.Metadata.Annotations),
-		},
-		Spec: spec.Spec,
-	}
-
-	// Step 1: Merge profile under spec (profile provides defaults, spec wins)
+	var profileSpec, overlaySpec *ResourceSpec
 	if profilePath != "" {
 		profile, err := LoadResourceSpec(profilePath)
 		if err != nil {
 			return ResourceSpec{}, fmt.Errorf("failed to load profile: %w", err)
 		}
-		if profile.Kind != KindProfile {
-			return ResourceSpec{}, fmt.Errorf("profile has invalid kind: %s (expected %s)", profile.Kind, KindProfile)
-		}
-
-		// Profile is base, spec overrides: DeepMergeMapsWithOptions(profile.Spec, spec.Spec, opts)
-		mergedSpec, err := DeepMergeMapsWithOptions(profile.Spec, result.Spec, opts)
-		if err != nil {
-			return ResourceSpec{}, fmt.Errorf("failed to merge profile into spec: %w", err)
-		}
-		result.Spec = mergedSpec
-
-		// Merge labels: profile first, then spec overrides
-		result.Metadata.Labels = mergeMaps(profile.Metadata.Labels, result.Metadata.Labels)
-		result.Metadata.Annotations = mergeMaps(profile.Metadata.Annotations, result.Metadata.Annotations)
+		profileSpec = &profile
 	}
-
-	// Step 2: Merge overlay on top (overlay wins over everything)
 	if overlayPath != "" {
 		overlay, err := LoadResourceSpec(overlayPath)
 		if err != nil {
 			return ResourceSpec{}, fmt.Errorf("failed to load overlay: %w", err)
 		}
-		if overlay.Kind != KindOverlay {
-			return ResourceSpec{}, fmt.Errorf("overlay has invalid kind: %s (expected %s)", overlay.Kind, KindOverlay)
-		}
-
-		// Overlay overrides current spec
-		mergedSpec, err := DeepMergeMapsWithOptions(result.Spec, overlay.Spec, opts)
-		if err != nil {
-			return ResourceSpec{}, fmt.Errorf("failed to merge overlay into spec: %w", err)
-		}
-		result.Spec = mergedSpec
-
-		// Merge labels: current first, then overlay overrides
-		result.Metadata.Labels = mergeMaps(result.Metadata.Labels, overlay.Metadata.Labels)
-		result.Metadata.Annotations = mergeMaps(result.Metadata.Annotations, overlay.Metadata.Annotations)
+		overlaySpec = &overlay
 	}
 
-	return result, nil
+	return ApplyGroupMergeFromSpecs(spec, profileSpec, overlaySpec, opts)
 }
 
 // ApplyGroupMergeFromSpecs performs a 3-way merge using already-loaded profile and overlay.
 // This avoids repeated disk I/O when processing multiple specs in a group.
 // Pass nil for profileSpec or overlaySpec to skip that merge layer.
 func ApplyGroupMergeFromSpecs(spec ResourceSpec, profileSpec, overlaySpec *ResourceSpec, opts MergeOptions) (ResourceSpec, error) {
-	if !IsResourceKind(spec.Kind) {
-		return ResourceSpec{}, fmt.Errorf("spec has non-resource kind: %s", spec.Kind)
-	}
-
-	// Preserve identity from spec
-	result := ResourceSpec{
-		APIVersion: spec.APIVersion,
-		Kind:       spec.Kind,
-		Metadata: Metadata{
-			Name:        spec.Metadata.Name,
-			Labels:      copyStringMap(spec.Metadata.Labels),
-			Annotations: copyStringMap(spec.Metadata.Annotations),
-		},
-		Spec: spec.Spec,
-	}
-
-	// Step 1: Merge profile under spec (profile provides defaults, spec wins)
+	var profiles, overlays []MergeLayer
 	if profileSpec != nil {
-		if profileSpec.Kind != KindProfile {
-			return ResourceSpec{}, fmt.Errorf("profile has invalid kind: %s (expected %s)", profileSpec.Kind, KindProfile)
-		}
-
-		mergedSpec, err := DeepMergeMapsWithOptions(profileSpec.Spec, result.Spec, opts)
-		if err != nil {
-			return ResourceSpec{}, fmt.Errorf("failed to merge profile into spec: %w", err)
-		}
-		result.Spec = mergedSpec
-
-		result.Metadata.Labels = mergeMaps(profileSpec.Metadata.Labels, result.Metadata.Labels)
-		result.Metadata.Annotations = mergeMaps(profileSpec.Metadata.Annotations, result.Metadata.Annotations)
+		profiles = append(profiles, MergeLayer{Spec: *profileSpec})
 	}
-
-	// Step 2: Merge overlay on top (overlay wins over everything)
 	if overlaySpec != nil {
-		if overlaySpec.Kind != KindOverlay {
-			return ResourceSpec{}, fmt.Errorf("overlay has invalid kind: %s (expected %s)", overlaySpec.Kind, KindOverlay)
-		}
-
-		mergedSpec, err := DeepMergeMapsWithOptions(result.Spec, overlaySpec.Spec, opts)
-		if err != nil {
-			return ResourceSpec{}, fmt.Errorf("failed to merge overlay into spec: %w", err)
-		}
-		result.Spec = mergedSpec
-
-		result.Metadata.Labels = mergeMaps(result.Metadata.Labels, overlaySpec.Metadata.Labels)
-		result.Metadata.Annotations = mergeMaps(result.Metadata.Annotations, overlaySpec.Metadata.Annotations)
+		overlays = append(overlays, MergeLayer{Spec: *overlaySpec})
 	}
 
-	return result, nil
+	result, err := MergeChain(spec, "", profiles, overlays, opts)
+	if err != nil {
+		return ResourceSpec{}, err
+	}
+	return result.Spec, nil
 }
 
 // copyStringMap returns a shallow copy of a string map, or nil if input is nil
@@ -0,0 +1,335 @@
+package resources
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// JSONPatchOperation is a single RFC 6902 JSON Patch operation. Paths are JSON Pointers
+// relative to the resource spec (e.g. "/storage/retention/quantity").
+type JSONPatchOperation struct {
+	Op    string      `yaml:"op" json:"op"`
+	Path  string      `yaml:"path" json:"path"`
+	From  string      `yaml:"from,omitempty" json:"from,omitempty"`
+	Value interface{} `yaml:"value,omitempty" json:"value,omitempty"`
+}
+
+// HasPatches reports whether the spec carries a merge patch or JSON patch
+func (r ResourceSpec) HasPatches() bool {
+	return len(r.MergePatch) > 0 || len(r.JSONPatch) > 0
+}
+
+// applyOverlayPatches applies an overlay's merge patch and then its JSON patch to a spec
+func applyOverlayPatches(spec map[string]interface{}, overlay ResourceSpec) (map[string]interface{}, error) {
+	result := spec
+	if len(overlay.MergePatch) > 0 {
+		result = ApplyMergePatch(result, overlay.MergePatch)
+	}
+	if len(overlay.JSONPatch) > 0 {
+		patched, err := ApplyJSONPatch(result, overlay.JSONPatch)
+		if err != nil {
+			return nil, err
+		}
+		result = patched
+	}
+	return result, nil
+}
+
+// ApplyMergePatch applies an RFC 7386 JSON Merge Patch to a document. Null values delete keys,
+// objects are merged recursively and everything else replaces the target value.
+// The input document is not modified.
+func ApplyMergePatch(target, patch map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(target))
+	for k, v := range target {
+		result[k] = v
+	}
+	for k, pv := range patch {
+		if pv == nil {
+			delete(result, k)
+			continue
+		}
+		patchMap, patchIsMap := pv.(map[string]interface{})
+		if !patchIsMap {
+			result[k] = deepCopyValue(pv)
+			continue
+		}
+		targetMap, _ := result[k].(map[string]interface{})
+		result[k] = ApplyMergePatch(targetMap, patchMap)
+	}
+	return result
+}
+
+// ApplyJSONPatch applies RFC 6902 operations in order to a copy of the document.
+// A failing "test" operation, or any operation on a missing path, returns an error.
+func ApplyJSONPatch(doc map[string]interface{}, ops []JSONPatchOperation) (map[string]interface{}, error) {
+	var root interface{} = deepCopyValue(doc)
+
+	for i, op := range ops {
+		var err error
+		switch op.Op {
+		case "add":
+			root, err = patchAdd(root, op.Path, deepCopyValue(op.Value))
+		case "remove":
+			root, _, err = patchRemove(root, op.Path)
+		case "replace":
+			if op.Path == "" {
+				root = deepCopyValue(op.Value)
+				break
+			}
+			root, _, err = patchRemove(root, op.Path)
+			if err == nil {
+				root, err = patchAdd(root, op.Path, deepCopyValue(op.Value))
+			}
+		case "move":
+			if op.Path == op.From || strings.HasPrefix(op.Path, op.From+"/") {
+				if op.Path != op.From {
+					err = fmt.Errorf("cannot move %q into its own child", op.From)
+				}
+				break
+			}
+			var value interface{}
+			root, value, err = patchRemove(root, op.From)
+			if err == nil {
+				root, err = patchAdd(root, op.Path, value)
+			}
+		case "copy":
+			var value interface{}
+			value, err = patchGet(root, op.From)
+			if err == nil {
+				root, err = patchAdd(root, op.Path, deepCopyValue(value))
+			}
+		case "test":
+			var current interface{}
+			current, err = patchGet(root, op.Path)
+			if err == nil && !patchValuesEqual(current, op.Value) {
+				err = fmt.Errorf("test failed: value is %v, expected %v", current, op.Value)
+			}
+		default:
+			err = fmt.Errorf("unsupported op %q (expected add, remove, replace, move, copy or test)", op.Op)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("json patch operation %d (%s %s): %w", i+1, op.Op, op.Path, err)
+		}
+	}
+
+	result, ok := root.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("json patch result is not an object: %T", root)
+	}
+	return result, nil
+}
+
+// parsePointer splits a JSON Pointer into unescaped reference tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q: must start with /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// arrayIndex parses an array index token. allowEnd permits "-" (one past the last element).
+func arrayIndex(token string, length int, allowEnd bool) (int, error) {
+	if token == "-" && allowEnd {
+		return length, nil
+	}
+	idx, err := strconv.Atoi(token)
+	if err != nil || idx < 0 || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	max := length - 1
+	if allowEnd {
+		max = length
+	}
+	if idx > max {
+		return 0, fmt.Errorf("array index %d out of range", idx)
+	}
+	return idx, nil
+}
+
+func patchGet(root interface{}, pointer string) (interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	current := root
+	for _, token := range tokens {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("path not found")
+			}
+			current = value
+		case []interface{}:
+			idx, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			current = node[idx]
+		default:
+			return nil, fmt.Errorf("path not found")
+		}
+	}
+	return current, nil
+}
+
+// patchAdd sets the value at pointer, inserting into arrays, and returns the (possibly new) root
+func patchAdd(root interface{}, pointer string, value interface{}) (interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return updateParent(root, tokens, func(parent interface{}, last string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			node[last] = value
+			return node, nil
+		case []interface{}:
+			idx, err := arrayIndex(last, len(node), true)
+			if err != nil {
+				return nil, err
+			}
+			node = append(node, nil)
+			copy(node[idx+1:], node[idx:])
+			node[idx] = value
+			return node, nil
+		default:
+			return nil, fmt.Errorf("path not found")
+		}
+	})
+}
+
+// patchRemove deletes the value at pointer and returns the new root and the removed value
+func patchRemove(root interface{}, pointer string) (interface{}, interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil, fmt.Errorf("cannot remove the whole spec")
+	}
+	var removed interface{}
+	newRoot, err := updateParent(root, tokens, func(parent interface{}, last string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			value, ok := node[last]
+			if !ok {
+				return nil, fmt.Errorf("path not found")
+			}
+			removed = value
+			delete(node, last)
+			return node, nil
+		case []interface{}:
+			idx, err := arrayIndex(last, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			removed = node[idx]
+			return append(node[:idx], node[idx+1:]...), nil
+		default:
+			return nil, fmt.Errorf("path not found")
+		}
+	})
+	return newRoot, removed, err
+}
+
+// updateParent walks to the parent of the last token, applies fn to it and writes the
+// (possibly reallocated) parent back, so array inserts and removals propagate to the root.
+func updateParent(root interface{}, tokens []string, fn func(parent interface{}, last string) (interface{}, error)) (interface{}, error) {
+	if len(tokens) == 1 {
+		return fn(root, tokens[0])
+	}
+
+	token := tokens[0]
+	switch node := root.(type) {
+	case map[string]interface{}:
+		child, ok := node[token]
+		if !ok {
+			return nil, fmt.Errorf("path not found")
+		}
+		updated, err := updateParent(child, tokens[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		node[token] = updated
+		return node, nil
+	case []interface{}:
+		idx, err := arrayIndex(token, len(node), false)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := updateParent(node[idx], tokens[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		node[idx] = updated
+		return node, nil
+	default:
+		return nil, fmt.Errorf("path not found")
+	}
+}
+
+// patchValuesEqual compares values for the "test" operation, treating numeric types as equal
+// when their values are (YAML decodes integers as int, JSON as float64).
+func patchValuesEqual(a, b interface{}) bool {
+	return reflect.DeepEqual(normalizeNumbers(a), normalizeNumbers(b))
+}
+
+func normalizeNumbers(v interface{}) interface{} {
+	switch val := v.(type) {
+	case int:
+		return float64(val)
+	case int64:
+		return float64(val)
+	case uint64:
+		return float64(val)
+	case float32:
+		return float64(val)
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(val))
+		for k, child := range val {
+			result[k] = normalizeNumbers(child)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(val))
+		for i, child := range val {
+			result[i] = normalizeNumbers(child)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+// deepCopyValue copies maps and slices so patches never modify their inputs
+func deepCopyValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(val))
+		for k, child := range val {
+			result[k] = deepCopyValue(child)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(val))
+		for i, child := range val {
+			result[i] = deepCopyValue(child)
+		}
+		return result
+	default:
+		return v
+	}
+}
@@ -0,0 +1,242 @@
+package resources
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func patchTestDoc() map[string]interface{} {
+	return map[string]interface{}{
+		"description": "Nightly",
+		"storage": map[string]interface{}{
+			"retention": map[string]interface{}{"type": "Days", "quantity": 7},
+		},
+		"objects": []interface{}{"vm-1", "vm-2"},
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		ops     []JSONPatchOperation
+		check   func(t *testing.T, got map[string]interface{})
+		wantErr string
+	}{
+		{
+			name: "replace nested value",
+			ops:  []JSONPatchOperation{{Op: "replace", Path: "/storage/retention/quantity", Value: 30}},
+			check: func(t *testing.T, got map[string]interface{}) {
+				retention := got["storage"].(map[string]interface{})["retention"].(map[string]interface{})
+				if retention["quantity"] != 30 {
+					t.Errorf("quantity = %v, want 30", retention["quantity"])
+				}
+			},
+		},
+		{
+			name: "add to map and append to array",
+			ops: []JSONPatchOperation{
+				{Op: "add", Path: "/isDisabled", Value: true},
+				{Op: "add", Path: "/objects/-", Value: "vm-3"},
+				{Op: "add", Path: "/objects/0", Value: "vm-0"},
+			},
+			check: func(t *testing.T, got map[string]interface{}) {
+				if got["isDisabled"] != true {
+					t.Errorf("isDisabled = %v, want true", got["isDisabled"])
+				}
+				want := []interface{}{"vm-0", "vm-1", "vm-2", "vm-3"}
+				if !reflect.DeepEqual(got["objects"], want) {
+					t.Errorf("objects = %v, want %v", got["objects"], want)
+				}
+			},
+		},
+		{
+			name: "remove array element and key",
+			ops: []JSONPatchOperation{
+				{Op: "remove", Path: "/objects/0"},
+				{Op: "remove", Path: "/description"},
+			},
+			check: func(t *testing.T, got map[string]interface{}) {
+				if _, ok := got["description"]; ok {
+					t.Error("description should be removed")
+				}
+				if !reflect.DeepEqual(got["objects"], []interface{}{"vm-2"}) {
+					t.Errorf("objects = %v, want [vm-2]", got["objects"])
+				}
+			},
+		},
+		{
+			name: "move and copy",
+			ops: []JSONPatchOperation{
+				{Op: "copy", From: "/storage/retention", Path: "/gfsRetention"},
+				{Op: "move", From: "/description", Path: "/storage/description"},
+			},
+			check: func(t *testing.T, got map[string]interface{}) {
+				if _, ok := got["description"]; ok {
+					t.Error("description should have moved")
+				}
+				if got["storage"].(map[string]interface{})["description"] != "Nightly" {
+					t.Error("storage.description should be Nightly")
+				}
+				if !reflect.DeepEqual(got["gfsRetention"], map[string]interface{}{"type": "Days", "quantity": 7}) {
+					t.Errorf("gfsRetention = %v", got["gfsRetention"])
+				}
+			},
+		},
+		{
+			name: "escaped pointer tokens",
+			ops:  []JSONPatchOperation{{Op: "add", Path: "/a~1b~0c", Value: 1}},
+			check: func(t *testing.T, got map[string]interface{}) {
+				if got["a/b~c"] != 1 {
+					t.Errorf("a/b~c = %v, want 1", got["a/b~c"])
+				}
+			},
+		},
+		{
+			name: "passing test treats numeric types as equal",
+			ops: []JSONPatchOperation{
+				{Op: "test", Path: "/storage/retention/quantity", Value: 7.0},
+				{Op: "test", Path: "/objects", Value: []interface{}{"vm-1", "vm-2"}},
+			},
+		},
+		{
+			name:    "failing test aborts the patch",
+			ops:     []JSONPatchOperation{{Op: "test", Path: "/storage/retention/type", Value: "RestorePoints"}, {Op: "remove", Path: "/objects"}},
+			wantErr: "operation 1 (test /storage/retention/type): test failed",
+		},
+		{
+			name:    "test on missing path fails",
+			ops:     []JSONPatchOperation{{Op: "test", Path: "/schedule/daily", Value: "22:00"}},
+			wantErr: "path not found",
+		},
+		{
+			name:    "replace missing path fails",
+			ops:     []JSONPatchOperation{{Op: "replace", Path: "/missing", Value: 1}},
+			wantErr: "path not found",
+		},
+		{
+			name:    "array index out of range",
+			ops:     []JSONPatchOperation{{Op: "remove", Path: "/objects/5"}},
+			wantErr: "out of range",
+		},
+		{
+			name:    "move into own child",
+			ops:     []JSONPatchOperation{{Op: "move", From: "/storage", Path: "/storage/inner"}},
+			wantErr: "own child",
+		},
+		{
+			name:    "unsupported op",
+			ops:     []JSONPatchOperation{{Op: "increment", Path: "/x"}},
+			wantErr: "unsupported op",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := patchTestDoc()
+			got, err := ApplyJSONPatch(doc, tt.ops)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.check != nil {
+				tt.check(t, got)
+			}
+			if !reflect.DeepEqual(doc, patchTestDoc()) {
+				t.Error("input document was modified")
+			}
+		})
+	}
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	doc := patchTestDoc()
+	patch := map[string]interface{}{
+		"description": nil,
+		"storage": map[string]interface{}{
+			"retention": map[string]interface{}{"quantity": 14},
+		},
+		"objects":  []interface{}{"vm-9"},
+		"schedule": map[string]interface{}{"daily": "02:00", "unset": nil},
+	}
+
+	got := ApplyMergePatch(doc, patch)
+
+	want := map[string]interface{}{
+		"storage": map[string]interface{}{
+			"retention": map[string]interface{}{"type": "Days", "quantity": 14},
+		},
+		"objects":  []interface{}{"vm-9"},
+		"schedule": map[string]interface{}{"daily": "02:00"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ApplyMergePatch() = %v\nwant %v", got, want)
+	}
+	if !reflect.DeepEqual(doc, patchTestDoc()) {
+		t.Error("input document was modified")
+	}
+}
+
+func TestApplyGroupMergeFromSpec_PatchOverlay(t *testing.T) {
+	dir := t.TempDir()
+	overlayPath := filepath.Join(dir, "overlay.yaml")
+	overlayYAML := `apiVersion: owlctl.veeam.com/v1
+kind: Overlay
+metadata:
+  name: retention-guard
+spec:
+  description: Managed
+mergePatch:
+  storage:
+    compression: null
+jsonPatch:
+  - op: test
+    path: /storage/retention/type
+    value: Days
+  - op: replace
+    path: /storage/retention/quantity
+    value: 30
+`
+	if err := os.WriteFile(overlayPath, []byte(overlayYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	spec := ResourceSpec{
+		APIVersion: "owlctl.veeam.com/v1",
+		Kind:       KindVBRJob,
+		Metadata:   Metadata{Name: "job"},
+		Spec: map[string]interface{}{
+			"storage": map[string]interface{}{
+				"compression": "Optimal",
+				"retention":   map[string]interface{}{"type": "Days", "quantity": 7},
+			},
+		},
+	}
+
+	got, err := ApplyGroupMergeFromSpec(spec, "", overlayPath, DefaultMergeOptions())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]interface{}{
+		"description": "Managed",
+		"storage": map[string]interface{}{
+			"retention": map[string]interface{}{"type": "Days", "quantity": 30},
+		},
+	}
+	if !reflect.DeepEqual(got.Spec, want) {
+		t.Errorf("Spec = %v\nwant %v", got.Spec, want)
+	}
+
+	// A test operation that doesn't match fails the merge
+	spec.Spec["storage"].(map[string]interface{})["retention"] = map[string]interface{}{"type": "RestorePoints", "quantity": 7}
+	if _, err := ApplyGroupMergeFromSpec(spec, "", overlayPath, DefaultMergeOptions()); err == nil || !strings.Contains(err.Error(), "test failed") {
+		t.Errorf("error = %v, want test failure", err)
+	}
+}
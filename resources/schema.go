@@ -0,0 +1,242 @@
+package resources
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/shapedthought/owlctl/models"
+)
+
+//go:generate go run gen_schemas.go
+
+// APIVersion is the apiVersion every owlctl spec declares
+const APIVersion = "owlctl.veeam.com/v1"
+
+// embeddedSchemas holds the generated JSON Schema for each kind (schemas/<Kind>.schema.json).
+// Regenerate with "go generate ./resources" after changing models or the simplified spec types.
+//
+//go:embed schemas/*.schema.json
+var embeddedSchemas embed.FS
+
+// SchemaKinds lists the kinds with a JSON Schema, in export order
+var SchemaKinds = []string{
+	KindVBRJob,
+	KindVBRRepository,
+	KindVBRScaleOutRepository,
+	KindVBREncryptionPassword,
+	KindVBRKmsServer,
+	KindVBRConfigurationBackup,
+	KindVBREmailSettings,
+	KindVBRTrafficRules,
+	KindVBRGeneralOptions,
+	KindProfile,
+	KindOverlay,
+}
+
+// localRepositoryTypes are the repository types the VbrRepoGet model describes
+var localRepositoryTypes = []interface{}{"WinLocal", "LinuxLocal", "LinuxHardened"}
+
+// schemaKind maps kind aliases to the kind their schema is stored under
+func schemaKind(kind string) string {
+	if kind == KindVBRSOBR {
+		return KindVBRScaleOutRepository
+	}
+	return kind
+}
+
+// SchemaFileName returns the file name a kind's schema is embedded and exported as
+func SchemaFileName(kind string) string {
+	return schemaKind(kind) + ".schema.json"
+}
+
+// Schema returns the embedded JSON Schema document for a kind
+func Schema(kind string) ([]byte, error) {
+	data, err := embeddedSchemas.ReadFile("schemas/" + SchemaFileName(kind))
+	if err != nil {
+		return nil, fmt.Errorf("no schema for kind %q", kind)
+	}
+	return data, nil
+}
+
+// GenerateSchema builds the JSON Schema for a kind from the models structs and simplified spec
+// types. The embedded schemas are this output; a test keeps the two in sync.
+func GenerateSchema(kind string) (map[string]interface{}, error) {
+	var spec map[string]interface{}
+
+	switch schemaKind(kind) {
+	case KindVBRJob:
+		// Jobs are written either in the full API shape (owlctl job export) or the simplified
+		// format (--simplified). The full shape is modelled for vSphere jobs only; other job
+		// types are accepted as long as they declare their type.
+		simplified := schemaForType(reflect.TypeOf(VBRJobSpec{}))
+		addSchemaProperty(simplified, "name", map[string]interface{}{"type": "string"})
+		spec = map[string]interface{}{
+			"anyOf": []interface{}{
+				describe(schemaForType(reflect.TypeOf(models.VbrJobGet{})), "vSphere backup job (VBR API format)"),
+				describe(simplified, "Simplified job format"),
+				map[string]interface{}{
+					"description": "Other job types (not checked field by field)",
+					"type":        "object",
+					"required":    []interface{}{"type"},
+					"properties": map[string]interface{}{
+						"type": map[string]interface{}{"not": map[string]interface{}{"enum": []interface{}{"VSphereBackup"}}},
+					},
+				},
+			},
+		}
+	case KindVBRRepository:
+		// The repository model covers local disk repositories only; SMB, NFS and object
+		// storage repositories are accepted as long as they declare their type.
+		spec = map[string]interface{}{
+			"anyOf": []interface{}{
+				describe(schemaForType(reflect.TypeOf(models.VbrRepoGet{})), "Local disk repository (VBR API format)"),
+				map[string]interface{}{
+					"description": "Other repository types (not checked field by field)",
+					"type":        "object",
+					"required":    []interface{}{"type"},
+					"properties": map[string]interface{}{
+						"type": map[string]interface{}{"not": map[string]interface{}{"enum": localRepositoryTypes}},
+					},
+				},
+			},
+		}
+	case KindVBRScaleOutRepository:
+		spec = schemaForType(reflect.TypeOf(models.VbrSobrGet{}))
+		addSchemaProperty(spec, "status", map[string]interface{}{"type": "string"})
+	case KindVBREncryptionPassword:
+		spec = schemaForType(reflect.TypeOf(models.VbrEncryptionPasswordGet{}))
+	case KindVBRKmsServer:
+		spec = schemaForType(reflect.TypeOf(models.VbrKmsServerGet{}))
+	case KindVBRConfigurationBackup, KindVBREmailSettings, KindVBRTrafficRules, KindVBRGeneralOptions:
+		// Settings singletons have no typed model; their fields pass through as-is
+		spec = map[string]interface{}{"type": "object"}
+	case KindProfile, KindOverlay:
+		spec = map[string]interface{}{
+			"type":        "object",
+			"description": "Checked against the resource kinds it can be merged into",
+		}
+	default:
+		return nil, fmt.Errorf("no schema for kind %q", kind)
+	}
+
+	stringMap := map[string]interface{}{
+		"type":                 "object",
+		"additionalProperties": map[string]interface{}{"type": "string"},
+	}
+	properties := map[string]interface{}{
+		"apiVersion": map[string]interface{}{"type": "string", "enum": []interface{}{APIVersion}},
+		"kind":       map[string]interface{}{"type": "string", "enum": kindAliases(kind)},
+		"metadata": map[string]interface{}{
+			"type":                 "object",
+			"required":             []interface{}{"name"},
+			"additionalProperties": false,
+			"properties": map[string]interface{}{
+				"name":        map[string]interface{}{"type": "string", "minLength": 1},
+				"labels":      stringMap,
+				"annotations": stringMap,
+			},
+		},
+		"spec": spec,
+	}
+	if kind == KindOverlay {
+		properties["mergePatch"] = map[string]interface{}{"type": "object"}
+		operation := schemaForType(reflect.TypeOf(JSONPatchOperation{}))
+		operation["required"] = []interface{}{"op", "path"}
+		operation["properties"].(map[string]interface{})["op"] = map[string]interface{}{
+			"type": "string",
+			"enum": []interface{}{"add", "remove", "replace", "move", "copy", "test"},
+		}
+		properties["jsonPatch"] = map[string]interface{}{"type": "array", "items": operation}
+	}
+
+	return map[string]interface{}{
+		"$schema":              "https://json-schema.org/draft/2020-12/schema",
+		"title":                fmt.Sprintf("owlctl %s", kind),
+		"type":                 "object",
+		"required":             []interface{}{"apiVersion", "kind", "metadata"},
+		"additionalProperties": false,
+		"properties":           properties,
+	}, nil
+}
+
+// MarshalSchema renders a schema the way it is embedded: indented JSON with a trailing newline
+func MarshalSchema(schema map[string]interface{}) ([]byte, error) {
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// schemaForType derives a JSON Schema from a Go type using its json tags.
+// Structs are closed (additionalProperties: false) so misspelt fields are caught;
+// interface{} and map fields accept anything.
+func schemaForType(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			properties[name] = schemaForType(field.Type)
+		}
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object"}
+	case reflect.Slice, reflect.Array:
+		items := schemaForType(t.Elem())
+		if len(items) == 0 {
+			return map[string]interface{}{"type": "array"}
+		}
+		return map[string]interface{}{"type": "array", "items": items}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	default:
+		// interface{}: any value
+		return map[string]interface{}{}
+	}
+}
+
+// kindAliases returns the kind names accepted for a schema
+func kindAliases(kind string) []interface{} {
+	if schemaKind(kind) == KindVBRScaleOutRepository {
+		return []interface{}{KindVBRScaleOutRepository, KindVBRSOBR}
+	}
+	return []interface{}{kind}
+}
+
+func addSchemaProperty(schema map[string]interface{}, name string, property map[string]interface{}) {
+	schema["properties"].(map[string]interface{})[name] = property
+}
+
+func describe(schema map[string]interface{}, description string) map[string]interface{} {
+	schema["description"] = description
+	return schema
+}
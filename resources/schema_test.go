@@ -0,0 +1,46 @@
+package resources
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestEmbeddedSchemasUpToDate(t *testing.T) {
+	for _, kind := range SchemaKinds {
+		schema, err := GenerateSchema(kind)
+		if err != nil {
+			t.Fatalf("GenerateSchema(%s): %v", kind, err)
+		}
+		want, err := MarshalSchema(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := Schema(kind)
+		if err != nil {
+			t.Fatalf("Schema(%s): %v", kind, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("embedded schema for %s is out of date: run \"go generate ./resources\"", kind)
+		}
+	}
+}
+
+func TestSchema_SOBRAlias(t *testing.T) {
+	data, err := Schema(KindVBRSOBR)
+	if err != nil {
+		t.Fatalf("Schema(VBRSOBR): %v", err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+	kinds := schema["properties"].(map[string]interface{})["kind"].(map[string]interface{})["enum"].([]interface{})
+	if len(kinds) != 2 || kinds[0] != KindVBRScaleOutRepository || kinds[1] != KindVBRSOBR {
+		t.Errorf("kind enum = %v, want both scale-out repository kinds", kinds)
+	}
+
+	if _, err := Schema("VBRUnknown"); err == nil {
+		t.Error("expected error for unknown kind")
+	}
+}
@@ -0,0 +1,226 @@
+package resources
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Selector matches resources by their metadata labels.
+//
+// Supported syntax (comma-separated requirements, all must match):
+//
+//	env=prod        label equals value ("==" is also accepted)
+//	env!=dev        label absent or not equal to value
+//	tier in (a,b)   label equals one of the values
+//	tier notin (a)  label absent or not one of the values
+//	critical        label exists
+//	!critical       label does not exist
+type Selector struct {
+	requirements []requirement
+}
+
+type requirement struct {
+	key    string
+	op     string // "=", "!=", "in", "notin", "exists", "!exists"
+	values []string
+}
+
+// ParseSelector parses a label selector expression. An empty expression matches everything.
+func ParseSelector(expr string) (Selector, error) {
+	var sel Selector
+
+	terms, err := splitSelectorTerms(expr)
+	if err != nil {
+		return sel, err
+	}
+
+	for _, term := range terms {
+		req, err := parseRequirement(term)
+		if err != nil {
+			return Selector{}, err
+		}
+		sel.requirements = append(sel.requirements, req)
+	}
+	return sel, nil
+}
+
+// SelectorFromLabels builds an equality selector matching all of the given labels
+func SelectorFromLabels(labels map[string]string) Selector {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sel Selector
+	for _, k := range keys {
+		sel.requirements = append(sel.requirements, requirement{key: k, op: "=", values: []string{labels[k]}})
+	}
+	return sel
+}
+
+// And returns a selector requiring both s and other to match
+func (s Selector) And(other Selector) Selector {
+	combined := make([]requirement, 0, len(s.requirements)+len(other.requirements))
+	combined = append(combined, s.requirements...)
+	combined = append(combined, other.requirements...)
+	return Selector{requirements: combined}
+}
+
+// Empty reports whether the selector has no requirements (and so matches everything)
+func (s Selector) Empty() bool {
+	return len(s.requirements) == 0
+}
+
+// Matches reports whether the given labels satisfy every requirement in the selector
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, r := range s.requirements {
+		if !r.matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the canonical form of the selector
+func (s Selector) String() string {
+	parts := make([]string, 0, len(s.requirements))
+	for _, r := range s.requirements {
+		switch r.op {
+		case "exists":
+			parts = append(parts, r.key)
+		case "!exists":
+			parts = append(parts, "!"+r.key)
+		case "in", "notin":
+			parts = append(parts, fmt.Sprintf("%s %s (%s)", r.key, r.op, strings.Join(r.values, ",")))
+		default:
+			parts = append(parts, r.key+r.op+r.values[0])
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func (r requirement) matches(labels map[string]string) bool {
+	value, exists := labels[r.key]
+	switch r.op {
+	case "exists":
+		return exists
+	case "!exists":
+		return !exists
+	case "=":
+		return exists && value == r.values[0]
+	case "!=":
+		return !exists || value != r.values[0]
+	case "in":
+		return exists && containsString(r.values, value)
+	case "notin":
+		return !exists || !containsString(r.values, value)
+	}
+	return false
+}
+
+// splitSelectorTerms splits a selector on top-level commas, keeping "in (a,b)" lists intact
+func splitSelectorTerms(expr string) ([]string, error) {
+	var terms []string
+	depth := 0
+	start := 0
+
+	for i, c := range expr {
+		switch c {
+		case '(':
+			depth++
+			if depth > 1 {
+				return nil, fmt.Errorf("invalid selector %q: nested parentheses", expr)
+			}
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("invalid selector %q: unbalanced parentheses", expr)
+			}
+		case ',':
+			if depth == 0 {
+				terms = append(terms, expr[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("invalid selector %q: unbalanced parentheses", expr)
+	}
+	terms = append(terms, expr[start:])
+
+	var result []string
+	for _, t := range terms {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			if strings.TrimSpace(expr) == "" {
+				continue
+			}
+			return nil, fmt.Errorf("invalid selector %q: empty requirement", expr)
+		}
+		result = append(result, t)
+	}
+	return result, nil
+}
+
+func parseRequirement(term string) (requirement, error) {
+	// Set-based: "key in (a,b)" / "key notin (a,b)"
+	if open := strings.Index(term, "("); open != -1 {
+		if !strings.HasSuffix(term, ")") {
+			return requirement{}, fmt.Errorf("invalid selector requirement %q", term)
+		}
+		fields := strings.Fields(term[:open])
+		if len(fields) != 2 || (fields[1] != "in" && fields[1] != "notin") {
+			return requirement{}, fmt.Errorf("invalid selector requirement %q: expected 'key in (...)' or 'key notin (...)'", term)
+		}
+
+		var values []string
+		for _, v := range strings.Split(term[open+1:len(term)-1], ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		if len(values) == 0 {
+			return requirement{}, fmt.Errorf("invalid selector requirement %q: empty value list", term)
+		}
+		return requirement{key: fields[0], op: fields[1], values: values}, nil
+	}
+
+	// Equality-based: "key!=value", "key==value", "key=value"
+	for _, op := range []string{"!=", "==", "="} {
+		if idx := strings.Index(term, op); idx != -1 {
+			key := strings.TrimSpace(term[:idx])
+			value := strings.TrimSpace(term[idx+len(op):])
+			if key == "" {
+				return requirement{}, fmt.Errorf("invalid selector requirement %q: missing key", term)
+			}
+			if op == "==" {
+				op = "="
+			}
+			return requirement{key: key, op: op, values: []string{value}}, nil
+		}
+	}
+
+	// Existence: "key" / "!key"
+	if strings.HasPrefix(term, "!") {
+		key := strings.TrimSpace(term[1:])
+		if key == "" || strings.ContainsAny(key, " \t") {
+			return requirement{}, fmt.Errorf("invalid selector requirement %q", term)
+		}
+		return requirement{key: key, op: "!exists"}, nil
+	}
+	if strings.ContainsAny(term, " \t") {
+		return requirement{}, fmt.Errorf("invalid selector requirement %q", term)
+	}
+	return requirement{key: term, op: "exists"}, nil
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
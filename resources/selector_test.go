@@ -0,0 +1,85 @@
+package resources_test
+
+import (
+	"testing"
+
+	"github.com/shapedthought/owlctl/resources"
+)
+
+func TestParseSelector_Matches(t *testing.T) {
+	labels := map[string]string{"env": "prod", "tier": "gold", "app": "sql"}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"", true},
+		{"env=prod", true},
+		{"env==prod", true},
+		{"env=dev", false},
+		{"env!=dev", true},
+		{"env!=prod", false},
+		{"missing!=x", true},
+		{"tier in (gold,silver)", true},
+		{"tier in (bronze)", false},
+		{"tier notin (bronze, silver)", true},
+		{"tier notin (gold)", false},
+		{"app", true},
+		{"!app", false},
+		{"!owner", true},
+		{"env=prod,tier in (gold,silver),app!=web", true},
+		{"env=prod, tier in (silver)", false},
+	}
+
+	for _, tt := range tests {
+		sel, err := resources.ParseSelector(tt.expr)
+		if err != nil {
+			t.Errorf("ParseSelector(%q) unexpected error: %v", tt.expr, err)
+			continue
+		}
+		if got := sel.Matches(labels); got != tt.want {
+			t.Errorf("ParseSelector(%q).Matches() = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseSelector_Errors(t *testing.T) {
+	invalid := []string{
+		"env=prod,,tier=gold",
+		"tier in (gold",
+		"tier in ()",
+		"tier between (a,b)",
+		"=prod",
+		"two words",
+		"tier in ((a))",
+	}
+
+	for _, expr := range invalid {
+		if _, err := resources.ParseSelector(expr); err == nil {
+			t.Errorf("ParseSelector(%q) expected error, got nil", expr)
+		}
+	}
+}
+
+func TestSelector_String(t *testing.T) {
+	sel, err := resources.ParseSelector("env==prod, tier in (gold, silver),!legacy")
+	if err != nil {
+		t.Fatalf("ParseSelector failed: %v", err)
+	}
+	if got, want := sel.String(), "env=prod,tier in (gold,silver),!legacy"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestSelectorFromLabels(t *testing.T) {
+	sel := resources.SelectorFromLabels(map[string]string{"tier": "gold", "env": "prod"})
+	if got, want := sel.String(), "env=prod,tier=gold"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if !sel.Matches(map[string]string{"env": "prod", "tier": "gold", "extra": "x"}) {
+		t.Error("Expected selector to match superset of labels")
+	}
+	if sel.Matches(map[string]string{"env": "prod"}) {
+		t.Error("Expected selector not to match missing label")
+	}
+}
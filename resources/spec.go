@@ -13,6 +13,11 @@ type ResourceSpec struct {
 	Kind       string                 `yaml:"kind" json:"kind"`
 	Metadata   Metadata               `yaml:"metadata" json:"metadata"`
 	Spec       map[string]interface{} `yaml:"spec" json:"spec"`
+
+	// MergePatch is an RFC 7386 merge patch applied to spec after the deep merge (Overlay only)
+	MergePatch map[string]interface{} `yaml:"mergePatch,omitempty" json:"mergePatch,omitempty"`
+	// JSONPatch is a list of RFC 6902 operations applied to spec last (Overlay only)
+	JSONPatch []JSONPatchOperation `yaml:"jsonPatch,omitempty" json:"jsonPatch,omitempty"`
 }
 
 // Metadata contains resource metadata
@@ -65,18 +70,41 @@ type JobStorageSettings struct {
 func LoadResourceSpec(path string) (ResourceSpec, error) {
 	var spec ResourceSpec
 
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return spec, fmt.Errorf("failed to read file: %w", err)
+	doc, err := loadSpecDocument(path)
+	if err != nil || doc.Kind == 0 {
+		return spec, err
 	}
 
-	if err := yaml.Unmarshal(data, &spec); err != nil {
+	if err := doc.Decode(&spec); err != nil {
 		return spec, fmt.Errorf("failed to unmarshal YAML: %w", err)
 	}
 
 	return spec, nil
 }
 
+// loadSpecDocument reads a spec file as a YAML node tree with ${var} references substituted.
+// An empty file returns a zero node.
+func loadSpecDocument(path string) (*yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal YAML: %w", err)
+	}
+	if doc.Kind == 0 {
+		return &doc, nil
+	}
+
+	// Substitute ${var} references before decoding so values are typed correctly
+	if err := interpolateNode(&doc, lookupSpecVariable, specVariables.strict); err != nil {
+		return nil, fmt.Errorf("failed to substitute variables: %w", err)
+	}
+	return &doc, nil
+}
+
 // SaveResourceSpec saves a ResourceSpec to a YAML file
 func SaveResourceSpec(spec ResourceSpec, path string) error {
 	data, err := yaml.Marshal(spec)
@@ -0,0 +1,304 @@
+package resources
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Annotations an overlay (or a spec merged over a profile) can use to control array merging
+const (
+	// AnnotationMergeKeys declares merge keys for array paths, e.g. "virtualMachines.includes=name,rules=name".
+	// Listed paths are merged by key regardless of the array strategy.
+	AnnotationMergeKeys = "owlctl.veeam.com/merge-keys"
+	// AnnotationArrayMerge selects the array strategy for the layer ("replace", "merge" or "keyed").
+	AnnotationArrayMerge = "owlctl.veeam.com/array-merge"
+)
+
+// PatchDirective is the element field carrying a strategic merge directive
+const PatchDirective = "$patch"
+
+// Values accepted for the $patch directive
+const (
+	// PatchDelete removes the base element with the same merge key
+	PatchDelete = "delete"
+	// PatchReplace, as a standalone array element, replaces the whole base array
+	PatchReplace = "replace"
+	// PatchMerge is the default: merge into the matching element or append
+	PatchMerge = "merge"
+)
+
+// mergeKeysByKind are the default array merge keys for each resource kind, keyed by field path
+var mergeKeysByKind = map[string]map[string]string{
+	KindVBRJob: {
+		"objects":                                                "name",
+		"virtualMachines.includes":                               "name",
+		"virtualMachines.excludes.vms":                           "name",
+		"guestProcessing.appAwareProcessing.appSettings":         "vm.name",
+		"guestProcessing.guestCredentials.credentialsPerMachine": "vm.name",
+	},
+	KindVBRSOBR: {
+		"performanceTier.performanceExtents": "name",
+	},
+	KindVBRScaleOutRepository: {
+		"performanceTier.performanceExtents": "name",
+	},
+	KindVBRTrafficRules: {
+		"throttlingRules": "name",
+	},
+}
+
+// MergeKeysForKind returns a copy of the default array merge keys for a resource kind
+func MergeKeysForKind(kind string) map[string]string {
+	keys := make(map[string]string, len(mergeKeysByKind[kind]))
+	for path, key := range mergeKeysByKind[kind] {
+		keys[path] = key
+	}
+	return keys
+}
+
+// ParseMergeKeys parses a comma-separated list of path=key pairs
+func ParseMergeKeys(value string) (map[string]string, error) {
+	keys := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		path, key, ok := strings.Cut(pair, "=")
+		path, key = strings.TrimSpace(path), strings.TrimSpace(key)
+		if !ok || path == "" || key == "" {
+			return nil, fmt.Errorf("invalid merge key %q: expected path=key", pair)
+		}
+		keys[path] = key
+	}
+	return keys, nil
+}
+
+// layerMergeOptions returns the options for merging one layer, applying the kind's default
+// merge keys and any merge directives from the overlaying layer's annotations.
+func layerMergeOptions(opts MergeOptions, kind string, annotations map[string]string) (MergeOptions, error) {
+	result := opts
+	if result.Kind == "" {
+		result.Kind = kind
+	}
+
+	if strategy, ok := annotations[AnnotationArrayMerge]; ok {
+		switch MergeStrategy(strategy) {
+		case StrategyReplace, StrategyMerge, StrategyKeyed:
+			result.ArrayStrategy = MergeStrategy(strategy)
+		default:
+			return opts, fmt.Errorf("invalid %s annotation %q (expected replace, merge or keyed)", AnnotationArrayMerge, strategy)
+		}
+	}
+
+	if value, ok := annotations[AnnotationMergeKeys]; ok {
+		declared, err := ParseMergeKeys(value)
+		if err != nil {
+			return opts, fmt.Errorf("invalid %s annotation: %w", AnnotationMergeKeys, err)
+		}
+		keys := make(map[string]string, len(opts.MergeKeys)+len(declared))
+		for path, key := range opts.MergeKeys {
+			keys[path] = key
+		}
+		for path, key := range declared {
+			keys[path] = key
+		}
+		result.MergeKeys = keys
+	}
+
+	return result, nil
+}
+
+// mergeKeyFor returns the merge key for an array path. Explicit MergeKeys always apply;
+// per-kind defaults apply under StrategyKeyed or when the overlay array carries directives.
+func (opts MergeOptions) mergeKeyFor(path string, hasDirectives bool) (string, bool) {
+	if key, ok := opts.MergeKeys[path]; ok {
+		return key, true
+	}
+	if opts.ArrayStrategy == StrategyKeyed || hasDirectives {
+		key, ok := mergeKeysByKind[opts.Kind][path]
+		return key, ok
+	}
+	return "", false
+}
+
+// mergeKeyedSlices performs a strategic merge of two arrays. Elements are matched on the
+// merge key: matches are merged recursively, new elements are appended in overlay order and
+// elements marked "$patch: delete" are removed. A standalone "$patch: replace" element makes
+// the rest of the overlay array replace the base. Returns handled=false when the array should
+// fall back to the plain array strategy.
+func mergeKeyedSlices(base, overlay []interface{}, opts MergeOptions, path string) (interface{}, bool, error) {
+	hasDirectives := false
+	for _, elem := range overlay {
+		m, ok := elem.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if directive, ok := m[PatchDirective]; ok {
+			hasDirectives = true
+			if directive == PatchReplace && len(m) == 1 {
+				return stripPatchDirectives(overlay), true, nil
+			}
+		}
+	}
+
+	key, keyed := opts.mergeKeyFor(path, hasDirectives)
+	if !keyed {
+		if hasDirectives {
+			return nil, true, fmt.Errorf("array %q uses %s but has no merge key (declare one with the %s annotation)", displayFieldPath(path), PatchDirective, AnnotationMergeKeys)
+		}
+		return nil, false, nil
+	}
+
+	result := make([]interface{}, len(base))
+	copy(result, base)
+	index := make(map[string]int)
+	for i, elem := range result {
+		if value, ok := lookupMergeKey(elem, key); ok {
+			if _, dup := index[value]; !dup {
+				index[value] = i
+			}
+		}
+	}
+
+	deleted := make(map[int]bool)
+	for _, elem := range overlay {
+		m, isMap := elem.(map[string]interface{})
+		if !isMap {
+			result = append(result, elem)
+			continue
+		}
+
+		directive := PatchMerge
+		if d, ok := m[PatchDirective]; ok {
+			s, isString := d.(string)
+			if !isString || (s != PatchMerge && s != PatchDelete) {
+				return nil, true, fmt.Errorf("array %q: invalid %s value %v (expected %s or %s)", displayFieldPath(path), PatchDirective, d, PatchMerge, PatchDelete)
+			}
+			directive = s
+		}
+
+		value, hasKey := lookupMergeKey(m, key)
+		if !hasKey {
+			if directive == PatchDelete {
+				return nil, true, fmt.Errorf("array %q: %s %s element is missing merge key %q", displayFieldPath(path), PatchDirective, PatchDelete, key)
+			}
+			result = append(result, stripPatchDirectives(m))
+			continue
+		}
+
+		i, matched := index[value]
+		if directive == PatchDelete {
+			if matched {
+				deleted[i] = true
+				delete(index, value)
+			}
+			continue
+		}
+
+		clean := withoutDirective(m)
+		if matched {
+			merged, err := mergeValuesAt(result[i], clean, opts, path)
+			if err != nil {
+				return nil, true, fmt.Errorf("failed to merge %s[%s=%s]: %w", displayFieldPath(path), key, value, err)
+			}
+			result[i] = merged
+			continue
+		}
+
+		index[value] = len(result)
+		result = append(result, stripPatchDirectives(clean))
+	}
+
+	if len(deleted) == 0 {
+		return result, true, nil
+	}
+	kept := make([]interface{}, 0, len(result)-len(deleted))
+	for i, elem := range result {
+		if !deleted[i] {
+			kept = append(kept, elem)
+		}
+	}
+	return kept, true, nil
+}
+
+// lookupMergeKey returns the merge key value of an array element. Dotted keys (e.g. "vm.name")
+// address nested fields.
+func lookupMergeKey(elem interface{}, key string) (string, bool) {
+	current := elem
+	for _, part := range strings.Split(key, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if current, ok = m[part]; !ok || current == nil {
+			return "", false
+		}
+	}
+	switch current.(type) {
+	case map[string]interface{}, []interface{}:
+		return "", false
+	}
+	return fmt.Sprint(current), true
+}
+
+// withoutDirective returns a copy of an array element without its $patch field
+func withoutDirective(m map[string]interface{}) map[string]interface{} {
+	if _, ok := m[PatchDirective]; !ok {
+		return m
+	}
+	clean := make(map[string]interface{}, len(m)-1)
+	for k, v := range m {
+		if k != PatchDirective {
+			clean[k] = v
+		}
+	}
+	return clean
+}
+
+// stripPatchDirectives removes $patch fields and "$patch: delete" elements from a value that
+// is being used as-is (e.g. an overlay array with no base array to patch).
+func stripPatchDirectives(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if v == nil {
+			return v
+		}
+		result := make(map[string]interface{}, len(v))
+		for k, child := range v {
+			if k == PatchDirective {
+				continue
+			}
+			result[k] = stripPatchDirectives(child)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, 0, len(v))
+		for _, elem := range v {
+			if m, ok := elem.(map[string]interface{}); ok {
+				if d, ok := m[PatchDirective]; ok && (d == PatchDelete || (d == PatchReplace && len(m) == 1)) {
+					continue
+				}
+			}
+			result = append(result, stripPatchDirectives(elem))
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// joinFieldPath appends a map key to a dotted field path
+func joinFieldPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func displayFieldPath(path string) string {
+	if path == "" {
+		return "spec"
+	}
+	return "spec." + path
+}
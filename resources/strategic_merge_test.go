@@ -0,0 +1,306 @@
+package resources
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func vm(name string, fields ...interface{}) map[string]interface{} {
+	m := map[string]interface{}{"type": "VirtualMachine", "name": name}
+	for i := 0; i+1 < len(fields); i += 2 {
+		m[fields[i].(string)] = fields[i+1]
+	}
+	return m
+}
+
+func TestMergeResourceSpecs_KeyedArrays(t *testing.T) {
+	baseSpec := func() ResourceSpec {
+		return ResourceSpec{
+			Kind:     KindVBRJob,
+			Metadata: Metadata{Name: "job"},
+			Spec: map[string]interface{}{
+				"objects": []interface{}{
+					vm("sql-01", "hostName", "esxi-01"),
+					vm("sql-02", "hostName", "esxi-02"),
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		opts        MergeOptions
+		annotations map[string]string
+		overlay     []interface{}
+		want        []interface{}
+		wantErr     string
+	}{
+		{
+			name:    "default strategy replaces arrays",
+			opts:    DefaultMergeOptions(),
+			overlay: []interface{}{vm("sql-02", "hostName", "esxi-09")},
+			want:    []interface{}{vm("sql-02", "hostName", "esxi-09")},
+		},
+		{
+			name:    "keyed strategy merges matching element and keeps others",
+			opts:    MergeOptions{ArrayStrategy: StrategyKeyed},
+			overlay: []interface{}{map[string]interface{}{"name": "sql-02", "hostName": "esxi-09"}},
+			want: []interface{}{
+				vm("sql-01", "hostName", "esxi-01"),
+				vm("sql-02", "hostName", "esxi-09"),
+			},
+		},
+		{
+			name:    "keyed strategy appends new elements",
+			opts:    MergeOptions{ArrayStrategy: StrategyKeyed},
+			overlay: []interface{}{vm("sql-03")},
+			want: []interface{}{
+				vm("sql-01", "hostName", "esxi-01"),
+				vm("sql-02", "hostName", "esxi-02"),
+				vm("sql-03"),
+			},
+		},
+		{
+			name:    "$patch delete removes element under default strategy",
+			opts:    DefaultMergeOptions(),
+			overlay: []interface{}{map[string]interface{}{"name": "sql-01", "$patch": "delete"}},
+			want:    []interface{}{vm("sql-02", "hostName", "esxi-02")},
+		},
+		{
+			name:    "$patch delete of missing element is a no-op",
+			opts:    MergeOptions{ArrayStrategy: StrategyKeyed},
+			overlay: []interface{}{map[string]interface{}{"name": "sql-99", "$patch": "delete"}},
+			want: []interface{}{
+				vm("sql-01", "hostName", "esxi-01"),
+				vm("sql-02", "hostName", "esxi-02"),
+			},
+		},
+		{
+			name:    "$patch replace element replaces whole array",
+			opts:    MergeOptions{ArrayStrategy: StrategyKeyed},
+			overlay: []interface{}{map[string]interface{}{"$patch": "replace"}, vm("sql-05")},
+			want:    []interface{}{vm("sql-05")},
+		},
+		{
+			name:        "merge-keys annotation enables keyed merge on a custom key",
+			opts:        DefaultMergeOptions(),
+			annotations: map[string]string{AnnotationMergeKeys: "objects=hostName"},
+			overlay:     []interface{}{map[string]interface{}{"hostName": "esxi-01", "name": "sql-renamed"}},
+			want: []interface{}{
+				vm("sql-renamed", "hostName", "esxi-01"),
+				vm("sql-02", "hostName", "esxi-02"),
+			},
+		},
+		{
+			name:        "array-merge annotation selects keyed strategy",
+			opts:        DefaultMergeOptions(),
+			annotations: map[string]string{AnnotationArrayMerge: "keyed"},
+			overlay:     []interface{}{vm("sql-03")},
+			want: []interface{}{
+				vm("sql-01", "hostName", "esxi-01"),
+				vm("sql-02", "hostName", "esxi-02"),
+				vm("sql-03"),
+			},
+		},
+		{
+			name:    "invalid $patch value",
+			opts:    MergeOptions{ArrayStrategy: StrategyKeyed},
+			overlay: []interface{}{map[string]interface{}{"name": "sql-01", "$patch": "remove"}},
+			wantErr: "invalid $patch value",
+		},
+		{
+			name:    "$patch delete without merge key value",
+			opts:    MergeOptions{ArrayStrategy: StrategyKeyed},
+			overlay: []interface{}{map[string]interface{}{"hostName": "esxi-01", "$patch": "delete"}},
+			wantErr: "missing merge key",
+		},
+		{
+			name:        "invalid array-merge annotation",
+			opts:        DefaultMergeOptions(),
+			annotations: map[string]string{AnnotationArrayMerge: "smart"},
+			overlay:     []interface{}{vm("sql-03")},
+			wantErr:     "invalid owlctl.veeam.com/array-merge annotation",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := baseSpec()
+			overlay := ResourceSpec{
+				Kind:     KindVBRJob,
+				Metadata: Metadata{Annotations: tt.annotations},
+				Spec:     map[string]interface{}{"objects": tt.overlay},
+			}
+
+			got, err := MergeResourceSpecs(base, overlay, tt.opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got.Spec["objects"], tt.want) {
+				t.Errorf("objects = %#v\nwant %#v", got.Spec["objects"], tt.want)
+			}
+			if !reflect.DeepEqual(base, baseSpec()) {
+				t.Error("base spec was mutated")
+			}
+		})
+	}
+}
+
+func TestMergeResourceSpecs_PatchWithoutMergeKey(t *testing.T) {
+	base := ResourceSpec{
+		Kind: KindVBRRepository,
+		Spec: map[string]interface{}{"tags": []interface{}{map[string]interface{}{"id": "a"}}},
+	}
+	overlay := ResourceSpec{
+		Kind: KindVBRRepository,
+		Spec: map[string]interface{}{"tags": []interface{}{map[string]interface{}{"id": "a", "$patch": "delete"}}},
+	}
+
+	_, err := MergeResourceSpecs(base, overlay, DefaultMergeOptions())
+	if err == nil || !strings.Contains(err.Error(), "has no merge key") {
+		t.Fatalf("error = %v, want missing merge key error", err)
+	}
+
+	overlay.Metadata.Annotations = map[string]string{AnnotationMergeKeys: "tags=id"}
+	got, err := MergeResourceSpecs(base, overlay, DefaultMergeOptions())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tags := got.Spec["tags"].([]interface{}); len(tags) != 0 {
+		t.Errorf("tags = %v, want empty", tags)
+	}
+}
+
+func TestMergeResourceSpecs_NestedKeyedArrays(t *testing.T) {
+	base := ResourceSpec{
+		Kind: KindVBRSOBR,
+		Spec: map[string]interface{}{
+			"performanceTier": map[string]interface{}{
+				"performanceExtents": []interface{}{
+					map[string]interface{}{"name": "extent-1", "status": "Available"},
+					map[string]interface{}{"name": "extent-2", "status": "Available"},
+				},
+			},
+		},
+	}
+	overlay := ResourceSpec{
+		Kind: KindVBRSOBR,
+		Spec: map[string]interface{}{
+			"performanceTier": map[string]interface{}{
+				"performanceExtents": []interface{}{
+					map[string]interface{}{"name": "extent-2", "status": "Maintenance"},
+				},
+			},
+		},
+	}
+
+	got, err := MergeResourceSpecs(base, overlay, MergeOptions{ArrayStrategy: StrategyKeyed})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []interface{}{
+		map[string]interface{}{"name": "extent-1", "status": "Available"},
+		map[string]interface{}{"name": "extent-2", "status": "Maintenance"},
+	}
+	extents := got.Spec["performanceTier"].(map[string]interface{})["performanceExtents"]
+	if !reflect.DeepEqual(extents, want) {
+		t.Errorf("performanceExtents = %#v\nwant %#v", extents, want)
+	}
+}
+
+func TestApplyGroupMergeFromSpecs_KeyedOverlay(t *testing.T) {
+	spec := ResourceSpec{
+		APIVersion: "owlctl.veeam.com/v1",
+		Kind:       KindVBRJob,
+		Metadata:   Metadata{Name: "app-job"},
+		Spec: map[string]interface{}{
+			"guestProcessing": map[string]interface{}{
+				"appAwareProcessing": map[string]interface{}{
+					"appSettings": []interface{}{
+						map[string]interface{}{"vm": map[string]interface{}{"name": "sql-01"}, "transactionLogs": "Truncate"},
+						map[string]interface{}{"vm": map[string]interface{}{"name": "sql-02"}, "transactionLogs": "Truncate"},
+					},
+				},
+			},
+		},
+	}
+	overlay := ResourceSpec{
+		Kind:     KindOverlay,
+		Metadata: Metadata{Name: "logs", Annotations: map[string]string{AnnotationArrayMerge: "keyed"}},
+		Spec: map[string]interface{}{
+			"guestProcessing": map[string]interface{}{
+				"appAwareProcessing": map[string]interface{}{
+					"appSettings": []interface{}{
+						map[string]interface{}{"vm": map[string]interface{}{"name": "sql-02"}, "transactionLogs": "Preserve"},
+					},
+				},
+			},
+		},
+	}
+
+	got, err := ApplyGroupMergeFromSpecs(spec, nil, &overlay, DefaultMergeOptions())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	settings := got.Spec["guestProcessing"].(map[string]interface{})["appAwareProcessing"].(map[string]interface{})["appSettings"].([]interface{})
+	if len(settings) != 2 {
+		t.Fatalf("appSettings has %d entries, want 2", len(settings))
+	}
+	if logs := settings[0].(map[string]interface{})["transactionLogs"]; logs != "Truncate" {
+		t.Errorf("sql-01 transactionLogs = %v, want Truncate", logs)
+	}
+	if logs := settings[1].(map[string]interface{})["transactionLogs"]; logs != "Preserve" {
+		t.Errorf("sql-02 transactionLogs = %v, want Preserve", logs)
+	}
+}
+
+func TestApplyGroupMergeFromSpecs_StripsUnmatchedDirectives(t *testing.T) {
+	spec := ResourceSpec{
+		Kind:     KindVBRJob,
+		Metadata: Metadata{Name: "job"},
+		Spec:     map[string]interface{}{"description": "no objects yet"},
+	}
+	overlay := ResourceSpec{
+		Kind: KindOverlay,
+		Spec: map[string]interface{}{
+			"objects": []interface{}{
+				map[string]interface{}{"name": "old-vm", "$patch": "delete"},
+				map[string]interface{}{"name": "new-vm", "$patch": "merge"},
+			},
+		},
+	}
+
+	got, err := ApplyGroupMergeFromSpecs(spec, nil, &overlay, DefaultMergeOptions())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []interface{}{map[string]interface{}{"name": "new-vm"}}
+	if !reflect.DeepEqual(got.Spec["objects"], want) {
+		t.Errorf("objects = %#v, want %#v", got.Spec["objects"], want)
+	}
+}
+
+func TestParseMergeKeys(t *testing.T) {
+	got, err := ParseMergeKeys("objects=name, performanceTier.performanceExtents = id")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{"objects": "name", "performanceTier.performanceExtents": "id"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseMergeKeys() = %v, want %v", got, want)
+	}
+
+	for _, bad := range []string{"objects", "=name", "objects="} {
+		if _, err := ParseMergeKeys(bad); err == nil {
+			t.Errorf("ParseMergeKeys(%q) expected error", bad)
+		}
+	}
+}
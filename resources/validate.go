@@ -0,0 +1,455 @@
+package resources
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ValidationError is a single schema violation in a spec document
+type ValidationError struct {
+	Path    string // Dotted path from the document root, e.g. "spec.storage.retension"
+	Message string
+
+	// excluded marks a "not" violation, which rules out an anyOf branch entirely
+	excluded bool
+}
+
+func (e ValidationError) String() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// ValidationErrors is every schema violation found in a spec document
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	lines := make([]string, len(e))
+	for i, v := range e {
+		lines[i] = v.String()
+	}
+	return fmt.Sprintf("spec failed schema validation: %s", strings.Join(lines, "; "))
+}
+
+// mixinTargetKinds are the kinds a Profile or Overlay is checked against when no target kind
+// is given. Settings singletons are omitted: their schemas accept any field.
+var mixinTargetKinds = []string{KindVBRJob, KindVBRRepository, KindVBRScaleOutRepository, KindVBRKmsServer, KindVBREncryptionPassword}
+
+var parsedSchemas struct {
+	once    sync.Once
+	schemas map[string]map[string]interface{}
+	err     error
+}
+
+// loadSchema returns the parsed embedded schema for a kind
+func loadSchema(kind string) (map[string]interface{}, error) {
+	parsedSchemas.once.Do(func() {
+		parsedSchemas.schemas = make(map[string]map[string]interface{})
+		for _, k := range SchemaKinds {
+			data, err := Schema(k)
+			if err != nil {
+				parsedSchemas.err = err
+				return
+			}
+			var schema map[string]interface{}
+			if err := json.Unmarshal(data, &schema); err != nil {
+				parsedSchemas.err = fmt.Errorf("embedded schema for %s is invalid: %w", k, err)
+				return
+			}
+			parsedSchemas.schemas[k] = schema
+		}
+	})
+	if parsedSchemas.err != nil {
+		return nil, parsedSchemas.err
+	}
+	schema, ok := parsedSchemas.schemas[schemaKind(kind)]
+	if !ok {
+		return nil, fmt.Errorf("no schema for kind %q", kind)
+	}
+	return schema, nil
+}
+
+// ValidateSpecFile reads a spec file, substituting variables, and validates every key in it
+// against the schema for its kind. targetKind is used for Profile and Overlay files (see
+// ValidateDocument). A file that cannot be read or parsed returns an error.
+func ValidateSpecFile(path, targetKind string) (ValidationErrors, error) {
+	node, err := loadSpecDocument(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if node.Kind != 0 {
+		if err := node.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal YAML: %w", err)
+		}
+	}
+	if doc == nil {
+		return ValidationErrors{{Message: "file is empty or not a YAML mapping"}}, nil
+	}
+	return ValidateDocument(doc, targetKind), nil
+}
+
+// ValidateResourceSpec validates a loaded or merged spec against the schema for its kind.
+// Unknown top-level keys are dropped when a file is decoded, so ValidateSpecFile is stricter.
+func ValidateResourceSpec(spec ResourceSpec) ValidationErrors {
+	doc, err := SpecDocument(spec)
+	if err != nil {
+		return ValidationErrors{{Message: err.Error()}}
+	}
+	return ValidateDocument(doc, "")
+}
+
+// SpecDocument converts a spec to the generic document form it has in YAML, with the numeric
+// and map types JSON decoding produces
+func SpecDocument(spec ResourceSpec) (map[string]interface{}, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode spec: %w", err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to encode spec: %w", err)
+	}
+	return doc, nil
+}
+
+// ValidateDocument validates a decoded spec document against the schema for its kind.
+// Profile and Overlay specs are also checked against the fields of targetKind, or when
+// targetKind is empty, the resource kind whose fields they most resemble.
+func ValidateDocument(doc map[string]interface{}, targetKind string) ValidationErrors {
+	kind, _ := doc["kind"].(string)
+	if kind == "" {
+		return ValidationErrors{{Path: "kind", Message: "required"}}
+	}
+	schema, err := loadSchema(kind)
+	if err != nil {
+		return ValidationErrors{{Path: "kind", Message: fmt.Sprintf("unknown kind %q", kind)}}
+	}
+
+	errs := schemaValidator{}.validate(schema, doc, "")
+
+	if IsMixinKind(kind) {
+		if spec, ok := doc["spec"].(map[string]interface{}); ok {
+			if targetKind == "" {
+				targetKind = closestResourceKind(spec)
+			}
+			if targetKind != "" {
+				targetSchema, err := loadSchema(targetKind)
+				if err != nil {
+					errs = append(errs, ValidationError{Message: fmt.Sprintf("unknown target kind %q", targetKind)})
+				} else {
+					// Profiles and overlays are partial, so required fields may be absent
+					errs = append(errs, schemaValidator{partial: true}.validate(specSchema(targetSchema), spec, "spec")...)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Path < errs[j].Path })
+	return errs
+}
+
+// specSchema returns the schema of a document's spec field
+func specSchema(docSchema map[string]interface{}) map[string]interface{} {
+	properties, _ := docSchema["properties"].(map[string]interface{})
+	spec, _ := properties["spec"].(map[string]interface{})
+	return spec
+}
+
+// closestResourceKind returns the kind whose spec fields match the most top-level keys of a
+// profile or overlay spec, or "" if none match any
+func closestResourceKind(spec map[string]interface{}) string {
+	best, bestCount := "", 0
+	for _, kind := range mixinTargetKinds {
+		schema, err := loadSchema(kind)
+		if err != nil {
+			continue
+		}
+		known := schemaProperties(specSchema(schema))
+		count := 0
+		for key := range spec {
+			if _, ok := known[key]; ok {
+				count++
+			}
+		}
+		if count > bestCount {
+			best, bestCount = kind, count
+		}
+	}
+	return best
+}
+
+// schemaProperties returns the properties a schema declares, including those of anyOf branches
+func schemaProperties(schema map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	if properties, ok := schema["properties"].(map[string]interface{}); ok {
+		for k, v := range properties {
+			result[k] = v
+		}
+	}
+	if branches, ok := schema["anyOf"].([]interface{}); ok {
+		for _, b := range branches {
+			if branch, ok := b.(map[string]interface{}); ok {
+				for k, v := range schemaProperties(branch) {
+					result[k] = v
+				}
+			}
+		}
+	}
+	return result
+}
+
+// schemaValidator checks values against the subset of JSON Schema used by the embedded schemas:
+// type, enum, minLength, properties, required, additionalProperties, items, anyOf and not.
+// Null values are accepted for any field, as YAML uses them for "not set".
+type schemaValidator struct {
+	partial bool // Skip required checks, for profiles and overlays
+}
+
+func (v schemaValidator) validate(schema map[string]interface{}, value interface{}, path string) []ValidationError {
+	if value == nil || schema == nil {
+		return nil
+	}
+
+	if branches, ok := schema["anyOf"].([]interface{}); ok {
+		// Report the errors of the branch that came closest to matching
+		var best []ValidationError
+		bestScore := 0
+		for _, b := range branches {
+			branch, _ := b.(map[string]interface{})
+			if v.partial && missingRequired(branch, value) {
+				// A partial spec can only be checked against branches it identifies itself
+				// with, otherwise an open branch would accept any overlay
+				continue
+			}
+			errs := v.validate(branch, value, path)
+			if len(errs) == 0 {
+				return nil
+			}
+			if score := branchScore(errs, path); best == nil || score < bestScore {
+				best, bestScore = errs, score
+			}
+		}
+		return best
+	}
+
+	if not, ok := schema["not"].(map[string]interface{}); ok {
+		if len(v.validate(not, value, path)) == 0 {
+			return []ValidationError{{Path: path, Message: fmt.Sprintf("value %s is not allowed here", formatSchemaValue(value)), excluded: true}}
+		}
+	}
+
+	if t, ok := schema["type"].(string); ok && !matchesType(t, value) {
+		return []ValidationError{{Path: path, Message: fmt.Sprintf("expected %s, got %s", t, describeType(value))}}
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok && !inEnum(enum, value) {
+		options := make([]string, len(enum))
+		for i, e := range enum {
+			options[i] = fmt.Sprint(e)
+		}
+		return []ValidationError{{Path: path, Message: fmt.Sprintf("must be one of: %s (got %s)", strings.Join(options, ", "), formatSchemaValue(value))}}
+	}
+
+	if minLength, ok := schema["minLength"].(float64); ok {
+		if s, ok := value.(string); ok && float64(len(s)) < minLength {
+			return []ValidationError{{Path: path, Message: "must not be empty"}}
+		}
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return v.validateObject(schema, value, path)
+	case []interface{}:
+		items, ok := schema["items"].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		var errs []ValidationError
+		for i, item := range value {
+			errs = append(errs, v.validate(items, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return errs
+	}
+	return nil
+}
+
+func (v schemaValidator) validateObject(schema map[string]interface{}, obj map[string]interface{}, path string) []ValidationError {
+	var errs []ValidationError
+	properties, _ := schema["properties"].(map[string]interface{})
+
+	if required, ok := schema["required"].([]interface{}); ok && !v.partial {
+		for _, r := range required {
+			name, _ := r.(string)
+			if _, ok := obj[name]; !ok {
+				errs = append(errs, ValidationError{Path: joinFieldPath(path, name), Message: "required"})
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		child := joinFieldPath(path, key)
+		if prop, ok := properties[key].(map[string]interface{}); ok {
+			errs = append(errs, v.validate(prop, obj[key], child)...)
+			continue
+		}
+		switch extra := schema["additionalProperties"].(type) {
+		case bool:
+			if !extra {
+				msg := "unknown field"
+				if suggestion := closestName(key, properties); suggestion != "" {
+					msg += fmt.Sprintf(" (did you mean %q?)", suggestion)
+				}
+				errs = append(errs, ValidationError{Path: child, Message: msg})
+			}
+		case map[string]interface{}:
+			errs = append(errs, v.validate(extra, obj[key], child)...)
+		}
+	}
+	return errs
+}
+
+// missingRequired reports whether an object lacks any field a schema requires
+func missingRequired(schema map[string]interface{}, value interface{}) bool {
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	required, _ := schema["required"].([]interface{})
+	for _, r := range required {
+		if _, ok := obj[r.(string)]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// branchScore ranks an anyOf branch by how badly a value failed it. A branch the value was
+// explicitly excluded from, or whose type it does not match at all, ranks below any branch
+// with only field-level errors.
+func branchScore(errs []ValidationError, path string) int {
+	score := len(errs)
+	for _, e := range errs {
+		if e.excluded || e.Path == path {
+			score += 1000
+		}
+	}
+	return score
+}
+
+func matchesType(t string, value interface{}) bool {
+	switch t {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "integer":
+		switch n := value.(type) {
+		case int, int64, uint64:
+			return true
+		case float64:
+			return n == math.Trunc(n)
+		}
+		return false
+	case "number":
+		switch value.(type) {
+		case int, int64, uint64, float64:
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+func describeType(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return fmt.Sprintf("string %q", value)
+	case bool:
+		return fmt.Sprintf("boolean %v", value)
+	case int, int64, uint64, float64:
+		return fmt.Sprintf("number %v", value)
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func formatSchemaValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprint(value)
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, e := range enum {
+		if fmt.Sprint(e) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// closestName suggests the declared property nearest to a misspelt key: a case-insensitive
+// match, or the closest name within an edit distance of 2
+func closestName(key string, properties map[string]interface{}) string {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	best, bestDistance := "", 3
+	for _, name := range names {
+		if strings.EqualFold(name, key) {
+			return name
+		}
+		if d := editDistance(strings.ToLower(key), strings.ToLower(name)); d < bestDistance {
+			best, bestDistance = name, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,288 @@
+package resources
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func validationMessages(errs ValidationErrors) []string {
+	var messages []string
+	for _, e := range errs {
+		messages = append(messages, e.String())
+	}
+	return messages
+}
+
+func TestValidateSpecFile(t *testing.T) {
+	tests := []struct {
+		name       string
+		yaml       string
+		targetKind string
+		want       []string // Expected error lines; empty means valid
+	}{
+		{
+			name: "valid job in API format",
+			yaml: `apiVersion: owlctl.veeam.com/v1
+kind: VBRJob
+metadata:
+  name: SQL Backup
+spec:
+  type: VSphereBackup
+  storage:
+    retentionPolicy:
+      type: Days
+      quantity: 7
+  schedule:
+    daily:
+      isEnabled: true
+      localTime: "22:00"
+`,
+		},
+		{
+			name: "valid simplified job",
+			yaml: `apiVersion: owlctl.veeam.com/v1
+kind: VBRJob
+metadata:
+  name: SQL Backup
+spec:
+  type: VSphereBackup
+  repository: Default Backup Repository
+  objects:
+    - type: VirtualMachine
+      name: sql-01
+`,
+		},
+		{
+			name: "other job types are not checked field by field",
+			yaml: `apiVersion: owlctl.veeam.com/v1
+kind: VBRJob
+metadata:
+  name: Agent Backup
+spec:
+  type: LinuxAgentBackup
+  anything: goes
+`,
+		},
+		{
+			name: "misspelt field suggests the right name",
+			yaml: `apiVersion: owlctl.veeam.com/v1
+kind: VBRJob
+metadata:
+  name: SQL Backup
+spec:
+  type: VSphereBackup
+  storage:
+    retentionPolicy:
+      type: Days
+      quantiy: 7
+`,
+			want: []string{`spec.storage.retentionPolicy.quantiy: unknown field (did you mean "quantity"?)`},
+		},
+		{
+			name: "wrong type",
+			yaml: `apiVersion: owlctl.veeam.com/v1
+kind: VBRJob
+metadata:
+  name: SQL Backup
+spec:
+  type: VSphereBackup
+  isDisabled: "no"
+`,
+			want: []string{`spec.isDisabled: expected boolean, got string "no"`},
+		},
+		{
+			name: "other repository types are not checked field by field",
+			yaml: `apiVersion: owlctl.veeam.com/v1
+kind: VBRRepository
+metadata:
+  name: S3 Repo
+spec:
+  type: AmazonS3
+  account:
+    credentialId: cred-1
+    regionType: Global
+  bucket:
+    bucketName: backups
+    folderName: vbr
+`,
+		},
+		{
+			name: "local repository fields are checked",
+			yaml: `apiVersion: owlctl.veeam.com/v1
+kind: VBRRepository
+metadata:
+  name: Local Repo
+spec:
+  type: LinuxLocal
+  bucket:
+    bucketName: backups
+`,
+			want: []string{"spec.bucket: unknown field"},
+		},
+		{
+			name: "envelope errors",
+			yaml: `apiVersion: owlctl.veeam.com/v2
+kind: VBRRepository
+metdata:
+  name: Default
+spec:
+  description: x
+`,
+			want: []string{
+				"apiVersion: must be one of: owlctl.veeam.com/v1 (got \"owlctl.veeam.com/v2\")",
+				"metadata: required",
+				`metdata: unknown field (did you mean "metadata"?)`,
+			},
+		},
+		{
+			name: "array items are checked",
+			yaml: `apiVersion: owlctl.veeam.com/v1
+kind: VBRJob
+metadata:
+  name: SQL Backup
+spec:
+  type: VSphereBackup
+  virtualMachines:
+    includes:
+      - name: sql-01
+        hostname: vc.local
+`,
+			want: []string{`spec.virtualMachines.includes[0].hostname: unknown field (did you mean "hostName"?)`},
+		},
+		{
+			name: "unknown kind",
+			yaml: `apiVersion: owlctl.veeam.com/v1
+kind: VBRWidget
+metadata:
+  name: x
+`,
+			want: []string{`kind: unknown kind "VBRWidget"`},
+		},
+		{
+			name: "overlay is checked against the kind it resembles without required fields",
+			yaml: `apiVersion: owlctl.veeam.com/v1
+kind: Overlay
+metadata:
+  name: retention
+spec:
+  storage:
+    retentionPolicy:
+      quantity: "30"
+`,
+			want: []string{`spec.storage.retentionPolicy.quantity: expected integer, got string "30"`},
+		},
+		{
+			name: "overlay checked against explicit kind",
+			yaml: `apiVersion: owlctl.veeam.com/v1
+kind: Overlay
+metadata:
+  name: repo-overlay
+spec:
+  description: Tuned
+  maxTasks: 4
+`,
+			targetKind: KindVBRRepository,
+			want:       []string{"spec.maxTasks: unknown field"},
+		},
+		{
+			name: "overlay json patch operations",
+			yaml: `apiVersion: owlctl.veeam.com/v1
+kind: Overlay
+metadata:
+  name: patch
+jsonPatch:
+  - op: update
+    path: /description
+`,
+			want: []string{"jsonPatch[0].op: must be one of: add, remove, replace, move, copy, test (got \"update\")"},
+		},
+		{
+			name: "settings kinds accept any spec",
+			yaml: `apiVersion: owlctl.veeam.com/v1
+kind: VBREmailSettings
+metadata:
+  name: email
+spec:
+  isEnabled: true
+  smtpServerName: smtp.local
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "spec.yaml")
+			if err := os.WriteFile(path, []byte(tt.yaml), 0644); err != nil {
+				t.Fatal(err)
+			}
+			errs, err := ValidateSpecFile(path, tt.targetKind)
+			if err != nil {
+				t.Fatalf("ValidateSpecFile: %v", err)
+			}
+			got := validationMessages(errs)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("errors:\n  %s\nwant:\n  %s", strings.Join(got, "\n  "), strings.Join(tt.want, "\n  "))
+			}
+		})
+	}
+}
+
+func TestValidateResourceSpec(t *testing.T) {
+	spec := ResourceSpec{
+		APIVersion: APIVersion,
+		Kind:       KindVBRKmsServer,
+		Metadata:   Metadata{Name: "Vault"},
+		Spec:       map[string]interface{}{"description": "Primary", "typ": "AzureKeyVault"},
+	}
+	errs := ValidateResourceSpec(spec)
+	if len(errs) != 1 || errs[0].Path != "spec.typ" {
+		t.Fatalf("errors = %v, want one error at spec.typ", validationMessages(errs))
+	}
+	if !strings.Contains(errs.Error(), `did you mean "type"?`) {
+		t.Errorf("Error() = %q", errs.Error())
+	}
+
+	spec.Spec = map[string]interface{}{"description": "Primary", "type": "AzureKeyVault"}
+	if errs := ValidateResourceSpec(spec); len(errs) != 0 {
+		t.Errorf("valid spec reported errors: %v", validationMessages(errs))
+	}
+}
+
+func TestValidateSpecFile_Examples(t *testing.T) {
+	paths, err := filepath.Glob("../examples/*/*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Skip("no example specs found")
+	}
+	for _, path := range paths {
+		errs, err := ValidateSpecFile(path, "")
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+			continue
+		}
+		for _, e := range errs {
+			t.Errorf("%s: %s", path, e)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"retention", "retention", 0},
+		{"retension", "retention", 1},
+		{"quantiy", "quantity", 1},
+		{"", "abc", 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
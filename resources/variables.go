@@ -0,0 +1,113 @@
+package resources
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// variableRef matches $${...} escapes and ${name} / ${name:-default} references
+var variableRef = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_.-]*)(:-([^}]*))?\}`)
+
+// specVariables holds the process-wide variables used when loading specs.
+// cmd sets these once flags and owlctl.yaml have been read, so every LoadResourceSpec
+// call site picks them up without threading values through.
+var specVariables struct {
+	values map[string]string
+	strict bool
+}
+
+// SetSpecVariables sets the variables substituted into specs by LoadResourceSpec.
+// In strict mode a reference to an undefined variable (with no default) is an error;
+// otherwise it is left in the spec unchanged. Environment variables are consulted when
+// a name is not in values.
+func SetSpecVariables(values map[string]string, strict bool) {
+	specVariables.values = values
+	specVariables.strict = strict
+}
+
+// lookupSpecVariable resolves a variable from the configured values, then the environment
+func lookupSpecVariable(name string) (string, bool) {
+	if v, ok := specVariables.values[name]; ok {
+		return v, true
+	}
+	return os.LookupEnv(name)
+}
+
+// InterpolateString substitutes ${name} and ${name:-default} references in s.
+// "$${" produces a literal "${". Undefined references are an error in strict mode and are
+// otherwise left as-is.
+func InterpolateString(s string, lookup func(string) (string, bool), strict bool) (string, error) {
+	var undefined []string
+	result := variableRef.ReplaceAllStringFunc(s, func(match string) string {
+		if match == "$${" {
+			return "${"
+		}
+		sub := variableRef.FindStringSubmatch(match)
+		name, hasDefault, def := sub[1], sub[2] != "", sub[3]
+		if v, ok := lookup(name); ok {
+			return v
+		}
+		if hasDefault {
+			return def
+		}
+		undefined = append(undefined, name)
+		return match
+	})
+
+	if strict && len(undefined) > 0 {
+		return "", fmt.Errorf("undefined variable(s): %s", strings.Join(uniqueSorted(undefined), ", "))
+	}
+	return result, nil
+}
+
+// interpolateNode substitutes variables in every scalar value of a YAML document. Mapping keys
+// and comments are left alone. A plain (unquoted) scalar that is a single reference is re-typed
+// after substitution, so "retention: ${days}" decodes as an integer.
+func interpolateNode(node *yaml.Node, lookup func(string) (string, bool), strict bool) error {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			if err := interpolateNode(child, lookup, strict); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			if err := interpolateNode(node.Content[i], lookup, strict); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		if !strings.Contains(node.Value, "${") {
+			return nil
+		}
+		value, err := InterpolateString(node.Value, lookup, strict)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		wholeRef := variableRef.FindString(node.Value) == node.Value
+		if wholeRef && node.Style == 0 && value != node.Value {
+			node.Tag = ""
+		}
+		node.Value = value
+	}
+	return nil
+}
+
+func uniqueSorted(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var result []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,119 @@
+package resources
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInterpolateString(t *testing.T) {
+	vars := map[string]string{"site": "london", "days": "30", "host.name": "vc01"}
+	lookup := func(name string) (string, bool) {
+		v, ok := vars[name]
+		return v, ok
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		strict  bool
+		want    string
+		wantErr string
+	}{
+		{name: "single reference", input: "${site}", want: "london"},
+		{name: "embedded references", input: "repo-${site}-${days}d", want: "repo-london-30d"},
+		{name: "dotted name", input: "${host.name}.local", want: "vc01.local"},
+		{name: "default used when undefined", input: "${tier:-gold}", want: "gold"},
+		{name: "default ignored when defined", input: "${site:-paris}", want: "london"},
+		{name: "empty default", input: "x${tier:-}y", want: "xy"},
+		{name: "escape", input: "$${site}", want: "${site}"},
+		{name: "undefined left as-is", input: "${missing}", want: "${missing}"},
+		{name: "undefined in strict mode", input: "${missing} ${other} ${missing}", strict: true, wantErr: "undefined variable(s): missing, other"},
+		{name: "no references", input: "plain $text", want: "plain $text"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := InterpolateString(tt.input, lookup, tt.strict)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("InterpolateString(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadResourceSpec_Variables(t *testing.T) {
+	defer SetSpecVariables(nil, false)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "job.yaml")
+	specYAML := `# ${comments} are not substituted
+apiVersion: owlctl.veeam.com/v1
+kind: VBRJob
+metadata:
+  name: SQL Backup ${site}
+  labels:
+    site: ${site}
+spec:
+  repository: "${site}-repo"
+  retentionDays: ${days}
+  quotedDays: "${days}"
+  vcenter: ${OWLCTL_TEST_VCENTER}
+  note: $${literal}
+`
+	if err := os.WriteFile(path, []byte(specYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("OWLCTL_TEST_VCENTER", "vc-env.local")
+
+	SetSpecVariables(map[string]string{"site": "london", "days": "30"}, true)
+	spec, err := LoadResourceSpec(path)
+	if err != nil {
+		t.Fatalf("LoadResourceSpec: %v", err)
+	}
+
+	if spec.Metadata.Name != "SQL Backup london" {
+		t.Errorf("name = %q", spec.Metadata.Name)
+	}
+	if spec.Metadata.Labels["site"] != "london" {
+		t.Errorf("label site = %q", spec.Metadata.Labels["site"])
+	}
+	checks := map[string]interface{}{
+		"repository":    "london-repo",
+		"retentionDays": 30,
+		"quotedDays":    "30",
+		"vcenter":       "vc-env.local",
+		"note":          "${literal}",
+	}
+	for field, want := range checks {
+		if got := spec.Spec[field]; got != want {
+			t.Errorf("spec.%s = %#v, want %#v", field, got, want)
+		}
+	}
+
+	// Strict mode fails on undefined variables
+	SetSpecVariables(map[string]string{"site": "london"}, true)
+	if _, err := LoadResourceSpec(path); err == nil || !strings.Contains(err.Error(), "undefined variable(s): days") {
+		t.Errorf("error = %v, want undefined days", err)
+	}
+
+	// Non-strict mode leaves undefined references in place
+	SetSpecVariables(map[string]string{"site": "london"}, false)
+	spec, err = LoadResourceSpec(path)
+	if err != nil {
+		t.Fatalf("LoadResourceSpec: %v", err)
+	}
+	if spec.Spec["retentionDays"] != "${days}" {
+		t.Errorf("retentionDays = %#v, want unsubstituted reference", spec.Spec["retentionDays"])
+	}
+}
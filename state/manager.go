@@ -218,6 +218,10 @@ func (m *Manager) GetStatePath() string {
 	return m.statePath
 }
 
+// EventHook, when set, is called after UpdateResource saves a resource whose newest history
+// event was not in state before. cmd uses it to forward state changes to event sinks.
+var EventHook func(instance string, resource *Resource, event ResourceEvent)
+
 // UpdateResource loads state, updates a resource under the active instance, and saves.
 // Stamps the active product onto the InstanceState if not already set.
 func (m *Manager) UpdateResource(resource *Resource) error {
@@ -231,9 +235,19 @@ func (m *Manager) UpdateResource(resource *Resource) error {
 	if inst.Product == "" {
 		inst.Product = activeProduct()
 	}
+	newEvent := len(resource.History) > 0
+	if existing, ok := state.GetResource(instName, resource.Name); ok && newEvent && len(existing.History) > 0 {
+		newEvent = !existing.History[0].Timestamp.Equal(resource.History[0].Timestamp)
+	}
 	state.SetResource(instName, resource)
 
-	return m.Save(state)
+	if err := m.Save(state); err != nil {
+		return err
+	}
+	if newEvent && EventHook != nil {
+		EventHook(instName, resource, resource.History[0])
+	}
+	return nil
 }
 
 // RemoveResource loads state, removes a resource from the active instance, and saves
@@ -273,6 +287,44 @@ func (m *Manager) ListResources(resourceType string) ([]*Resource, error) {
 	return state.ListResources(activeInstance(), resourceType), nil
 }
 
+// ListAcks loads state and returns the acknowledgements of the active instance
+func (m *Manager) ListAcks() ([]Acknowledgement, error) {
+	state, err := m.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	return state.ListAcks(activeInstance()), nil
+}
+
+// SetAck loads state, adds or replaces an acknowledgement on the active instance, and saves
+func (m *Manager) SetAck(ack Acknowledgement) error {
+	state, err := m.Load()
+	if err != nil {
+		return err
+	}
+
+	state.SetAck(activeInstance(), ack)
+
+	return m.Save(state)
+}
+
+// RemoveAcks loads state, removes the matching acknowledgements from the active instance,
+// and saves. It returns how many were removed.
+func (m *Manager) RemoveAcks(remove func(Acknowledgement) bool) (int, error) {
+	state, err := m.Load()
+	if err != nil {
+		return 0, err
+	}
+
+	removed := state.DeleteAcks(activeInstance(), remove)
+	if removed == 0 {
+		return 0, nil
+	}
+
+	return removed, m.Save(state)
+}
+
 // stateExists checks if the state file exists
 func (m *Manager) StateExists() bool {
 	_, err := os.Stat(m.statePath)
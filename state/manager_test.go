@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -304,6 +305,38 @@ func TestUpdateResource(t *testing.T) {
 	}
 }
 
+func TestUpdateResourceCallsEventHook(t *testing.T) {
+	_, cleanup := setupManagerTest(t)
+	defer cleanup()
+
+	var got []string
+	EventHook = func(instance string, r *Resource, ev ResourceEvent) {
+		got = append(got, instance+"/"+r.Name+"/"+ev.Action)
+	}
+	defer func() { EventHook = nil }()
+
+	m := NewManager()
+	r := &Resource{Type: "VBRJob", ID: "job-1", Name: "MyJob"}
+	r.AddEvent(NewEvent("snapshotted", "alice"))
+	if err := m.UpdateResource(r); err != nil {
+		t.Fatalf("UpdateResource failed: %v", err)
+	}
+
+	// Saving again without a new event does not repeat it
+	if err := m.UpdateResource(r); err != nil {
+		t.Fatalf("UpdateResource failed: %v", err)
+	}
+
+	r.AddEvent(NewEvent("applied", "bob"))
+	if err := m.UpdateResource(r); err != nil {
+		t.Fatalf("UpdateResource failed: %v", err)
+	}
+
+	if strings.Join(got, ",") != "default/MyJob/snapshotted,default/MyJob/applied" {
+		t.Errorf("Unexpected hook calls: %v", got)
+	}
+}
+
 func TestUpdateResourceOverwrites(t *testing.T) {
 	_, cleanup := setupManagerTest(t)
 	defer cleanup()
@@ -1,6 +1,9 @@
 package state
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // CurrentStateVersion is the latest state file format version.
 // Increment when changing the Resource schema and add a migration in manager.go.
@@ -13,6 +16,7 @@ const DefaultMaxHistoryEvents = 20
 type InstanceState struct {
 	Product   string               `json:"product,omitempty"` // e.g. "vbr", "azure" — used for export folder structure
 	Resources map[string]*Resource `json:"resources"`
+	Acks      []Acknowledgement    `json:"acks,omitempty"` // Accepted drift, suppressed until expiry
 }
 
 // State represents the owlctl state file structure
@@ -29,11 +33,12 @@ type State struct {
 
 // ResourceEvent represents an action taken on a resource
 type ResourceEvent struct {
-	Action    string    `json:"action"`           // "snapshotted", "adopted", "applied", "created"
+	Action    string    `json:"action"`           // "snapshotted", "adopted", "applied", "created", "remediated", "acknowledged", "unacknowledged"
 	Timestamp time.Time `json:"timestamp"`        // When the action occurred
 	User      string    `json:"user"`             // Who performed the action
 	Fields    []string  `json:"fields,omitempty"` // Fields that were changed (for apply/created)
 	Partial   bool      `json:"partial,omitempty"` // Reserved for future partial-apply support; currently always false
+	Note      string    `json:"note,omitempty"`    // Details, e.g. a drift acknowledgement's expiry and reason
 }
 
 // Resource represents a managed resource in state
@@ -44,10 +49,37 @@ type Resource struct {
 	LastApplied   time.Time              `json:"lastApplied"`           // When it was last applied
 	LastAppliedBy string                 `json:"lastAppliedBy"`         // User who applied it
 	Origin        string                 `json:"origin"`                // "applied" (declarative) or "observed" (snapshot)
+	Labels        map[string]string      `json:"labels,omitempty"`      // Labels from the applied spec's metadata
 	Spec          map[string]interface{} `json:"spec"`                  // The applied configuration
 	History       []ResourceEvent        `json:"history,omitempty"`     // Audit trail of actions
 }
 
+// Acknowledgement accepts drift on a resource field until it expires. Drift on the field,
+// or on any field below it, is suppressed by diff and scan while the ack is active.
+type Acknowledgement struct {
+	Kind      string    `json:"kind,omitempty"` // Resource type, recorded for reports
+	Resource  string    `json:"resource"`       // Resource name
+	Path      string    `json:"path"`           // Dotted field path, e.g. "storage.retentionPolicy"
+	Until     time.Time `json:"until"`          // Expiry; drift is reported again from this time
+	Reason    string    `json:"reason"`         // Why the drift is accepted
+	User      string    `json:"user"`           // Who acknowledged it
+	CreatedAt time.Time `json:"createdAt"`      // When it was acknowledged
+}
+
+// Active reports whether the acknowledgement has not yet expired at now
+func (a Acknowledgement) Active(now time.Time) bool {
+	return now.Before(a.Until)
+}
+
+// Covers reports whether the acknowledgement applies to a field of the named resource.
+// An ack on a parent path covers all fields below it.
+func (a Acknowledgement) Covers(resource, path string) bool {
+	if a.Resource != resource {
+		return false
+	}
+	return path == a.Path || strings.HasPrefix(path, a.Path+".")
+}
+
 // NewState creates a new empty state
 func NewState() *State {
 	return &State{
@@ -113,6 +145,49 @@ func (s *State) ListResources(instance, resourceType string) []*Resource {
 	return resources
 }
 
+// ListAcks returns the acknowledgements of the given instance
+func (s *State) ListAcks(instance string) []Acknowledgement {
+	inst, ok := s.Instances[instance]
+	if !ok || inst == nil {
+		return nil
+	}
+	return inst.Acks
+}
+
+// SetAck adds an acknowledgement to the given instance, replacing any ack on the same
+// resource and path
+func (s *State) SetAck(instance string, ack Acknowledgement) {
+	inst := s.getInstance(instance)
+	for i, existing := range inst.Acks {
+		if existing.Resource == ack.Resource && existing.Path == ack.Path {
+			inst.Acks[i] = ack
+			return
+		}
+	}
+	inst.Acks = append(inst.Acks, ack)
+}
+
+// DeleteAcks removes the acknowledgements of the given instance for which remove returns
+// true and returns how many were removed
+func (s *State) DeleteAcks(instance string, remove func(Acknowledgement) bool) int {
+	inst, ok := s.Instances[instance]
+	if !ok || inst == nil {
+		return 0
+	}
+	kept := inst.Acks[:0]
+	for _, ack := range inst.Acks {
+		if !remove(ack) {
+			kept = append(kept, ack)
+		}
+	}
+	removed := len(inst.Acks) - len(kept)
+	inst.Acks = kept
+	if len(inst.Acks) == 0 {
+		inst.Acks = nil
+	}
+	return removed
+}
+
 // AddEvent records a new event to the resource's history and prunes old events
 func (r *Resource) AddEvent(event ResourceEvent) {
 	// Prepend new event (most recent first)
@@ -251,3 +251,57 @@ func TestNewEventWithFields(t *testing.T) {
 		t.Error("Expected Partial=true")
 	}
 }
+
+func TestAcknowledgementCovers(t *testing.T) {
+	ack := Acknowledgement{Resource: "Nightly", Path: "storage.retentionPolicy"}
+
+	tests := []struct {
+		resource, path string
+		want           bool
+	}{
+		{"Nightly", "storage.retentionPolicy", true},
+		{"Nightly", "storage.retentionPolicy.quantity", true},
+		{"Nightly", "storage.retentionPolicyType", false},
+		{"Nightly", "storage", false},
+		{"Weekly", "storage.retentionPolicy", false},
+	}
+	for _, tt := range tests {
+		if got := ack.Covers(tt.resource, tt.path); got != tt.want {
+			t.Errorf("Covers(%s, %s) = %v, want %v", tt.resource, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestAcknowledgementActive(t *testing.T) {
+	until := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	ack := Acknowledgement{Until: until}
+	if !ack.Active(until.Add(-time.Second)) {
+		t.Error("Expected ack to be active before expiry")
+	}
+	if ack.Active(until) {
+		t.Error("Expected ack to expire at Until")
+	}
+}
+
+func TestStateSetAndDeleteAcks(t *testing.T) {
+	s := NewState()
+	s.SetAck("prod", Acknowledgement{Resource: "Nightly", Path: "isDisabled", Reason: "maintenance"})
+	s.SetAck("prod", Acknowledgement{Resource: "Nightly", Path: "description"})
+	s.SetAck("prod", Acknowledgement{Resource: "Nightly", Path: "isDisabled", Reason: "extended"})
+
+	acks := s.ListAcks("prod")
+	if len(acks) != 2 || acks[0].Reason != "extended" {
+		t.Fatalf("Expected the isDisabled ack to be replaced, got %+v", acks)
+	}
+	if s.ListAcks("dr") != nil {
+		t.Error("Expected no acks for another instance")
+	}
+
+	removed := s.DeleteAcks("prod", func(a Acknowledgement) bool { return a.Path == "isDisabled" })
+	if removed != 1 || len(s.ListAcks("prod")) != 1 {
+		t.Errorf("Expected 1 ack removed, got %d, remaining %+v", removed, s.ListAcks("prod"))
+	}
+	if s.DeleteAcks("dr", func(Acknowledgement) bool { return true }) != 0 {
+		t.Error("Expected nothing removed from a missing instance")
+	}
+}
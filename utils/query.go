@@ -0,0 +1,193 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/jmespath/go-jmespath"
+)
+
+// Query applies a JMESPath expression (e.g. data[].name) to decoded JSON data.
+//
+// Expressions starting with "$" are JSONPath and are translated to JMESPath. The common subset
+// is supported: .child and ['child'] access, [n] indexes, [a:b] slices, [*] and .* wildcards,
+// and [?(@.field op value)] filters. Recursive descent (..) is not.
+func Query(data interface{}, expr string) (interface{}, error) {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "$") {
+		translated, err := jsonPathToJMESPath(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSONPath %q: %w", expr, err)
+		}
+		expr = translated
+	}
+	if expr == "" || expr == "@" {
+		return data, nil
+	}
+
+	result, err := jmespath.Search(expr, data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query %q: %w", expr, err)
+	}
+	return result, nil
+}
+
+// jsonPathToJMESPath translates a JSONPath expression to the equivalent JMESPath
+func jsonPathToJMESPath(path string) (string, error) {
+	path = strings.TrimPrefix(path, "$")
+	if strings.Contains(path, "..") {
+		return "", fmt.Errorf("recursive descent (..) is not supported")
+	}
+
+	var out strings.Builder
+	for i := 0; i < len(path); {
+		switch path[i] {
+		case '.':
+			i++
+			if out.Len() > 0 {
+				out.WriteByte('.')
+			}
+		case '[':
+			end := closingBracket(path, i)
+			if end < 0 {
+				return "", fmt.Errorf("unterminated [")
+			}
+			inner := strings.TrimSpace(path[i+1 : end])
+			i = end + 1
+
+			switch {
+			case strings.HasPrefix(inner, "?"):
+				filter, err := translateFilter(inner[1:])
+				if err != nil {
+					return "", err
+				}
+				out.WriteString("[?" + filter + "]")
+			case isQuoted(inner):
+				// ['name'] member access
+				if out.Len() > 0 {
+					out.WriteByte('.')
+				}
+				out.WriteString(jmespathIdentifier(inner[1 : len(inner)-1]))
+			default:
+				// Index, slice or wildcard: the syntax is the same
+				out.WriteString("[" + inner + "]")
+			}
+		default:
+			start := i
+			for i < len(path) && path[i] != '.' && path[i] != '[' {
+				i++
+			}
+			name := path[start:i]
+			if name == "*" {
+				out.WriteString("*")
+			} else {
+				out.WriteString(jmespathIdentifier(name))
+			}
+		}
+	}
+	return out.String(), nil
+}
+
+// translateFilter converts a JSONPath filter body, e.g. (@.type == 'Backup' && @.size > 10),
+// to JMESPath: @.field becomes field, strings become raw string literals and numbers, booleans
+// and null become JSON literals.
+func translateFilter(filter string) (string, error) {
+	filter = strings.TrimSpace(filter)
+	if strings.HasPrefix(filter, "(") && strings.HasSuffix(filter, ")") {
+		filter = filter[1 : len(filter)-1]
+	}
+
+	var out strings.Builder
+	for i := 0; i < len(filter); {
+		c := filter[i]
+		switch {
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(filter[i+1:], c)
+			if end < 0 {
+				return "", fmt.Errorf("unterminated string in filter")
+			}
+			value := filter[i+1 : i+1+end]
+			out.WriteString("'" + strings.ReplaceAll(value, "'", `\'`) + "'")
+			i += end + 2
+		case c == '@':
+			if strings.HasPrefix(filter[i:], "@.") {
+				i += 2
+			} else {
+				out.WriteByte('@')
+				i++
+			}
+		case c == '-' || unicode.IsDigit(rune(c)) || unicode.IsLetter(rune(c)) || c == '_':
+			start := i
+			i++
+			for i < len(filter) && (unicode.IsLetter(rune(filter[i])) || unicode.IsDigit(rune(filter[i])) ||
+				filter[i] == '_' || filter[i] == '.' && unicode.IsDigit(rune(filter[i-1]))) {
+				i++
+			}
+			word := filter[start:i]
+			switch {
+			case word == "true" || word == "false" || word == "null" || isNumber(word):
+				out.WriteString("`" + word + "`")
+			default:
+				out.WriteString(word)
+			}
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// closingBracket finds the ] matching the [ at start, skipping quoted strings and nested brackets
+func closingBracket(s string, start int) int {
+	depth := 0
+	var quote byte
+	for i := start; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func isQuoted(s string) bool {
+	return len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0]
+}
+
+func isNumber(s string) bool {
+	digits := 0
+	for i, c := range s {
+		switch {
+		case unicode.IsDigit(c):
+			digits++
+		case c == '-' && i == 0, c == '.':
+		default:
+			return false
+		}
+	}
+	return digits > 0
+}
+
+// jmespathIdentifier quotes a member name when it isn't a plain JMESPath identifier
+func jmespathIdentifier(name string) string {
+	for i, c := range name {
+		if !(unicode.IsLetter(c) || c == '_' || i > 0 && unicode.IsDigit(c)) {
+			return fmt.Sprintf("%q", name)
+		}
+	}
+	return name
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const queryTestResponse = `{
+  "data": [
+    {"name": "SQL Backup", "type": "VSphereBackup", "isDisabled": false, "storage": {"retention": 7}, "my-tag": "a"},
+    {"name": "Web Backup", "type": "VSphereBackup", "isDisabled": true, "storage": {"retention": 14}, "my-tag": "b"},
+    {"name": "Agent Backup", "type": "WindowsAgentBackup", "isDisabled": false, "storage": {"retention": 30}, "my-tag": "c"}
+  ],
+  "pagination": {"total": 3}
+}`
+
+func TestQuery(t *testing.T) {
+	var data interface{}
+	if err := json.Unmarshal([]byte(queryTestResponse), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		want interface{}
+	}{
+		{"jmespath projection", "data[].name", []interface{}{"SQL Backup", "Web Backup", "Agent Backup"}},
+		{"jmespath filter", "data[?isDisabled].name", []interface{}{"Web Backup"}},
+		{"jmespath scalar", "pagination.total", 3.0},
+		{"jsonpath wildcard", "$.data[*].name", []interface{}{"SQL Backup", "Web Backup", "Agent Backup"}},
+		{"jsonpath index", "$.data[1].name", "Web Backup"},
+		{"jsonpath negative index", "$.data[-1].name", "Agent Backup"},
+		{"jsonpath slice", "$.data[0:2].name", []interface{}{"SQL Backup", "Web Backup"}},
+		{"jsonpath bracket member", "$['data'][0]['my-tag']", "a"},
+		{"jsonpath string filter", "$.data[?(@.type == 'WindowsAgentBackup')].name", []interface{}{"Agent Backup"}},
+		{"jsonpath double-quoted filter", `$.data[?(@.type == "WindowsAgentBackup")].name`, []interface{}{"Agent Backup"}},
+		{"jsonpath numeric filter", "$.data[?(@.storage.retention > 10)].name", []interface{}{"Web Backup", "Agent Backup"}},
+		{"jsonpath boolean filter", "$.data[?(@.isDisabled == false && @.storage.retention < 10)].name", []interface{}{"SQL Backup"}},
+		{"jsonpath root", "$", data},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Query(data, tt.expr)
+			if err != nil {
+				t.Fatalf("Query(%q): %v", tt.expr, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Query(%q) = %#v, want %#v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuery_Invalid(t *testing.T) {
+	for _, expr := range []string{"data[", "$..name", "$.data[0"} {
+		if _, err := Query(map[string]interface{}{}, expr); err == nil {
+			t.Errorf("Query(%q): expected error", expr)
+		}
+	}
+}
@@ -40,6 +40,42 @@ func PostDataWithError(url string, data interface{}, profile models.Profile) ([]
 
 // sendRequestWithError is like sendRequest but returns errors instead of calling log.Fatal()
 func sendRequestWithError(method string, url string, data interface{}, profile models.Profile) ([]byte, error) {
+	var payload []byte
+	if data != nil {
+		jsonData, err := json.Marshal(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		payload = jsonData
+	}
+
+	res, err := Send(method, url, payload, profile)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.Body, res.Error()
+	}
+	return res.Body, nil
+}
+
+// Response is the raw result of a request sent with Send
+type Response struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+	Body       []byte
+}
+
+// Error describes a non-2xx response
+func (r *Response) Error() error {
+	return fmt.Errorf("HTTP %d: %s %s\nResponse: %s", r.StatusCode, r.Method, r.URL, string(r.Body))
+}
+
+// Send sends a request with an already encoded JSON payload (nil for none) and returns the
+// response whatever its status code. The error covers only failures to send the request.
+func Send(method string, url string, payload []byte, profile models.Profile) (*Response, error) {
 	settings := utils.ReadSettings()
 
 	// With v1.0 profiles, credentials are always from environment variables
@@ -54,12 +90,8 @@ func sendRequestWithError(method string, url string, data interface{}, profile m
 	connstring := fmt.Sprintf("https://%v:%v%v/%v", api_url, profile.Port, profile.Endpoints.APIPrefix, url)
 
 	var reqBody io.Reader
-	if data != nil {
-		jsonData, err := json.Marshal(data)
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal request body: %w", err)
-		}
-		reqBody = bytes.NewReader(jsonData)
+	if payload != nil {
+		reqBody = bytes.NewReader(payload)
 	}
 
 	r, err := http.NewRequest(method, connstring, reqBody)
@@ -94,11 +126,13 @@ func sendRequestWithError(method string, url string, data interface{}, profile m
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	if res.StatusCode < 200 || res.StatusCode >= 300 {
-		return body, fmt.Errorf("HTTP %d: %s %s\nResponse: %s", res.StatusCode, method, connstring, string(body))
-	}
-
-	return body, nil
+	return &Response{
+		Method:     method,
+		URL:        connstring,
+		StatusCode: res.StatusCode,
+		Status:     res.Status,
+		Body:       body,
+	}, nil
 }
 
 // DeleteData sends a DELETE request